@@ -0,0 +1,136 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// HandlerOptions configures Handler.
+type HandlerOptions struct {
+	// Options configures the history table and migrator, as for RunMigrationsWithOptions.
+	Options Options
+	// Authorize is called before every request and rejects it with 401 Unauthorized when it
+	// returns an error, e.g. when a bearer token or client certificate is missing. Required:
+	// without it every request is rejected.
+	Authorize func(r *http.Request) error
+}
+
+// adminResponse is the JSON body written by Handler.
+type adminResponse struct {
+	Status *MigrationStatus `json:"status,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// admin serves the endpoints of Handler.
+type admin struct {
+	db         *gorm.DB
+	migrations []*Migration
+	opts       HandlerOptions
+	// running is held while up or down runs, so concurrent requests don't race each other
+	running sync.Mutex
+}
+
+// Handler returns an http.Handler for inspecting and running migrations on an internal admin port:
+//
+//	GET  /status     the applied, pending and gated migrations
+//	POST /up         apply the pending migrations
+//	POST /down/{id}  roll back to migration id, like down --id
+//
+// Every endpoint responds with JSON holding the status after the request. A request arriving
+// while up or down is running gets 409 Conflict. Mount it with http.StripPrefix under a path
+// prefix, and always set HandlerOptions.Authorize. Like StartWithOptions, it fails on duplicate
+// migration IDs and orders the migrations by DependsOn, so down rolls back in that order.
+func Handler(db *gorm.DB, migrations []*Migration, opts HandlerOptions) (http.Handler, error) {
+	if err := checkDuplicateIDs(migrations); err != nil {
+		return nil, err
+	}
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	a := &admin{db: db, migrations: sorted, opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", a.handleStatus)
+	mux.HandleFunc("POST /up", a.handleUp)
+	mux.HandleFunc("POST /down/{id}", a.handleDown)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Authorize == nil {
+			writeAdminResponse(w, http.StatusUnauthorized, adminResponse{Error: "no Authorize hook configured"})
+			return
+		}
+		if err := opts.Authorize(r); err != nil {
+			writeAdminResponse(w, http.StatusUnauthorized, adminResponse{Error: err.Error()})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+func (a *admin) handleStatus(w http.ResponseWriter, r *http.Request) {
+	a.respond(w, nil)
+}
+
+func (a *admin) handleUp(w http.ResponseWriter, r *http.Request) {
+	if !a.running.TryLock() {
+		writeAdminResponse(w, http.StatusConflict, adminResponse{Error: "a migration is already running"})
+		return
+	}
+	defer a.running.Unlock()
+	a.respond(w, RunMigrationsWithOptions(a.db.WithContext(r.Context()), a.migrations, a.opts.Options))
+}
+
+func (a *admin) handleDown(w http.ResponseWriter, r *http.Request) {
+	if !a.running.TryLock() {
+		writeAdminResponse(w, http.StatusConflict, adminResponse{Error: "a migration is already running"})
+		return
+	}
+	defer a.running.Unlock()
+	a.respond(w, rollbackTo(a.db.WithContext(r.Context()), a.migrations, a.opts.Options, r.PathValue("id")))
+}
+
+// respond writes the current status, together with err if the request failed.
+func (a *admin) respond(w http.ResponseWriter, err error) {
+	var resp adminResponse
+	code := http.StatusOK
+	if err != nil {
+		resp.Error = err.Error()
+		code = http.StatusInternalServerError
+		if errors.Is(err, gormigrate.ErrMigrationIDDoesNotExist) {
+			code = http.StatusNotFound
+		}
+	}
+	status, statusErr := GetStatus(a.db, a.migrations, a.opts.Options)
+	if statusErr != nil {
+		resp.Error = errors.Join(err, statusErr).Error()
+		code = http.StatusInternalServerError
+	}
+	resp.Status = status
+	writeAdminResponse(w, code, resp)
+}
+
+// writeAdminResponse writes resp as JSON with the given status code.
+func writeAdminResponse(w http.ResponseWriter, code int, resp adminResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rollbackTo rolls back the applied migrations after id, the library equivalent of down --id.
+func rollbackTo(db *gorm.DB, migrations []*Migration, opts Options, id string) error {
+	opts = opts.withDefaults()
+	defer invalidateStatusCaches()
+	return withSessionTimeouts(db, opts, func(conn *gorm.DB) error {
+		if err := getMigrator(conn, withHooks(migrations, opts), opts).RollbackTo(id); err != nil {
+			return fmt.Errorf("failed to rollback to migration: %w", err)
+		}
+		out.Printf("✅ Rollback to migration: %s complete.\n", id)
+		return nil
+	})
+}
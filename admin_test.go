@@ -0,0 +1,107 @@
+package gormeasy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestHandlerAuthorize tests that requests are rejected unless Authorize accepts them
+func TestHandlerAuthorize(t *testing.T) {
+	h, err := Handler(nil, nil, HandlerOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/up", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without Authorize, got %d", rec.Code)
+	}
+
+	h, err = Handler(nil, nil, HandlerOptions{Authorize: func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return errors.New("invalid token")
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/down/1", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with an invalid token, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/up", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET /up, got %d", rec.Code)
+	}
+}
+
+// TestHandlerConflict tests that up and down are refused while another run is in progress
+func TestHandlerConflict(t *testing.T) {
+	a := &admin{}
+	a.running.Lock()
+	defer a.running.Unlock()
+
+	rec := httptest.NewRecorder()
+	a.handleUp(rec, httptest.NewRequest(http.MethodPost, "/up", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for up, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.handleDown(rec, httptest.NewRequest(http.MethodPost, "/down/1", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for down, got %d", rec.Code)
+	}
+}
+
+// TestHandlerSortsMigrations tests that down rolls back in DependsOn order, and that invalid
+// migrations are refused when the handler is created
+func TestHandlerSortsMigrations(t *testing.T) {
+	saved := out
+	out = &output{level: levelQuiet, w: &strings.Builder{}, errW: &strings.Builder{}}
+	defer func() { out = saved }()
+
+	if _, err := Handler(nil, []*Migration{{ID: "001"}, {ID: "001"}}, HandlerOptions{}); err == nil {
+		t.Error("Expected an error for duplicate IDs")
+	}
+	if _, err := Handler(nil, []*Migration{{ID: "001", DependsOn: []string{"002"}}, {ID: "002", DependsOn: []string{"001"}}}, HandlerOptions{}); err == nil {
+		t.Error("Expected an error for a dependency cycle")
+	}
+
+	var rolledBack []string
+	migration := func(id string, dependsOn ...string) *Migration {
+		return &Migration{
+			ID:        id,
+			DependsOn: dependsOn,
+			Migrate:   func(tx *gorm.DB) error { return nil },
+			Rollback:  func(tx *gorm.DB) error { rolledBack = append(rolledBack, id); return nil },
+		}
+	}
+	db := openSQLite(t)
+	h, err := Handler(db, []*Migration{migration("003", "002"), migration("002", "001"), migration("001")},
+		HandlerOptions{Authorize: func(r *http.Request) error { return nil }})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, path := range []string{"/up", "/down/001"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", path, rec.Code, rec.Body)
+		}
+	}
+	if !slices.Equal(rolledBack, []string{"003", "002"}) {
+		t.Errorf("Expected [003 002] rolled back, got %v", rolledBack)
+	}
+}
@@ -0,0 +1,201 @@
+package gormeasy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// anonymizeSecretEnvVar holds the secret of the anonymize hashes when Options.AnonymizeSecret
+// is not set.
+const anonymizeSecretEnvVar = "GORMEASY_ANONYMIZE_SECRET"
+
+// AnonymizeStrategy is how Anonymize replaces the values of a column.
+type AnonymizeStrategy string
+
+const (
+	// AnonymizeMask replaces values with "masked-" and a hash of the value.
+	AnonymizeMask AnonymizeStrategy = "mask"
+	// AnonymizeEmail replaces values with a fake address like user-<hash>@example.com.
+	AnonymizeEmail AnonymizeStrategy = "email"
+	// AnonymizeName replaces values with a fake name like "User <hash>".
+	AnonymizeName AnonymizeStrategy = "name"
+	// AnonymizePhone replaces values with a fake phone number like +15550123456.
+	AnonymizePhone AnonymizeStrategy = "phone"
+	// AnonymizeNull replaces values with NULL.
+	AnonymizeNull AnonymizeStrategy = "null"
+)
+
+// AnonymizeRule replaces the values of a column holding personal data.
+type AnonymizeRule struct {
+	Table    string            `json:"table"`
+	Column   string            `json:"column"`
+	Strategy AnonymizeStrategy `json:"strategy"`
+}
+
+// Anonymize replaces the values of the columns of rules in place, e.g. on a copy of production
+// data for developers. Values are derived from an HMAC-SHA256 of the original value keyed with
+// secret, so equal values stay equal and unique columns stay unique, while the originals
+// cannot be recovered by hashing guesses without the secret. With the same secret, the same
+// text gives the same result in the database and in export-fixtures. An empty secret is
+// replaced with a random one per process. NULL stays NULL. All tables are updated in one
+// transaction. Only PostgreSQL and MySQL are supported, other databases support the null
+// strategy only.
+func Anonymize(db *gorm.DB, rules []AnonymizeRule, secret string) error {
+	if err := validateAnonymizeRules(rules); err != nil {
+		return err
+	}
+	var tables []string
+	sets := make(map[string][]string)
+	for _, rule := range rules {
+		expr, err := anonymizeSQL(db.Dialector.Name(), rule.Strategy, db.Statement.Quote(rule.Column), secret)
+		if err != nil {
+			return err
+		}
+		if sets[rule.Table] == nil {
+			tables = append(tables, rule.Table)
+		}
+		sets[rule.Table] = append(sets[rule.Table], db.Statement.Quote(rule.Column)+" = "+expr)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			result := tx.Exec(fmt.Sprintf("UPDATE %s SET %s", tx.Statement.Quote(table), strings.Join(sets[table], ", ")))
+			if result.Error != nil {
+				return fmt.Errorf("failed to anonymize table %s: %w", table, result.Error)
+			}
+			out.Printf("  - %s: %d rows\n", table, result.RowsAffected)
+		}
+		out.Printf("✅ Anonymized %d columns\n", len(rules))
+		return nil
+	})
+}
+
+// validateAnonymizeRules returns an error for incomplete rules and unknown strategies.
+func validateAnonymizeRules(rules []AnonymizeRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("no anonymize rules configured, set Options.Anonymize or the anonymize key of gormeasy.json")
+	}
+	for _, rule := range rules {
+		if rule.Table == "" || rule.Column == "" {
+			return fmt.Errorf("anonymize rule %+v needs a table and a column", rule)
+		}
+		switch rule.Strategy {
+		case AnonymizeMask, AnonymizeEmail, AnonymizeName, AnonymizePhone, AnonymizeNull:
+		default:
+			return fmt.Errorf("unknown anonymize strategy %q of %s.%s, use mask, email, name, phone or null", rule.Strategy, rule.Table, rule.Column)
+		}
+	}
+	return nil
+}
+
+// anonymizeRulesFor returns the strategy of each of columns of table, empty for columns without rule.
+func anonymizeRulesFor(rules []AnonymizeRule, table string, columns []string) []AnonymizeStrategy {
+	strategies := make([]AnonymizeStrategy, len(columns))
+	for _, rule := range rules {
+		for i, column := range columns {
+			if rule.Table == table && rule.Column == column {
+				strategies[i] = rule.Strategy
+			}
+		}
+	}
+	return strategies
+}
+
+// randomAnonymizeKey is the key of the anonymize hashes when no secret is configured, so
+// equal values stay equal within a run.
+var randomAnonymizeKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+})
+
+// anonymizePads returns the inner and outer padded keys of the HMAC-SHA256 keyed with secret,
+// or with randomAnonymizeKey when secret is empty. The HMAC of m is
+// sha256(opad || sha256(ipad || m)), which databases compute without extensions.
+func anonymizePads(secret string) (ipad, opad []byte) {
+	key := []byte(secret)
+	if secret == "" {
+		key = randomAnonymizeKey()
+	}
+	if len(key) > sha256.BlockSize {
+		sum := sha256.Sum256(key)
+		key = sum[:]
+	}
+	ipad, opad = make([]byte, sha256.BlockSize), make([]byte, sha256.BlockSize)
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	return ipad, opad
+}
+
+// anonymizeSQL returns the SQL expression replacing column, a quoted column name, with strategy,
+// hashing with the HMAC keyed with secret.
+func anonymizeSQL(dialect string, strategy AnonymizeStrategy, column, secret string) (string, error) {
+	if strategy == AnonymizeNull {
+		return "NULL", nil
+	}
+	ipad, opad := anonymizePads(secret)
+	switch dialect {
+	case "postgres":
+		hash := fmt.Sprintf("encode(sha256(decode('%x', 'hex') || sha256(decode('%x', 'hex') || convert_to(%s::text, 'UTF8'))), 'hex')", opad, ipad, column)
+		switch strategy {
+		case AnonymizeMask:
+			return fmt.Sprintf("'masked-' || left(%s, 12)", hash), nil
+		case AnonymizeEmail:
+			return fmt.Sprintf("'user-' || left(%s, 12) || '@example.com'", hash), nil
+		case AnonymizeName:
+			return fmt.Sprintf("'User ' || left(%s, 8)", hash), nil
+		case AnonymizePhone:
+			return fmt.Sprintf("'+1555' || lpad(((('x' || left(%s, 6))::bit(24)::int) %% 10000000)::text, 7, '0')", hash), nil
+		}
+	case "mysql":
+		hash := fmt.Sprintf("SHA2(CONCAT(UNHEX('%x'), UNHEX(SHA2(CONCAT(UNHEX('%x'), CAST(%s AS CHAR)), 256))), 256)", opad, ipad, column)
+		switch strategy {
+		case AnonymizeMask:
+			return fmt.Sprintf("CONCAT('masked-', LEFT(%s, 12))", hash), nil
+		case AnonymizeEmail:
+			return fmt.Sprintf("CONCAT('user-', LEFT(%s, 12), '@example.com')", hash), nil
+		case AnonymizeName:
+			return fmt.Sprintf("CONCAT('User ', LEFT(%s, 8))", hash), nil
+		case AnonymizePhone:
+			return fmt.Sprintf("CONCAT('+1555', LPAD(CONV(LEFT(%s, 6), 16, 10) %% 10000000, 7, '0'))", hash), nil
+		}
+	}
+	return "", fmt.Errorf("anonymize strategy %s is not supported for %s. Currently supported: PostgreSQL, MySQL", strategy, dialect)
+}
+
+// anonymizeValue returns v replaced with strategy, the same as anonymizeSQL for text values.
+func anonymizeValue(strategy AnonymizeStrategy, v any, secret string) any {
+	if v == nil || strategy == AnonymizeNull {
+		return nil
+	}
+	key := []byte(secret)
+	if secret == "" {
+		key = randomAnonymizeKey()
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fmt.Sprint(v)))
+	hash := hex.EncodeToString(mac.Sum(nil))
+	switch strategy {
+	case AnonymizeEmail:
+		return "user-" + hash[:12] + "@example.com"
+	case AnonymizeName:
+		return "User " + hash[:8]
+	case AnonymizePhone:
+		n, _ := strconv.ParseInt(hash[:6], 16, 64)
+		return fmt.Sprintf("+1555%07d", n%10000000)
+	default:
+		return "masked-" + hash[:12]
+	}
+}
@@ -0,0 +1,100 @@
+package gormeasy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestAnonymizeValue tests that anonymized values keep NULL and equal values
+func TestAnonymizeValue(t *testing.T) {
+	for _, strategy := range []AnonymizeStrategy{AnonymizeMask, AnonymizeEmail, AnonymizeName, AnonymizePhone} {
+		if anonymizeValue(strategy, nil, "s3cret") != nil {
+			t.Errorf("Expected NULL to stay NULL with %s", strategy)
+		}
+		a, b := anonymizeValue(strategy, "alice@example.com", "s3cret"), anonymizeValue(strategy, "bob@example.com", "s3cret")
+		if a == b || a != anonymizeValue(strategy, "alice@example.com", "s3cret") {
+			t.Errorf("Expected distinct values to stay distinct and equal values equal with %s, got %v and %v", strategy, a, b)
+		}
+		if a == anonymizeValue(strategy, "alice@example.com", "other") {
+			t.Errorf("Expected another secret to give another value with %s", strategy)
+		}
+	}
+	// HMAC-SHA256("s3cret", "alice@example.com") = 578cae3dea73e06490ba447ab961d5219f0e379c2ce3610b8dcd7ee36a39e53d
+	tests := map[AnonymizeStrategy]any{
+		AnonymizeMask:  "masked-578cae3dea73",
+		AnonymizeEmail: "user-578cae3dea73@example.com",
+		AnonymizeName:  "User 578cae3d",
+		AnonymizePhone: "+15555737646",
+		AnonymizeNull:  nil,
+	}
+	for strategy, want := range tests {
+		if got := anonymizeValue(strategy, "alice@example.com", "s3cret"); got != want {
+			t.Errorf("Expected %v with %s, got %v", want, strategy, got)
+		}
+	}
+	if anonymizeValue(AnonymizeMask, "alice@example.com", "") != anonymizeValue(AnonymizeMask, "alice@example.com", "") {
+		t.Error("Expected equal values to stay equal within a run without secret")
+	}
+}
+
+// TestAnonymizePads tests that the padded keys used in SQL compute the HMAC of anonymizeValue
+func TestAnonymizePads(t *testing.T) {
+	for _, secret := range []string{"s3cret", strings.Repeat("long", 20)} {
+		ipad, opad := anonymizePads(secret)
+		inner := sha256.Sum256(append(ipad, "alice@example.com"...))
+		outer := sha256.Sum256(append(opad, inner[:]...))
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("alice@example.com"))
+		if !hmac.Equal(outer[:], mac.Sum(nil)) {
+			t.Errorf("Expected the padded keys to compute the HMAC with a secret of %d bytes", len(secret))
+		}
+	}
+}
+
+// TestAnonymizeSQL tests the anonymize expressions of each dialect
+func TestAnonymizeSQL(t *testing.T) {
+	ipad, opad := anonymizePads("s3cret")
+	got, err := anonymizeSQL("postgres", AnonymizeEmail, `"email"`, "s3cret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	hash := fmt.Sprintf(`encode(sha256(decode('%x', 'hex') || sha256(decode('%x', 'hex') || convert_to("email"::text, 'UTF8'))), 'hex')`, opad, ipad)
+	if want := `'user-' || left(` + hash + `, 12) || '@example.com'`; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	got, err = anonymizeSQL("mysql", AnonymizePhone, "`phone`", "s3cret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	hash = fmt.Sprintf("SHA2(CONCAT(UNHEX('%x'), UNHEX(SHA2(CONCAT(UNHEX('%x'), CAST(`phone` AS CHAR)), 256))), 256)", opad, ipad)
+	if want := "CONCAT('+1555', LPAD(CONV(LEFT(" + hash + ", 6), 16, 10) % 10000000, 7, '0'))"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got, err := anonymizeSQL("sqlite", AnonymizeNull, `"phone"`, "s3cret"); err != nil || got != "NULL" {
+		t.Errorf("Expected NULL on sqlite, got %s, %v", got, err)
+	}
+	if _, err := anonymizeSQL("sqlite", AnonymizeMask, `"email"`, "s3cret"); err == nil {
+		t.Error("Expected error for mask on sqlite, got nil")
+	}
+}
+
+// TestValidateAnonymizeRules tests rejecting incomplete rules and unknown strategies
+func TestValidateAnonymizeRules(t *testing.T) {
+	valid := []AnonymizeRule{{Table: "users", Column: "email", Strategy: AnonymizeEmail}}
+	if err := validateAnonymizeRules(valid); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cases := map[string][]AnonymizeRule{
+		"no anonymize rules": nil,
+		"needs a table":      {{Column: "email", Strategy: AnonymizeEmail}},
+		"unknown anonymize":  {{Table: "users", Column: "email", Strategy: "faker"}},
+	}
+	for want, rules := range cases {
+		if err := validateAnonymizeRules(rules); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package gormeasy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BackfillOptions configures Backfill.
+type BackfillOptions struct {
+	// Name identifies the backfill in the checkpoint table. Required.
+	Name string
+	// Table is the table whose rows are processed. Required.
+	Table string
+	// KeyColumn is an integer column with unique, positive values used to split the
+	// table into batches. Defaults to "id".
+	KeyColumn string
+	// BatchSize is the maximum number of rows per batch. Defaults to 1000.
+	BatchSize int
+	// Sleep is the pause between batches, limiting the load on the database.
+	Sleep time.Duration
+	// Context cancels the backfill between batches. Defaults to context.Background().
+	Context context.Context
+	// Batch processes the rows whose key is in (from, to], e.g.
+	// tx.Exec("UPDATE orders SET total = price * qty WHERE id > ? AND id <= ?", from, to).
+	// Each batch runs in its own transaction together with the checkpoint update.
+	Batch func(tx *gorm.DB, from, to int64) error
+}
+
+// ErrBackfillPaused is returned by Backfill when it stops because the backfill was paused
+// with PauseBackfill. Running the backfill again resumes it.
+var ErrBackfillPaused = errors.New("backfill paused")
+
+// backfillCheckpoint records the progress of a backfill.
+type backfillCheckpoint struct {
+	Name    string `gorm:"primaryKey;size:255"`
+	LastKey int64
+	// Paused asks the running backfill to stop after its current batch.
+	Paused      bool
+	CompletedAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName returns the table name of backfill checkpoints.
+func (backfillCheckpoint) TableName() string {
+	return "gormeasy_backfills"
+}
+
+// Backfill processes the rows of a large table in batches of keys, sleeping between batches
+// and printing progress. After each batch the last processed key is saved in the
+// gormeasy_backfills table, so an interrupted, cancelled or paused backfill resumes where it
+// stopped when run again. Once every row is processed the backfill is marked complete;
+// running it again starts over from the first row.
+func Backfill(tx *gorm.DB, opts BackfillOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("backfill name is required")
+	}
+	if opts.Table == "" {
+		return fmt.Errorf("backfill %s: table is required", opts.Name)
+	}
+	if opts.Batch == nil {
+		return fmt.Errorf("backfill %s: Batch function is required", opts.Name)
+	}
+	if opts.KeyColumn == "" {
+		opts.KeyColumn = "id"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := tx.AutoMigrate(&backfillCheckpoint{}); err != nil {
+		return fmt.Errorf("failed to migrate backfill checkpoint table: %w", err)
+	}
+	checkpoint := backfillCheckpoint{Name: opts.Name}
+	if err := tx.Where("name = ?", opts.Name).Limit(1).Find(&checkpoint).Error; err != nil {
+		return fmt.Errorf("failed to read checkpoint of backfill %s: %w", opts.Name, err)
+	}
+	if checkpoint.CompletedAt != nil {
+		checkpoint.LastKey = 0
+		checkpoint.CompletedAt = nil
+	}
+	if checkpoint.Paused {
+		checkpoint.Paused = false
+		if err := tx.Model(&checkpoint).Update("paused", false).Error; err != nil {
+			return fmt.Errorf("failed to resume backfill %s: %w", opts.Name, err)
+		}
+	}
+	if checkpoint.LastKey != 0 {
+		out.Printf("Resuming backfill %s after %s %d\n", opts.Name, opts.KeyColumn, checkpoint.LastKey)
+	}
+
+	key := tx.Statement.Quote(opts.KeyColumn)
+	var maxKey *int64
+	if err := tx.Table(opts.Table).Select("MAX(" + key + ")").Scan(&maxKey).Error; err != nil {
+		return fmt.Errorf("failed to read max %s of %s: %w", opts.KeyColumn, opts.Table, err)
+	}
+
+	clock := clockOf(tx)
+	start := clock.Now()
+	lastReport := start
+	processed := 0
+	for maxKey != nil && checkpoint.LastKey < *maxKey {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("backfill %s cancelled after %s %d: %w", opts.Name, opts.KeyColumn, checkpoint.LastKey, err)
+		}
+
+		// The upper key of the batch is the BatchSize-th key after the checkpoint
+		var keys []int64
+		if err := tx.Table(opts.Table).Where(key+" > ?", checkpoint.LastKey).Order(key).
+			Limit(opts.BatchSize).Pluck(opts.KeyColumn, &keys).Error; err != nil {
+			return fmt.Errorf("failed to read keys of %s: %w", opts.Table, err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+		from, to := checkpoint.LastKey, keys[len(keys)-1]
+
+		var paused []bool
+		if err := tx.Model(&backfillCheckpoint{}).Where("name = ?", opts.Name).Pluck("paused", &paused).Error; err != nil {
+			return fmt.Errorf("failed to read checkpoint of backfill %s: %w", opts.Name, err)
+		}
+		if len(paused) > 0 && paused[0] {
+			out.Printf("⏸️  Backfill %s paused after %s %d\n", opts.Name, opts.KeyColumn, from)
+			return fmt.Errorf("backfill %s stopped after %s %d: %w", opts.Name, opts.KeyColumn, from, ErrBackfillPaused)
+		}
+
+		err := tx.Transaction(func(batchTx *gorm.DB) error {
+			if err := opts.Batch(batchTx, from, to); err != nil {
+				return err
+			}
+			checkpoint.LastKey = to
+			checkpoint.UpdatedAt = clock.Now()
+			return saveCheckpoint(batchTx, &checkpoint)
+		})
+		if err != nil {
+			checkpoint.LastKey = from
+			return fmt.Errorf("backfill %s failed for %s in (%d, %d]: %w", opts.Name, opts.KeyColumn, from, to, err)
+		}
+		processed += len(keys)
+
+		if since(clock, lastReport) >= progressInterval || to >= *maxKey {
+			out.Printf("  - %s: %d rows, %s %d/%d\n", opts.Name, processed, opts.KeyColumn, to, *maxKey)
+			lastReport = clock.Now()
+		}
+		if opts.Sleep > 0 && to < *maxKey {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.Sleep):
+			}
+		}
+	}
+
+	now := clock.Now()
+	checkpoint.CompletedAt = &now
+	checkpoint.UpdatedAt = now
+	if err := saveCheckpoint(tx, &checkpoint); err != nil {
+		return fmt.Errorf("failed to mark backfill %s complete: %w", opts.Name, err)
+	}
+	out.Printf("✅ Backfill %s complete: %d rows in %s\n", opts.Name, processed, since(clock, start).Round(time.Millisecond))
+	return nil
+}
+
+// saveCheckpoint stores the progress of checkpoint. The paused flag is left alone, so a
+// PauseBackfill arriving during a batch is not overwritten.
+func saveCheckpoint(tx *gorm.DB, checkpoint *backfillCheckpoint) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_key", "completed_at", "updated_at"}),
+	}).Create(checkpoint).Error
+}
+
+// PauseBackfill asks the backfill called name to stop after its current batch, e.g. from
+// another process while it loads the database too much. Backfill then returns ErrBackfillPaused;
+// running the backfill again resumes it.
+func PauseBackfill(db *gorm.DB, name string) error {
+	if err := db.AutoMigrate(&backfillCheckpoint{}); err != nil {
+		return fmt.Errorf("failed to migrate backfill checkpoint table: %w", err)
+	}
+	checkpoint := backfillCheckpoint{Name: name, Paused: true, UpdatedAt: clockOf(db).Now()}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_at"}),
+	}).Create(&checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("failed to pause backfill %s: %w", name, err)
+	}
+	out.Printf("⏸️  Paused backfill %s, a running backfill stops after its current batch\n", name)
+	return nil
+}
+
+// runBackfills runs backfills in order. A paused backfill doesn't stop the ones after it;
+// the paused backfills are reported once all have run.
+func runBackfills(db *gorm.DB, backfills []*BackfillOptions) error {
+	var paused []string
+	for _, b := range backfills {
+		if err := Backfill(db, *b); err != nil {
+			if errors.Is(err, ErrBackfillPaused) {
+				paused = append(paused, b.Name)
+				continue
+			}
+			return err
+		}
+	}
+	if len(paused) > 0 {
+		out.Printf("⏸️  Paused backfills: %s, run them again to resume\n", strings.Join(paused, ", "))
+	}
+	return nil
+}
+
+// printBackfillStatus prints the state and progress of backfills: not started, in progress,
+// paused or complete.
+func printBackfillStatus(db *gorm.DB, backfills []*BackfillOptions) error {
+	checkpoints := make(map[string]backfillCheckpoint)
+	if db.Migrator().HasTable(&backfillCheckpoint{}) {
+		var rows []backfillCheckpoint
+		if err := db.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to read backfill checkpoints: %w", err)
+		}
+		for _, row := range rows {
+			checkpoints[row.Name] = row
+		}
+	}
+
+	out.Println("\n=== Backfill Status ===")
+	for _, b := range backfills {
+		checkpoint, ok := checkpoints[b.Name]
+		switch {
+		case !ok:
+			out.Printf("  - %s: not started\n", b.Name)
+		case checkpoint.CompletedAt != nil:
+			out.Printf("✅ %s: complete at %s\n", b.Name, checkpoint.CompletedAt.Local().Format(time.DateTime))
+		default:
+			keyColumn := b.KeyColumn
+			if keyColumn == "" {
+				keyColumn = "id"
+			}
+			var maxKey *int64
+			if err := db.Table(b.Table).Select("MAX(" + db.Statement.Quote(keyColumn) + ")").Scan(&maxKey).Error; err != nil {
+				return fmt.Errorf("failed to read max %s of %s: %w", keyColumn, b.Table, err)
+			}
+			state := "in progress"
+			if checkpoint.Paused {
+				state = "paused"
+			}
+			var total int64
+			if maxKey != nil {
+				total = *maxKey
+			}
+			out.Printf("  - %s: %s, %s %d/%d\n", b.Name, state, keyColumn, checkpoint.LastKey, total)
+		}
+	}
+	return nil
+}
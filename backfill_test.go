@@ -0,0 +1,80 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestBackfillRequiresOptions tests that Backfill validates its options before touching the database
+func TestBackfillRequiresOptions(t *testing.T) {
+	cases := map[string]BackfillOptions{
+		"missing name":  {Table: "orders"},
+		"missing table": {Name: "order-totals"},
+		"missing batch": {Name: "order-totals", Table: "orders"},
+	}
+	for name, opts := range cases {
+		if err := Backfill(nil, opts); err == nil {
+			t.Errorf("Expected error for %s, got nil", name)
+		}
+	}
+}
+
+// TestSelectBackfills tests selecting configured backfills by name
+func TestSelectBackfills(t *testing.T) {
+	c := &cli{}
+	if _, err := c.selectBackfills(""); err == nil {
+		t.Error("Expected an error without configured backfills")
+	}
+
+	c.opts.Backfills = []*BackfillOptions{{Name: "order-totals"}, {Name: "user-slugs"}}
+	selected, err := c.selectBackfills("")
+	if err != nil || len(selected) != 2 {
+		t.Errorf("Expected all backfills, got %v (%v)", selected, err)
+	}
+	selected, err = c.selectBackfills("user-slugs")
+	if err != nil || len(selected) != 1 || selected[0].Name != "user-slugs" {
+		t.Errorf("Expected [user-slugs], got %v (%v)", selected, err)
+	}
+	if _, err := c.selectBackfills("missing"); err == nil {
+		t.Error("Expected an error for an unknown backfill")
+	}
+}
+
+// TestRunBackfillsSkipsPaused tests that a paused backfill doesn't stop the backfills after it
+// and is reported at the end
+func TestRunBackfillsSkipsPaused(t *testing.T) {
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	if err := db.Exec("CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO orders (id) VALUES (1), (2)").Error; err != nil {
+		t.Fatal(err)
+	}
+	backfills := []*BackfillOptions{
+		{Name: "order-totals", Table: "orders", BatchSize: 1, Batch: func(tx *gorm.DB, from, to int64) error {
+			// Paused by another process during the first batch
+			return PauseBackfill(tx, "order-totals")
+		}},
+		{Name: "order-flags", Table: "orders", Batch: func(tx *gorm.DB, from, to int64) error {
+			return tx.Exec("UPDATE orders SET total = 0 WHERE id > ? AND id <= ?", from, to).Error
+		}},
+	}
+	if err := runBackfills(db, backfills); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var updated int64
+	db.Raw("SELECT COUNT(*) FROM orders WHERE total = 0").Scan(&updated)
+	if updated != 2 {
+		t.Errorf("Expected the backfill after the paused one to run, %d rows updated", updated)
+	}
+	if !strings.Contains(buf.String(), "Paused backfills: order-totals") {
+		t.Errorf("Expected the paused backfill to be reported, got %q", buf.String())
+	}
+}
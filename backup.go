@@ -0,0 +1,240 @@
+package gormeasy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// backupLogName is the file in the backup directory listing the backups written, one per line.
+const backupLogName = "backups.log"
+
+// backupDatabase writes a backup of the database of dsn, which db is connected to, into dir
+// before command runs, and returns the path of the backup. PostgreSQL and MySQL are dumped
+// with pg_dump and mysqldump, which must be on PATH; SQLite is copied with VACUUM INTO.
+// The backup is recorded in the backups.log of dir.
+func backupDatabase(db *gorm.DB, dsn, dir, command string) (string, error) {
+	dbName := databaseNameFromURL(dsn)
+	if dbName == "" {
+		dbName = "database"
+	}
+	clock := clockOf(db)
+	now := clock.Now()
+	path := backupPath(dir, dbName, command, db.Dialector.Name(), now)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	out.Printf("💾 Backing up %s to %s...\n", dbName, path)
+	start := clock.Now()
+	if err := dumpDatabase(db, dsn, path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	line := fmt.Sprintf("%s\t%s\t%s\t%s\n", now.UTC().Format(time.RFC3339), command, dbName, path)
+	f, err := os.OpenFile(filepath.Join(dir, backupLogName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to record backup: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		return "", fmt.Errorf("failed to record backup: %w", err)
+	}
+	out.Printf("✅ Backup written in %s: %s\n", since(clock, start).Round(time.Millisecond), path)
+	return path, nil
+}
+
+// backupBeforeDelete backs up database dbName on the server of ownerDB, connected with
+// ownerURL, into dir before delete-db drops it. It skips databases that don't exist.
+func (c *cli) backupBeforeDelete(ownerDB *gorm.DB, ownerURL, dbName, dir string) error {
+	var dsn string
+	if ownerDB.Dialector.Name() == "sqlite" {
+		mainFile, err := sqliteMainFile(ownerDB)
+		if err != nil {
+			return err
+		}
+		dsn = sqliteDatabasePath(mainFile, dbName)
+		if _, err := os.Stat(dsn); err != nil {
+			return nil
+		}
+	} else {
+		var count int64
+		query := "SELECT COUNT(*) FROM pg_database WHERE datname = ?"
+		if ownerDB.Dialector.Name() == "mysql" {
+			query = "SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?"
+		}
+		if err := ownerDB.Raw(query, dbName).Scan(&count).Error; err != nil {
+			return fmt.Errorf("failed to check database existence: %w", err)
+		}
+		if count == 0 {
+			return nil
+		}
+		var err error
+		if dsn, err = withDatabaseName(ownerURL, dbName); err != nil {
+			return err
+		}
+	}
+
+	db, err := getGorm(dsn, c.getGormFromURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	_, err = backupDatabase(db, dsn, dir, "delete-db")
+	return err
+}
+
+// backupPath returns the file of the backup of dbName taken at t before command, e.g.
+// backups/app-20240101120000-down.dump.
+func backupPath(dir, dbName, command, dialect string, t time.Time) string {
+	ext := ".sql"
+	switch dialect {
+	case "postgres":
+		ext = ".dump"
+	case "sqlite":
+		ext = ".db"
+	}
+	name := fmt.Sprintf("%s-%s-%s%s", filepath.Base(dbName), t.UTC().Format("20060102150405"), command, ext)
+	return filepath.Join(dir, name)
+}
+
+// dumpDatabase writes the database of dsn, which db is connected to, to path.
+func dumpDatabase(db *gorm.DB, dsn, path string) error {
+	var cmd *exec.Cmd
+	switch dialect := db.Dialector.Name(); dialect {
+	case "postgres":
+		args, password, err := pgDumpArgs(dsn, path)
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command("pg_dump", args...)
+		// The password is passed in the environment, so it doesn't show up in the process list
+		if password != "" {
+			cmd.Env = append(os.Environ(), "PGPASSWORD="+password)
+		}
+	case "mysql":
+		args, password, err := mysqldumpArgs(dsn, path)
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command("mysqldump", args...)
+		// The password is passed in the environment, so it doesn't show up in the process list
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+password)
+	case "sqlite":
+		if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+			return fmt.Errorf("failed to back up database: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("backups are not supported on dialect %q", dialect)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to back up database with %s: %w: %s", cmd.Args[0], err, msg)
+		}
+		return fmt.Errorf("failed to back up database with %s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// pgPasswordField matches the password of a key=value connection string, quoted or not.
+var pgPasswordField = regexp.MustCompile(`(^|\s)password\s*=\s*('(?:[^'\\]|\\.)*'|\S*)`)
+
+// pgDumpArgs returns the pg_dump arguments dumping the database of dsn, a URL or key=value
+// connection string, to path in the custom format read by pg_restore, and the password of dsn
+// to pass in PGPASSWORD. The password is removed from the --dbname argument.
+func pgDumpArgs(dsn, path string) (args []string, password string, err error) {
+	dsn = strings.TrimSpace(dsn)
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse database URL: %w", err)
+		}
+		if u.User != nil {
+			password, _ = u.User.Password()
+			u.User = url.User(u.User.Username())
+		}
+		if query := u.Query(); query.Has("password") {
+			password = query.Get("password")
+			query.Del("password")
+			u.RawQuery = query.Encode()
+		}
+		dsn = u.String()
+	} else if match := pgPasswordField.FindStringSubmatch(dsn); match != nil {
+		password = match[2]
+		if strings.HasPrefix(password, "'") {
+			password = strings.NewReplacer(`\'`, "'", `\\`, `\`).Replace(password[1 : len(password)-1])
+		}
+		dsn = strings.TrimSpace(pgPasswordField.ReplaceAllString(dsn, ""))
+	}
+	return []string{"--format=custom", "--file=" + path, "--dbname=" + dsn}, password, nil
+}
+
+// mysqldumpArgs returns the mysqldump arguments dumping the database of dsn, a Go MySQL
+// driver DSN ([user[:password]@][net[(addr)]]/dbname[?params]), to path, and the password
+// to pass in MYSQL_PWD.
+func mysqldumpArgs(dsn, path string) (args []string, password string, err error) {
+	dsn = strings.TrimSpace(dsn)
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return nil, "", fmt.Errorf("failed to parse MySQL DSN: missing /dbname")
+	}
+	dbName, _, _ := strings.Cut(dsn[slash+1:], "?")
+	if dbName == "" {
+		return nil, "", fmt.Errorf("failed to parse MySQL DSN: missing database name")
+	}
+
+	args = []string{"--single-transaction", "--routines", "--triggers", "--result-file=" + path}
+	head := dsn[:slash]
+	if at := strings.LastIndex(head, "@"); at >= 0 {
+		user, pass, _ := strings.Cut(head[:at], ":")
+		if user != "" {
+			args = append(args, "--user="+user)
+		}
+		password = pass
+		head = head[at+1:]
+	}
+	if proto, addr, ok := strings.Cut(head, "("); ok {
+		addr = strings.TrimSuffix(addr, ")")
+		switch {
+		case proto == "unix":
+			args = append(args, "--socket="+addr)
+		case addr != "":
+			host, port, splitErr := net.SplitHostPort(addr)
+			if splitErr != nil {
+				host, port = addr, ""
+			}
+			args = append(args, "--host="+host)
+			if port != "" {
+				args = append(args, "--port="+port)
+			}
+		}
+	}
+	return append(args, dbName), password, nil
+}
+
+// pendingDestructive returns the IDs of the pending migrations in status that are marked
+// Destructive in migrations.
+func pendingDestructive(status *MigrationStatus, migrations []*Migration) []string {
+	var ids []string
+	for _, m := range migrations {
+		if m.Destructive && slices.Contains(status.Pending, m.ID) {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
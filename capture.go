@@ -0,0 +1,188 @@
+package gormeasy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// capturedMigration holds the statements a migration would execute.
+type capturedMigration struct {
+	ID         string
+	Statements []string
+}
+
+// capturePool is a gorm.ConnPool that forwards reads to the real database and records
+// every other statement instead of executing it. Running a migration on a session using it
+// shows the SQL the migration would execute without changing the database or taking locks:
+// introspection queries of the GORM migrator still see the real schema.
+type capturePool struct {
+	pool    gorm.ConnPool
+	dialect gorm.Dialector
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// captureResult is the sql.Result of a captured statement.
+type captureResult struct{}
+
+func (captureResult) LastInsertId() (int64, error) { return 0, nil }
+func (captureResult) RowsAffected() (int64, error) { return 0, nil }
+
+// emptyQuery returns no rows on every supported database.
+const emptyQuery = "SELECT 1 FROM (SELECT 1) AS empty WHERE 1 = 0"
+
+// isReadStatement reports whether query only reads data or schema.
+func isReadStatement(query string) bool {
+	keyword := strings.ToUpper(firstKeyword(query))
+	switch keyword {
+	case "SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "VALUES":
+		return true
+	case "PRAGMA":
+		return !strings.Contains(query, "=")
+	case "WITH":
+		upper := strings.ToUpper(query)
+		return !strings.Contains(upper, "INSERT ") && !strings.Contains(upper, "UPDATE ") && !strings.Contains(upper, "DELETE ")
+	}
+	return false
+}
+
+// firstKeyword returns the first word of query, skipping whitespace, comments and parentheses.
+func firstKeyword(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			if i := strings.IndexByte(query, '\n'); i >= 0 {
+				query = query[i+1:]
+				continue
+			}
+			return ""
+		case strings.HasPrefix(query, "/*"):
+			if i := strings.Index(query, "*/"); i >= 0 {
+				query = query[i+2:]
+				continue
+			}
+			return ""
+		}
+		if i := strings.IndexAny(query, " \t\r\n(;"); i >= 0 {
+			return query[:i]
+		}
+		return query
+	}
+}
+
+// record stores a captured statement, skipping transaction bookkeeping.
+func (p *capturePool) record(query string, args []interface{}) {
+	keyword := strings.ToUpper(firstKeyword(query))
+	if keyword == "SAVEPOINT" || keyword == "RELEASE" || keyword == "ROLLBACK" {
+		return
+	}
+	stmt := strings.TrimSpace(query)
+	if len(args) > 0 {
+		stmt = p.dialect.Explain(stmt, args...)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements = append(p.statements, stmt)
+}
+
+func (p *capturePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.pool.PrepareContext(ctx, query)
+}
+
+func (p *capturePool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.record(query, args)
+	return captureResult{}, nil
+}
+
+func (p *capturePool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if isReadStatement(query) {
+		return p.pool.QueryContext(ctx, query, args...)
+	}
+	// Writes returning rows (e.g. INSERT ... RETURNING) are captured and return no rows
+	p.record(query, args)
+	return p.pool.QueryContext(ctx, emptyQuery)
+}
+
+func (p *capturePool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if isReadStatement(query) {
+		return p.pool.QueryRowContext(ctx, query, args...)
+	}
+	p.record(query, args)
+	return p.pool.QueryRowContext(ctx, emptyQuery)
+}
+
+// BeginTx starts a pretend transaction, so migrations using tx.Transaction can be captured.
+func (p *capturePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &captureTx{p}, nil
+}
+
+// captureTx is the pretend transaction of a capturePool.
+type captureTx struct {
+	*capturePool
+}
+
+func (captureTx) Commit() error   { return nil }
+func (captureTx) Rollback() error { return nil }
+
+// isCapturing reports whether tx records statements instead of executing them.
+func isCapturing(tx *gorm.DB) bool {
+	switch tx.Statement.ConnPool.(type) {
+	case *capturePool, *captureTx:
+		return true
+	}
+	return false
+}
+
+// captureSQL runs the Migrate function of each migration against a capturing session of db
+// and returns the statements they would execute. The database is not changed. Migrations are
+// captured in order but each sees the real schema, so statements depending on objects created
+// by an earlier pending migration may differ from a real run.
+func captureSQL(db *gorm.DB, migrations []*Migration) ([]capturedMigration, error) {
+	captured := make([]capturedMigration, 0, len(migrations))
+	for _, m := range migrations {
+		session, pool := captureSession(db)
+		if migrate := m.toGormigrate().Migrate; migrate != nil {
+			if err := migrate(session); err != nil {
+				return nil, fmt.Errorf("failed to capture SQL of migration %s: %w", m.ID, err)
+			}
+		}
+		captured = append(captured, capturedMigration{ID: m.ID, Statements: pool.statements})
+	}
+	return captured, nil
+}
+
+// captureRollbackSQL runs the Rollback function of m against a capturing session of db, which
+// has m applied, and returns the statements it would execute, none without a Rollback function.
+func captureRollbackSQL(db *gorm.DB, m *Migration) ([]string, error) {
+	rollback := m.toGormigrate().Rollback
+	if rollback == nil {
+		return nil, nil
+	}
+	session, pool := captureSession(db)
+	if err := rollback(session); err != nil {
+		return nil, fmt.Errorf("failed to capture rollback SQL of migration %s: %w", m.ID, err)
+	}
+	return pool.statements, nil
+}
+
+// captureSession returns a session of db recording its statements in the returned pool.
+func captureSession(db *gorm.DB) (*gorm.DB, *capturePool) {
+	pool := &capturePool{pool: db.Statement.ConnPool, dialect: db.Dialector}
+	if pool.pool == nil {
+		pool.pool = db.ConnPool
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	session := db.Session(&gorm.Session{NewDB: true, Context: ctx, SkipDefaultTransaction: true})
+	session.Statement.ConnPool = pool
+	return session, pool
+}
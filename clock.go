@@ -0,0 +1,90 @@
+package gormeasy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Clock tells the current time. Set Options.Clock to a fixed clock in tests to get
+// deterministic migration IDs, timestamps and durations.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator creates the ID of a new migration from its name, for the new and init commands.
+type IDGenerator interface {
+	NewID(name string) string
+}
+
+// systemClock is the Clock reading the system time.
+type systemClock struct{}
+
+// Now returns the system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// TimestampIDGenerator creates sortable IDs like "20240101150405-create-users"
+// from the UTC time of Clock and the name converted to lower-case kebab-case.
+type TimestampIDGenerator struct {
+	Clock Clock
+}
+
+// NewID returns the timestamped ID for name.
+func (g TimestampIDGenerator) NewID(name string) string {
+	c := g.Clock
+	if c == nil {
+		c = systemClock{}
+	}
+	return c.Now().UTC().Format("20060102150405") + "-" + slugify(name)
+}
+
+// slugify converts name to lower-case kebab-case, e.g. "Add Orders_Index" to "add-orders-index".
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// clockKey is the context key of the Clock of a session.
+type clockKey struct{}
+
+// withClock returns a session of db whose timestamps and durations come from c, for the
+// functions without Options such as Backfill and RunSeeds.
+func withClock(db *gorm.DB, c Clock) *gorm.DB {
+	if c == nil {
+		return db
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, clockKey{}, c))
+}
+
+// clockOf returns the Clock of the session db, the system clock unless set with withClock.
+func clockOf(db *gorm.DB) Clock {
+	if db != nil && db.Statement != nil && db.Statement.Context != nil {
+		if c, ok := db.Statement.Context.Value(clockKey{}).(Clock); ok {
+			return c
+		}
+	}
+	return systemClock{}
+}
+
+// since returns the time elapsed since t according to clock.
+func since(clock Clock, t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
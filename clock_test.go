@@ -0,0 +1,82 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fixedClock is a Clock always returning the same time.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// TestTimestampIDGenerator tests that IDs combine the clock time and the slugified name
+func TestTimestampIDGenerator(t *testing.T) {
+	ids := TimestampIDGenerator{Clock: fixedClock(time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC))}
+	if got := ids.NewID("Add Orders_Index"); got != "20240301123045-add-orders-index" {
+		t.Errorf("Expected '20240301123045-add-orders-index', got '%s'", got)
+	}
+}
+
+// TestNewMigrationFile tests that new writes a registering migration in the package directory
+func TestNewMigrationFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	ids := TimestampIDGenerator{Clock: fixedClock(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))}
+
+	path, err := newMigrationFile(dir, "add orders index", ids)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filepath.Base(path) != "20240301000000-add-orders-index.go" {
+		t.Errorf("Expected file named after the ID, got %s", filepath.Base(path))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected file to exist, got %v", err)
+	}
+	for _, want := range []string{"package migrations", `ID: "20240301000000-add-orders-index"`, "Registry.Register"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected generated file to contain %q", want)
+		}
+	}
+
+	if _, err := newMigrationFile(dir, "add orders index", ids); err == nil {
+		t.Error("Expected error when the file already exists, got nil")
+	}
+}
+
+// TestOptionsClock tests that Options.Clock reaches GetStatus and, through the migration
+// session, the helpers without Options such as SnapshotTable
+func TestOptionsClock(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	db := openSQLite(t)
+	status, err := GetStatus(db, nil, Options{Clock: fixedClock(now)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.CheckedAt.Equal(now) {
+		t.Errorf("Expected the status to be checked at %s, got %s", now, status.CheckedAt)
+	}
+
+	var snapshot string
+	migrations := []*Migration{{ID: "20240101000000-snapshot-users", Migrate: func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE TABLE users (id integer)").Error; err != nil {
+			return err
+		}
+		snapshot, err = SnapshotTable(tx, "users")
+		return err
+	}}}
+	if err := getMigrator(db, migrations, Options{Clock: fixedClock(now)}.withDefaults()).Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot != "users_snapshot_20240301123045" {
+		t.Errorf("Expected the snapshot to be named after the clock, got %s", snapshot)
+	}
+}
@@ -0,0 +1,63 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CommentOnTable sets the comment of table, or removes it when comment is empty, so the gen
+// command writes it as the doc comment of the model. Table comments are supported on
+// PostgreSQL and MySQL.
+func CommentOnTable(tx *gorm.DB, table, comment string) error {
+	commentSQL, err := commentOnTableSQL(tx.Dialector.Name(), tx.Statement.Quote(table), comment)
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec(commentSQL).Error; err != nil {
+		return fmt.Errorf("failed to comment on table %s: %w", table, err)
+	}
+	return nil
+}
+
+// CommentOnColumn sets the comment of column of table, or removes it when comment is empty,
+// so the gen command writes it as the doc comment of the field. Column comments are supported
+// on PostgreSQL; on MySQL a column comment is part of the column definition, so set it with
+// COMMENT in the definition passed to AddColumnIfNotExists instead.
+func CommentOnColumn(tx *gorm.DB, table, column, comment string) error {
+	dialect := tx.Dialector.Name()
+	if dialect != "postgres" {
+		return fmt.Errorf("column comments are not supported for %s. Currently supported: PostgreSQL", dialect)
+	}
+	commentSQL := fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", tx.Statement.Quote(table), tx.Statement.Quote(column), commentLiteral(dialect, comment))
+	if err := tx.Exec(commentSQL).Error; err != nil {
+		return fmt.Errorf("failed to comment on column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// commentOnTableSQL returns the statement setting the comment of the quoted table on dialect.
+func commentOnTableSQL(dialect, quotedTable, comment string) (string, error) {
+	switch dialect {
+	case "postgres":
+		return fmt.Sprintf("COMMENT ON TABLE %s IS %s", quotedTable, commentLiteral(dialect, comment)), nil
+	case "mysql":
+		return fmt.Sprintf("ALTER TABLE %s COMMENT = %s", quotedTable, commentLiteral(dialect, comment)), nil
+	default:
+		return "", fmt.Errorf("table comments are not supported for %s. Currently supported: PostgreSQL, MySQL", dialect)
+	}
+}
+
+// commentLiteral quotes comment as a string literal of dialect. An empty comment is NULL on
+// PostgreSQL, which removes the comment.
+func commentLiteral(dialect, comment string) string {
+	if dialect == "postgres" {
+		if comment == "" {
+			return "NULL"
+		}
+		return quotePostgresString(comment)
+	}
+	comment = strings.ReplaceAll(comment, `\`, `\\`)
+	return "'" + strings.ReplaceAll(comment, "'", "''") + "'"
+}
@@ -0,0 +1,26 @@
+package gormeasy
+
+import "testing"
+
+// TestCommentOnTableSQL tests the statements setting table comments
+func TestCommentOnTableSQL(t *testing.T) {
+	cases := []struct {
+		dialect, comment, expected string
+	}{
+		{"postgres", "Registered users", `COMMENT ON TABLE "users" IS 'Registered users'`},
+		{"postgres", "", `COMMENT ON TABLE "users" IS NULL`},
+		{"mysql", `User's \ list`, `ALTER TABLE "users" COMMENT = 'User''s \\ list'`},
+	}
+	for _, c := range cases {
+		got, err := commentOnTableSQL(c.dialect, `"users"`, c.comment)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+	if _, err := commentOnTableSQL("sqlite", `"users"`, "Users"); err == nil {
+		t.Error("Expected error for sqlite, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// defaultConfigPath is the config file read when --config is not given.
+const defaultConfigPath = "gormeasy.json"
+
+// fileConfig is the optional project config file (gormeasy.json).
+// Values are only used when neither a flag nor an environment variable is set.
+type fileConfig struct {
+	DatabaseURL           string `json:"database_url"`
+	OwnerDatabaseURL      string `json:"owner_database_url"`
+	RegressionDatabaseURL string `json:"regression_database_url"`
+	// Environments are the database URLs of the named profiles selected with --env,
+	// e.g. staging, used instead of the URLs above.
+	Environments map[string]databaseURLs `json:"environments"`
+	// ProtectedDatabases extends Options.ProtectedDatabases.
+	ProtectedDatabases []string `json:"protected_databases"`
+	// ProtectedHosts extends Options.ProtectedHosts.
+	ProtectedHosts []string `json:"protected_hosts"`
+	// ProtectedURLPattern overrides Options.ProtectedURLPattern.
+	ProtectedURLPattern string `json:"protected_url_pattern"`
+	// GuardNonEmptyDrop enables Options.GuardNonEmptyDrop.
+	GuardNonEmptyDrop bool `json:"guard_non_empty_drop"`
+	// MinVersion raises Options.MinVersion.
+	MinVersion int `json:"min_version"`
+	// SQLVars override Options.SQLVars, so each environment can bring its own config file.
+	SQLVars map[string]string `json:"sql_vars"`
+	// Flags override Options.FlagProvider for the listed feature flags.
+	Flags map[string]bool `json:"flags"`
+	// Partitions extends Options.Partitions.
+	Partitions []*TimePartitions `json:"partitions"`
+	// Anonymize extends Options.Anonymize.
+	Anonymize []AnonymizeRule `json:"anonymize"`
+	// NotifyURL is the webhook notified after up, down and regression, see Options.NotifyURL.
+	NotifyURL string `json:"notify_url"`
+	// Gen configures the fields of the models generated by the gen command.
+	Gen genConfig `json:"gen"`
+}
+
+// databaseURLs are the database URLs of the config file, or of one of its environments.
+type databaseURLs struct {
+	DatabaseURL           string `json:"database_url"`
+	OwnerDatabaseURL      string `json:"owner_database_url"`
+	RegressionDatabaseURL string `json:"regression_database_url"`
+}
+
+// urls returns the database URLs of the environment env, or the top-level ones when env is empty.
+func (cfg *fileConfig) urls(env string) databaseURLs {
+	if env == "" {
+		return databaseURLs{cfg.DatabaseURL, cfg.OwnerDatabaseURL, cfg.RegressionDatabaseURL}
+	}
+	return cfg.Environments[env]
+}
+
+// loadConfig reads the config file at path. A missing file yields an empty config
+// unless required is set, i.e. the path was given explicitly with --config.
+func loadConfig(path string, required bool) (*fileConfig, error) {
+	cfg := &fileConfig{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,46 @@
+package gormeasy
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// confirmInput is where confirmations are read from, and stdinIsTerminal reports whether a
+// user can answer there. Both are replaced in tests.
+var (
+	confirmInput    io.Reader = os.Stdin
+	stdinIsTerminal           = func() bool {
+		info, err := os.Stdin.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+)
+
+// addYesFlag registers the --yes flag skipping the confirmation of a destructive command.
+func addYesFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("yes", false, "Do not ask for confirmation, e.g. in scripts and CI")
+}
+
+// confirmDestructive asks the user to type dbName before action (e.g. "delete database app")
+// runs. It returns nil when yes is set, and refuses to prompt when stdin is not a terminal,
+// so scripts must pass --yes explicitly.
+func confirmDestructive(action, dbName string, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !stdinIsTerminal() {
+		return fmt.Errorf("refusing to %s without confirmation: stdin is not a terminal, pass --yes to confirm", action)
+	}
+	fmt.Fprint(out.errW, out.format(fmt.Sprintf("⚠️  This will %s. Type the database name (%s) to continue: ", action, dbName)))
+	answer, err := bufio.NewReader(confirmInput).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(answer) != dbName {
+		return fmt.Errorf("confirmation did not match %s, aborting", dbName)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestConfirmDestructive tests the typed confirmation, --yes and the refusal without a terminal
+func TestConfirmDestructive(t *testing.T) {
+	savedInput, savedTerminal := confirmInput, stdinIsTerminal
+	defer func() { confirmInput, stdinIsTerminal = savedInput, savedTerminal }()
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	stdinIsTerminal = func() bool { return false }
+	if err := confirmDestructive("delete database app", "app", false); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("Expected a refusal mentioning --yes without a terminal, got %v", err)
+	}
+	if err := confirmDestructive("delete database app", "app", true); err != nil {
+		t.Errorf("Expected --yes to skip the confirmation, got %v", err)
+	}
+
+	stdinIsTerminal = func() bool { return true }
+	confirmInput = strings.NewReader("app\n")
+	if err := confirmDestructive("delete database app", "app", false); err != nil {
+		t.Errorf("Expected the typed name to confirm, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Type the database name (app)") {
+		t.Errorf("Expected a prompt, got %q", buf.String())
+	}
+	confirmInput = strings.NewReader("ap\n")
+	if err := confirmDestructive("delete database app", "app", false); err == nil {
+		t.Error("Expected a mismatched name to abort")
+	}
+}
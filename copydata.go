@@ -0,0 +1,197 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CopyDataOptions configures CopyData.
+type CopyDataOptions struct {
+	// Tables are the tables to copy. Defaults to all tables of the source but the gormeasy
+	// history tables.
+	Tables []string
+	// BatchSize is the number of rows inserted per statement. Defaults to 1000.
+	BatchSize int
+	// Clear deletes the rows of the tables in the target first. Without it, CopyData fails
+	// when a table of the target has rows.
+	Clear bool
+	// Anonymize are the rules replacing personal data in the copied values, see Anonymize.
+	Anonymize []AnonymizeRule
+}
+
+// CopyData streams the rows of tables from source to target, which must have the same
+// schema, e.g. a freshly migrated environment. Tables are copied after the tables they
+// reference according to the foreign keys of target, each in its own transaction and in
+// batches, printing progress. Sequences of target are reset afterwards on PostgreSQL.
+func CopyData(source, target *gorm.DB, opts Options, copyOpts CopyDataOptions) error {
+	if len(copyOpts.Anonymize) > 0 {
+		if err := validateAnonymizeRules(copyOpts.Anonymize); err != nil {
+			return err
+		}
+	}
+	tables, err := copyDataTables(source, opts, copyOpts.Tables)
+	if err != nil {
+		return err
+	}
+	edges, err := referencingTables(target, tables)
+	if err != nil {
+		return err
+	}
+	ordered, err := copyOrder(tables, edges)
+	if err != nil {
+		return err
+	}
+
+	if copyOpts.Clear {
+		if err := truncate(target, tables, edges); err != nil {
+			return err
+		}
+	} else {
+		for _, table := range ordered {
+			var rows int64
+			if err := target.Table(table).Limit(1).Count(&rows).Error; err != nil {
+				return fmt.Errorf("failed to check table %s of the target: %w", table, err)
+			}
+			if rows > 0 {
+				return fmt.Errorf("table %s of the target has rows, set Clear or pass --clear to delete them first", table)
+			}
+		}
+	}
+
+	batchSize := copyOpts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultSeedBatchSize
+	}
+	clock := opts.withDefaults().Clock
+	start := clock.Now()
+	for _, table := range ordered {
+		err := target.Transaction(func(tx *gorm.DB) error {
+			return copyTable(source, tx, table, batchSize, copyOpts.Anonymize, opts.AnonymizeSecret, clock)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy table %s: %w", table, err)
+		}
+	}
+	if target.Dialector.Name() == "postgres" {
+		if err := ResetSequences(target); err != nil {
+			return err
+		}
+	}
+	out.Printf("✅ Copied %d tables in %s\n", len(ordered), since(clock, start).Round(time.Millisecond))
+	return nil
+}
+
+// copyDataTables returns tables after checking that they exist in source, or all tables of
+// source but the gormeasy history tables when tables is empty. The history tables are never
+// copied, so the target keeps its own history and version stamp.
+func copyDataTables(source *gorm.DB, opts Options, tables []string) ([]string, error) {
+	all, err := source.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	history := historyTables(opts)
+	if len(tables) == 0 {
+		for _, table := range all {
+			if !slices.Contains(history, table) {
+				tables = append(tables, table)
+			}
+		}
+		return tables, nil
+	}
+	for _, table := range tables {
+		if !slices.Contains(all, table) {
+			return nil, fmt.Errorf("table %s does not exist in the source", table)
+		}
+		if slices.Contains(history, table) {
+			return nil, fmt.Errorf("table %s holds gormeasy history and is never copied", table)
+		}
+	}
+	return tables, nil
+}
+
+// copyOrder orders tables so that referenced tables come before the tables referencing them.
+func copyOrder(tables []string, edges map[string][]string) ([]string, error) {
+	ordered, err := truncateOrder(tables, edges)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(ordered)
+	return ordered, nil
+}
+
+// copyTable inserts the rows of table of source into tx in batches of batchSize.
+func copyTable(source, tx *gorm.DB, table string, batchSize int, rules []AnonymizeRule, secret string, clock Clock) error {
+	var total int64
+	if err := source.Table(table).Count(&total).Error; err != nil {
+		return err
+	}
+	rows, err := source.Table(table).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	strategies := anonymizeRulesFor(rules, table, columns)
+
+	copied := 0
+	lastReport := clock.Now()
+	batch := make([]map[string]any, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(table).Create(&batch).Error; err != nil {
+			return fmt.Errorf("failed to insert rows %d-%d: %w", copied+1, copied+len(batch), err)
+		}
+		copied += len(batch)
+		batch = batch[:0]
+		if since(clock, lastReport) >= progressInterval {
+			out.Printf("  - %s: %d/%d rows\n", table, copied, total)
+			lastReport = clock.Now()
+		}
+		return nil
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			v := values[i]
+			if strategies[i] != "" {
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				v = anonymizeValue(strategies[i], v, secret)
+			}
+			row[column] = v
+		}
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	out.Printf("  - %s: %d rows\n", table, copied)
+	return nil
+}
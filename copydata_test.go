@@ -0,0 +1,51 @@
+package gormeasy
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCopyOrder tests that referenced tables are copied before the tables referencing them
+func TestCopyOrder(t *testing.T) {
+	edges := map[string][]string{
+		"users":  {"orders", "users"},
+		"orders": {"order_items"},
+	}
+	ordered, err := copyOrder([]string{"order_items", "orders", "users"}, edges)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"users", "orders", "order_items"}
+	if !slices.Equal(ordered, expected) {
+		t.Errorf("Expected %v, got %v", expected, ordered)
+	}
+}
+
+// TestHistoryTables tests that custom history table names are excluded from copies
+func TestHistoryTables(t *testing.T) {
+	tables := historyTables(Options{TableName: "schema_migrations"})
+	expected := []string{"schema_migrations", "data_migrations", "gormeasy_maintenance", "gormeasy_backfills", "schema_migrations_meta"}
+	if !slices.Equal(tables, expected) {
+		t.Errorf("Expected %v, got %v", expected, tables)
+	}
+}
+
+// TestCopyDataTablesSkipsHistory tests that copies leave out the history and version stamp tables
+func TestCopyDataTablesSkipsHistory(t *testing.T) {
+	db := openSQLite(t)
+	for _, table := range []string{"migrations", "migrations_meta", "users"} {
+		if err := db.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)").Error; err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	tables, err := copyDataTables(db, Options{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(tables, []string{"users"}) {
+		t.Errorf("Expected [users], got %v", tables)
+	}
+	if _, err := copyDataTables(db, Options{}, []string{"users", "migrations_meta"}); err == nil {
+		t.Error("Expected error for copying the version stamp, got nil")
+	}
+}
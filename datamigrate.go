@@ -0,0 +1,38 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RunDataMigrations applies the pending data migrations of opts.DataMigrations.
+// Data migrations have their own history table (opts.DataTableName) and only run once
+// every schema migration in migrations is applied, since they usually rely on the latest schema.
+func RunDataMigrations(db *gorm.DB, migrations []*Migration, opts Options) error {
+	opts = opts.withDefaults()
+	if len(opts.DataMigrations) == 0 {
+		return fmt.Errorf("no data migrations configured, set Options.DataMigrations")
+	}
+	if err := checkDuplicateIDs(opts.DataMigrations); err != nil {
+		return err
+	}
+
+	if err := ensureHistoryTable(db, opts); err != nil {
+		return fmt.Errorf("failed to migrate migrations table: %w", err)
+	}
+	applied := getAppliedIDs(db, opts)
+	var pending []string
+	for _, m := range migrations {
+		if !applied[m.ID] {
+			pending = append(pending, m.ID)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema migrations are pending, run up first: %s", strings.Join(pending, ", "))
+	}
+
+	out.Println("Running data migrations...")
+	return RunMigrationsWithOptions(db, opts.DataMigrations, opts.dataOptions())
+}
@@ -0,0 +1,174 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// ObjectKind is the kind of a database object managed by ObjectMigration.
+type ObjectKind string
+
+const (
+	// ObjectView is a view, dropped with DROP VIEW.
+	ObjectView ObjectKind = "view"
+	// ObjectFunction is a function, dropped with DROP FUNCTION.
+	ObjectFunction ObjectKind = "function"
+	// ObjectTrigger is a trigger, dropped with DROP TRIGGER.
+	ObjectTrigger ObjectKind = "trigger"
+)
+
+// DBObject is a named function, trigger or view definition, which AutoMigrate can't manage.
+type DBObject struct {
+	// Kind is the kind of the object.
+	Kind ObjectKind
+	// Name is the object name as used in DROP statements, e.g. "active_users" or
+	// "billing.touch_updated_at()" for a PostgreSQL function.
+	Name string
+	// Table is the table of a trigger. PostgreSQL requires it to drop the trigger.
+	Table string
+	// Definition is the statement creating the object, e.g. CREATE OR REPLACE VIEW ... AS ...
+	// It is executed as a single statement, so function bodies may contain semicolons.
+	Definition string
+	// Previous is the definition of the version this one replaces, created again by the
+	// rollback after dropping the object. When empty, the rollback only drops the object.
+	Previous string
+}
+
+// ObjectMigration returns a migration creating objects in order. Its rollback drops each
+// object in reverse order and creates its Previous definition again, if any, since CREATE OR
+// REPLACE VIEW cannot change the columns of a view. Triggers are dropped
+// before being created, since not every database can replace a trigger. Keep definitions
+// in constants so a new version can refer to the one it replaces:
+//
+//	gormeasy.ObjectMigration("20240301000000-active-users-v2", gormeasy.DBObject{
+//		Kind:       gormeasy.ObjectView,
+//		Name:       "active_users",
+//		Definition: activeUsersV2,
+//		Previous:   activeUsersV1,
+//	})
+//
+// The list-objects command shows which migration owns each object.
+func ObjectMigration(id string, objects ...DBObject) *Migration {
+	return &Migration{
+		ID:      id,
+		Objects: objects,
+		Migrate: func(tx *gorm.DB) error {
+			for _, obj := range objects {
+				if err := createObject(tx, obj, obj.Definition); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, obj := range slices.Backward(objects) {
+				if err := dropObject(tx, obj); err != nil {
+					return err
+				}
+				if obj.Previous == "" {
+					continue
+				}
+				if err := createObject(tx, obj, obj.Previous); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// createObject executes definition, the current or previous definition of obj.
+func createObject(tx *gorm.DB, obj DBObject, definition string) error {
+	if obj.Kind == ObjectTrigger {
+		if err := dropObject(tx, obj); err != nil {
+			return err
+		}
+	}
+	if err := tx.Exec(definition).Error; err != nil {
+		return fmt.Errorf("failed to create %s %s: %w", obj.Kind, obj.Name, err)
+	}
+	return nil
+}
+
+// dropObject drops obj if it exists.
+func dropObject(tx *gorm.DB, obj DBObject) error {
+	dropSQL, err := dropObjectSQL(tx.Dialector.Name(), obj)
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec(dropSQL).Error; err != nil {
+		return fmt.Errorf("failed to drop %s %s: %w", obj.Kind, obj.Name, err)
+	}
+	return nil
+}
+
+// dropObjectSQL returns the statement dropping obj on dialect.
+func dropObjectSQL(dialect string, obj DBObject) (string, error) {
+	switch obj.Kind {
+	case ObjectView:
+		return "DROP VIEW IF EXISTS " + obj.Name, nil
+	case ObjectFunction:
+		return "DROP FUNCTION IF EXISTS " + obj.Name, nil
+	case ObjectTrigger:
+		if dialect != "postgres" {
+			return "DROP TRIGGER IF EXISTS " + obj.Name, nil
+		}
+		if obj.Table == "" {
+			return "", fmt.Errorf("trigger %s needs a Table to be dropped on PostgreSQL", obj.Name)
+		}
+		return fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", obj.Name, obj.Table), nil
+	default:
+		return "", fmt.Errorf("unknown kind %q of database object %s", obj.Kind, obj.Name)
+	}
+}
+
+// objectOwner is a database object with the migration owning it, listed by list-objects.
+type objectOwner struct {
+	Object      DBObject
+	MigrationID string
+	Applied     bool
+}
+
+// objectOwners returns the objects of migrations in order of first definition, each owned by
+// the last applied migration defining it, or by the first one when none is applied yet.
+func objectOwners(migrations []*Migration, applied map[string]bool) []objectOwner {
+	var owners []objectOwner
+	index := make(map[string]int)
+	for _, m := range migrations {
+		for _, obj := range m.Objects {
+			key := string(obj.Kind) + " " + obj.Name
+			i, seen := index[key]
+			if !seen {
+				index[key] = len(owners)
+				owners = append(owners, objectOwner{Object: obj, MigrationID: m.ID, Applied: applied[m.ID]})
+				continue
+			}
+			if applied[m.ID] {
+				owners[i] = objectOwner{Object: obj, MigrationID: m.ID, Applied: true}
+			}
+		}
+	}
+	return owners
+}
+
+// printObjectOwners prints the objects of migrations with the migration owning each.
+func printObjectOwners(migrations []*Migration, status *MigrationStatus) {
+	applied := make(map[string]bool)
+	for _, id := range status.Applied {
+		applied[id] = true
+	}
+	out.Println("\n=== Database Objects ===")
+	owners := objectOwners(migrations, applied)
+	for _, o := range owners {
+		state := "pending"
+		if o.Applied {
+			state = "applied"
+		}
+		out.Printf("  - %-8s  %s  %s (%s)\n", o.Object.Kind, o.Object.Name, o.MigrationID, state)
+	}
+	if len(owners) == 0 {
+		out.Println("  (none)")
+	}
+}
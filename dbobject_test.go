@@ -0,0 +1,89 @@
+package gormeasy
+
+import (
+	"testing"
+)
+
+// TestDropObjectSQL tests the statements dropping views, functions and triggers
+func TestDropObjectSQL(t *testing.T) {
+	cases := []struct {
+		dialect  string
+		obj      DBObject
+		expected string
+	}{
+		{"postgres", DBObject{Kind: ObjectView, Name: "active_users"}, "DROP VIEW IF EXISTS active_users"},
+		{"postgres", DBObject{Kind: ObjectFunction, Name: "touch_updated_at()"}, "DROP FUNCTION IF EXISTS touch_updated_at()"},
+		{"postgres", DBObject{Kind: ObjectTrigger, Name: "orders_touch", Table: "orders"}, "DROP TRIGGER IF EXISTS orders_touch ON orders"},
+		{"mysql", DBObject{Kind: ObjectTrigger, Name: "orders_touch", Table: "orders"}, "DROP TRIGGER IF EXISTS orders_touch"},
+	}
+	for _, c := range cases {
+		got, err := dropObjectSQL(c.dialect, c.obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != c.expected {
+			t.Errorf("Expected %s, got %s", c.expected, got)
+		}
+	}
+
+	if _, err := dropObjectSQL("postgres", DBObject{Kind: ObjectTrigger, Name: "orders_touch"}); err == nil {
+		t.Error("Expected error for a PostgreSQL trigger without table, got nil")
+	}
+	if _, err := dropObjectSQL("postgres", DBObject{Kind: "index", Name: "idx"}); err == nil {
+		t.Error("Expected error for an unknown kind, got nil")
+	}
+}
+
+// TestObjectMigrationRollback tests that the rollback drops the object before creating the
+// previous definition, whose columns differ
+func TestObjectMigrationRollback(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+	m := ObjectMigration("002-user-names-v2", DBObject{
+		Kind:       ObjectView,
+		Name:       "user_names",
+		Definition: "CREATE VIEW user_names AS SELECT id, name FROM users",
+		Previous:   "CREATE VIEW user_names AS SELECT name FROM users",
+	})
+	if err := m.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rollback(db); err != nil {
+		t.Fatalf("Expected the previous view to be restored, got %v", err)
+	}
+	columns, err := db.Migrator().ColumnTypes("user_names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 1 || columns[0].Name() != "name" {
+		t.Errorf("Expected the previous view with the name column only, got %d columns", len(columns))
+	}
+}
+
+// TestObjectOwners tests that each object is owned by the last applied migration defining it
+func TestObjectOwners(t *testing.T) {
+	view := DBObject{Kind: ObjectView, Name: "active_users"}
+	function := DBObject{Kind: ObjectFunction, Name: "touch_updated_at()"}
+	migrations := []*Migration{
+		ObjectMigration("1", view, function),
+		ObjectMigration("2", view),
+		ObjectMigration("3", view),
+	}
+	owners := objectOwners(migrations, map[string]bool{"1": true, "2": true})
+	if len(owners) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(owners))
+	}
+	if owners[0].Object.Name != "active_users" || owners[0].MigrationID != "2" || !owners[0].Applied {
+		t.Errorf("Expected active_users to be owned by applied migration 2, got %+v", owners[0])
+	}
+	if owners[1].MigrationID != "1" {
+		t.Errorf("Expected touch_updated_at() to be owned by migration 1, got %s", owners[1].MigrationID)
+	}
+
+	owners = objectOwners(migrations, nil)
+	if owners[0].MigrationID != "1" || owners[0].Applied {
+		t.Errorf("Expected active_users to be owned by pending migration 1, got %+v", owners[0])
+	}
+}
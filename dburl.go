@@ -0,0 +1,255 @@
+package gormeasy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// databaseNameFromURL extracts the database name from a connection URL.
+// It understands URLs (postgres://host/name), key/value DSNs (dbname=name),
+// MySQL DSNs (user:pass@tcp(host)/name) and SQLite file paths.
+// It returns an empty string when no name can be found.
+func databaseNameFromURL(dsn string) string {
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		return ""
+	}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return ""
+		}
+		if name := u.Query().Get("dbname"); name != "" {
+			return name
+		}
+		return strings.TrimPrefix(u.Path, "/")
+	}
+
+	if strings.Contains(dsn, "=") && !strings.Contains(dsn, "/") {
+		for _, field := range strings.Fields(dsn) {
+			if key, value, ok := strings.Cut(field, "="); ok && key == "dbname" {
+				return strings.Trim(value, "'")
+			}
+		}
+		return ""
+	}
+
+	// MySQL DSN: [user[:password]@][net[(addr)]]/dbname[?params]
+	if i := strings.Index(dsn, ")/"); i >= 0 || strings.Contains(dsn, "@/") {
+		rest := dsn[strings.LastIndex(dsn, "/")+1:]
+		name, _, _ := strings.Cut(rest, "?")
+		return name
+	}
+
+	// SQLite file path, optionally with a file: prefix and query parameters
+	path, _, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// checkRegressionTargets refuses regression runs that could drop or roll back a database
+// other than the disposable regression database: the owner and regression URLs must not
+// point at the same database, the regression URL must point at dbName, and dbName must not
+// be the database of DATABASE_URL.
+func checkRegressionTargets(ownerURL, regressionURL, databaseURL, dbName string) error {
+	regressionName := databaseNameFromURL(regressionURL)
+	if regressionName != "" && regressionName == databaseNameFromURL(ownerURL) {
+		return fmt.Errorf("owner-db-url and regression-db-url point at the same database %s", regressionName)
+	}
+	if regressionName != "" && regressionName != dbName {
+		return fmt.Errorf("regression-db-url points at database %s but db-name is %s", regressionName, dbName)
+	}
+	if productionName := databaseNameFromURL(databaseURL); productionName != "" && productionName == dbName {
+		return fmt.Errorf("db-name %s is the database of DATABASE_URL, refusing to drop it", dbName)
+	}
+	return nil
+}
+
+// withDatabaseName returns dsn pointing at the database dbName on the same server.
+// It supports the same URL, key/value and MySQL DSN formats as databaseNameFromURL.
+func withDatabaseName(dsn, dbName string) (string, error) {
+	dsn = strings.TrimSpace(dsn)
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse database URL: %w", err)
+		}
+		query := u.Query()
+		if query.Has("dbname") {
+			query.Set("dbname", dbName)
+			u.RawQuery = query.Encode()
+		} else {
+			u.Path = "/" + dbName
+		}
+		return u.String(), nil
+	}
+
+	if strings.Contains(dsn, "=") && !strings.Contains(dsn, "/") {
+		fields := strings.Fields(dsn)
+		replaced := false
+		for i, field := range fields {
+			if key, _, ok := strings.Cut(field, "="); ok && key == "dbname" {
+				fields[i] = "dbname=" + dbName
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, "dbname="+dbName)
+		}
+		return strings.Join(fields, " "), nil
+	}
+
+	if i := strings.LastIndex(dsn, "/"); i >= 0 && (strings.Contains(dsn, ")/") || strings.Contains(dsn, "@/")) {
+		_, params, hasParams := strings.Cut(dsn[i+1:], "?")
+		result := dsn[:i+1] + dbName
+		if hasParams {
+			result += "?" + params
+		}
+		return result, nil
+	}
+
+	return "", fmt.Errorf("cannot change the database of %q, use a URL or key/value connection string", dsn)
+}
+
+// redactURL returns dsn with its password replaced by "xxxxx", so it can be printed.
+// It supports the same URL, key/value and MySQL DSN formats as databaseNameFromURL.
+func redactURL(dsn string) string {
+	dsn = strings.TrimSpace(dsn)
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "(unparsable URL)"
+		}
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", "xxxxx")
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	}
+
+	if strings.Contains(dsn, "=") && !strings.Contains(dsn, "/") {
+		fields := strings.Fields(dsn)
+		for i, field := range fields {
+			if key, _, ok := strings.Cut(field, "="); ok && key == "password" {
+				fields[i] = "password=xxxxx"
+			}
+		}
+		return strings.Join(fields, " ")
+	}
+
+	// MySQL DSN: user:password@net(addr)/dbname
+	if at := strings.LastIndex(dsn, "@"); at >= 0 {
+		if colon := strings.Index(dsn[:at], ":"); colon >= 0 {
+			return dsn[:colon+1] + "xxxxx" + dsn[at:]
+		}
+	}
+	return dsn
+}
+
+// hostFromURL returns the host name of the server dsn connects to, or an empty string for
+// SQLite files and DSNs without a host. It supports the same formats as databaseNameFromURL.
+func hostFromURL(dsn string) string {
+	dsn = strings.TrimSpace(dsn)
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return ""
+		}
+		if host := u.Query().Get("host"); host != "" && u.Hostname() == "" {
+			return host
+		}
+		return u.Hostname()
+	}
+
+	if strings.Contains(dsn, "=") && !strings.Contains(dsn, "/") {
+		for _, field := range strings.Fields(dsn) {
+			if key, value, ok := strings.Cut(field, "="); ok && key == "host" {
+				return strings.Trim(value, "'")
+			}
+		}
+		return ""
+	}
+
+	// MySQL DSN: [user[:password]@]net(host:port)/dbname
+	if open := strings.Index(dsn, "("); open >= 0 {
+		if end := strings.Index(dsn[open:], ")"); end >= 0 {
+			addr := dsn[open+1 : open+end]
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				return host
+			}
+			return addr
+		}
+	}
+	return ""
+}
+
+// printRegressionPlan prints the operations a regression run would perform, in order, with
+// resolved database names and redacted URLs, without connecting to any database. With
+// template, the run ends by replacing the template database with a copy of dbName.
+func printRegressionPlan(ownerURL, regressionURL, dbName string, migrations []*Migration, bootstrap regressionBootstrap, data *regressionData, template string, protected []string) error {
+	if isProtectedDatabase(dbName, protected) {
+		return fmt.Errorf("database %s is protected and cannot be deleted", dbName)
+	}
+	n := len(migrations)
+	tested := fmt.Sprintf("%d migrations", n)
+	if bootstrap.pendingFrom != "" {
+		tested = "the migrations from the first pending one on"
+	}
+	steps := []string{
+		"Connect to owner database " + redactURL(ownerURL),
+		fmt.Sprintf("Drop database %s if it exists", dbName),
+		fmt.Sprintf("Create database %s", dbName),
+		"Connect to regression database " + redactURL(regressionURL),
+		"Migrate up " + tested,
+		"Roll back " + tested,
+		"Migrate up " + tested + " again",
+	}
+	if n > 0 && bootstrap.pendingFrom == "" {
+		steps[4] += fmt.Sprintf(" (%s ... %s)", migrations[0].ID, migrations[n-1].ID)
+	}
+	if data != nil {
+		var sources []string
+		if len(data.seeds) > 0 {
+			sources = append(sources, fmt.Sprintf("%d seeds", len(data.seeds)))
+		}
+		if data.fixtures != "" {
+			sources = append(sources, "the fixtures of "+data.fixtures)
+		}
+		steps = slices.Insert(steps, 5,
+			"Load "+strings.Join(sources, " and "),
+			fmt.Sprintf("Roll back %d migrations and migrate up again, comparing row counts", data.steps))
+	}
+	if bootstrap.baseline != "" || (bootstrap.pendingFrom != "" && (bootstrap.schemaFile != "" || bootstrap.template != "")) {
+		check := "Check that exactly the migrations up to the baseline " + bootstrap.baseline + " are applied"
+		if bootstrap.baseline == "" {
+			check = "Check that exactly the migrations before the first pending one are applied"
+		}
+		if bootstrap.template != "" {
+			steps[2] = fmt.Sprintf("Clone database %s from %s", dbName, bootstrap.template)
+			steps = slices.Insert(steps, 4, check)
+		} else {
+			steps = slices.Insert(steps, 4, "Load the schema file "+bootstrap.schemaFile, check)
+		}
+	}
+	if bootstrap.pendingFrom != "" {
+		steps = slices.Insert(steps, 0, "Connect to "+redactURL(bootstrap.pendingFrom)+" to find the first pending migration")
+	}
+	if template != "" {
+		steps = append(steps, fmt.Sprintf("Replace database %s with a copy of %s", template, dbName))
+	}
+
+	out.Println("=== Regression Plan ===")
+	for i, step := range steps {
+		out.Printf("%d. %s\n", i+1, step)
+	}
+	out.Printf("The database %s is left in place after the run.\n", dbName)
+	return nil
+}
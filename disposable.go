@@ -0,0 +1,60 @@
+package gormeasy
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// checkDisposableDatabase refuses to use dbName as a disposable database when it is the
+// database of the owner URL or of DATABASE_URL, since it is dropped before and after use.
+func checkDisposableDatabase(ownerURL, databaseURL, dbName string) error {
+	if databaseNameFromURL(ownerURL) == dbName {
+		return fmt.Errorf("db-name %s is the database of owner-db-url, refusing to drop it", dbName)
+	}
+	if databaseNameFromURL(databaseURL) == dbName {
+		return fmt.Errorf("db-name %s is the database of DATABASE_URL, refusing to drop it", dbName)
+	}
+	return nil
+}
+
+// withDisposableDatabase creates the empty database dbName on the server of ownerURL, runs fn
+// on it and deletes it again, unless keep is set. An existing database of that name is
+// deleted first, unless it is in protected.
+func withDisposableDatabase(ownerURL, dbName string, keep bool, protected []string, getGormFromURL func(string) (*gorm.DB, error), fn func(db *gorm.DB) error) error {
+	dbURL, err := withDatabaseName(ownerURL, dbName)
+	if err != nil {
+		return err
+	}
+
+	ownerDB, err := getGorm(ownerURL, getGormFromURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	if err = deleteDatabase(ownerDB, dbName, protected, false); err != nil {
+		return err
+	}
+	if err = CreateDatabase(ownerDB, dbName); err != nil {
+		return err
+	}
+
+	err = func() error {
+		db, err := getGorm(dbURL, getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database %s: %w", dbName, err)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			defer sqlDB.Close()
+		}
+		return fn(db)
+	}()
+
+	if keep {
+		out.Printf("Database kept: %s\n", dbName)
+		return err
+	}
+	if dropErr := deleteDatabase(ownerDB, dbName, protected, false); err == nil {
+		err = dropErr
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package gormeasy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dockerPassword is the superuser password of the throwaway database containers. The
+// containers only listen on 127.0.0.1 and are removed after the run.
+const dockerPassword = "gormeasy"
+
+// dockerReadyTimeout is how long to wait for a database container to accept queries.
+const dockerReadyTimeout = 2 * time.Minute
+
+// dockerEngine describes how to run a database server in a container.
+type dockerEngine struct {
+	// image is the default image, overridden by --docker-image.
+	image string
+	// port is the port the server listens on in the container.
+	port string
+	// env is the environment setting the superuser password.
+	env string
+	// ownerURL returns the URL of the superuser connecting to hostPort.
+	ownerURL func(hostPort string) string
+}
+
+// dockerEngines are the database servers supported by regression --docker.
+var dockerEngines = map[string]dockerEngine{
+	"postgres": {
+		image: "postgres:16-alpine",
+		port:  "5432",
+		env:   "POSTGRES_PASSWORD=" + dockerPassword,
+		ownerURL: func(hostPort string) string {
+			return fmt.Sprintf("postgres://postgres:%s@%s/postgres?sslmode=disable", dockerPassword, hostPort)
+		},
+	},
+	"mysql": {
+		image: "mysql:8.4",
+		port:  "3306",
+		env:   "MYSQL_ROOT_PASSWORD=" + dockerPassword,
+		ownerURL: func(hostPort string) string {
+			return fmt.Sprintf("root:%s@tcp(%s)/mysql?parseTime=true", dockerPassword, hostPort)
+		},
+	},
+}
+
+// dockerDatabase is a throwaway database server running in a Docker container.
+type dockerDatabase struct {
+	// id is the container ID, empty once the container is removed.
+	id string
+	// ownerURL is the URL of the superuser of the server.
+	ownerURL string
+}
+
+// startDockerDatabase starts a container of engine ("postgres" or "mysql") from image, or
+// from the default image of engine when image is empty, and waits until it accepts queries.
+// The container publishes its port on a random port of 127.0.0.1 and is removed by stop.
+func startDockerDatabase(engine, image string, getGormFromURL func(string) (*gorm.DB, error)) (*dockerDatabase, error) {
+	e, ok := dockerEngines[engine]
+	if !ok {
+		return nil, fmt.Errorf("unknown docker database %q, use postgres or mysql", engine)
+	}
+	if image == "" {
+		image = e.image
+	}
+
+	out.Printf("Starting %s container...\n", image)
+	id, err := runDocker(dockerRunArgs(e, image)...)
+	if err != nil {
+		return nil, err
+	}
+	d := &dockerDatabase{id: id}
+	ports, err := runDocker("port", id, e.port+"/tcp")
+	if err != nil {
+		d.stop()
+		return nil, err
+	}
+	hostPort, err := parseDockerPort(ports)
+	if err != nil {
+		d.stop()
+		return nil, err
+	}
+	d.ownerURL = e.ownerURL(hostPort)
+
+	err = waitForDatabase(func() (*gorm.DB, error) {
+		return getGormFromURL(d.ownerURL)
+	}, dockerReadyTimeout, time.Second, systemClock{})
+	if err != nil {
+		d.stop()
+		return nil, err
+	}
+	return d, nil
+}
+
+// stop removes the container. It does nothing when d is nil or the container is already removed.
+func (d *dockerDatabase) stop() {
+	if d == nil || d.id == "" {
+		return
+	}
+	if _, err := runDocker("rm", "--force", "--volumes", d.id); err != nil {
+		out.Errorln("⚠️  Failed to remove container", d.id, "-", err)
+		return
+	}
+	out.Verbosef("Removed container %s\n", d.id)
+	d.id = ""
+}
+
+// dockerRunArgs returns the docker arguments starting a detached container of e from image.
+func dockerRunArgs(e dockerEngine, image string) []string {
+	return []string{"run", "--detach", "--rm", "--env", e.env, "--publish", "127.0.0.1::" + e.port, image}
+}
+
+// parseDockerPort returns the host and port of the output of docker port, which lists one
+// address per line, e.g. 127.0.0.1:49153.
+func parseDockerPort(output string) (string, error) {
+	for line := range strings.Lines(output) {
+		if hostPort := strings.TrimSpace(line); hostPort != "" {
+			if _, _, err := net.SplitHostPort(hostPort); err != nil {
+				return "", fmt.Errorf("unexpected docker port output %q", output)
+			}
+			return hostPort, nil
+		}
+	}
+	return "", fmt.Errorf("container publishes no port")
+}
+
+// runDocker runs the docker command with args and returns its trimmed output.
+func runDocker(args ...string) (string, error) {
+	cmd := exec.Command("docker", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		if stderr != "" {
+			return "", fmt.Errorf("failed to run docker %s: %w: %s", args[0], err, stderr)
+		}
+		return "", fmt.Errorf("failed to run docker %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,243 @@
+package gormeasy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// schemaSumFile is the file in the migrations directory recording the schema fingerprint
+// after each migration, written and checked by the verify command.
+const schemaSumFile = "schema.sum"
+
+// schemaFingerprint returns the SHA-256 of the schema of db, without the history table.
+func schemaFingerprint(db *gorm.DB, opts Options) (string, error) {
+	dump, err := dumpSchema(db, opts.TableName, metadataTableName(opts))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(dump.SQL))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readSchemaSums reads a schema.sum file of "<migration ID> <fingerprint>" lines.
+// A missing file has no sums.
+func readSchemaSums(path string) (map[string]string, error) {
+	sums := make(map[string]string)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return sums, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, sum, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"<migration ID> <fingerprint>\"", path, line)
+		}
+		sums[id] = strings.TrimSpace(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return sums, nil
+}
+
+// writeSchemaSums writes the sums of migrations to path, in migration order.
+func writeSchemaSums(path string, migrations []*Migration, sums map[string]string) error {
+	var b strings.Builder
+	b.WriteString("# Schema fingerprint after each migration, written by the verify command. Do not edit.\n")
+	for _, m := range migrations {
+		if sum, ok := sums[m.ID]; ok {
+			fmt.Fprintf(&b, "%s %s\n", m.ID, sum)
+		}
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// verifyMigrations applies migrations one at a time to the empty database db and compares
+// the schema after each with the fingerprint recorded in sums. Migrations defining their
+// tables with inline structs and AutoMigrate silently change their schema when the struct
+// is edited later; verify catches this by failing on the first migration whose schema
+// differs. Fingerprints of migrations not in sums are added to it. With update, changed
+// fingerprints are replaced instead of failing.
+func verifyMigrations(db *gorm.DB, migrations []*Migration, opts Options, sums map[string]string, update bool) error {
+	opts = opts.withDefaults()
+	m := getMigrator(db, migrations, opts)
+	for _, migration := range migrations {
+		if err := m.MigrateTo(migration.ID); err != nil {
+			return fmt.Errorf("failed to migrate to %s: %w", migration.ID, err)
+		}
+		sum, err := schemaFingerprint(db, opts)
+		if err != nil {
+			return err
+		}
+		recorded, ok := sums[migration.ID]
+		switch {
+		case !ok:
+			out.Println("🆕 Recorded schema of", migration.ID)
+		case recorded == sum:
+			out.Println("✅", migration.ID)
+			continue
+		case update:
+			out.Println("⚠️  Updated schema of", migration.ID)
+		default:
+			return fmt.Errorf("migration %s no longer produces the schema recorded in %s.\n"+
+				"A struct or helper it uses has probably changed since it was applied, so new databases would get a different schema than existing ones.\n"+
+				"Restore the original definition, then freeze its SQL with `export-sql --id %s` and run it with gormeasy.ExecSQL.\n"+
+				"Use `verify --update` only if the change is intended",
+				migration.ID, schemaSumFile, migration.ID)
+		}
+		sums[migration.ID] = sum
+	}
+	return nil
+}
+
+// exportFormats are the file layouts of export-sql: sql writes <ID>.sql per migration,
+// golang-migrate numbered <version>_<ID>.up.sql and .down.sql files.
+var exportFormats = []string{"sql", "golang-migrate"}
+
+// exportMigrationSQL applies migrations one at a time to the empty database db and writes
+// the SQL each selected migration executes to dir in format. Capturing on a database that
+// has exactly the preceding migrations applied records the statements the migration ran
+// historically, e.g. the CREATE TABLE generated by an inline struct and AutoMigrate. The
+// golang-migrate format also captures the rollback once the migration is applied.
+func exportMigrationSQL(db *gorm.DB, migrations []*Migration, opts Options, ids []string, dir, format string) error {
+	opts = opts.withDefaults()
+	if !slices.Contains(exportFormats, format) {
+		return fmt.Errorf("unknown format %q, use sql or golang-migrate", format)
+	}
+	for _, id := range ids {
+		if !slices.ContainsFunc(migrations, func(m *Migration) bool { return m.ID == id }) {
+			return fmt.Errorf("unknown migration ID: %s", id)
+		}
+	}
+	if format == "golang-migrate" {
+		versions := make(map[string]string)
+		for _, migration := range migrations {
+			if len(ids) > 0 && !slices.Contains(ids, migration.ID) {
+				continue
+			}
+			name, err := golangMigrateName(migration.ID)
+			if err != nil {
+				return err
+			}
+			version, _, _ := strings.Cut(name, "_")
+			if other, ok := versions[version]; ok {
+				return fmt.Errorf("migrations %s and %s have the same golang-migrate version %s", other, migration.ID, version)
+			}
+			versions[version] = migration.ID
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", dir, err)
+	}
+
+	m := getMigrator(db, migrations, opts)
+	remaining := len(ids)
+	if remaining == 0 {
+		remaining = len(migrations)
+	}
+	for _, migration := range migrations {
+		if remaining == 0 {
+			break
+		}
+		selected := len(ids) == 0 || slices.Contains(ids, migration.ID)
+		var up []capturedMigration
+		if selected {
+			var err error
+			if up, err = captureSQL(db, []*Migration{migration}); err != nil {
+				return err
+			}
+		}
+		if err := m.MigrateTo(migration.ID); err != nil {
+			return fmt.Errorf("failed to migrate to %s: %w", migration.ID, err)
+		}
+		if !selected {
+			continue
+		}
+		remaining--
+
+		if format == "sql" {
+			path := filepath.Join(dir, migration.ID+".sql")
+			if err := writeExportedSQL(path, fmt.Sprintf("-- SQL of migration %s, exported by gormeasy export-sql\n", migration.ID), up[0].Statements); err != nil {
+				return err
+			}
+			continue
+		}
+		name, err := golangMigrateName(migration.ID)
+		if err != nil {
+			return err
+		}
+		base := filepath.Join(dir, name)
+		if err := writeExportedSQL(base+".up.sql", fmt.Sprintf("-- Migration %s, exported by gormeasy export-sql\n", migration.ID), up[0].Statements); err != nil {
+			return err
+		}
+		if migration.Rollback == nil {
+			continue
+		}
+		down, err := captureRollbackSQL(db, migration)
+		if err != nil {
+			return err
+		}
+		if err := writeExportedSQL(base+".down.sql", fmt.Sprintf("-- Rollback of migration %s, exported by gormeasy export-sql\n", migration.ID), down); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// golangMigrateName returns the file name of the migration id without extension, e.g.
+// 20240101000000_create_users. golang-migrate needs numeric versions, taken from the numeric
+// timestamp prefix of the ID, so squashing or deleting migrations never renumbers the files
+// already exported.
+func golangMigrateName(id string) (string, error) {
+	end := strings.IndexFunc(id, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(id)
+	}
+	if end == 0 {
+		return "", fmt.Errorf("migration %s has no numeric timestamp prefix to use as golang-migrate version", id)
+	}
+	title := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.TrimLeft(id[end:], "-_."))
+	if title == "" {
+		return id, nil
+	}
+	return id[:end] + "_" + title, nil
+}
+
+// writeExportedSQL writes the statements after header to path.
+func writeExportedSQL(path, header string, statements []string) error {
+	content := header
+	if len(statements) > 0 {
+		content += "\n" + strings.Join(statements, ";\n\n") + ";\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	out.Println("✅ Exported:", path)
+	return nil
+}
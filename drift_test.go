@@ -0,0 +1,95 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSchemaSums tests that schema.sum files round-trip in migration order
+func TestSchemaSums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), schemaSumFile)
+	sums, err := readSchemaSums(path)
+	if err != nil {
+		t.Fatalf("Expected no error for a missing file, got %v", err)
+	}
+	if len(sums) != 0 {
+		t.Errorf("Expected no sums, got %v", sums)
+	}
+
+	migrations := []*Migration{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	sums = map[string]string{"2": "bbb", "1": "aaa"}
+	if err := writeSchemaSums(path, migrations, sums); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "\n1 aaa\n2 bbb\n") {
+		t.Errorf("Expected sums in migration order, got:\n%s", content)
+	}
+
+	read, err := readSchemaSums(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(read) != 2 || read["1"] != "aaa" || read["2"] != "bbb" {
+		t.Errorf("Expected the written sums, got %v", read)
+	}
+
+	if err := os.WriteFile(path, []byte("broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSchemaSums(path); err == nil {
+		t.Error("Expected error for a malformed line, got nil")
+	}
+}
+
+// TestCheckDisposableDatabase tests that disposable databases never target the owner or development database
+func TestCheckDisposableDatabase(t *testing.T) {
+	owner := "postgres://localhost/postgres"
+	dev := "postgres://localhost/app"
+
+	if err := checkDisposableDatabase(owner, dev, "gormeasy_verify"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := checkDisposableDatabase(owner, dev, "postgres"); err == nil {
+		t.Error("Expected error for the owner database, got nil")
+	}
+	if err := checkDisposableDatabase(owner, dev, "app"); err == nil {
+		t.Error("Expected error for the DATABASE_URL database, got nil")
+	}
+}
+
+// TestExportMigrationSQLUnknownID tests that exporting an unknown migration fails before touching the database
+func TestExportMigrationSQLUnknownID(t *testing.T) {
+	err := exportMigrationSQL(nil, []*Migration{{ID: "1"}}, Options{}, []string{"2"}, t.TempDir(), "sql")
+	if err == nil || !strings.Contains(err.Error(), "unknown migration ID: 2") {
+		t.Errorf("Expected unknown migration ID error, got %v", err)
+	}
+	err = exportMigrationSQL(nil, []*Migration{{ID: "1"}}, Options{}, nil, t.TempDir(), "flyway")
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("Expected unknown format error, got %v", err)
+	}
+}
+
+// TestGolangMigrateName tests that the golang-migrate versions are the timestamps of the IDs
+func TestGolangMigrateName(t *testing.T) {
+	for id, want := range map[string]string{
+		"20240101000000-create-invoices": "20240101000000_create_invoices",
+		"20240101000000":                 "20240101000000",
+	} {
+		if name, err := golangMigrateName(id); err != nil || name != want {
+			t.Errorf("Expected %s, got %s, %v", want, name, err)
+		}
+	}
+	if _, err := golangMigrateName("billing-20240101000000-create-invoices"); err == nil {
+		t.Error("Expected an error for an ID without timestamp prefix, got nil")
+	}
+	err := exportMigrationSQL(nil, []*Migration{{ID: "1-a"}, {ID: "1-b"}}, Options{}, nil, t.TempDir(), "golang-migrate")
+	if err == nil || !strings.Contains(err.Error(), "same golang-migrate version 1") {
+		t.Errorf("Expected duplicate version error, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CreateEnum creates the PostgreSQL enum type name (optionally schema-qualified, e.g.
+// "billing.invoice_status") with values, unless the type exists. Enum types are PostgreSQL
+// only: on other databases it returns an error, use OnlyDialects to skip the migration there.
+func CreateEnum(tx *gorm.DB, name string, values ...string) error {
+	if err := checkEnumDialect(tx); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("enum %s needs at least one value", name)
+	}
+	exists, err := enumExists(tx, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		out.Verbosef("Enum already exists: %s\n", name)
+		return nil
+	}
+	if err := tx.Exec(createEnumSQL(name, values)).Error; err != nil {
+		return fmt.Errorf("failed to create enum %s: %w", name, err)
+	}
+	return nil
+}
+
+// AddEnumValue appends value to the PostgreSQL enum type name, unless it has the value.
+// It runs on tx, never on a side connection that would wait for the locks of the migration
+// transaction. Inside a transaction ALTER TYPE ... ADD VALUE needs PostgreSQL 12 or later,
+// and the value cannot be used before the transaction commits: keep Options.UseTransaction
+// disabled to use it in later migrations of the same up.
+func AddEnumValue(tx *gorm.DB, name, value string) error {
+	if err := checkEnumDialect(tx); err != nil {
+		return err
+	}
+	labels, err := enumValues(tx, name)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		return fmt.Errorf("enum %s does not exist", name)
+	}
+	if slices.Contains(labels, value) {
+		return nil
+	}
+	if err := tx.Exec(addEnumValueSQL(name, value)).Error; err != nil {
+		return fmt.Errorf("failed to add value %s to enum %s: %w", value, name, err)
+	}
+	return nil
+}
+
+// RenameEnumValue renames value oldValue of the PostgreSQL enum type name to newValue
+// (PostgreSQL 10 or later). It does nothing when the type has newValue but not oldValue,
+// i.e. the value was already renamed.
+func RenameEnumValue(tx *gorm.DB, name, oldValue, newValue string) error {
+	if err := checkEnumDialect(tx); err != nil {
+		return err
+	}
+	labels, err := enumValues(tx, name)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		return fmt.Errorf("enum %s does not exist", name)
+	}
+	if !slices.Contains(labels, oldValue) && slices.Contains(labels, newValue) {
+		return nil
+	}
+	renameSQL := fmt.Sprintf("ALTER TYPE %s RENAME VALUE %s TO %s", quoteQualifiedPostgresName(name), quotePostgresString(oldValue), quotePostgresString(newValue))
+	if err := tx.Exec(renameSQL).Error; err != nil {
+		return fmt.Errorf("failed to rename value %s of enum %s: %w", oldValue, name, err)
+	}
+	return nil
+}
+
+// checkEnumDialect returns an error unless tx is connected to PostgreSQL.
+func checkEnumDialect(tx *gorm.DB) error {
+	if dialectorName := tx.Dialector.Name(); dialectorName != "postgres" {
+		return fmt.Errorf("enum types are not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+	return nil
+}
+
+// enumExists reports whether the type name exists in the search path of db.
+func enumExists(db *gorm.DB, name string) (bool, error) {
+	var exists bool
+	if err := db.Raw("SELECT to_regtype(?) IS NOT NULL", quoteQualifiedPostgresName(name)).Scan(&exists).Error; err != nil {
+		return false, fmt.Errorf("failed to check enum %s: %w", name, err)
+	}
+	return exists, nil
+}
+
+// enumValues returns the values of the enum type name in order, or nil if it does not exist.
+func enumValues(db *gorm.DB, name string) ([]string, error) {
+	exists, err := enumExists(db, name)
+	if err != nil || !exists {
+		return nil, err
+	}
+	labels := []string{}
+	if err := db.Raw("SELECT enumlabel FROM pg_enum WHERE enumtypid = to_regtype(?) ORDER BY enumsortorder", quoteQualifiedPostgresName(name)).Scan(&labels).Error; err != nil {
+		return nil, fmt.Errorf("failed to read values of enum %s: %w", name, err)
+	}
+	return labels, nil
+}
+
+// createEnumSQL returns the statement creating the enum type name with values.
+func createEnumSQL(name string, values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quotePostgresString(value)
+	}
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", quoteQualifiedPostgresName(name), strings.Join(quoted, ", "))
+}
+
+// addEnumValueSQL returns the statement appending value to the enum type name.
+func addEnumValueSQL(name, value string) string {
+	return fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", quoteQualifiedPostgresName(name), quotePostgresString(value))
+}
+
+// quoteQualifiedPostgresName quotes each part of a possibly schema-qualified name.
+func quoteQualifiedPostgresName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quotePostgresIdent(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quotePostgresString quotes s as a PostgreSQL string literal.
+func quotePostgresString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,32 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestEnumSQL tests the statements creating and extending enum types
+func TestEnumSQL(t *testing.T) {
+	if got, expected := createEnumSQL("billing.invoice_status", []string{"draft", "it's paid"}), `CREATE TYPE "billing"."invoice_status" AS ENUM ('draft', 'it''s paid')`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got, expected := addEnumValueSQL("mood", "happy"), `ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'happy'`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// TestEnumUnsupportedDialect tests that enum helpers refuse databases other than PostgreSQL
+func TestEnumUnsupportedDialect(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := CreateEnum(db, "mood", "happy"); err == nil {
+		t.Error("Expected error for CreateEnum on a non-PostgreSQL database, got nil")
+	}
+	if err := AddEnumValue(db, "mood", "sad"); err == nil {
+		t.Error("Expected error for AddEnumValue on a non-PostgreSQL database, got nil")
+	}
+	if err := RenameEnumValue(db, "mood", "sad", "blue"); err == nil {
+		t.Error("Expected error for RenameEnumValue on a non-PostgreSQL database, got nil")
+	}
+}
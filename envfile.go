@@ -0,0 +1,128 @@
+package gormeasy
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// quietEnvFilesEnvVar silences the warning about missing .env files when set, like
+// Options.QuietEnvFiles.
+const quietEnvFilesEnvVar = "GORMEASY_QUIET_ENV_FILES"
+
+// addEnvFlags registers --env and --env-file on fs. The env files are loaded from the raw
+// arguments by envFileArgs before any flag is parsed, so --env-file only needs to be accepted.
+func addEnvFlags(fs *flag.FlagSet, env *string) {
+	fs.StringVar(env, "env", *env, "Environment profile of the database URLs and .env files, e.g. staging (default $GORMEASY_ENV)")
+	fs.Func("env-file", "Load environment variables from this file instead of the .env files, repeatable", func(string) error {
+		return nil
+	})
+}
+
+// envFileArgs returns the values of every --env-file and the last --env of args, the
+// arguments after the program name, before or after the command.
+func envFileArgs(args []string) (files []string, env string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || (name != "env-file" && name != "env") {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				break
+			}
+			i++
+			value = args[i]
+		}
+		if name == "env" {
+			env = value
+		} else {
+			files = append(files, value)
+		}
+	}
+	return files, env
+}
+
+// envFiles returns the .env files of the environment env, most specific first:
+// .env.<env>.local, .env.local, .env.<env> and .env.
+func envFiles(env string) []string {
+	if env == "" {
+		return []string{".env.local", ".env"}
+	}
+	return []string{".env." + env + ".local", ".env.local", ".env." + env, ".env"}
+}
+
+// loadEnvFiles loads the files given with --env-file, which must exist, or else the existing
+// files of the envFiles cascade of env. Variables already set are never overridden, so the
+// real environment wins over every file, a more specific .env file over a general one, and a
+// later --env-file over an earlier one. The database URLs of the .env.<env> files also set the
+// variables of the profile, e.g. DATABASE_URL of .env.staging sets DATABASE_URL_STAGING.
+// When no .env file exists, the cascade is returned for warnMissingEnvFiles.
+func loadEnvFiles(files []string, env string) (missing []string, err error) {
+	if len(files) > 0 {
+		files = slices.Clone(files)
+		slices.Reverse(files)
+		if err := godotenv.Load(files...); err != nil {
+			return nil, fmt.Errorf("failed to load env file: %w", err)
+		}
+		return nil, nil
+	}
+	var found []string
+	for _, file := range envFiles(env) {
+		if _, err := os.Stat(file); err == nil {
+			found = append(found, file)
+		}
+	}
+	if len(found) == 0 {
+		return envFiles(env), nil
+	}
+	if err := setProfileURLs(found, env); err != nil {
+		return nil, err
+	}
+	if err := godotenv.Load(found...); err != nil {
+		return nil, fmt.Errorf("failed to load env file: %w", err)
+	}
+	return nil, nil
+}
+
+// setProfileURLs sets the profile variables of env, e.g. DATABASE_URL_STAGING, from the plain
+// database URLs of the .env.<env> and .env.<env>.local files among files, most specific first,
+// so these files can be shared with the application that reads DATABASE_URL itself.
+func setProfileURLs(files []string, env string) error {
+	if env == "" {
+		return nil
+	}
+	for _, file := range files {
+		if file != ".env."+env && file != ".env."+env+".local" {
+			continue
+		}
+		vars, err := godotenv.Read(file)
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+		for _, u := range urlFlags {
+			name := envVarName(u.envVar, env)
+			if _, ok := os.LookupEnv(name); !ok && vars[u.envVar] != "" {
+				os.Setenv(name, vars[u.envVar])
+			}
+		}
+	}
+	return nil
+}
+
+// warnMissingEnvFiles prints a warning when none of the .env files in missing exists, since
+// the variables may still come from the environment. It is called once the output flags are
+// parsed, so --quiet silences it.
+func warnMissingEnvFiles(missing []string) {
+	if len(missing) > 0 {
+		out.Printf("Warning: no .env file found (%s), using the environment variables\n", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,108 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestEnvFileArgs tests that --env-file and --env are found before and after the command
+func TestEnvFileArgs(t *testing.T) {
+	files, env := envFileArgs([]string{"--env-file", "a.env", "up", "--env-file=b.env", "-env", "staging", "--", "--env", "prod"})
+	if !slices.Equal(files, []string{"a.env", "b.env"}) {
+		t.Errorf("Expected [a.env b.env], got %v", files)
+	}
+	if env != "staging" {
+		t.Errorf("Expected staging, got %s", env)
+	}
+	if files, env := envFileArgs([]string{"up", "--environment=x", "--env"}); files != nil || env != "" {
+		t.Errorf("Expected no env files and environment, got %v %q", files, env)
+	}
+}
+
+// TestLoadEnvFiles tests the precedence of the .env cascade and of repeated --env-file
+func TestLoadEnvFiles(t *testing.T) {
+	for _, key := range []string{"GORMEASY_TEST_A", "GORMEASY_TEST_B", "GORMEASY_TEST_C"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".env", "GORMEASY_TEST_A=env\nGORMEASY_TEST_B=env\nGORMEASY_TEST_C=env\n")
+	write(".env.staging", "GORMEASY_TEST_A=staging\nGORMEASY_TEST_B=staging\n")
+	write(".env.local", "GORMEASY_TEST_A=local\n")
+
+	if _, err := loadEnvFiles(nil, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"GORMEASY_TEST_A": "local", "GORMEASY_TEST_B": "staging", "GORMEASY_TEST_C": "env"} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("Expected %s=%s, got %s", key, want, got)
+		}
+	}
+
+	os.Unsetenv("GORMEASY_TEST_A")
+	write("first.env", "GORMEASY_TEST_A=first\n")
+	write("second.env", "GORMEASY_TEST_A=second\n")
+	if _, err := loadEnvFiles([]string{"first.env", "second.env"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("GORMEASY_TEST_A"); got != "second" {
+		t.Errorf("Expected the later --env-file to win, got %s", got)
+	}
+
+	_, err := loadEnvFiles([]string{"missing.env"}, "")
+	if err == nil || !strings.Contains(err.Error(), "missing.env") {
+		t.Errorf("Expected error for a missing --env-file, got %v", err)
+	}
+
+	missing, err := loadEnvFiles(nil, "prod")
+	if err != nil || slices.Contains(missing, ".env") {
+		t.Errorf("Expected no missing files when .env exists, got %v %v", missing, err)
+	}
+	t.Chdir(t.TempDir())
+	if missing, _ := loadEnvFiles(nil, ""); !slices.Equal(missing, []string{".env.local", ".env"}) {
+		t.Errorf("Expected the cascade to be reported missing, got %v", missing)
+	}
+}
+
+// TestLoadEnvFilesProfileURLs tests that the plain database URLs of .env.<env> files set the
+// variables of the profile, without overriding the environment
+func TestLoadEnvFilesProfileURLs(t *testing.T) {
+	for _, key := range []string{"DATABASE_URL", "DATABASE_URL_STAGING", "OWNER_DATABASE_URL", "OWNER_DATABASE_URL_STAGING"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("OWNER_DATABASE_URL_STAGING", "postgres://owner-from-env")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for name, content := range map[string]string{
+		".env":         "DATABASE_URL=postgres://development\n",
+		".env.staging": "DATABASE_URL=postgres://staging\nOWNER_DATABASE_URL=postgres://owner-staging\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := loadEnvFiles(nil, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	c := &cli{env: "staging"}
+	if err := c.checkEnvironment(); err != nil {
+		t.Errorf("Expected the profile to be defined by .env.staging, got %v", err)
+	}
+	if url := c.resolveURL(urlFlags[0]); url != "postgres://staging" {
+		t.Errorf("Expected the URL of .env.staging, got %s", url)
+	}
+	if url := os.Getenv("OWNER_DATABASE_URL_STAGING"); url != "postgres://owner-from-env" {
+		t.Errorf("Expected the environment to win, got %s", url)
+	}
+}
@@ -0,0 +1,296 @@
+package gormeasy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// erd is the entity-relationship diagram of the tables of a database.
+type erd struct {
+	Tables      []erdTable
+	ForeignKeys []ForeignKey
+}
+
+type erdTable struct {
+	Name    string
+	Columns []erdColumn
+}
+
+type erdColumn struct {
+	Name string
+	// Type is the column type, e.g. varchar(255).
+	Type       string
+	PrimaryKey bool
+	Nullable   bool
+	ForeignKey bool
+}
+
+// erdFormats are the diagram formats of the erd command by name.
+var erdFormats = map[string]func(*erd) string{
+	"mermaid":  (*erd).mermaid,
+	"dbml":     (*erd).dbml,
+	"plantuml": (*erd).plantUML,
+}
+
+// erdFormat returns the diagram format format, or else the format of the extension of out,
+// mermaid by default.
+func erdFormat(format, out string) (string, error) {
+	if format == "" {
+		switch filepath.Ext(out) {
+		case ".dbml":
+			format = "dbml"
+		case ".puml", ".plantuml":
+			format = "plantuml"
+		default:
+			format = "mermaid"
+		}
+	}
+	if _, ok := erdFormats[format]; !ok {
+		return "", fmt.Errorf("unknown format %q, use mermaid, dbml or plantuml", format)
+	}
+	return format, nil
+}
+
+// erdTables returns the tables of all matching one of the glob patterns, all of them when
+// there are none, without the gormeasy history tables.
+func erdTables(all []string, opts Options, patterns []string) ([]string, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	history := historyTables(opts)
+	var tables []string
+	for _, table := range all {
+		if slices.Contains(history, table) {
+			continue
+		}
+		if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(pattern string) bool {
+			ok, _ := path.Match(pattern, table)
+			return ok
+		}) {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// readERD reads the columns of the tables matching patterns and the foreign keys between them,
+// in schema on Postgres, the current schema when empty.
+func readERD(db *gorm.DB, opts Options, schema string, patterns []string) (*erd, error) {
+	var all []string
+	var err error
+	if schema != "" {
+		if dialect := db.Dialector.Name(); dialect != "postgres" {
+			return nil, fmt.Errorf("schemas are only supported on postgres, got %s", dialect)
+		}
+		all, err = schemaTables(db, schema)
+	} else if all, err = db.Migrator().GetTables(); err != nil {
+		err = fmt.Errorf("failed to list tables: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tables, err := erdTables(all, opts, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables match %s", strings.Join(patterns, ","))
+	}
+
+	rows, err := loadForeignKeyRows(db, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	d := &erd{}
+	for _, fk := range foreignKeysOf(rows) {
+		if slices.Contains(tables, fk.Table) && slices.Contains(tables, fk.RefTable) {
+			d.ForeignKeys = append(d.ForeignKeys, fk)
+		}
+	}
+	for _, table := range tables {
+		qualified := table
+		if schema != "" {
+			qualified = schema + "." + table
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(qualified)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		t := erdTable{Name: table}
+		for _, c := range columnTypes {
+			column := erdColumn{Name: c.Name(), Type: strings.ToLower(c.DatabaseTypeName())}
+			if typ, ok := c.ColumnType(); ok && typ != "" {
+				column.Type = strings.ToLower(typ)
+			}
+			column.PrimaryKey, _ = c.PrimaryKey()
+			column.Nullable, _ = c.Nullable()
+			column.ForeignKey = slices.ContainsFunc(d.ForeignKeys, func(fk ForeignKey) bool {
+				return fk.Table == table && slices.Contains(fk.Columns, column.Name)
+			})
+			t.Columns = append(t.Columns, column)
+		}
+		d.Tables = append(d.Tables, t)
+	}
+	return d, nil
+}
+
+// optional reports whether the foreign key fk may be NULL, i.e. a row may have no parent.
+func (d *erd) optional(fk ForeignKey) bool {
+	for _, t := range d.Tables {
+		if t.Name != fk.Table {
+			continue
+		}
+		for _, c := range t.Columns {
+			if c.Nullable && slices.Contains(fk.Columns, c.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mermaidParams and mermaidWord match the type parameters, e.g. (10,2), and the characters
+// not allowed in a Mermaid attribute type.
+var (
+	mermaidParams = regexp.MustCompile(`\(.*\)`)
+	mermaidWord   = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]]+`)
+)
+
+// mermaid returns the diagram as a Mermaid erDiagram.
+func (d *erd) mermaid() string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, t := range d.Tables {
+		fmt.Fprintf(&b, "    %s {\n", t.Name)
+		for _, c := range t.Columns {
+			var keys []string
+			if c.PrimaryKey {
+				keys = append(keys, "PK")
+			}
+			if c.ForeignKey {
+				keys = append(keys, "FK")
+			}
+			fmt.Fprintf(&b, "        %s %s", mermaidWord.ReplaceAllString(mermaidParams.ReplaceAllString(c.Type, ""), "_"), c.Name)
+			if len(keys) > 0 {
+				b.WriteString(" " + strings.Join(keys, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, fk := range d.ForeignKeys {
+		parent := "||"
+		if d.optional(fk) {
+			parent = "|o"
+		}
+		fmt.Fprintf(&b, "    %s %s--o{ %s : %q\n", fk.RefTable, parent, fk.Table, strings.Join(fk.Columns, ", "))
+	}
+	return b.String()
+}
+
+// dbmlName matches the names usable without quotes in DBML.
+var dbmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dbmlQuote returns name, quoted unless it is an identifier.
+func dbmlQuote(name string) string {
+	if dbmlName.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+// dbml returns the diagram in DBML, e.g. for dbdiagram.io.
+func (d *erd) dbml() string {
+	var b strings.Builder
+	for i, t := range d.Tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Table %s {\n", dbmlQuote(t.Name))
+		for _, c := range t.Columns {
+			var settings []string
+			if c.PrimaryKey {
+				settings = append(settings, "pk")
+			} else if !c.Nullable {
+				settings = append(settings, "not null")
+			}
+			fmt.Fprintf(&b, "  %s %s", dbmlQuote(c.Name), dbmlQuote(c.Type))
+			if len(settings) > 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(settings, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	}
+	if len(d.ForeignKeys) > 0 {
+		b.WriteString("\n")
+	}
+	columns := func(table string, names []string) string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = dbmlQuote(name)
+		}
+		if len(quoted) == 1 {
+			return dbmlQuote(table) + "." + quoted[0]
+		}
+		return dbmlQuote(table) + ".(" + strings.Join(quoted, ", ") + ")"
+	}
+	for _, fk := range d.ForeignKeys {
+		fmt.Fprintf(&b, "Ref %s: %s > %s\n", dbmlQuote(fk.Name), columns(fk.Table, fk.Columns), columns(fk.RefTable, fk.RefColumns))
+	}
+	return b.String()
+}
+
+// plantUML returns the diagram as a PlantUML entity diagram in IE notation, with mandatory
+// columns marked with *.
+func (d *erd) plantUML() string {
+	var b strings.Builder
+	b.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n")
+	for _, t := range d.Tables {
+		fmt.Fprintf(&b, "\nentity %q {\n", t.Name)
+		column := func(c erdColumn) {
+			mark := ""
+			if !c.Nullable {
+				mark = "* "
+			}
+			fmt.Fprintf(&b, "  %s%s : %s", mark, c.Name, c.Type)
+			if c.ForeignKey {
+				b.WriteString(" <<FK>>")
+			}
+			b.WriteString("\n")
+		}
+		for _, c := range t.Columns {
+			if c.PrimaryKey {
+				column(c)
+			}
+		}
+		b.WriteString("  --\n")
+		for _, c := range t.Columns {
+			if !c.PrimaryKey {
+				column(c)
+			}
+		}
+		b.WriteString("}\n")
+	}
+	if len(d.ForeignKeys) > 0 {
+		b.WriteString("\n")
+	}
+	for _, fk := range d.ForeignKeys {
+		parent := "||"
+		if d.optional(fk) {
+			parent = "|o"
+		}
+		fmt.Fprintf(&b, "%q %s--o{ %q : %s\n", fk.RefTable, parent, fk.Table, strings.Join(fk.Columns, ", "))
+	}
+	b.WriteString("@enduml\n")
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// testERD is a diagram of users, their orders and the order lines, keyed by order and tenant
+var testERD = &erd{
+	Tables: []erdTable{
+		{Name: "users", Columns: []erdColumn{
+			{Name: "id", Type: "bigint", PrimaryKey: true},
+			{Name: "email", Type: "character varying(255)"},
+		}},
+		{Name: "orders", Columns: []erdColumn{
+			{Name: "id", Type: "bigint", PrimaryKey: true},
+			{Name: "tenant_id", Type: "bigint", PrimaryKey: true},
+			{Name: "user_id", Type: "bigint", Nullable: true, ForeignKey: true},
+			{Name: "total", Type: "numeric(10,2)"},
+		}},
+		{Name: "order lines", Columns: []erdColumn{
+			{Name: "order_id", Type: "bigint", ForeignKey: true},
+			{Name: "tenant_id", Type: "bigint", ForeignKey: true},
+		}},
+	},
+	ForeignKeys: []ForeignKey{
+		{Name: "fk_orders_user", Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		{Name: "fk_lines_order", Table: "order lines", Columns: []string{"order_id", "tenant_id"}, RefTable: "orders", RefColumns: []string{"id", "tenant_id"}},
+	},
+}
+
+// TestERD tests the Mermaid, DBML and PlantUML diagrams
+func TestERD(t *testing.T) {
+	for format, expected := range map[string][]string{
+		"mermaid": {
+			"erDiagram\n    users {\n        bigint id PK\n        character_varying email\n    }\n",
+			"        numeric total\n",
+			"    users |o--o{ orders : \"user_id\"\n",
+		},
+		"dbml": {
+			"Table users {\n  id bigint [pk]\n  email \"character varying(255)\" [not null]\n}\n",
+			"Table \"order lines\" {\n",
+			"Ref fk_orders_user: orders.user_id > users.id\n",
+			"Ref fk_lines_order: \"order lines\".(order_id, tenant_id) > orders.(id, tenant_id)\n",
+		},
+		"plantuml": {
+			"@startuml\n",
+			"entity \"orders\" {\n  * id : bigint\n  * tenant_id : bigint\n  --\n  user_id : bigint <<FK>>\n",
+			"\"orders\" ||--o{ \"order lines\" : order_id, tenant_id\n",
+			"@enduml\n",
+		},
+	} {
+		diagram := erdFormats[format](testERD)
+		for _, e := range expected {
+			if !strings.Contains(diagram, e) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", format, e, diagram)
+			}
+		}
+	}
+}
+
+// TestERDFormat tests the diagram format of the --format flag and the --out extension
+func TestERDFormat(t *testing.T) {
+	for _, tt := range []struct{ format, out, expected string }{
+		{"", "schema.mmd", "mermaid"},
+		{"", "", "mermaid"},
+		{"", "docs/schema.dbml", "dbml"},
+		{"", "schema.puml", "plantuml"},
+		{"dbml", "schema.txt", "dbml"},
+	} {
+		format, err := erdFormat(tt.format, tt.out)
+		if err != nil || format != tt.expected {
+			t.Errorf("erdFormat(%q, %q) = %q, %v, expected %q", tt.format, tt.out, format, err, tt.expected)
+		}
+	}
+	if _, err := erdFormat("graphviz", ""); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+// TestERDTables tests the table globs of the erd command
+func TestERDTables(t *testing.T) {
+	all := []string{"migrations", "users", "billing_invoices", "billing_payments", "orders"}
+	tables, err := erdTables(all, Options{}, nil)
+	if err != nil || strings.Join(tables, ",") != "users,billing_invoices,billing_payments,orders" {
+		t.Errorf("Expected all tables but the history, got %v, %v", tables, err)
+	}
+	tables, err = erdTables(all, Options{}, []string{"billing_*", "users"})
+	if err != nil || strings.Join(tables, ",") != "users,billing_invoices,billing_payments" {
+		t.Errorf("Expected the matching tables, got %v, %v", tables, err)
+	}
+	if _, err := erdTables(all, Options{}, []string{"billing_["}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
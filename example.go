@@ -0,0 +1,137 @@
+package gormeasy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// defaultExampleDatabase is the disposable database used by the example command.
+const defaultExampleDatabase = "gormeasy_example"
+
+// exampleMigrations are the migrations run by the example command: the users, orders and
+// feedbacks of the example application in example/, with an index built concurrently. They
+// only use portable column types so they run on every database gormeasy supports.
+func exampleMigrations() []*Migration {
+	type user struct {
+		ID        uint      `gorm:"primaryKey"`
+		CreatedAt time.Time `gorm:"autoCreateTime;index"`
+		Name      string    `gorm:"type:varchar(64);index"`
+		Email     string    `gorm:"type:varchar(255);unique"`
+		Role      string    `gorm:"type:varchar(64);default:'customer'"`
+	}
+	type order struct {
+		ID       uint   `gorm:"primaryKey"`
+		UserID   uint   `gorm:"index"`
+		Amount   int    `gorm:"default:0"`
+		Currency string `gorm:"type:varchar(10);default:'usd'"`
+	}
+	type feedback struct {
+		ID     uint   `gorm:"primaryKey"`
+		UserID uint   `gorm:"index"`
+		Title  string `gorm:"type:varchar(255);index"`
+		Rating int    `gorm:"default:0"`
+	}
+	type feedbackContent struct {
+		ID         uint   `gorm:"primaryKey"`
+		FeedbackID uint   `gorm:"index"`
+		Content    string `gorm:"type:varchar(4096)"`
+	}
+
+	return []*Migration{
+		{
+			ID: "20240101000000-create-users",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&user{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return DropTable(tx, "users")
+			},
+		},
+		{
+			ID: "20240102000000-create-orders",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&order{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return DropTable(tx, "orders")
+			},
+		},
+		{
+			ID: "20240102120000-create-feedbacks",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&feedback{}, &feedbackContent{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return DropTable(tx, "feedbacks", "feedback_contents")
+			},
+		},
+		{
+			ID: "20240103000000-index-orders-currency",
+			Migrate: func(tx *gorm.DB) error {
+				return CreateIndexConcurrently(tx, "idx_orders_currency", "orders", "currency")
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return DropIndexConcurrently(tx, "idx_orders_currency")
+			},
+		},
+	}
+}
+
+// runExample runs the example migrations on the disposable database dbName on the server
+// of ownerURL and prints the resulting schema. Unless keep is set the database is deleted
+// afterwards.
+func runExample(ownerURL, dbName string, keep bool, protected []string, getGormFromURL func(string) (*gorm.DB, error)) error {
+	return withDisposableDatabase(ownerURL, dbName, keep, protected, getGormFromURL, showExample)
+}
+
+// runSQLiteExample runs the example migrations on a new SQLite database dbName in a
+// temporary directory, so the example needs no database server. Unless keep is set the
+// directory is deleted afterwards.
+func runSQLiteExample(dbName string, keep bool) error {
+	dir, err := os.MkdirTemp("", "gormeasy-example-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	path := filepath.Join(dir, dbName+".db")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	err = showExample(out.applyLogger(db))
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		sqlDB.Close()
+	}
+	if keep {
+		out.Printf("Database kept: %s\n", path)
+		return err
+	}
+	if removeErr := os.RemoveAll(dir); err == nil && removeErr != nil {
+		err = fmt.Errorf("failed to remove %s: %w", dir, removeErr)
+	}
+	return err
+}
+
+// showExample runs the example migrations on db and prints the migration status and the
+// resulting schema.
+func showExample(db *gorm.DB) error {
+	migrations := exampleMigrations()
+	opts := Options{}.withDefaults()
+	// Prints the migration status after applying the migrations
+	if err := RunMigrationsWithOptions(db, migrations, opts); err != nil {
+		return err
+	}
+
+	dump, err := dumpSchema(db, historyTables(opts)...)
+	if err != nil {
+		return err
+	}
+	out.Println("\n=== Resulting Schema ===")
+	out.Println(dump.SQL)
+	return nil
+}
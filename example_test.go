@@ -0,0 +1,41 @@
+package gormeasy
+
+import (
+	"os"
+	"testing"
+)
+
+// TestExampleMigrations tests that the bundled example migrations are valid and ordered
+func TestExampleMigrations(t *testing.T) {
+	migrations := exampleMigrations()
+	if err := checkDuplicateIDs(migrations); err != nil {
+		t.Fatalf("Expected no duplicate IDs, got %v", err)
+	}
+	for _, m := range migrations {
+		if m.Migrate == nil || m.Rollback == nil {
+			t.Errorf("Expected migration %s to define Migrate and Rollback", m.ID)
+		}
+	}
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i, m := range sorted {
+		if m.ID != migrations[i].ID {
+			t.Errorf("Expected migration %s at position %d, got %s", migrations[i].ID, i, m.ID)
+		}
+	}
+}
+
+// TestRunSQLiteExample tests that the example runs without a database server and removes
+// its temporary database
+func TestRunSQLiteExample(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	if err := runSQLiteExample(defaultExampleDatabase, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entries, err := os.ReadDir(tmp); err != nil || len(entries) != 0 {
+		t.Errorf("Expected the temporary database to be removed, got %v %v", entries, err)
+	}
+}
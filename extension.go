@@ -0,0 +1,71 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// EnsureExtension enables the PostgreSQL extensions names (e.g. "pgcrypto", "uuid-ossp",
+// "postgis") in the database of tx, skipping those already enabled. Call it from the first
+// migration that needs an extension, e.g. before a gen_random_uuid() default, so a fresh
+// cluster fails loudly instead of the default silently missing. Extensions are PostgreSQL
+// only: on other databases it returns an error, use OnlyDialects to skip the migration there.
+func EnsureExtension(tx *gorm.DB, names ...string) error {
+	if dialectorName := tx.Dialector.Name(); dialectorName != "postgres" {
+		return fmt.Errorf("extensions are not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+	for _, name := range names {
+		var exists bool
+		if err := tx.Raw("SELECT EXISTS(SELECT FROM pg_extension WHERE extname = ?)", name).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check extension %s: %w", name, err)
+		}
+		if exists {
+			out.Verbosef("Extension already enabled: %s\n", name)
+			continue
+		}
+		if err := tx.Exec(createExtensionSQL(name)).Error; err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", name, err)
+		}
+		out.Printf("✅ Enabled extension: %s\n", name)
+	}
+	return nil
+}
+
+// createExtensionSQL returns the statement enabling extension name.
+func createExtensionSQL(name string) string {
+	return fmt.Sprintf(`CREATE EXTENSION IF NOT EXISTS "%s"`, strings.ReplaceAll(name, `"`, `""`))
+}
+
+// mergeExtensions returns the extensions of Options.Extensions followed by the comma-separated
+// extensions of the --with-extensions flag, without duplicates.
+func mergeExtensions(declared []string, flag string) []string {
+	extensions := slices.Clone(declared)
+	for _, name := range splitList(flag) {
+		if !slices.Contains(extensions, name) {
+			extensions = append(extensions, name)
+		}
+	}
+	return extensions
+}
+
+// createExtensions connects to the new database dbName on the server of ownerURL and enables extensions.
+func createExtensions(ownerURL, dbName string, extensions []string, getGormFromURL func(string) (*gorm.DB, error)) error {
+	if len(extensions) == 0 {
+		return nil
+	}
+	dbURL, err := withDatabaseName(ownerURL, dbName)
+	if err != nil {
+		return err
+	}
+	db, err := getGorm(dbURL, getGormFromURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %w", dbName, err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	return EnsureExtension(db, extensions...)
+}
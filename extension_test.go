@@ -0,0 +1,32 @@
+package gormeasy
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestMergeExtensions tests merging the declared extensions with the --with-extensions flag
+func TestMergeExtensions(t *testing.T) {
+	declared := []string{"pgcrypto"}
+	got := mergeExtensions(declared, "uuid-ossp, pgcrypto,postgis")
+	if expected := []string{"pgcrypto", "uuid-ossp", "postgis"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if len(declared) != 1 {
+		t.Errorf("Expected the declared extensions to be left as is, got %v", declared)
+	}
+}
+
+// TestEnsureExtension tests the extension statement and that other databases are rejected
+func TestEnsureExtension(t *testing.T) {
+	if got := createExtensionSQL("uuid-ossp"); got != `CREATE EXTENSION IF NOT EXISTS "uuid-ossp"` {
+		t.Errorf(`Expected CREATE EXTENSION IF NOT EXISTS "uuid-ossp", got %s`, got)
+	}
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := EnsureExtension(db, "pgcrypto"); err == nil {
+		t.Error("Expected an error for a database without extensions")
+	}
+}
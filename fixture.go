@@ -0,0 +1,318 @@
+package gormeasy
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// fixtureLabelColumn is the CSV column holding the labels of fixture rows.
+const fixtureLabelColumn = "_label"
+
+// maxFixtureID bounds the IDs derived from fixture labels, so they fit in a 32-bit integer column.
+const maxFixtureID = 1<<30 - 1
+
+// fixtureRow is a row of a fixture table. Label is empty for rows that can't be referenced.
+type fixtureRow struct {
+	Label  string
+	Values map[string]any
+}
+
+// fixtureTable is the rows of a table, from all fixture files in file name order.
+type fixtureTable struct {
+	Name string
+	Rows []*fixtureRow
+	// DependsOn are the tables referenced by the rows.
+	DependsOn []string
+}
+
+// LoadFixtures replaces the rows of the tables found in the fixture files of dir of fsys with
+// the fixture rows, in one transaction. YAML and JSON files map tables to rows by label:
+//
+//	users:
+//	  alice: {email: alice@example.com}
+//	orders:
+//	  first: {user_id: "@users.alice", total: 10}
+//
+// A <table>.csv file holds the rows of table under a header of column names, with the
+// label in an optional _label column and \N for NULL. A value "@table.label" is replaced by
+// the id of the labelled row, which gets an id derived from its label when it has none, and
+// tables are inserted after the tables they reference. Values starting with "@@" are
+// inserted with a single "@". Existing rows are deleted in reverse order first. Sequences
+// are reset on PostgreSQL afterwards, see ResetSequences.
+func LoadFixtures(db *gorm.DB, fsys fs.FS, dir string) error {
+	tables, err := readFixtures(fsys, dir)
+	if err != nil {
+		return err
+	}
+	ordered, err := resolveFixtures(tables)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range slices.Backward(ordered) {
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s", tx.Statement.Quote(t.Name))).Error; err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", t.Name, err)
+			}
+		}
+		for _, t := range ordered {
+			for _, row := range t.Rows {
+				if err := tx.Table(t.Name).Create(row.Values).Error; err != nil {
+					return fmt.Errorf("failed to insert fixture %s: %w", row.name(t.Name), err)
+				}
+			}
+			out.Printf("  - %s: %d rows\n", t.Name, len(t.Rows))
+		}
+		if tx.Dialector.Name() == "postgres" {
+			if err := ResetSequences(tx); err != nil {
+				return err
+			}
+		}
+		out.Println("✅ Fixtures loaded from:", dir)
+		return nil
+	})
+}
+
+// name returns the name of the row in error messages.
+func (r *fixtureRow) name(table string) string {
+	if r.Label == "" {
+		return table + " row"
+	}
+	return table + "." + r.Label
+}
+
+// readFixtures reads the .yaml, .yml, .json and .csv files of dir, in file name order.
+func readFixtures(fsys fs.FS, dir string) ([]*fixtureTable, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures dir %s: %w", dir, err)
+	}
+
+	var tables []*fixtureTable
+	add := func(name string, rows []*fixtureRow) {
+		i := slices.IndexFunc(tables, func(t *fixtureTable) bool { return t.Name == name })
+		if i < 0 {
+			tables = append(tables, &fixtureTable{Name: name})
+			i = len(tables) - 1
+		}
+		tables[i].Rows = append(tables[i].Rows, rows...)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		ext := path.Ext(name)
+		if e.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json", ".csv"}, ext) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if ext == ".csv" {
+			rows, err := parseCSVFixture(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			add(strings.TrimSuffix(name, ext), rows)
+			continue
+		}
+		// JSON is valid YAML, and the YAML parser keeps the order of tables and rows
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("failed to parse %s: fixtures must map tables to rows", name)
+		}
+		for i := 0; i < len(root.Content); i += 2 {
+			table := root.Content[i].Value
+			rows, err := parseFixtureRows(root.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: table %s: %w", name, table, err)
+			}
+			add(table, rows)
+		}
+	}
+	return tables, nil
+}
+
+// parseFixtureRows parses the rows of a table, a mapping of labels to rows or a list of
+// rows without labels.
+func parseFixtureRows(node *yaml.Node) ([]*fixtureRow, error) {
+	var rows []*fixtureRow
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			values, err := parseFixtureValues(node.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("row %s: %w", node.Content[i].Value, err)
+			}
+			rows = append(rows, &fixtureRow{Label: node.Content[i].Value, Values: values})
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			values, err := parseFixtureValues(item)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			rows = append(rows, &fixtureRow{Values: values})
+		}
+	default:
+		return nil, fmt.Errorf("rows must be a mapping of labels to rows or a list of rows")
+	}
+	return rows, nil
+}
+
+// parseFixtureValues parses a row, a mapping of columns to values. Nested mappings and
+// lists are stored as JSON, e.g. in json and jsonb columns.
+func parseFixtureValues(node *yaml.Node) (map[string]any, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("row must be a mapping of columns to values")
+	}
+	var values map[string]any
+	if err := node.Decode(&values); err != nil {
+		return nil, err
+	}
+	for column, value := range values {
+		switch value.(type) {
+		case map[string]any, []any:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+			values[column] = string(data)
+		}
+	}
+	return values, nil
+}
+
+// parseCSVFixture parses the rows of a CSV fixture file.
+func parseCSVFixture(content string) ([]*fixtureRow, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	columns, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var rows []*fixtureRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := &fixtureRow{Values: make(map[string]any, len(columns))}
+		for i, column := range columns {
+			switch {
+			case column == fixtureLabelColumn:
+				row.Label = record[i]
+			case record[i] == csvNull:
+				row.Values[column] = nil
+			default:
+				row.Values[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resolveFixtures replaces the references of tables with the ids of the rows they reference,
+// and returns tables ordered so that every table comes after the tables it references.
+func resolveFixtures(tables []*fixtureTable) ([]*fixtureTable, error) {
+	labels := make(map[string]*fixtureRow)
+	for _, t := range tables {
+		for _, row := range t.Rows {
+			if row.Label == "" {
+				continue
+			}
+			key := t.Name + "." + row.Label
+			if labels[key] != nil {
+				return nil, fmt.Errorf("duplicate fixture %s", key)
+			}
+			labels[key] = row
+		}
+	}
+
+	// ids records the derived ids of each table to detect collisions between labels
+	ids := make(map[string]map[int64]string)
+	rowID := func(table string, row *fixtureRow) (any, error) {
+		if id, ok := row.Values["id"]; ok {
+			return id, nil
+		}
+		id := fixtureID(row.Label)
+		if other, ok := ids[table][id]; ok {
+			return nil, fmt.Errorf("fixtures %s.%s and %s.%s derive the same id %d, set the id of one of them", table, other, table, row.Label, id)
+		}
+		if ids[table] == nil {
+			ids[table] = make(map[int64]string)
+		}
+		ids[table][id] = row.Label
+		row.Values["id"] = id
+		return id, nil
+	}
+
+	names := make([]string, len(tables))
+	deps := make(map[string][]string)
+	for i, t := range tables {
+		names[i] = t.Name
+		for _, row := range t.Rows {
+			for column, value := range row.Values {
+				s, ok := value.(string)
+				if !ok || !strings.HasPrefix(s, "@") {
+					continue
+				}
+				if strings.HasPrefix(s, "@@") {
+					row.Values[column] = s[1:]
+					continue
+				}
+				table, _, ok := strings.Cut(s[1:], ".")
+				target := labels[s[1:]]
+				if !ok || target == nil {
+					return nil, fmt.Errorf("fixture %s references unknown fixture %s in column %s", row.name(t.Name), s[1:], column)
+				}
+				id, err := rowID(table, target)
+				if err != nil {
+					return nil, err
+				}
+				row.Values[column] = id
+				if table != t.Name && !slices.Contains(t.DependsOn, table) {
+					t.DependsOn = append(t.DependsOn, table)
+				}
+			}
+		}
+		slices.Sort(t.DependsOn)
+		deps[t.Name] = t.DependsOn
+	}
+
+	order, err := topologicalOrder("fixture table", names, deps)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]*fixtureTable, len(order))
+	for i, name := range order {
+		ordered[i] = tables[slices.Index(names, name)]
+	}
+	return ordered, nil
+}
+
+// fixtureID returns the id derived from label, stable across runs so tests can rely on it.
+func fixtureID(label string) int64 {
+	return int64(crc32.ChecksumIEEE([]byte(label))%maxFixtureID) + 1
+}
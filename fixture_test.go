@@ -0,0 +1,80 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestResolveFixtures tests reading fixture files and resolving references between them
+func TestResolveFixtures(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/1-orders.yaml": {Data: []byte(`
+orders:
+  first: {user_id: "@users.alice", total: 10, meta: {gift: true}}
+  second: {user_id: "@users.bob", note: "@@home"}
+`)},
+		"fixtures/2-users.json": {Data: []byte(`{"users": {"alice": {"email": "alice@example.com"}}}`)},
+		"fixtures/users.csv":    {Data: []byte("_label,id,email,name\nbob,7,bob@example.com,\\N\n")},
+		"fixtures/README.md":    {Data: []byte("notes")},
+	}
+	tables, err := readFixtures(fsys, "fixtures")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ordered, err := resolveFixtures(tables)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ordered) != 2 || ordered[0].Name != "users" || ordered[1].Name != "orders" {
+		t.Fatalf("Expected tables [users orders], got %v", ordered)
+	}
+
+	users, orders := ordered[0].Rows, ordered[1].Rows
+	if len(users) != 2 || users[0].Label != "alice" || users[1].Label != "bob" {
+		t.Fatalf("Expected users alice and bob, got %v", users)
+	}
+	if id := users[0].Values["id"]; id != fixtureID("alice") {
+		t.Errorf("Expected alice to get id %d, got %v", fixtureID("alice"), id)
+	}
+	if users[1].Values["name"] != nil {
+		t.Errorf("Expected \\N to be NULL, got %v", users[1].Values["name"])
+	}
+	if got := orders[0].Values["user_id"]; got != fixtureID("alice") {
+		t.Errorf("Expected first order to reference alice, got %v", got)
+	}
+	if got := orders[1].Values["user_id"]; got != "7" {
+		t.Errorf("Expected second order to reference the id of bob, got %v", got)
+	}
+	if got := orders[0].Values["meta"]; got != `{"gift":true}` {
+		t.Errorf("Expected nested values as JSON, got %v", got)
+	}
+	if got := orders[1].Values["note"]; got != "@home" {
+		t.Errorf("Expected @@ to be unescaped, got %v", got)
+	}
+}
+
+// TestResolveFixturesErrors tests unknown references and reference cycles
+func TestResolveFixturesErrors(t *testing.T) {
+	cases := map[string]string{
+		"orders:\n  first: {user_id: \"@users.alice\"}\n":                                         "unknown fixture users.alice",
+		"a:\n  x: {b_id: \"@b.y\"}\nb:\n  y: {a_id: \"@a.x\"}\n":                                  "dependency cycle",
+		"users:\n  alice: {email: a}\norders:\n  - {user_id: \"@users.alice\"}\n  - {total: 1}\n": "",
+	}
+	for content, want := range cases {
+		tables, err := readFixtures(fstest.MapFS{"f.yaml": {Data: []byte(content)}}, ".")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err = resolveFixtures(tables)
+		if want == "" {
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package gormeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// FixtureExportOptions configures ExportFixtures.
+type FixtureExportOptions struct {
+	// Format is "yaml" or "json". Defaults to "yaml".
+	Format string
+	// Limit is the maximum number of rows exported per table, 0 for all rows.
+	Limit int
+	// Mask lists the columns whose values are masked, as column for every table or table.column.
+	// Masked values become "masked-" and a hash of the value, so equal values stay equal,
+	// e.g. in unique columns. Only mask text columns.
+	Mask []string
+	// Anonymize are the rules replacing personal data in the exported values, see Anonymize.
+	Anonymize []AnonymizeRule
+	// AnonymizeSecret keys the hashes of Mask and Anonymize, see Options.AnonymizeSecret.
+	AnonymizeSecret string
+}
+
+// ExportFixtures writes the rows of tables to fixture files in dir, one <table>.yaml or
+// <table>.json file per table, which LoadFixtures and load-fixtures read back. Rows are
+// written as a list in the order the database returns them, with their ids, so references
+// between tables are kept as is. With a Limit, export the rows referenced by other tables too.
+func ExportFixtures(db *gorm.DB, tables []string, dir string, opts FixtureExportOptions) error {
+	if len(tables) == 0 {
+		return fmt.Errorf("at least one table is required")
+	}
+	format := opts.Format
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("unknown fixture format %q, use yaml or json", format)
+	}
+	if len(opts.Anonymize) > 0 {
+		if err := validateAnonymizeRules(opts.Anonymize); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", dir, err)
+	}
+
+	for _, table := range tables {
+		columns, rows, err := exportFixtureRows(db, table, opts)
+		if err != nil {
+			return fmt.Errorf("failed to export table %s: %w", table, err)
+		}
+		var data []byte
+		if format == "json" {
+			data, err = fixtureJSON(table, columns, rows)
+		} else {
+			data, err = fixtureYAML(table, columns, rows)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode table %s: %w", table, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, table+"."+format), data, 0644); err != nil {
+			return fmt.Errorf("failed to write fixtures of %s: %w", table, err)
+		}
+		out.Printf("  - %s: %d rows\n", table, len(rows))
+	}
+	out.Println("✅ Fixtures saved in:", dir)
+	return nil
+}
+
+// exportFixtureRows returns the columns and rows of table, with the masked columns of opts masked.
+func exportFixtureRows(db *gorm.DB, table string, opts FixtureExportOptions) ([]string, [][]any, error) {
+	query := db.Table(table)
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	strategies := anonymizeRulesFor(opts.Anonymize, table, columns)
+	for i, column := range columns {
+		if slices.Contains(opts.Mask, column) || slices.Contains(opts.Mask, table+"."+column) {
+			strategies[i] = AnonymizeMask
+		}
+	}
+
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			if strategies[i] != "" {
+				v = anonymizeValue(strategies[i], v, opts.AnonymizeSecret)
+			}
+			values[i] = fixtureValue(v)
+		}
+		result = append(result, values)
+	}
+	return columns, result, rows.Err()
+}
+
+// fixtureValue converts a scanned database value to a value of a fixture file. Strings
+// starting with @ are escaped, so LoadFixtures doesn't take them for references.
+func fixtureValue(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return fixtureValue(string(v))
+	case string:
+		if strings.HasPrefix(v, "@") {
+			return "@" + v
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// fixtureYAML returns the YAML fixture file of the rows of table, keeping the column order.
+func fixtureYAML(table string, columns []string, rows [][]any) ([]byte, error) {
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range rows {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for i, column := range columns {
+			value := &yaml.Node{}
+			if err := value.Encode(row[i]); err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: column}, value)
+		}
+		list.Content = append(list.Content, mapping)
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: table}, list}}
+	return yaml.Marshal(doc)
+}
+
+// fixtureJSON returns the JSON fixture file of the rows of table, one row per line, keeping
+// the column order.
+func fixtureJSON(table string, columns []string, rows [][]any) ([]byte, error) {
+	var buf bytes.Buffer
+	encode := func(v any) error {
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		// Encode ends with a newline
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+
+	buf.WriteString("{\n  ")
+	if err := encode(table); err != nil {
+		return nil, err
+	}
+	buf.WriteString(": [")
+	for r, row := range rows {
+		if r > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n    {")
+		for i, column := range columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := encode(column); err != nil {
+				return nil, err
+			}
+			buf.WriteString(": ")
+			if err := encode(row[i]); err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+		}
+		buf.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n  ")
+	}
+	buf.WriteString("]\n}\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,54 @@
+package gormeasy
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestFixtureFiles tests that exported YAML and JSON fixtures are read back by LoadFixtures
+func TestFixtureFiles(t *testing.T) {
+	columns := []string{"id", "email", "note", "created_at"}
+	rows := [][]any{
+		{int64(2), anonymizeValue(AnonymizeMask, "bob@example.com", "s3cret"), nil, fixtureValue(time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC))},
+		{int64(1), "a<b>&c", fixtureValue([]byte("@home")), "2024"},
+	}
+	dir := t.TempDir()
+	yamlData, err := fixtureYAML("users", columns, rows)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	jsonData, err := fixtureJSON("accounts", columns, rows)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := os.WriteFile(dir+"/users.yaml", yamlData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/accounts.json", jsonData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := readFixtures(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := resolveFixtures(tables); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("Expected 2 tables, got %d", len(tables))
+	}
+	for _, table := range tables {
+		if len(table.Rows) != 2 {
+			t.Fatalf("Expected 2 rows in %s, got %d", table.Name, len(table.Rows))
+		}
+		first, second := table.Rows[0].Values, table.Rows[1].Values
+		if first["id"] != 2 || first["note"] != nil || first["created_at"] != "2024-01-15T08:00:00Z" {
+			t.Errorf("Expected the first row of %s unchanged, got %v", table.Name, first)
+		}
+		if second["email"] != "a<b>&c" || second["note"] != "@home" || second["created_at"] != "2024" {
+			t.Errorf("Expected the second row of %s unchanged, got %v", table.Name, second)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// FlagProvider looks up the feature flags gating migrations with Migration.GateFlag,
+// e.g. by asking the feature flag service for the current environment.
+type FlagProvider interface {
+	FlagEnabled(ctx context.Context, flag string) (bool, error)
+}
+
+// FlagProviderFunc adapts a function to a FlagProvider.
+type FlagProviderFunc func(ctx context.Context, flag string) (bool, error)
+
+// FlagEnabled calls f.
+func (f FlagProviderFunc) FlagEnabled(ctx context.Context, flag string) (bool, error) {
+	return f(ctx, flag)
+}
+
+// StaticFlags is a FlagProvider with a fixed set of flags. Flags missing from the map are off.
+type StaticFlags map[string]bool
+
+// FlagEnabled reports whether flag is set to true.
+func (f StaticFlags) FlagEnabled(ctx context.Context, flag string) (bool, error) {
+	return f[flag], nil
+}
+
+// overrideFlags is a FlagProvider answering the flags listed in static itself
+// and asking next for all others.
+type overrideFlags struct {
+	static StaticFlags
+	next   FlagProvider
+}
+
+// FlagEnabled returns the static value of flag if it has one, otherwise asks next.
+func (f overrideFlags) FlagEnabled(ctx context.Context, flag string) (bool, error) {
+	if enabled, ok := f.static[flag]; ok || f.next == nil {
+		return enabled, nil
+	}
+	return f.next.FlagEnabled(ctx, flag)
+}
+
+// gatedMigrations returns the IDs of the pending migrations whose GateFlag is off.
+// Every flag is looked up once. Without a FlagProvider every flag is off.
+func gatedMigrations(db *gorm.DB, migrations []*Migration, applied map[string]bool, provider FlagProvider) (map[string]string, error) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	enabled := make(map[string]bool)
+	gated := make(map[string]string)
+	for _, m := range migrations {
+		if m.GateFlag == "" || applied[m.ID] {
+			continue
+		}
+		on, ok := enabled[m.GateFlag]
+		if !ok && provider != nil {
+			var err error
+			if on, err = provider.FlagEnabled(ctx, m.GateFlag); err != nil {
+				return nil, fmt.Errorf("failed to look up flag %s of %s: %w", m.GateFlag, m.ID, err)
+			}
+			enabled[m.GateFlag] = on
+		}
+		if !on {
+			gated[m.ID] = m.GateFlag
+		}
+	}
+	return gated, nil
+}
@@ -0,0 +1,71 @@
+package gormeasy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestGatedMigrations tests that pending migrations behind an off flag are held back
+func TestGatedMigrations(t *testing.T) {
+	migrations := []*Migration{
+		{ID: "1"},
+		{ID: "2", GateFlag: "billing"},
+		{ID: "3", GateFlag: "billing"},
+		{ID: "4", GateFlag: "search"},
+		{ID: "5", GateFlag: "reports"},
+	}
+	applied := map[string]bool{"1": true, "5": true}
+
+	lookups := 0
+	provider := FlagProviderFunc(func(ctx context.Context, flag string) (bool, error) {
+		lookups++
+		return flag == "search", nil
+	})
+	gated, err := gatedMigrations(&gorm.DB{Statement: &gorm.Statement{}}, migrations, applied, provider)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(gated) != 2 || gated["2"] != "billing" || gated["3"] != "billing" {
+		t.Errorf("Expected 2 and 3 gated by billing, got %v", gated)
+	}
+	if lookups != 2 {
+		t.Errorf("Expected 2 flag lookups, got %d", lookups)
+	}
+
+	gated, err = gatedMigrations(&gorm.DB{Statement: &gorm.Statement{}}, migrations, applied, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(gated) != 3 {
+		t.Errorf("Expected every gated pending migration held back without a provider, got %v", gated)
+	}
+
+	failing := FlagProviderFunc(func(ctx context.Context, flag string) (bool, error) {
+		return false, errors.New("flag service unavailable")
+	})
+	if _, err := gatedMigrations(&gorm.DB{Statement: &gorm.Statement{}}, migrations, applied, failing); err == nil {
+		t.Error("Expected an error when the flag lookup fails")
+	}
+}
+
+// TestOverrideFlags tests that static flags take precedence over the next provider
+func TestOverrideFlags(t *testing.T) {
+	provider := overrideFlags{
+		static: StaticFlags{"billing": false},
+		next:   StaticFlags{"billing": true, "search": true},
+	}
+	for flag, want := range map[string]bool{"billing": false, "search": true, "reports": false} {
+		got, err := provider.FlagEnabled(context.Background(), flag)
+		if err != nil || got != want {
+			t.Errorf("Expected %s to be %v, got %v (%v)", flag, want, got, err)
+		}
+	}
+
+	got, _ := overrideFlags{static: StaticFlags{"billing": true}}.FlagEnabled(context.Background(), "search")
+	if got {
+		t.Error("Expected flags missing without a next provider to be off")
+	}
+}
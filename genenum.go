@@ -0,0 +1,274 @@
+package gormeasy
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// genEnum is a Go string type generated for the values of a Postgres enum type or of a
+// CHECK (column IN (...)) constraint.
+type genEnum struct {
+	// Name is the Go type name, e.g. InvoiceStatus.
+	Name string
+	// Source describes where the values come from, for the doc comment of the type.
+	Source string
+	// Values are the allowed values, in database order.
+	Values []string
+}
+
+// Consts returns the constant names of the values of e, the type name followed by the value
+// as an identifier, e.g. InvoiceStatusDraft.
+func (e genEnum) Consts() []string {
+	consts := make([]string, len(e.Values))
+	for i, value := range e.Values {
+		name := e.Name + goIdentifier(value)
+		for slices.Contains(consts[:i], name) {
+			name += "_"
+		}
+		consts[i] = name
+	}
+	return consts
+}
+
+// genEnums are the enums of the models of a package and the columns using them.
+type genEnums struct {
+	enums []genEnum
+	// columns maps table, then column to the name of the enum type of the column.
+	columns map[string]map[string]string
+}
+
+// add records that column of table uses the enum named name.
+func (e *genEnums) add(table, column, name string) {
+	if e.columns == nil {
+		e.columns = make(map[string]map[string]string)
+	}
+	if e.columns[table] == nil {
+		e.columns[table] = make(map[string]string)
+	}
+	e.columns[table][column] = name
+}
+
+// modelOpts returns the options changing the type of the enum columns of table to their enum
+// type, keeping the pointer of nullable columns.
+func (e *genEnums) modelOpts(table string) []gen.ModelOpt {
+	if e == nil || len(e.columns[table]) == 0 {
+		return nil
+	}
+	columns := e.columns[table]
+	return []gen.ModelOpt{gen.FieldModify(func(f gen.Field) gen.Field {
+		if name, ok := columns[f.ColumnName]; ok {
+			if strings.HasPrefix(f.Type, "*") {
+				name = "*" + name
+			}
+			f.Type = name
+		}
+		return f
+	})}
+}
+
+// loadEnums reads the enum types of a Postgres schema, the current schema when empty, and
+// the CHECK (column IN (...)) constraints of its tables. A check constraint gets the type
+// named after its table and column, e.g. OrderStatus for orders.status. Type names are
+// suffixed with Enum where they collide with the model of one of tables, see enumGoName.
+func loadEnums(db *gorm.DB, schema string, tables []string) (*genEnums, error) {
+	if dialect := db.Dialector.Name(); dialect != "postgres" {
+		return nil, fmt.Errorf("enums are only supported on postgres, got %s", dialect)
+	}
+	var labels []struct {
+		TypeName string
+		Label    string
+	}
+	err := db.Raw(`SELECT t.typname AS type_name, e.enumlabel AS label FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = COALESCE(NULLIF(?, ''), current_schema()) ORDER BY t.typname, e.enumsortorder`, schema).Scan(&labels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enum types: %w", err)
+	}
+	enums := &genEnums{}
+	typeNames := make(map[string]string)
+	for _, l := range labels {
+		name, ok := typeNames[l.TypeName]
+		if !ok {
+			name = enumGoName(db.NamingStrategy, db.NamingStrategy.SchemaName(l.TypeName), tables)
+			typeNames[l.TypeName] = name
+			enums.enums = append(enums.enums, genEnum{Name: name, Source: "the " + l.TypeName + " enum type"})
+		}
+		enums.enums[len(enums.enums)-1].Values = append(enums.enums[len(enums.enums)-1].Values, l.Label)
+	}
+
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		UdtName    string
+	}
+	err = db.Raw(`SELECT table_name, column_name, udt_name FROM information_schema.columns
+WHERE table_schema = COALESCE(NULLIF(?, ''), current_schema()) AND data_type = 'USER-DEFINED'
+AND udt_schema = table_schema ORDER BY table_name, ordinal_position`, schema).Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enum columns: %w", err)
+	}
+	for _, c := range columns {
+		if name, ok := typeNames[c.UdtName]; ok {
+			enums.add(c.TableName, c.ColumnName, name)
+		}
+	}
+
+	var checks []struct {
+		TableName  string
+		Definition string
+	}
+	err = db.Raw(`SELECT rel.relname AS table_name, pg_get_constraintdef(c.oid) AS definition FROM pg_constraint c
+JOIN pg_class rel ON rel.oid = c.conrelid JOIN pg_namespace n ON n.oid = rel.relnamespace
+WHERE c.contype = 'c' AND n.nspname = COALESCE(NULLIF(?, ''), current_schema()) ORDER BY rel.relname, c.conname`, schema).Scan(&checks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check constraints: %w", err)
+	}
+	for _, c := range checks {
+		column, values, ok := parseCheckEnum(c.Definition)
+		if !ok {
+			continue
+		}
+		name := enumGoName(db.NamingStrategy, db.NamingStrategy.SchemaName(c.TableName)+goIdentifier(column), tables)
+		if slices.ContainsFunc(enums.enums, func(e genEnum) bool { return e.Name == name }) {
+			continue
+		}
+		enums.enums = append(enums.enums, genEnum{Name: name, Source: "the check constraint on " + c.TableName + "." + column, Values: values})
+		enums.add(c.TableName, column, name)
+	}
+	return enums, nil
+}
+
+// enumGoName returns name, the Go type name of an enum, suffixed with Enum when it is also
+// the name of the model of one of tables, e.g. UserRoleEnum for the user_role type next to
+// the UserRole model of user_roles, which would not compile in the same package.
+func enumGoName(naming schema.Namer, name string, tables []string) string {
+	if slices.ContainsFunc(tables, func(table string) bool { return naming.SchemaName(table) == name }) {
+		return name + "Enum"
+	}
+	return name
+}
+
+var (
+	// checkAnyPattern matches the definition Postgres returns for CHECK (column IN (...)), e.g.
+	// CHECK (((status)::text = ANY ((ARRAY['draft'::character varying, 'sent'::character varying])::text[]))).
+	checkAnyPattern = regexp.MustCompile(`^CHECK \(+"?(\w+)"?\)?(?:::[\w ]+)? = ANY \(+ARRAY\[(.*?)\]\)?(?:::[\w ]+\[\])?\)+$`)
+	// checkInPattern matches CHECK (column IN (...)) as written.
+	checkInPattern = regexp.MustCompile(`(?i)^CHECK \(+"?(\w+)"? IN \((.*)\)\)+$`)
+	// checkValuePattern matches a string literal of a check constraint, with an optional cast.
+	checkValuePattern = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[\w ]+)?$`)
+)
+
+// parseCheckEnum returns the column and the allowed values of a check constraint definition
+// of the form CHECK (column IN ('a', 'b')), and false for any other constraint.
+func parseCheckEnum(definition string) (column string, values []string, ok bool) {
+	m := checkAnyPattern.FindStringSubmatch(definition)
+	if m == nil {
+		if m = checkInPattern.FindStringSubmatch(definition); m == nil {
+			return "", nil, false
+		}
+	}
+	for _, item := range strings.Split(m[2], ",") {
+		v := checkValuePattern.FindStringSubmatch(strings.TrimSpace(item))
+		if v == nil {
+			return "", nil, false
+		}
+		values = append(values, strings.ReplaceAll(v[1], "''", "'"))
+	}
+	return m[1], values, true
+}
+
+// goIdentifier returns s as an exported Go identifier, e.g. InProgress for "in-progress".
+func goIdentifier(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		b.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		id = "V" + id
+	}
+	return id
+}
+
+// enumTemplate is the file of the enum types of a model package.
+var enumTemplate = template.Must(template.New("enums").Parse(`// Code generated by gormeasy. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+{{range .Enums}}{{$enum := .}}
+// {{.Name}} is a value of {{.Source}}.
+type {{.Name}} string
+
+const (
+{{range $i, $const := .Consts}}	{{$const}} {{$enum.Name}} = {{printf "%q" (index $enum.Values $i)}}
+{{end}})
+
+// Valid reports whether e is one of the values of {{.Name}}.
+func (e {{.Name}}) Valid() bool {
+	switch e {
+	case {{range $i, $const := .Consts}}{{if $i}}, {{end}}{{$const}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// Value implements driver.Valuer, rejecting values not in {{.Name}}.
+func (e {{.Name}}) Value() (driver.Value, error) {
+	if !e.Valid() {
+		return nil, fmt.Errorf("invalid {{.Name}} %q", string(e))
+	}
+	return string(e), nil
+}
+
+// Scan implements sql.Scanner.
+func (e *{{.Name}}) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*e = {{.Name}}(v)
+	case []byte:
+		*e = {{.Name}}(v)
+	default:
+		return fmt.Errorf("cannot scan %T into {{.Name}}", value)
+	}
+	return nil
+}
+{{end}}`))
+
+// writeEnums writes the enum types of enums into enums.gen.go of the model directory dir.
+func writeEnums(dir string, enums *genEnums) error {
+	if enums == nil || len(enums.enums) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	err := enumTemplate.Execute(&buf, map[string]any{"Package": filepath.Base(dir), "Enums": enums.enums})
+	if err != nil {
+		return fmt.Errorf("failed to render enums: %w", err)
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format enums: %w", err)
+	}
+	path := filepath.Join(dir, "enums.gen.go")
+	if err := os.WriteFile(path, source, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	out.Printf("✅ Generated %d enum types in: %s\n", len(enums.enums), path)
+	return nil
+}
@@ -0,0 +1,100 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// TestParseCheckEnum tests reading the values of CHECK (column IN (...)) constraints
+func TestParseCheckEnum(t *testing.T) {
+	cases := []struct {
+		definition string
+		column     string
+		values     []string
+	}{
+		{"CHECK (((status)::text = ANY ((ARRAY['draft'::character varying, 'sent'::character varying])::text[])))", "status", []string{"draft", "sent"}},
+		{"CHECK ((kind = ANY (ARRAY['a'::text, 'it''s'::text])))", "kind", []string{"a", "it's"}},
+		{"CHECK (level IN ('low', 'high'))", "level", []string{"low", "high"}},
+		{"CHECK ((price > (0)::numeric))", "", nil},
+		{"CHECK ((status = ANY (ARRAY[lower(name), 'x'::text])))", "", nil},
+	}
+	for _, c := range cases {
+		column, values, ok := parseCheckEnum(c.definition)
+		if ok != (c.column != "") || column != c.column || !slices.Equal(values, c.values) {
+			t.Errorf("Expected %s %v for %s, got %s %v (%v)", c.column, c.values, c.definition, column, values, ok)
+		}
+	}
+}
+
+// TestGoIdentifier tests the constant names of enum values
+func TestGoIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"draft":       "Draft",
+		"in-progress": "InProgress",
+		"on_hold":     "OnHold",
+		"2fa":         "V2fa",
+		"":            "V",
+	}
+	for value, expected := range cases {
+		if got := goIdentifier(value); got != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, value, got)
+		}
+	}
+	consts := genEnum{Name: "Kind", Values: []string{"a-b", "a_b"}}.Consts()
+	if !slices.Equal(consts, []string{"KindAB", "KindAB_"}) {
+		t.Errorf("Expected unique constants, got %v", consts)
+	}
+}
+
+// TestEnumGoName tests that enum types colliding with a model name are suffixed with Enum
+func TestEnumGoName(t *testing.T) {
+	tables := []string{"users", "user_roles"}
+	if name := enumGoName(schema.NamingStrategy{}, "UserRole", tables); name != "UserRoleEnum" {
+		t.Errorf("Expected UserRoleEnum, got %s", name)
+	}
+	if name := enumGoName(schema.NamingStrategy{}, "UserStatus", tables); name != "UserStatus" {
+		t.Errorf("Expected UserStatus, got %s", name)
+	}
+}
+
+// TestWriteEnums tests the generated enum types
+func TestWriteEnums(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "model")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	enums := &genEnums{enums: []genEnum{{Name: "InvoiceStatus", Source: "the invoice_status enum type", Values: []string{"draft", "paid"}}}}
+	enums.add("invoices", "status", "InvoiceStatus")
+	if err := writeEnums(dir, enums); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "enums.gen.go"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{
+		"package model",
+		"type InvoiceStatus string",
+		`InvoiceStatusDraft InvoiceStatus = "draft"`,
+		"case InvoiceStatusDraft, InvoiceStatusPaid:",
+		"func (e *InvoiceStatus) Scan(value any) error {",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected enums to contain %s, got:\n%s", expected, data)
+		}
+	}
+	if generated, _ := isGeneratedFile(filepath.Join(dir, "enums.gen.go")); !generated {
+		t.Error("Expected enums.gen.go to be marked as generated")
+	}
+	if n := len(enums.modelOpts("invoices")); n != 1 {
+		t.Errorf("Expected 1 option for invoices, got %d", n)
+	}
+	if n := len(enums.modelOpts("users")); n != 0 {
+		t.Errorf("Expected no option for users, got %d", n)
+	}
+}
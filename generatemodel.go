@@ -1,21 +1,239 @@
 package gormeasy
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"gorm.io/gen"
+	"gorm.io/gen/field"
 	"gorm.io/gorm"
 )
 
+// genConfig configures the fields of generated models, set by the gen key of gormeasy.json
+// and the gen flags.
+type genConfig struct {
+	// FieldNullable generates pointers for nullable columns.
+	FieldNullable bool `json:"field_nullable"`
+	// FieldCoverable generates pointers for columns with a default value, so zero values
+	// can be written.
+	FieldCoverable bool `json:"field_coverable"`
+	// FieldSignable generates unsigned Go types for unsigned integer columns.
+	FieldSignable bool `json:"field_signable"`
+	// TypeMap maps database types to Go types, e.g. {"jsonb": "datatypes.JSON"}. A type of
+	// another package is given with its import path: "github.com/shopspring/decimal.Decimal".
+	TypeMap map[string]string `json:"type_map"`
+	// JSONTag is the naming of JSON tags: snake (the column name, default), camel or pascal.
+	JSONTag string `json:"json_tag"`
+	// JSONOmitEmpty adds omitempty to the JSON tags.
+	JSONOmitEmpty bool `json:"json_omitempty"`
+	// Tags are extra struct tags of columns, keyed by column name, or table.column for the
+	// column of one table, e.g. {"users.email": {"validate": "required,email"}}.
+	Tags map[string]map[string]string `json:"tags"`
+	// Preserve only deletes the files gen generated from the output directories, keeping
+	// hand-written files such as methods in *_custom.go next to the models.
+	Preserve bool `json:"preserve"`
+	// Schemas are the Postgres schemas to generate models of, each into a subpackage of the
+	// model directory named after it. Only the tables of the current schema when empty.
+	Schemas []string `json:"schemas"`
+	// Enums generates Go string types with constants for the Postgres enum types and the
+	// CHECK (column IN (...)) constraints, and uses them as the types of their columns.
+	Enums bool `json:"enums"`
+	// Relations adds BelongsTo and HasMany association fields for the foreign keys between
+	// the tables, so the models can be preloaded.
+	Relations bool `json:"relations"`
+	// IncludeViews also generates read-only models of the views and materialized views.
+	IncludeViews bool `json:"include_views"`
+	// TSOut is the directory of TypeScript interfaces mirroring the models, none when empty.
+	TSOut string `json:"ts_out"`
+	// TSZod adds zod schemas of the models to the TypeScript interfaces.
+	TSZod bool `json:"ts_zod"`
+	// ProtoOut is the directory of protobuf messages mirroring the models, none when empty.
+	ProtoOut string `json:"proto_out"`
+	// ProtoPackage is the protobuf package of the messages, the Go package name when empty.
+	ProtoPackage string `json:"proto_package"`
+	// ProtoNumbers is the JSON file keeping the field numbers of the messages across runs,
+	// proto-numbers.json in ProtoOut when empty. Commit it next to the .proto files.
+	ProtoNumbers string `json:"proto_numbers"`
+	// OpenAPIOut is the directory of the OpenAPI component schemas of the models, none when empty.
+	OpenAPIOut string `json:"openapi_out"`
+}
+
+// genOptions configures the models and the query API generated by the gen command.
+type genOptions struct {
+	genConfig
+	// queryOut is the directory of the generated query package, no query API when empty.
+	queryOut string
+	// mode is the gen mode of the query package, e.g. gen.WithDefaultQuery|gen.WithQueryInterface.
+	mode gen.GenerateMode
+	// enums are the enums of the generated models, loaded when Enums is set.
+	enums *genEnums
+	// views are the names of the views of the generated models, set by withViews.
+	views map[string]bool
+}
+
+// apply sets the field options, the type map and the JSON tag naming of o on cfg.
+func (o genOptions) apply(cfg *gen.Config) error {
+	cfg.FieldNullable = o.FieldNullable
+	cfg.FieldCoverable = o.FieldCoverable
+	cfg.FieldSignable = o.FieldSignable
+	jsonTag, err := genJSONTag(o.JSONTag, o.JSONOmitEmpty)
+	if err != nil {
+		return err
+	}
+	cfg.WithJSONTagNameStrategy(jsonTag)
+	if len(o.TypeMap) == 0 {
+		return nil
+	}
+	types, imports, err := genTypeMap(o.TypeMap)
+	if err != nil {
+		return err
+	}
+	cfg.WithDataTypeMap(types)
+	cfg.WithImportPkgPath(imports...)
+	return nil
+}
+
+// modelOpts returns the options adding the extra tags of o to the model of table.
+func (o genOptions) modelOpts(table string) []gen.ModelOpt {
+	var opts []gen.ModelOpt
+	for _, key := range slices.Sorted(maps.Keys(o.Tags)) {
+		column := key
+		if t, c, ok := strings.Cut(key, "."); ok {
+			if t != table {
+				continue
+			}
+			column = c
+		}
+		opts = append(opts, gen.FieldNewTag(column, field.Tag(o.Tags[key])))
+	}
+	if o.views[table] {
+		opts = append(opts, readOnlyField)
+	}
+	return append(opts, o.enums.modelOpts(table)...)
+}
+
+// genJSONTag returns the JSON tag of a column named with naming, snake, camel or pascal.
+func genJSONTag(naming string, omitEmpty bool) (func(column string) string, error) {
+	var name func(string) string
+	switch naming {
+	case "", "snake":
+		name = func(column string) string { return column }
+	case "camel", "pascal":
+		name = func(column string) string {
+			var b strings.Builder
+			for i, word := range strings.Split(column, "_") {
+				if i == 0 && naming == "camel" {
+					b.WriteString(strings.ToLower(word))
+				} else if word != "" {
+					b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+				}
+			}
+			return b.String()
+		}
+	default:
+		return nil, fmt.Errorf("unknown JSON tag naming %q, use snake, camel or pascal", naming)
+	}
+	if !omitEmpty {
+		return name, nil
+	}
+	return func(column string) string { return name(column) + ",omitempty" }, nil
+}
+
+// genTypeMap returns the gen data type mapping of typeMap and the import paths of its Go types.
+// Database types are matched in lower and upper case, since drivers report either.
+func genTypeMap(typeMap map[string]string) (map[string]func(gorm.ColumnType) string, []string, error) {
+	types := make(map[string]func(gorm.ColumnType) string)
+	var imports []string
+	for _, dbType := range slices.Sorted(maps.Keys(typeMap)) {
+		goType := typeMap[dbType]
+		if dbType == "" || goType == "" {
+			return nil, nil, fmt.Errorf("invalid type mapping %q: %q, use database type and Go type", dbType, goType)
+		}
+		pointer := strings.HasPrefix(goType, "*")
+		goType = strings.TrimPrefix(goType, "*")
+		if strings.Contains(goType, "/") {
+			i := strings.LastIndex(goType, ".")
+			if i < strings.LastIndex(goType, "/") {
+				return nil, nil, fmt.Errorf("invalid Go type %q of %s, use import/path.Type", typeMap[dbType], dbType)
+			}
+			imports = appendUnique(imports, goType[:i])
+			goType = filepath.Base(goType[:i]) + goType[i:]
+		}
+		if pointer {
+			goType = "*" + goType
+		}
+		mapping := func(gorm.ColumnType) string { return goType }
+		types[strings.ToLower(dbType)] = mapping
+		types[strings.ToUpper(dbType)] = mapping
+	}
+	return types, imports, nil
+}
+
+// parseTypeMap parses the gen --type-map flag, comma-separated dbtype=GoType pairs.
+func parseTypeMap(s string) (map[string]string, error) {
+	typeMap := make(map[string]string)
+	for _, pair := range splitList(s) {
+		dbType, goType, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid type mapping %q, use dbtype=GoType", pair)
+		}
+		typeMap[strings.TrimSpace(dbType)] = strings.TrimSpace(goType)
+	}
+	return typeMap, nil
+}
+
+// genModes are the values of the gen --query-mode flag.
+var genModes = map[string]gen.GenerateMode{
+	"default":         gen.WithDefaultQuery,
+	"interface":       gen.WithQueryInterface,
+	"without-context": gen.WithoutContext,
+}
+
+// parseGenMode parses a comma-separated list of genModes.
+func parseGenMode(s string) (gen.GenerateMode, error) {
+	var mode gen.GenerateMode
+	for _, name := range splitList(s) {
+		m, ok := genModes[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown query mode %q, use default, interface or without-context", name)
+		}
+		mode |= m
+	}
+	return mode, nil
+}
+
 // generateGormCode generates GORM model files by reverse engineering the database structure.
-func generateGormCode(db *gorm.DB, basePath string) error {
+// The models are written to the model directory next to basePath. With opts.queryOut, the
+// gen query API of the models is generated into that directory as well.
+func generateGormCode(db *gorm.DB, basePath string, opts genOptions) error {
 	modelPath := filepath.Join(basePath)
 
 	// Safety check: prevent accidental deletion of project root directory
-	if basePath == "." || basePath == "/" {
-		return fmt.Errorf("refusing to generate into critical directory: %s", basePath)
+	for _, p := range []string{basePath, opts.queryOut} {
+		if p == "." || p == "/" {
+			return fmt.Errorf("refusing to generate into critical directory: %s", p)
+		}
+	}
+	var cfg gen.Config
+	if err := opts.apply(&cfg); err != nil {
+		return err
+	}
+	if len(opts.Schemas) > 0 {
+		return generateSchemaCode(db, basePath, cfg, opts)
+	}
+	var queryModelPath, queryPath string
+	if opts.queryOut != "" {
+		var err error
+		if queryModelPath, queryPath, err = genQueryPaths(basePath, opts.queryOut); err != nil {
+			return err
+		}
 	}
 
 	// Query all tables in the database
@@ -24,26 +242,110 @@ func generateGormCode(db *gorm.DB, basePath string) error {
 		return fmt.Errorf("failed to list tables: %w", err)
 	}
 
-	if err := clearDirectory(basePath); err != nil {
+	if db, tables, err = opts.withViews(db, "", tables); err != nil {
+		return err
+	}
+	if opts.Enums {
+		if opts.enums, err = loadEnums(db, "", tables); err != nil {
+			return err
+		}
+	}
+
+	if err := opts.clear(basePath); err != nil {
 		return fmt.Errorf("failed to clear directory: %w", err)
 	}
 
-	fmt.Println("Generating GORM code for tables:", tables)
+	out.Println("Generating GORM code for tables:", tables)
+
+	if opts.queryOut != "" {
+		cfg.OutPath, cfg.ModelPkgPath, cfg.Mode = queryPath, queryModelPath, opts.mode
+		return generateQueryCode(db, tables, cfg, opts)
+	}
 
 	// Generate model layer
-	gModel := gen.NewGenerator(gen.Config{
-		OutPath:      modelPath,
-		ModelPkgPath: "model",
-		Mode:         gen.WithoutContext, // Pure structs only
-	})
+	cfg.OutPath = modelPath
+	cfg.ModelPkgPath = "model"
+	cfg.Mode = gen.WithoutContext // Pure structs only
+	gModel := gen.NewGenerator(cfg)
 	gModel.UseDB(db)
-	for _, table := range tables {
-		gModel.GenerateModel(table)
+	if _, err := generateModels(db, gModel.GenerateModelAs, gen.FieldRelate, "", tables, opts); err != nil {
+		return err
 	}
 	gModel.Execute()
-	fmt.Println("✅ Models generated in:", modelPath)
+	if err := docComments(filepath.Join(filepath.Dir(modelPath), cfg.ModelPkgPath)); err != nil {
+		return err
+	}
+	if err := writeEnums(filepath.Join(filepath.Dir(modelPath), cfg.ModelPkgPath), opts.enums); err != nil {
+		return err
+	}
+	if err := opts.writeTypeScript(filepath.Join(filepath.Dir(modelPath), cfg.ModelPkgPath), "models.ts"); err != nil {
+		return err
+	}
+	if err := opts.writeProto(filepath.Join(filepath.Dir(modelPath), cfg.ModelPkgPath), "model"); err != nil {
+		return err
+	}
+	if err := opts.writeOpenAPI(filepath.Join(filepath.Dir(modelPath), cfg.ModelPkgPath), "model", "models.yaml"); err != nil {
+		return err
+	}
+	out.Println("✅ Models generated in:", modelPath)
 
-	fmt.Println("🎉 GORM code generation complete.")
+	out.Println("🎉 GORM code generation complete.")
+	return nil
+}
+
+// genQueryPaths returns the absolute model directory of generateGormCode for basePath, the
+// directory named model next to it, and the absolute query directory of queryOut, which must
+// differ from it.
+func genQueryPaths(basePath, queryOut string) (modelPath, queryPath string, err error) {
+	base, err := filepath.Abs(basePath)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid output path %s: %w", basePath, err)
+	}
+	queryPath, err = filepath.Abs(queryOut)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid query output path %s: %w", queryOut, err)
+	}
+	modelPath = filepath.Join(filepath.Dir(base), "model")
+	if queryPath == modelPath {
+		return "", "", fmt.Errorf("query-out must differ from the model directory %s", modelPath)
+	}
+	return modelPath, queryPath, nil
+}
+
+// generateQueryCode generates the models of tables with the tags of opts into cfg.ModelPkgPath
+// and their gen query API into cfg.OutPath.
+func generateQueryCode(db *gorm.DB, tables []string, cfg gen.Config, opts genOptions) error {
+	if err := opts.clear(cfg.OutPath); err != nil {
+		return fmt.Errorf("failed to clear directory: %w", err)
+	}
+
+	g := gen.NewGenerator(cfg)
+	g.UseDB(db)
+	models, err := generateModels(db, g.GenerateModelAs, gen.FieldRelate, "", tables, opts)
+	if err != nil {
+		return err
+	}
+	g.ApplyBasic(models...)
+	g.Execute()
+	if err := docComments(cfg.ModelPkgPath); err != nil {
+		return err
+	}
+	if err := writeEnums(cfg.ModelPkgPath, opts.enums); err != nil {
+		return err
+	}
+	if err := opts.writeTypeScript(cfg.ModelPkgPath, "models.ts"); err != nil {
+		return err
+	}
+	if err := opts.writeProto(cfg.ModelPkgPath, "model"); err != nil {
+		return err
+	}
+	if err := opts.writeOpenAPI(cfg.ModelPkgPath, "model", "models.yaml"); err != nil {
+		return err
+	}
+	out.Println("✅ Models generated in:", cfg.ModelPkgPath)
+	out.Println("✅ Query API generated in:", cfg.OutPath)
+
+	out.Println("🎉 GORM code generation complete.")
 	return nil
 }
 
@@ -63,3 +365,102 @@ func clearDirectory(outputPath string) error {
 	}
 	return nil
 }
+
+// fieldCommentPattern matches a field of a generated model followed by its column comment.
+var fieldCommentPattern = regexp.MustCompile("(?m)^([ \t]+)([A-Z]\\w*)([ \t]+[^ \t/].*`)[ \t]*// (.*)$")
+
+// docComments moves the column comments gen writes after the fields of the models in dir
+// above the fields, as doc comments starting with the field name. Table comments are already
+// the doc comments of the models.
+func docComments(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read dir %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".gen.go") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		documented := fieldCommentPattern.ReplaceAll(data, []byte("${1}// ${2} ${4}\n${1}${2}${3}"))
+		if bytes.Equal(documented, data) {
+			continue
+		}
+		if documented, err = format.Source(documented); err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, documented, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// generatedPattern matches the comment marking generated Go files, see https://go.dev/s/generatedcode.
+var generatedPattern = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// clear empties outputPath before generating into it, or with o.Preserve deletes only the
+// generated files.
+func (o genOptions) clear(outputPath string) error {
+	if o.Preserve {
+		return clearGenerated(outputPath)
+	}
+	return clearDirectory(outputPath)
+}
+
+// clearGenerated deletes the generated Go files of outputPath, those with a "Code generated
+// ... DO NOT EDIT." comment before the package clause, keeping every other file and *_custom.go
+// files in any case. Subdirectories are left alone.
+func clearGenerated(outputPath string) error {
+	if outputPath == "" {
+		return fmt.Errorf("missing output path, please set MODEL_DIR in .env file")
+	}
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", outputPath, err)
+	}
+	entries, err := os.ReadDir(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read dir %s: %w", outputPath, err)
+	}
+	kept := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		path := filepath.Join(outputPath, name)
+		generated, err := isGeneratedFile(path)
+		if err != nil {
+			return err
+		}
+		if !generated || strings.HasSuffix(name, "_custom.go") {
+			kept++
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", path, err)
+		}
+	}
+	if kept > 0 {
+		out.Printf("Kept %d hand-written files in %s\n", kept, outputPath)
+	}
+	return nil
+}
+
+// isGeneratedFile reports whether the Go file at path has a generated code comment before its
+// package clause.
+func isGeneratedFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	header := "\n" + string(data)
+	if i := strings.Index(header, "\npackage "); i >= 0 {
+		header = header[:i]
+	}
+	return generatedPattern.MatchString(header), nil
+}
@@ -0,0 +1,193 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestParseGenMode tests parsing the modes of gen --query-mode
+func TestParseGenMode(t *testing.T) {
+	mode, err := parseGenMode("default,interface")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mode != gen.WithDefaultQuery|gen.WithQueryInterface {
+		t.Errorf("Expected default and interface modes, got %d", mode)
+	}
+	if _, err := parseGenMode("context"); err == nil {
+		t.Error("Expected error for unknown mode, got nil")
+	}
+}
+
+// TestGenQueryPaths tests that models are generated next to the output path and apart from the query API
+func TestGenQueryPaths(t *testing.T) {
+	dir := t.TempDir()
+	modelPath, queryPath, err := genQueryPaths(filepath.Join(dir, "dal", "query"), filepath.Join(dir, "dal", "query"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if modelPath != filepath.Join(dir, "dal", "model") || queryPath != filepath.Join(dir, "dal", "query") {
+		t.Errorf("Expected dal/model and dal/query, got %s and %s", modelPath, queryPath)
+	}
+	if _, _, err := genQueryPaths(filepath.Join(dir, "dal", "query"), filepath.Join(dir, "dal", "model")); err == nil {
+		t.Error("Expected error for a query path equal to the model directory, got nil")
+	}
+}
+
+// TestGenTypeMap tests mapping database types to Go types with their imports
+func TestGenTypeMap(t *testing.T) {
+	typeMap, err := parseTypeMap("jsonb=datatypes.JSON, numeric=*github.com/shopspring/decimal.Decimal")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	types, imports, err := genTypeMap(typeMap)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(imports) != 1 || imports[0] != "github.com/shopspring/decimal" {
+		t.Errorf("Expected the decimal import, got %v", imports)
+	}
+	cases := map[string]string{"jsonb": "datatypes.JSON", "NUMERIC": "*decimal.Decimal", "numeric": "*decimal.Decimal"}
+	for dbType, expected := range cases {
+		mapping, ok := types[dbType]
+		if !ok {
+			t.Errorf("Expected a mapping of %s", dbType)
+			continue
+		}
+		if got := mapping(nil); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, dbType, got)
+		}
+	}
+
+	if _, err := parseTypeMap("jsonb"); err == nil {
+		t.Error("Expected error for a mapping without Go type, got nil")
+	}
+	if _, _, err := genTypeMap(map[string]string{"numeric": "github.com/shopspring/decimal"}); err == nil {
+		t.Error("Expected error for an import path without type, got nil")
+	}
+}
+
+// TestGenJSONTag tests the JSON tag namings
+func TestGenJSONTag(t *testing.T) {
+	cases := []struct {
+		naming    string
+		omitEmpty bool
+		expected  string
+	}{
+		{"", false, "created_at"},
+		{"camel", false, "createdAt"},
+		{"pascal", true, "CreatedAt,omitempty"},
+	}
+	for _, c := range cases {
+		name, err := genJSONTag(c.naming, c.omitEmpty)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := name("created_at"); got != c.expected {
+			t.Errorf("Expected %s for %q, got %s", c.expected, c.naming, got)
+		}
+	}
+	if _, err := genJSONTag("kebab", false); err == nil {
+		t.Error("Expected error for unknown naming, got nil")
+	}
+}
+
+// TestGenModelOpts tests that extra tags apply to columns of every table or of one table
+func TestGenModelOpts(t *testing.T) {
+	opts := genOptions{genConfig: genConfig{Tags: map[string]map[string]string{
+		"email":      {"validate": "email"},
+		"users.name": {"validate": "required"},
+	}}}
+	if n := len(opts.modelOpts("users")); n != 2 {
+		t.Errorf("Expected 2 options for users, got %d", n)
+	}
+	if n := len(opts.modelOpts("orders")); n != 1 {
+		t.Errorf("Expected 1 option for orders, got %d", n)
+	}
+}
+
+// TestClearGenerated tests that only generated files are deleted, keeping hand-written ones
+func TestClearGenerated(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.gen.go":    "// Code generated by gorm.io/gen. DO NOT EDIT.\n\npackage model\n",
+		"users_custom.go": "// Code generated by hand. DO NOT EDIT.\n\npackage model\n",
+		"helpers.go":      "package model\n\n// Code generated by gorm.io/gen. DO NOT EDIT.\n",
+		"notes.txt":       "// Code generated by gorm.io/gen. DO NOT EDIT.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := clearGenerated(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if deleted := os.IsNotExist(err); deleted != (name == "users.gen.go") {
+			t.Errorf("Expected only users.gen.go to be deleted, %s deleted: %v", name, deleted)
+		}
+	}
+
+	missing := filepath.Join(dir, "query")
+	if err := clearGenerated(missing); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(missing); err != nil {
+		t.Errorf("Expected %s to be created, got %v", missing, err)
+	}
+}
+
+// TestSchemaPackage tests the package names of schemas
+func TestSchemaPackage(t *testing.T) {
+	cases := map[string]string{
+		"billing":   "billing",
+		"Auth":      "auth",
+		"audit-log": "audit_log",
+		"2024":      "_2024",
+	}
+	for schema, expected := range cases {
+		if got := schemaPackage(schema); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, schema, got)
+		}
+	}
+}
+
+// TestGenerateSchemaCodeDialect tests that schemas are rejected on databases other than postgres
+func TestGenerateSchemaCodeDialect(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	opts := genOptions{genConfig: genConfig{Schemas: []string{"billing"}}}
+	if err := generateGormCode(db, filepath.Join(t.TempDir(), "model"), opts); err == nil {
+		t.Error("Expected error for schemas on dummy, got nil")
+	}
+}
+
+// TestDocComments tests that column comments are moved above the fields of generated models
+func TestDocComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.gen.go")
+	model := "// Code generated by gorm.io/gen. DO NOT EDIT.\n\npackage model\n\n// User registered users\ntype User struct {\n" +
+		"\tID    int64  `gorm:\"column:id;primaryKey\" json:\"id\"`\n" +
+		"\tEmail string `gorm:\"column:email;comment:login email\" json:\"email\"` // login email\n}\n"
+	if err := os.WriteFile(path, []byte(model), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := docComments(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "\t// Email login email\n\tEmail string `gorm:\"column:email;comment:login email\" json:\"email\"`\n}"
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("Expected doc comment on Email, got:\n%s", data)
+	}
+}
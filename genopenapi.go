@@ -0,0 +1,171 @@
+package gormeasy
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISchema is an OpenAPI 3.0 schema object.
+type openAPISchema struct {
+	Ref         string           `yaml:"$ref,omitempty"`
+	AllOf       []*openAPISchema `yaml:"allOf,omitempty"`
+	Type        string           `yaml:"type,omitempty"`
+	Format      string           `yaml:"format,omitempty"`
+	Description string           `yaml:"description,omitempty"`
+	Nullable    bool             `yaml:"nullable,omitempty"`
+	Enum        []string         `yaml:"enum,omitempty"`
+	Items       *openAPISchema   `yaml:"items,omitempty"`
+	Properties  openAPISchemas   `yaml:"properties,omitempty"`
+	Required    []string         `yaml:"required,omitempty"`
+}
+
+// openAPINamed is a schema of openAPISchemas with its name.
+type openAPINamed struct {
+	name   string
+	schema *openAPISchema
+}
+
+// openAPISchemas are schemas by name, marshaled as a mapping keeping their order.
+type openAPISchemas []openAPINamed
+
+func (s openAPISchemas) MarshalYAML() (interface{}, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, named := range s {
+		value := &yaml.Node{}
+		if err := value.Encode(named.schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", named.name, err)
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: named.name}, value)
+	}
+	return mapping, nil
+}
+
+// openAPIRef returns the reference to the component schema name.
+func openAPIRef(name string) *openAPISchema {
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// goToOpenAPI returns the OpenAPI schema of the Go type expr of a generated model. Models and
+// enum types of the package are references, types unknown in JSON are any value.
+func goToOpenAPI(expr ast.Expr, models, enums map[string]bool) *openAPISchema {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		inner := goToOpenAPI(t.X, models, enums)
+		if inner.Ref != "" {
+			// Siblings of $ref are ignored in OpenAPI 3.0
+			return &openAPISchema{AllOf: []*openAPISchema{inner}, Nullable: true}
+		}
+		inner.Nullable = true
+		return inner
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		// Elements are not nullable, as in goToTS
+		elem := t.Elt
+		if star, ok := elem.(*ast.StarExpr); ok {
+			elem = star.X
+		}
+		return &openAPISchema{Type: "array", Items: goToOpenAPI(elem, models, enums)}
+	case *ast.MapType:
+		return &openAPISchema{Type: "object"}
+	case *ast.SelectorExpr:
+		switch selectorName(t) {
+		case "time.Time":
+			return &openAPISchema{Type: "string", Format: "date-time"}
+		case "gorm.DeletedAt":
+			return &openAPISchema{Type: "string", Format: "date-time", Nullable: true}
+		case "decimal.Decimal":
+			return &openAPISchema{Type: "string", Format: "decimal"}
+		}
+	case *ast.Ident:
+		switch name := t.Name; name {
+		case "string":
+			return &openAPISchema{Type: "string"}
+		case "bool":
+			return &openAPISchema{Type: "boolean"}
+		case "int8", "int16", "int32", "uint8", "uint16", "byte", "rune":
+			return &openAPISchema{Type: "integer", Format: "int32"}
+		case "int", "int64", "uint", "uint32", "uint64":
+			return &openAPISchema{Type: "integer", Format: "int64"}
+		case "float32":
+			return &openAPISchema{Type: "number", Format: "float"}
+		case "float64":
+			return &openAPISchema{Type: "number", Format: "double"}
+		default:
+			if enums[name] || models[name] {
+				return openAPIRef(name)
+			}
+		}
+	}
+	return &openAPISchema{}
+}
+
+// renderOpenAPI returns the OpenAPI 3.0 document of the component schemas of models and enums
+// of the Go package pkg. Fields without omitempty are required, as they are always written.
+func renderOpenAPI(pkg string, models []modelDecl, enums []genEnum) ([]byte, error) {
+	modelNames, enumNames := modelTypes(models, enums)
+	var schemas openAPISchemas
+	for _, e := range enums {
+		schemas = append(schemas, openAPINamed{e.Name, &openAPISchema{Type: "string", Enum: e.Values}})
+	}
+	for _, m := range models {
+		schema := &openAPISchema{Type: "object", Description: m.Doc}
+		for _, f := range m.Fields {
+			property := goToOpenAPI(f.Type, modelNames, enumNames)
+			property.Description = f.Doc
+			schema.Properties = append(schema.Properties, openAPINamed{f.JSON, property})
+			if !f.OmitEmpty {
+				schema.Required = append(schema.Required, f.JSON)
+			}
+		}
+		schemas = append(schemas, openAPINamed{m.Name, schema})
+	}
+	doc := struct {
+		OpenAPI string `yaml:"openapi"`
+		Info    struct {
+			Title   string `yaml:"title"`
+			Version string `yaml:"version"`
+		} `yaml:"info"`
+		Paths      map[string]any `yaml:"paths"`
+		Components struct {
+			Schemas openAPISchemas `yaml:"schemas"`
+		} `yaml:"components"`
+	}{OpenAPI: "3.0.3", Paths: map[string]any{}}
+	doc.Info.Title, doc.Info.Version = pkg+" models", "1.0.0"
+	doc.Components.Schemas = schemas
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("# Code generated by gormeasy. DO NOT EDIT.\n"), data...), nil
+}
+
+// writeOpenAPI writes the OpenAPI component schemas of the models generated into modelDir,
+// the Go package pkg, to the file name in o.OpenAPIOut.
+func (o genOptions) writeOpenAPI(modelDir, pkg, name string) error {
+	if o.OpenAPIOut == "" {
+		return nil
+	}
+	models, enums, err := parseModels(modelDir)
+	if err != nil {
+		return err
+	}
+	data, err := renderOpenAPI(pkg, models, enums)
+	if err != nil {
+		return fmt.Errorf("failed to render OpenAPI schemas: %w", err)
+	}
+	if err := os.MkdirAll(o.OpenAPIOut, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", o.OpenAPIOut, err)
+	}
+	path := filepath.Join(o.OpenAPIOut, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	out.Println("✅ OpenAPI schemas generated in:", path)
+	return nil
+}
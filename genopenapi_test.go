@@ -0,0 +1,66 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestOpenAPI tests the OpenAPI component schemas of generated models
+func TestOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testTSModels {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	models, enums, err := parseModels(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := renderOpenAPI("model", models, enums)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	doc := string(data)
+	for _, expected := range []string{
+		"openapi: 3.0.3\n",
+		"        OrderStatus:\n            type: string\n            enum:\n                - new\n                - paid\n",
+		"        Order:\n            type: object\n            description: placed orders\n            properties:\n                id:\n                    type: integer\n                    format: int64\n",
+		"                status:\n                    $ref: '#/components/schemas/OrderStatus'\n                    description: current state\n",
+		"                note:\n                    type: string\n                    nullable: true\n",
+		"                created-at:\n                    type: string\n                    format: date-time\n",
+		"                lines:\n                    type: array\n                    items:\n                        $ref: '#/components/schemas/Line'\n",
+		"                payload:\n                    type: string\n                    format: byte\n",
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("Expected OpenAPI to contain %q, got:\n%s", expected, doc)
+		}
+	}
+
+	var parsed struct {
+		Components struct {
+			Schemas map[string]struct {
+				Required []string `yaml:"required"`
+			} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Expected valid YAML, got %v", err)
+	}
+	if required := strings.Join(parsed.Components.Schemas["Order"].Required, ","); required != "id,status,created-at,deleted_at,lines" {
+		t.Errorf("Expected the fields without omitempty to be required, got %s", required)
+	}
+
+	opts := genOptions{genConfig: genConfig{OpenAPIOut: filepath.Join(t.TempDir(), "openapi")}}
+	if err := opts.writeOpenAPI(dir, "model", "models.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(opts.OpenAPIOut, "models.yaml")); err != nil {
+		t.Errorf("Expected models.yaml to be written, got %v", err)
+	}
+}
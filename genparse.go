@@ -0,0 +1,170 @@
+package gormeasy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// modelDecl is a model struct read back from the generated Go files, for the outputs
+// mirroring the models in other languages.
+type modelDecl struct {
+	Name string
+	Doc  string
+	// Fields are the exported fields, without those hidden from JSON.
+	Fields []modelField
+}
+
+// modelField is a field of a modelDecl.
+type modelField struct {
+	// Name is the Go field name, JSON the name of its JSON tag or else Name.
+	Name string
+	JSON string
+	// Column is the column of the gorm tag, empty for relations.
+	Column    string
+	Doc       string
+	Type      ast.Expr
+	OmitEmpty bool
+}
+
+// parseModels reads the models and the enum types, string types with constants, of the
+// generated Go files of dir, in file order.
+func parseModels(dir string) ([]modelDecl, []genEnum, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.gen.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, path := range matches {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, file)
+	}
+
+	var enums []genEnum
+	var structs []*ast.TypeSpec
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if spec.Doc == nil {
+					spec.Doc = gen.Doc
+				}
+				switch t := spec.Type.(type) {
+				case *ast.StructType:
+					structs = append(structs, spec)
+				case *ast.Ident:
+					if t.Name == "string" {
+						enums = append(enums, genEnum{Name: spec.Name.Name})
+					}
+				}
+			}
+		}
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				for _, spec := range gen.Specs {
+					addEnumValue(enums, spec.(*ast.ValueSpec))
+				}
+			}
+		}
+	}
+
+	models := make([]modelDecl, 0, len(structs))
+	for _, spec := range structs {
+		model := modelDecl{Name: spec.Name.Name, Doc: docText(spec.Doc, spec.Name.Name)}
+		for _, f := range spec.Type.(*ast.StructType).Fields.List {
+			if len(f.Names) == 0 || !f.Names[0].IsExported() {
+				continue
+			}
+			field := modelField{Name: f.Names[0].Name, JSON: f.Names[0].Name, Type: f.Type}
+			if f.Tag != nil {
+				tag, _ := strconv.Unquote(f.Tag.Value)
+				jsonName, opts, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+				if jsonName == "-" && opts == "" {
+					continue
+				}
+				if jsonName != "" {
+					field.JSON = jsonName
+				}
+				field.OmitEmpty = slices.Contains(strings.Split(opts, ","), "omitempty")
+				for _, setting := range strings.Split(reflect.StructTag(tag).Get("gorm"), ";") {
+					if key, value, ok := strings.Cut(setting, ":"); ok && strings.EqualFold(key, "column") {
+						field.Column = value
+					}
+				}
+			}
+			if field.Doc = docText(f.Doc, field.Name); field.Doc == "" {
+				field.Doc = docText(f.Comment, "")
+			}
+			model.Fields = append(model.Fields, field)
+		}
+		models = append(models, model)
+	}
+	return models, slices.DeleteFunc(enums, func(e genEnum) bool { return len(e.Values) == 0 }), nil
+}
+
+// addEnumValue adds the value of a typed string constant to its enum of enums.
+func addEnumValue(enums []genEnum, spec *ast.ValueSpec) {
+	typ, ok := spec.Type.(*ast.Ident)
+	if !ok || len(spec.Values) != 1 {
+		return
+	}
+	lit, ok := spec.Values[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	for i := range enums {
+		if enums[i].Name == typ.Name {
+			enums[i].Values = append(enums[i].Values, value)
+		}
+	}
+}
+
+// docText returns the text of a doc comment without the leading name it documents.
+func docText(group *ast.CommentGroup, name string) string {
+	text := strings.TrimSpace(group.Text())
+	if name != "" {
+		text = strings.TrimSpace(strings.TrimPrefix(text, name+" "))
+		if text == name {
+			return ""
+		}
+	}
+	return text
+}
+
+// modelTypes returns the names of models and enums, to tell references to them from other types.
+func modelTypes(models []modelDecl, enums []genEnum) (modelNames, enumNames map[string]bool) {
+	modelNames, enumNames = make(map[string]bool), make(map[string]bool)
+	for _, m := range models {
+		modelNames[m.Name] = true
+	}
+	for _, e := range enums {
+		enumNames[e.Name] = true
+	}
+	return modelNames, enumNames
+}
+
+// selectorName returns the qualified name of a type of another package, e.g. time.Time.
+func selectorName(expr *ast.SelectorExpr) string {
+	pkg, _ := expr.X.(*ast.Ident)
+	return pkg.String() + "." + expr.Sel.Name
+}
@@ -0,0 +1,293 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// protoNumbering is the field numbers of a message, or the values of an enum, persisted so
+// fields keep their numbers when columns are added or dropped.
+type protoNumbering struct {
+	Fields map[string]int `json:"fields"`
+	// Reserved and ReservedNames are the numbers and names of the dropped fields, never reused.
+	Reserved      []int    `json:"reserved,omitempty"`
+	ReservedNames []string `json:"reserved_names,omitempty"`
+}
+
+// protoFileNumbering is the numbering of the messages and enums of a .proto file.
+type protoFileNumbering struct {
+	Messages map[string]*protoNumbering `json:"messages"`
+	Enums    map[string]*protoNumbering `json:"enums,omitempty"`
+}
+
+// assign returns the numbers of names, keeping the numbers of n, numbering new names from
+// first after the highest number ever used, and reserving the numbers and names dropped since.
+func (n *protoNumbering) assign(names []string, first int) []int {
+	if n.Fields == nil {
+		n.Fields = make(map[string]int)
+	}
+	next := first
+	for _, number := range slices.Concat(n.Reserved, slices.Collect(maps.Values(n.Fields))) {
+		next = max(next, number+1)
+	}
+	for name, number := range n.Fields {
+		if !slices.Contains(names, name) {
+			delete(n.Fields, name)
+			n.Reserved = append(n.Reserved, number)
+			n.ReservedNames = append(n.ReservedNames, name)
+		}
+	}
+	slices.Sort(n.Reserved)
+	slices.Sort(n.ReservedNames)
+
+	numbers := make([]int, len(names))
+	for i, name := range names {
+		number, ok := n.Fields[name]
+		if !ok {
+			// 19000 to 19999 are reserved by protobuf
+			if next >= 19000 && next < 20000 {
+				next = 20000
+			}
+			number, next = next, next+1
+			n.Fields[name] = number
+			n.ReservedNames = slices.DeleteFunc(n.ReservedNames, func(r string) bool { return r == name })
+		}
+		numbers[i] = number
+	}
+	return numbers
+}
+
+// protoType is the type of a message field and its label, optional or repeated.
+type protoType struct {
+	typ   string
+	label string
+}
+
+// goToProto returns the protobuf type of the Go type expr of a generated model. Models and
+// enum types of the package are referenced by name, unknown types are bytes.
+func goToProto(expr ast.Expr, models, enums map[string]bool) protoType {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		inner := goToProto(t.X, models, enums)
+		// Messages have presence, scalars and enums need optional
+		if inner.label == "" && !models[inner.typ] && inner.typ != "google.protobuf.Timestamp" {
+			inner.label = "optional"
+		}
+		return inner
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return protoType{typ: "bytes"}
+		}
+		elem := goToProto(t.Elt, models, enums)
+		if elem.label == "repeated" {
+			return protoType{typ: "bytes"}
+		}
+		return protoType{typ: elem.typ, label: "repeated"}
+	case *ast.SelectorExpr:
+		switch selectorName(t) {
+		case "time.Time", "gorm.DeletedAt":
+			return protoType{typ: "google.protobuf.Timestamp"}
+		case "decimal.Decimal":
+			return protoType{typ: "string"}
+		}
+	case *ast.Ident:
+		switch name := t.Name; name {
+		case "string", "bool":
+			return protoType{typ: name}
+		case "int8", "int16", "int32", "rune":
+			return protoType{typ: "int32"}
+		case "int", "int64":
+			return protoType{typ: "int64"}
+		case "uint8", "uint16", "uint32", "byte":
+			return protoType{typ: "uint32"}
+		case "uint", "uint64":
+			return protoType{typ: "uint64"}
+		case "float32":
+			return protoType{typ: "float"}
+		case "float64":
+			return protoType{typ: "double"}
+		default:
+			if enums[name] || models[name] {
+				return protoType{typ: name}
+			}
+		}
+	}
+	return protoType{typ: "bytes"}
+}
+
+// protoFieldName returns the snake case name of a message field: its column, or else the
+// snake case of its Go name for relations.
+func protoFieldName(f modelField) string {
+	if f.Column != "" {
+		return f.Column
+	}
+	return schema.NamingStrategy{}.ColumnName("", f.Name)
+}
+
+// protoEnumValue returns the name of the value of enum e, prefixed with the enum as proto
+// enum values share the scope of the package, e.g. ORDER_STATUS_IN_PROGRESS.
+func protoEnumValue(e, value string) string {
+	return strings.ToUpper(schema.NamingStrategy{}.ColumnName("", e+value))
+}
+
+// renderProto returns the .proto file of package pkg of models and enums, numbering the
+// fields and enum values with numbering, which it updates.
+func renderProto(pkg string, models []modelDecl, enums []genEnum, numbering *protoFileNumbering) string {
+	if numbering.Messages == nil {
+		numbering.Messages = make(map[string]*protoNumbering)
+	}
+	if numbering.Enums == nil {
+		numbering.Enums = make(map[string]*protoNumbering)
+	}
+	modelNames, enumNames := modelTypes(models, enums)
+	var body strings.Builder
+	timestamp := false
+	for _, e := range enums {
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = protoEnumValue(e.Name, goIdentifier(v))
+		}
+		if numbering.Enums[e.Name] == nil {
+			numbering.Enums[e.Name] = &protoNumbering{}
+		}
+		n := numbering.Enums[e.Name]
+		numbers := n.assign(values, 1)
+		fmt.Fprintf(&body, "\nenum %s {\n  %s = 0;\n", e.Name, protoEnumValue(e.Name, "Unspecified"))
+		writeProtoReserved(&body, n)
+		for i, value := range values {
+			fmt.Fprintf(&body, "  %s = %d; // %s\n", value, numbers[i], strconv.Quote(e.Values[i]))
+		}
+		body.WriteString("}\n")
+	}
+	for _, m := range models {
+		names := make([]string, len(m.Fields))
+		for i, f := range m.Fields {
+			names[i] = protoFieldName(f)
+		}
+		if numbering.Messages[m.Name] == nil {
+			numbering.Messages[m.Name] = &protoNumbering{}
+		}
+		n := numbering.Messages[m.Name]
+		numbers := n.assign(names, 1)
+		body.WriteString("\n")
+		if m.Doc != "" {
+			fmt.Fprintf(&body, "// %s\n", strings.Join(strings.Fields(m.Doc), " "))
+		}
+		fmt.Fprintf(&body, "message %s {\n", m.Name)
+		writeProtoReserved(&body, n)
+		for i, f := range m.Fields {
+			if f.Doc != "" {
+				fmt.Fprintf(&body, "  // %s\n", strings.Join(strings.Fields(f.Doc), " "))
+			}
+			typ := goToProto(f.Type, modelNames, enumNames)
+			timestamp = timestamp || typ.typ == "google.protobuf.Timestamp"
+			if typ.label != "" {
+				typ.typ = typ.label + " " + typ.typ
+			}
+			fmt.Fprintf(&body, "  %s %s = %d;\n", typ.typ, names[i], numbers[i])
+		}
+		body.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by gormeasy. DO NOT EDIT.\n\nsyntax = \"proto3\";\n\npackage %s;\n", pkg)
+	if timestamp {
+		b.WriteString("\nimport \"google/protobuf/timestamp.proto\";\n")
+	}
+	b.WriteString(body.String())
+	return b.String()
+}
+
+// writeProtoReserved writes the reserved statements of the dropped fields of n.
+func writeProtoReserved(b *strings.Builder, n *protoNumbering) {
+	if len(n.Reserved) > 0 {
+		numbers := make([]string, len(n.Reserved))
+		for i, number := range n.Reserved {
+			numbers[i] = strconv.Itoa(number)
+		}
+		fmt.Fprintf(b, "  reserved %s;\n", strings.Join(numbers, ", "))
+	}
+	if len(n.ReservedNames) > 0 {
+		names := make([]string, len(n.ReservedNames))
+		for i, name := range n.ReservedNames {
+			names[i] = strconv.Quote(name)
+		}
+		fmt.Fprintf(b, "  reserved %s;\n", strings.Join(names, ", "))
+	}
+}
+
+// protoNumbersPath returns the file persisting the field numbers, next to the .proto files
+// unless o.ProtoNumbers is set.
+func (o genOptions) protoNumbersPath() string {
+	if o.ProtoNumbers != "" {
+		return o.ProtoNumbers
+	}
+	return filepath.Join(o.ProtoOut, "proto-numbers.json")
+}
+
+// writeProto writes the protobuf messages of the models generated into modelDir, the Go
+// package pkg, to <pkg>.proto in o.ProtoOut, keeping the field numbers of the previous runs
+// in the file of protoNumbersPath.
+func (o genOptions) writeProto(modelDir, pkg string) error {
+	if o.ProtoOut == "" {
+		return nil
+	}
+	models, enums, err := parseModels(modelDir)
+	if err != nil {
+		return err
+	}
+	numbersPath := o.protoNumbersPath()
+	numbering := make(map[string]*protoFileNumbering)
+	data, err := os.ReadFile(numbersPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", numbersPath, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &numbering); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", numbersPath, err)
+		}
+	}
+
+	protoPackage := o.ProtoPackage
+	if protoPackage == "" {
+		protoPackage = pkg
+	} else if len(o.Schemas) > 0 {
+		protoPackage += "." + pkg
+	}
+	name := pkg + ".proto"
+	if numbering[name] == nil {
+		numbering[name] = &protoFileNumbering{}
+	}
+	proto := renderProto(protoPackage, models, enums, numbering[name])
+
+	if err := os.MkdirAll(o.ProtoOut, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", o.ProtoOut, err)
+	}
+	path := filepath.Join(o.ProtoOut, name)
+	if err := os.WriteFile(path, []byte(proto), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	data, err = json.MarshalIndent(numbering, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(numbersPath), 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", filepath.Dir(numbersPath), err)
+	}
+	if err := os.WriteFile(numbersPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", numbersPath, err)
+	}
+	out.Println("✅ Protobuf messages generated in:", path)
+	return nil
+}
@@ -0,0 +1,65 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestProto tests the protobuf messages of generated models and their persisted field numbers
+func TestProto(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testTSModels {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	models, enums, err := parseModels(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	numbering := &protoFileNumbering{}
+	proto := renderProto("model", models, enums, numbering)
+	for _, expected := range []string{
+		"syntax = \"proto3\";\n\npackage model;\n\nimport \"google/protobuf/timestamp.proto\";\n",
+		"enum OrderStatus {\n  ORDER_STATUS_UNSPECIFIED = 0;\n  ORDER_STATUS_NEW = 1; // \"new\"\n  ORDER_STATUS_PAID = 2; // \"paid\"\n}",
+		"// placed orders\nmessage Order {\n  int64 id = 1;\n  // current state\n  OrderStatus status = 2;\n",
+		"  optional string note = 3;\n",
+		"  google.protobuf.Timestamp created_at = 4;\n",
+		"  repeated Line lines = 6;\n}",
+		"message Line {\n  bytes payload = 1;\n}",
+	} {
+		if !strings.Contains(proto, expected) {
+			t.Errorf("Expected proto to contain %q, got:\n%s", expected, proto)
+		}
+	}
+
+	// Dropping note and adding a column keeps the numbers and never reuses 3
+	order := &models[slices.IndexFunc(models, func(m modelDecl) bool { return m.Name == "Order" })]
+	order.Fields = slices.DeleteFunc(order.Fields, func(f modelField) bool { return f.Column == "note" })
+	order.Fields = append(order.Fields, modelField{Name: "Total", Column: "total", Type: order.Fields[0].Type})
+	proto = renderProto("model", models, enums, numbering)
+	for _, expected := range []string{
+		"message Order {\n  reserved 3;\n  reserved \"note\";\n  int64 id = 1;\n",
+		"  repeated Line lines = 6;\n  int64 total = 7;\n}",
+	} {
+		if !strings.Contains(proto, expected) {
+			t.Errorf("Expected proto to contain %q, got:\n%s", expected, proto)
+		}
+	}
+
+	opts := genOptions{genConfig: genConfig{ProtoOut: filepath.Join(t.TempDir(), "proto"), ProtoPackage: "shop.v1"}}
+	if err := opts.writeProto(dir, "model"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(opts.ProtoOut, "model.proto"))
+	if err != nil || !strings.Contains(string(data), "package shop.v1;") {
+		t.Errorf("Expected model.proto of package shop.v1, got %v:\n%s", err, data)
+	}
+	if _, err := os.Stat(filepath.Join(opts.ProtoOut, "proto-numbers.json")); err != nil {
+		t.Errorf("Expected proto-numbers.json to be written, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// genRelation is an association field generated from a foreign key.
+type genRelation struct {
+	// Table is the table of the model getting the field.
+	Table string
+	// Kind is field.BelongsTo on the referencing table or field.HasMany on the referenced table.
+	Kind field.RelationshipType
+	// Name is the field name, e.g. User or Orders.
+	Name string
+	// RefTable is the table of the related model.
+	RefTable string
+	// ForeignKey and References are the field names of the foreign key and referenced columns.
+	ForeignKey string
+	References string
+}
+
+// foreignKeyRow is a row of the foreign key queries of loadForeignKeys, one per column.
+type foreignKeyRow struct {
+	FkName    string
+	FkTable   string
+	FkColumn  string
+	RefTable  string
+	RefColumn string
+}
+
+// loadForeignKeys reads the single-column foreign keys between tables, in schema on Postgres,
+// the current schema when empty. Foreign keys of several columns are skipped.
+func loadForeignKeys(db *gorm.DB, schema string, tables []string) ([]ForeignKey, error) {
+	rows, err := loadForeignKeyRows(db, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	return groupForeignKeys(rows), nil
+}
+
+// loadForeignKeyRows reads the columns of the foreign keys of tables, in schema on Postgres,
+// the current schema when empty.
+func loadForeignKeyRows(db *gorm.DB, schema string, tables []string) ([]foreignKeyRow, error) {
+	var rows []foreignKeyRow
+	switch dialect := db.Dialector.Name(); dialect {
+	case "postgres":
+		// conkey and confkey pair the columns of composite foreign keys by position
+		err := db.Raw(`SELECT c.conname AS fk_name, rel.relname AS fk_table, a.attname AS fk_column,
+ref.relname AS ref_table, ra.attname AS ref_column FROM pg_constraint c
+JOIN pg_class rel ON rel.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = rel.relnamespace
+JOIN pg_class ref ON ref.oid = c.confrelid
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, ref_attnum, ord)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.ref_attnum
+WHERE c.contype = 'f' AND n.nspname = COALESCE(NULLIF(?, ''), current_schema()) AND ref.relnamespace = n.oid
+ORDER BY rel.relname, c.conname, k.ord`, schema).Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+		}
+	case "mysql":
+		err := db.Raw(`SELECT CONSTRAINT_NAME AS fk_name, TABLE_NAME AS fk_table, COLUMN_NAME AS fk_column,
+REFERENCED_TABLE_NAME AS ref_table, REFERENCED_COLUMN_NAME AS ref_column FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`).Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+		}
+	case "sqlite":
+		for _, table := range tables {
+			var tableRows []foreignKeyRow
+			err := db.Raw(`SELECT CAST(id AS TEXT) AS fk_name, ? AS fk_table, "from" AS fk_column, "table" AS ref_table,
+COALESCE("to", '') AS ref_column FROM pragma_foreign_key_list(?) ORDER BY id`, table, table).Scan(&tableRows).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to list foreign keys of %s: %w", table, err)
+			}
+			rows = append(rows, tableRows...)
+		}
+	default:
+		return nil, fmt.Errorf("foreign keys are not supported for %s. Currently supported: PostgreSQL, MySQL, SQLite", dialect)
+	}
+	return rows, nil
+}
+
+// groupForeignKeys returns the foreign keys of rows with a single column and a known
+// referenced column, in the order of rows.
+func groupForeignKeys(rows []foreignKeyRow) []ForeignKey {
+	return slices.DeleteFunc(foreignKeysOf(rows), func(fk ForeignKey) bool { return len(fk.Columns) != 1 })
+}
+
+// foreignKeysOf returns the foreign keys of rows with known referenced columns, in the order of rows.
+func foreignKeysOf(rows []foreignKeyRow) []ForeignKey {
+	var fks []ForeignKey
+	index := make(map[string]int)
+	for _, row := range rows {
+		key := row.FkTable + "." + row.FkName
+		i, ok := index[key]
+		if !ok {
+			i = len(fks)
+			index[key] = i
+			fks = append(fks, ForeignKey{Name: row.FkName, Table: row.FkTable, RefTable: row.RefTable})
+		}
+		fks[i].Columns = appendUnique(fks[i].Columns, row.FkColumn)
+		fks[i].RefColumns = appendUnique(fks[i].RefColumns, row.RefColumn)
+	}
+	return slices.DeleteFunc(fks, func(fk ForeignKey) bool {
+		return slices.Contains(fk.RefColumns, "") || len(fk.RefColumns) != len(fk.Columns)
+	})
+}
+
+// genRelations returns the association fields of the foreign keys between tables: a BelongsTo
+// field on the referencing model, named after the foreign key column without _id or else after
+// the referenced model, and a HasMany field on the referenced model, named after the
+// referencing table and prefixed with the BelongsTo field when the tables have several foreign
+// keys. Fields clashing with a field of fields, keyed by table, or another relation are skipped.
+func genRelations(fks []ForeignKey, tables []string, fields map[string][]string, fieldName, structName func(string) string) []genRelation {
+	var relations []genRelation
+	add := func(r genRelation) {
+		clash := slices.Contains(fields[r.Table], r.Name) || slices.ContainsFunc(relations, func(o genRelation) bool {
+			return o.Table == r.Table && o.Name == r.Name
+		})
+		if clash {
+			out.Printf("⚠️  Skipping relation %s of %s: a field of that name exists\n", r.Name, r.Table)
+			return
+		}
+		relations = append(relations, r)
+	}
+	for _, fk := range fks {
+		if !slices.Contains(tables, fk.Table) || !slices.Contains(tables, fk.RefTable) {
+			continue
+		}
+		column := fk.Columns[0]
+		name := structName(fk.RefTable)
+		if base, ok := strings.CutSuffix(column, "_id"); ok && base != "" {
+			name = fieldName(base)
+		}
+		foreignKey, references := fieldName(column), fieldName(fk.RefColumns[0])
+		add(genRelation{Table: fk.Table, Kind: field.BelongsTo, Name: name, RefTable: fk.RefTable, ForeignKey: foreignKey, References: references})
+
+		many := goIdentifier(fk.Table)
+		siblings := 0
+		for _, other := range fks {
+			if other.Table == fk.Table && other.RefTable == fk.RefTable {
+				siblings++
+			}
+		}
+		if siblings > 1 {
+			many = name + many
+		}
+		add(genRelation{Table: fk.RefTable, Kind: field.HasMany, Name: many, RefTable: fk.Table, ForeignKey: foreignKey, References: references})
+	}
+	return relations
+}
+
+// genFieldName returns the name gen gives the field of column, see gorm.io/gen/internal/generate.
+func genFieldName(db *gorm.DB, column string) string {
+	if ns, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
+		ns.SingularTable = true
+		return ns.SchemaName(ns.TablePrefix + column)
+	}
+	return db.NamingStrategy.SchemaName(column)
+}
+
+// generateModels generates the models of tables, in schema when not empty, with generate,
+// gen.Generator.GenerateModelAs, and returns them. With opts.Relations, the models of tables
+// related by foreign keys are generated again with their association fields, built by relate,
+// gen.FieldRelate, from the models generated without.
+func generateModels[M any, O gen.ModelOpt](db *gorm.DB, generate func(string, string, ...gen.ModelOpt) M,
+	relate func(field.RelationshipType, string, M, *field.RelateConfig) O, schema string, tables []string, opts genOptions) ([]any, error) {
+	qualified := func(table string) string {
+		if schema == "" {
+			return table
+		}
+		return schema + "." + table
+	}
+	plain := make(map[string]M, len(tables))
+	models := make([]any, len(tables))
+	for i, table := range tables {
+		plain[table] = generate(qualified(table), db.NamingStrategy.SchemaName(table), opts.modelOpts(table)...)
+		models[i] = plain[table]
+	}
+	if !opts.Relations {
+		return models, nil
+	}
+
+	fks, err := loadForeignKeys(db, schema, tables)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string][]string)
+	for _, fk := range fks {
+		for _, table := range []string{fk.Table, fk.RefTable} {
+			if _, ok := fields[table]; ok || !slices.Contains(tables, table) {
+				continue
+			}
+			columns, err := db.Migrator().ColumnTypes(qualified(table))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+			}
+			fields[table] = []string{}
+			for _, c := range columns {
+				fields[table] = append(fields[table], genFieldName(db, c.Name()))
+			}
+		}
+	}
+	jsonTag, err := genJSONTag(opts.JSONTag, opts.JSONOmitEmpty)
+	if err != nil {
+		return nil, err
+	}
+	relations := genRelations(fks, tables, fields, func(column string) string { return genFieldName(db, column) }, db.NamingStrategy.SchemaName)
+	for i, table := range tables {
+		var relateOpts []gen.ModelOpt
+		for _, r := range relations {
+			if r.Table != table {
+				continue
+			}
+			cfg := &field.RelateConfig{
+				RelatePointer:      r.Kind == field.BelongsTo,
+				RelateSlicePointer: r.Kind == field.HasMany,
+				JSONTag:            jsonTag(db.NamingStrategy.ColumnName("", r.Name)),
+				GORMTag:            field.GormTag{"foreignKey": {r.ForeignKey}, "references": {r.References}},
+			}
+			relateOpts = append(relateOpts, relate(r.Kind, r.Name, plain[r.RefTable], cfg))
+		}
+		if len(relateOpts) > 0 {
+			models[i] = generate(qualified(table), db.NamingStrategy.SchemaName(table), append(opts.modelOpts(table), relateOpts...)...)
+		}
+	}
+	return models, nil
+}
@@ -0,0 +1,59 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// TestGroupForeignKeys tests that foreign keys of several columns are skipped
+func TestGroupForeignKeys(t *testing.T) {
+	fks := groupForeignKeys([]foreignKeyRow{
+		{FkName: "fk_orders_user", FkTable: "orders", FkColumn: "user_id", RefTable: "users", RefColumn: "id"},
+		{FkName: "fk_lines_order", FkTable: "lines", FkColumn: "order_id", RefTable: "orders", RefColumn: "id"},
+		{FkName: "fk_lines_order", FkTable: "lines", FkColumn: "tenant_id", RefTable: "orders", RefColumn: "tenant_id"},
+		{FkName: "1", FkTable: "notes", FkColumn: "user_id", RefTable: "users", RefColumn: ""},
+	})
+	if len(fks) != 1 || fks[0].Name != "fk_orders_user" || fks[0].Columns[0] != "user_id" || fks[0].RefColumns[0] != "id" {
+		t.Errorf("Expected only fk_orders_user, got %+v", fks)
+	}
+}
+
+// TestGenRelations tests the association fields generated from foreign keys
+func TestGenRelations(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+	fieldName := func(column string) string { return genFieldName(db, column) }
+	fks := []ForeignKey{
+		{Name: "fk_orders_user", Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		{Name: "fk_posts_author", Table: "posts", Columns: []string{"author_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		{Name: "fk_posts_editor", Table: "posts", Columns: []string{"editor_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		{Name: "fk_orders_coupon", Table: "orders", Columns: []string{"coupon"}, RefTable: "coupons", RefColumns: []string{"code"}},
+		{Name: "fk_invoices_order", Table: "invoices", Columns: []string{"order_id"}, RefTable: "orders", RefColumns: []string{"id"}},
+	}
+	fields := map[string][]string{"orders": {"ID", "UserID", "Coupon"}}
+	relations := genRelations(fks, []string{"orders", "posts", "users", "coupons"}, fields, fieldName, db.NamingStrategy.SchemaName)
+
+	var got []string
+	for _, r := range relations {
+		got = append(got, fmt.Sprintf("%s.%s %s %s %s>%s", r.Table, r.Name, r.Kind, r.RefTable, r.ForeignKey, r.References))
+	}
+	expected := []string{
+		"orders.User belongs_to users UserID>ID",
+		"users.Orders has_many orders UserID>ID",
+		"posts.Author belongs_to users AuthorID>ID",
+		"users.AuthorPosts has_many posts AuthorID>ID",
+		"posts.Editor belongs_to users EditorID>ID",
+		"users.EditorPosts has_many posts EditorID>ID",
+		"coupons.Orders has_many orders Coupon>Code",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if relations[0].Kind != field.BelongsTo {
+		t.Errorf("Expected belongs to, got %s", relations[0].Kind)
+	}
+}
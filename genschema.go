@@ -0,0 +1,131 @@
+package gormeasy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+// schemaPackage returns the Go package name of the models of a database schema: lower case,
+// with characters not allowed in identifiers replaced by underscores.
+func schemaPackage(schema string) string {
+	pkg := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(schema))
+	if pkg == "" || pkg[0] >= '0' && pkg[0] <= '9' {
+		pkg = "_" + pkg
+	}
+	return pkg
+}
+
+// schemaTables lists the tables of a Postgres schema.
+func schemaTables(db *gorm.DB, schema string) ([]string, error) {
+	var tables []string
+	err := db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE' ORDER BY table_name", schema).
+		Scan(&tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables of schema %s: %w", schema, err)
+	}
+	return tables, nil
+}
+
+// generateSchemaCode generates the models of each schema of opts.Schemas into a subpackage
+// named after the schema in the model directory next to basePath, e.g. model/billing, whose
+// TableName() includes the schema. With opts.queryOut, the query API of each schema is
+// generated into a subpackage of that directory as well.
+func generateSchemaCode(db *gorm.DB, basePath string, cfg gen.Config, opts genOptions) error {
+	if dialect := db.Dialector.Name(); dialect != "postgres" {
+		return fmt.Errorf("schemas are only supported on postgres, got %s", dialect)
+	}
+	base, err := filepath.Abs(basePath)
+	if err != nil {
+		return fmt.Errorf("invalid output path %s: %w", basePath, err)
+	}
+	modelPath := filepath.Join(filepath.Dir(base), "model")
+	queryPath := ""
+	if opts.queryOut != "" {
+		if _, queryPath, err = genQueryPaths(basePath, opts.queryOut); err != nil {
+			return err
+		}
+	}
+
+	if err := opts.clear(basePath); err != nil {
+		return fmt.Errorf("failed to clear directory: %w", err)
+	}
+
+	// The file of a model is named after its table, without the schema
+	cfg.WithFileNameStrategy(func(table string) string {
+		return strings.ToLower(table[strings.LastIndex(table, ".")+1:])
+	})
+	for _, schema := range opts.Schemas {
+		tables, err := schemaTables(db, schema)
+		if err != nil {
+			return err
+		}
+
+		schemaOpts := opts
+		schemaDB, tables, err := schemaOpts.withViews(db, schema, tables)
+		if err != nil {
+			return err
+		}
+		out.Printf("Generating GORM code for schema %s tables: %v\n", schema, tables)
+		if opts.Enums {
+			if schemaOpts.enums, err = loadEnums(db, schema, tables); err != nil {
+				return err
+			}
+		}
+
+		pkg := schemaPackage(schema)
+		schemaCfg := cfg
+		schemaCfg.ModelPkgPath = filepath.Join(modelPath, pkg)
+		if err := opts.clear(schemaCfg.ModelPkgPath); err != nil {
+			return fmt.Errorf("failed to clear directory: %w", err)
+		}
+		schemaCfg.OutPath, schemaCfg.Mode = base, gen.WithoutContext
+		if queryPath != "" {
+			schemaCfg.OutPath, schemaCfg.Mode = filepath.Join(queryPath, pkg), opts.mode
+			if err := opts.clear(schemaCfg.OutPath); err != nil {
+				return fmt.Errorf("failed to clear directory: %w", err)
+			}
+		}
+
+		g := gen.NewGenerator(schemaCfg)
+		g.UseDB(schemaDB)
+		models, err := generateModels(schemaDB, g.GenerateModelAs, gen.FieldRelate, schema, tables, schemaOpts)
+		if err != nil {
+			return err
+		}
+		if queryPath != "" {
+			g.ApplyBasic(models...)
+		}
+		g.Execute()
+		if err := docComments(schemaCfg.ModelPkgPath); err != nil {
+			return err
+		}
+		if err := writeEnums(schemaCfg.ModelPkgPath, schemaOpts.enums); err != nil {
+			return err
+		}
+		if err := schemaOpts.writeTypeScript(schemaCfg.ModelPkgPath, pkg+".ts"); err != nil {
+			return err
+		}
+		if err := schemaOpts.writeProto(schemaCfg.ModelPkgPath, pkg); err != nil {
+			return err
+		}
+		if err := schemaOpts.writeOpenAPI(schemaCfg.ModelPkgPath, pkg, pkg+".yaml"); err != nil {
+			return err
+		}
+		out.Println("✅ Models generated in:", schemaCfg.ModelPkgPath)
+		if queryPath != "" {
+			out.Println("✅ Query API generated in:", schemaCfg.OutPath)
+		}
+	}
+
+	out.Println("🎉 GORM code generation complete.")
+	return nil
+}
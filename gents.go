@@ -0,0 +1,153 @@
+package gormeasy
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tsType is the TypeScript type and the zod schema of a Go type.
+type tsType struct {
+	ts       string
+	zod      string
+	nullable bool
+}
+
+// goToTS returns the TypeScript type of the Go type expr of a generated model. Models and
+// enum types of the package are referenced by name, types unknown in JSON are unknown.
+func goToTS(expr ast.Expr, models, enums map[string]bool) tsType {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		inner := goToTS(t.X, models, enums)
+		inner.nullable = true
+		return inner
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return tsType{ts: "string", zod: "z.string()"}
+		}
+		// Elements are not nullable: the slices of gen, e.g. []*Order of has many relations, hold no nil
+		elem := goToTS(t.Elt, models, enums)
+		return tsType{ts: elem.ts + "[]", zod: "z.array(" + elem.zod + ")"}
+	case *ast.MapType:
+		return tsType{ts: "Record<string, unknown>", zod: "z.record(z.string(), z.unknown())"}
+	case *ast.SelectorExpr:
+		switch selectorName(t) {
+		case "time.Time", "decimal.Decimal":
+			return tsType{ts: "string", zod: "z.string()"}
+		case "gorm.DeletedAt":
+			return tsType{ts: "string", zod: "z.string()", nullable: true}
+		}
+	case *ast.Ident:
+		switch name := t.Name; {
+		case name == "string":
+			return tsType{ts: "string", zod: "z.string()"}
+		case name == "bool":
+			return tsType{ts: "boolean", zod: "z.boolean()"}
+		case strings.HasPrefix(name, "int"), strings.HasPrefix(name, "uint"), strings.HasPrefix(name, "float"), name == "byte", name == "rune":
+			return tsType{ts: "number", zod: "z.number()"}
+		case enums[name]:
+			return tsType{ts: name, zod: name + "Schema"}
+		case models[name]:
+			return tsType{ts: name, zod: "z.lazy(() => " + name + "Schema)"}
+		}
+	}
+	return tsType{ts: "unknown", zod: "z.unknown()"}
+}
+
+// tsIdentifier matches the property names that need no quotes.
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// renderTS returns the TypeScript file of models and enums, with zod schemas when zod is set.
+func renderTS(models []modelDecl, enums []genEnum, zod bool) string {
+	modelNames, enumNames := modelTypes(models, enums)
+	var b strings.Builder
+	b.WriteString("// Code generated by gormeasy. DO NOT EDIT.\n")
+	if zod {
+		b.WriteString("\nimport { z } from 'zod';\n")
+	}
+	for _, e := range enums {
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, "\nexport type %s = %s;\n", e.Name, strings.Join(values, " | "))
+		if zod {
+			fmt.Fprintf(&b, "export const %sSchema = z.enum([%s]);\n", e.Name, strings.Join(values, ", "))
+		}
+	}
+	for _, m := range models {
+		b.WriteString("\n")
+		if m.Doc != "" {
+			fmt.Fprintf(&b, "/** %s */\n", tsComment(m.Doc))
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", m.Name)
+		for _, f := range m.Fields {
+			if f.Doc != "" {
+				fmt.Fprintf(&b, "  /** %s */\n", tsComment(f.Doc))
+			}
+			property, typ := tsPropertyName(f.JSON), goToTS(f.Type, modelNames, enumNames)
+			if f.OmitEmpty {
+				property += "?"
+			}
+			if typ.nullable {
+				typ.ts += " | null"
+			}
+			fmt.Fprintf(&b, "  %s: %s;\n", property, typ.ts)
+		}
+		b.WriteString("}\n")
+		if !zod {
+			continue
+		}
+		fmt.Fprintf(&b, "export const %sSchema: z.ZodType<%s> = z.lazy(() =>\n  z.object({\n", m.Name, m.Name)
+		for _, f := range m.Fields {
+			typ := goToTS(f.Type, modelNames, enumNames)
+			if typ.nullable {
+				typ.zod += ".nullable()"
+			}
+			if f.OmitEmpty {
+				typ.zod += ".optional()"
+			}
+			fmt.Fprintf(&b, "    %s: %s,\n", tsPropertyName(f.JSON), typ.zod)
+		}
+		b.WriteString("  }),\n);\n")
+	}
+	return b.String()
+}
+
+// tsPropertyName returns name as a property name, quoted unless it is an identifier.
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// tsComment returns text on one line, safe in a /** */ comment.
+func tsComment(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "*/", "* /")
+}
+
+// writeTypeScript writes the TypeScript interfaces of the models generated into modelDir to
+// the file name in o.TSOut, with zod schemas when o.TSZod is set.
+func (o genOptions) writeTypeScript(modelDir, name string) error {
+	if o.TSOut == "" {
+		return nil
+	}
+	models, enums, err := parseModels(modelDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.TSOut, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", o.TSOut, err)
+	}
+	path := filepath.Join(o.TSOut, name)
+	if err := os.WriteFile(path, []byte(renderTS(models, enums, o.TSZod)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	out.Println("✅ TypeScript types generated in:", path)
+	return nil
+}
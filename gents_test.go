@@ -0,0 +1,78 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testTSModels is a generated model package with an enum and a relation
+var testTSModels = map[string]string{
+	"orders.gen.go": "// Code generated by gorm.io/gen. DO NOT EDIT.\n\npackage model\n\nimport (\n\t\"time\"\n\n\t\"gorm.io/gorm\"\n)\n\n" +
+		"// Order placed orders\ntype Order struct {\n" +
+		"\tID int64 `gorm:\"column:id;primaryKey\" json:\"id\"`\n" +
+		"\t// Status current state\n\tStatus OrderStatus `gorm:\"column:status\" json:\"status\"`\n" +
+		"\tNote *string `gorm:\"column:note\" json:\"note,omitempty\"`\n" +
+		"\tCreatedAt time.Time `gorm:\"column:created_at\" json:\"created-at\"`\n" +
+		"\tDeletedAt gorm.DeletedAt `gorm:\"column:deleted_at\" json:\"deleted_at\"`\n" +
+		"\tSecret string `gorm:\"column:secret\" json:\"-\"`\n" +
+		"\tLines []*Line `gorm:\"foreignKey:OrderID\" json:\"lines\"`\n}\n\n" +
+		"// Line mapped from table <lines>\ntype Line struct {\n\tPayload []byte `gorm:\"column:payload\" json:\"payload\"`\n}\n",
+	"enums.gen.go": "// Code generated by gormeasy. DO NOT EDIT.\n\npackage model\n\n// OrderStatus is a value of the order_status enum type.\ntype OrderStatus string\n\n" +
+		"const (\n\tOrderStatusNew OrderStatus = \"new\"\n\tOrderStatusPaid OrderStatus = \"paid\"\n)\n",
+}
+
+// TestTypeScript tests the TypeScript interfaces and zod schemas of generated models
+func TestTypeScript(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testTSModels {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	models, enums, err := parseModels(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ts := renderTS(models, enums, false)
+	for _, expected := range []string{
+		`export type OrderStatus = "new" | "paid";`,
+		"/** placed orders */\nexport interface Order {\n  id: number;\n",
+		"  /** current state */\n  status: OrderStatus;\n",
+		"  note?: string | null;\n",
+		`  "created-at": string;`,
+		"  deleted_at: string | null;\n",
+		"  lines: Line[];\n}",
+		"/** mapped from table <lines> */\nexport interface Line {\n  payload: string;\n}",
+	} {
+		if !strings.Contains(ts, expected) {
+			t.Errorf("Expected TypeScript to contain %q, got:\n%s", expected, ts)
+		}
+	}
+	if strings.Contains(ts, "secret") || strings.Contains(ts, "zod") {
+		t.Errorf("Expected no secret field and no zod, got:\n%s", ts)
+	}
+
+	zod := renderTS(models, enums, true)
+	for _, expected := range []string{
+		"import { z } from 'zod';",
+		`export const OrderStatusSchema = z.enum(["new", "paid"]);`,
+		"export const OrderSchema: z.ZodType<Order> = z.lazy(() =>\n  z.object({\n    id: z.number(),\n    status: OrderStatusSchema,\n",
+		"    note: z.string().nullable().optional(),\n",
+		"    lines: z.array(z.lazy(() => LineSchema)),\n",
+	} {
+		if !strings.Contains(zod, expected) {
+			t.Errorf("Expected zod to contain %q, got:\n%s", expected, zod)
+		}
+	}
+
+	opts := genOptions{genConfig: genConfig{TSOut: filepath.Join(t.TempDir(), "types")}}
+	if err := opts.writeTypeScript(dir, "models.ts"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(opts.TSOut, "models.ts")); err != nil {
+		t.Errorf("Expected models.ts to be written, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+)
+
+// listViews lists the views and materialized views of schema on Postgres, the current schema
+// when empty, or of the current database on MySQL and SQLite.
+func listViews(db *gorm.DB, schema string) (views, matviews []string, err error) {
+	switch dialect := db.Dialector.Name(); dialect {
+	case "postgres":
+		err = db.Raw("SELECT table_name FROM information_schema.views WHERE table_schema = COALESCE(NULLIF(?, ''), current_schema()) ORDER BY table_name", schema).
+			Scan(&views).Error
+		if err == nil {
+			err = db.Raw("SELECT matviewname FROM pg_matviews WHERE schemaname = COALESCE(NULLIF(?, ''), current_schema()) ORDER BY matviewname", schema).
+				Scan(&matviews).Error
+		}
+	case "mysql":
+		err = db.Raw("SELECT TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME").Scan(&views).Error
+	case "sqlite":
+		err = db.Raw("SELECT name FROM sqlite_master WHERE type = 'view' ORDER BY name").Scan(&views).Error
+	default:
+		return nil, nil, fmt.Errorf("views are not supported for %s. Currently supported: PostgreSQL, MySQL, SQLite", dialect)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	return views, matviews, nil
+}
+
+// withViews appends the views and materialized views of schema to tables when o.IncludeViews,
+// marking their models read-only, and returns the database to generate the models from, whose
+// migrator reads the columns of materialized views, missing from information_schema on Postgres.
+func (o *genOptions) withViews(db *gorm.DB, schema string, tables []string) (*gorm.DB, []string, error) {
+	if !o.IncludeViews {
+		return db, tables, nil
+	}
+	views, matviews, err := listViews(db, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.views = make(map[string]bool)
+	for _, view := range slices.Concat(views, matviews) {
+		o.views[view] = true
+	}
+	tables = slices.Concat(tables, views, matviews)
+	if len(matviews) == 0 {
+		return db, tables, nil
+	}
+	config := *db.Config
+	config.Dialector = viewDialector{Dialector: db.Dialector, matviews: matviews, schema: schema}
+	viewDB := db.Session(&gorm.Session{NewDB: true})
+	viewDB.Config = &config
+	return viewDB, tables, nil
+}
+
+// readOnlyField makes the fields of a view model read-only for GORM, without the tags filling
+// columns on create or update.
+var readOnlyField = gen.FieldModify(func(f gen.Field) gen.Field {
+	f.GORMTag.Remove("autoCreateTime").Remove("autoUpdateTime").Remove("default").Set("<-", "false")
+	return f
+})
+
+// viewDialector is a dialector whose migrator reads the column types of the materialized
+// views matviews of schema from a query, since Postgres lists them in pg_attribute only.
+type viewDialector struct {
+	gorm.Dialector
+	matviews []string
+	schema   string
+}
+
+func (d viewDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return viewMigrator{Migrator: d.Dialector.Migrator(db), db: db, dialector: d}
+}
+
+type viewMigrator struct {
+	gorm.Migrator
+	db        *gorm.DB
+	dialector viewDialector
+}
+
+// ColumnTypes returns the column types of the result of the materialized view value, or else
+// those of the wrapped migrator.
+func (m viewMigrator) ColumnTypes(value interface{}) ([]gorm.ColumnType, error) {
+	table, ok := value.(string)
+	if !ok || !slices.Contains(m.dialector.matviews, strings.TrimPrefix(table, m.dialector.schema+".")) {
+		return m.Migrator.ColumnTypes(value)
+	}
+	rows, err := m.db.Session(&gorm.Session{NewDB: true}).Table(table).Limit(0).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes := make([]gorm.ColumnType, len(columns))
+	for i, c := range columns {
+		columnTypes[i] = migrator.ColumnType{SQLColumnType: c}
+	}
+	return columnTypes, nil
+}
@@ -0,0 +1,48 @@
+package gormeasy
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// columnTypesMigrator is a gorm.Migrator recording the tables of ColumnTypes
+type columnTypesMigrator struct {
+	gorm.Migrator
+	tables []any
+}
+
+func (m *columnTypesMigrator) ColumnTypes(value interface{}) ([]gorm.ColumnType, error) {
+	m.tables = append(m.tables, value)
+	return nil, errors.New("no columns")
+}
+
+// TestViewMigrator tests that only materialized views are read from a query
+func TestViewMigrator(t *testing.T) {
+	inner := &columnTypesMigrator{}
+	m := viewMigrator{Migrator: inner, dialector: viewDialector{matviews: []string{"sales_mv"}, schema: "billing"}}
+	for _, table := range []any{"billing.invoices", "invoices", &struct{}{}} {
+		if _, err := m.ColumnTypes(table); err == nil {
+			t.Errorf("Expected the error of the wrapped migrator for %v, got nil", table)
+		}
+	}
+	if len(inner.tables) != 3 {
+		t.Errorf("Expected 3 calls of the wrapped migrator, got %v", inner.tables)
+	}
+}
+
+// TestWithViews tests that views are only listed with IncludeViews, on supported databases
+func TestWithViews(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	opts := genOptions{}
+	got, tables, err := opts.withViews(db, "", []string{"users"})
+	if err != nil || got != db || len(tables) != 1 {
+		t.Errorf("Expected the tables unchanged, got %v, %v", tables, err)
+	}
+	opts.IncludeViews = true
+	if _, _, err := opts.withViews(db, "", []string{"users"}); err == nil {
+		t.Error("Expected error for views on dummy, got nil")
+	}
+}
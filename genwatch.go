@@ -0,0 +1,87 @@
+package gormeasy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// watchSchema runs generate, then reads the schema fingerprint every interval and runs
+// generate again whenever it changed, e.g. after `up` in another terminal, until ctx is done.
+// Errors while watching are printed without stopping, since the schema may be mid-migration.
+func watchSchema(ctx context.Context, interval time.Duration, fingerprint func() (string, error), generate func() error) error {
+	last, err := fingerprint()
+	if err != nil {
+		return err
+	}
+	if err := generate(); err != nil {
+		return err
+	}
+	out.Printf("👀 Watching the schema every %s, press Ctrl+C to stop\n", interval)
+	for {
+		select {
+		case <-ctx.Done():
+			out.Println("Stopped watching the schema")
+			return nil
+		case <-time.After(interval):
+		}
+		sum, err := fingerprint()
+		if err != nil {
+			out.Errorln("⚠️  Failed to read the schema:", err)
+			continue
+		}
+		if sum == last {
+			continue
+		}
+		out.Println("🔄 Schema changed, regenerating models")
+		if err := generate(); err != nil {
+			out.Errorln("❌ Failed to generate GORM code:", err)
+			continue
+		}
+		last = sum
+	}
+}
+
+// genFingerprint returns the fingerprint of what gen generates from: the tables, views and
+// enum types of each Postgres schema of schemas, or of the current schema, so changes to
+// views or enum labels regenerate the models too.
+func genFingerprint(db *gorm.DB, opts Options, schemas []string) (string, error) {
+	if len(schemas) == 0 || db.Dialector.Name() != "postgres" {
+		schemas = []string{""}
+	}
+	h := sha256.New()
+	for _, schema := range schemas {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if schema != "" {
+				if err := tx.Exec("SET LOCAL search_path TO " + quotePostgresIdent(schema)).Error; err != nil {
+					return fmt.Errorf("failed to select schema %s: %w", schema, err)
+				}
+			}
+			dump, err := dumpSchema(tx, opts.TableName, metadataTableName(opts))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "-- Schema %s\n%s", schema, dump.SQL)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recoverGen runs generate, returning the panic of gen, e.g. on a table dropped while its
+// model is generated, as an error.
+func recoverGen(generate func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gen failed: %v", r)
+		}
+	}()
+	return generate()
+}
@@ -0,0 +1,83 @@
+package gormeasy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWatchSchema tests that models are regenerated once per schema change
+func TestWatchSchema(t *testing.T) {
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sums := []string{"a", "a", "b", "b", "", "c"}
+	fingerprint := func() (string, error) {
+		if len(sums) == 0 {
+			cancel()
+			return "c", nil
+		}
+		sum := sums[0]
+		sums = sums[1:]
+		if sum == "" {
+			return "", errors.New("connection reset")
+		}
+		return sum, nil
+	}
+	generated := 0
+	generate := func() error {
+		generated++
+		return nil
+	}
+	if err := watchSchema(ctx, time.Millisecond, fingerprint, generate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if generated != 3 {
+		t.Errorf("Expected 3 generations, got %d", generated)
+	}
+	if !strings.Contains(buf.String(), "connection reset") || !strings.Contains(buf.String(), "Stopped watching") {
+		t.Errorf("Expected the error and the stop to be printed, got:\n%s", buf.String())
+	}
+
+	failing := func() error { return errors.New("no tables") }
+	if err := watchSchema(context.Background(), time.Millisecond, func() (string, error) { return "a", nil }, failing); err == nil {
+		t.Error("Expected error of the first generation, got nil")
+	}
+}
+
+// TestRecoverGen tests that a panic of gen is returned as an error
+func TestRecoverGen(t *testing.T) {
+	err := recoverGen(func() error { panic("generate struct fail") })
+	if err == nil || !strings.Contains(err.Error(), "generate struct fail") {
+		t.Errorf("Expected the panic as error, got %v", err)
+	}
+}
+
+// TestGenFingerprint tests that the fingerprint changes with the tables and views
+func TestGenFingerprint(t *testing.T) {
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	var sums []string
+	for _, statement := range []string{"", "CREATE TABLE users (id integer PRIMARY KEY, name text)", "CREATE VIEW user_names AS SELECT name FROM users"} {
+		if statement != "" {
+			if err := db.Exec(statement).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		sum, err := genFingerprint(db, opts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slices.Contains(sums, sum) {
+			t.Errorf("Expected the fingerprint to change after %q", statement)
+		}
+		sums = append(sums, sum)
+	}
+}
@@ -0,0 +1,92 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestResolveURLPrecedence tests the precedence chain of database URL flags
+func TestResolveURLPrecedence(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("OWNER_DATABASE_URL", "")
+	t.Setenv("REGRESSION_DATABASE_URL", "")
+	c := &cli{config: &fileConfig{DatabaseURL: "config-db", OwnerDatabaseURL: "config-owner"}}
+	dbURL, ownerURL, regressionURL := urlFlags[0], urlFlags[1], urlFlags[2]
+
+	if got := c.resolveURL(dbURL); got != "config-db" {
+		t.Errorf("Expected config file value 'config-db', got '%s'", got)
+	}
+
+	t.Setenv("DATABASE_URL", "env-db")
+	if got := c.resolveURL(dbURL); got != "env-db" {
+		t.Errorf("Expected DATABASE_URL 'env-db' over config file, got '%s'", got)
+	}
+	if got := c.resolveURL(ownerURL); got != "env-db" {
+		t.Errorf("Expected owner URL to fall back to DATABASE_URL 'env-db', got '%s'", got)
+	}
+	if got := c.resolveURL(regressionURL); got != "" {
+		t.Errorf("Expected regression URL to never fall back to DATABASE_URL, got '%s'", got)
+	}
+
+	t.Setenv("OWNER_DATABASE_URL", "env-owner")
+	if got := c.resolveURL(ownerURL); got != "env-owner" {
+		t.Errorf("Expected OWNER_DATABASE_URL 'env-owner' over DATABASE_URL, got '%s'", got)
+	}
+
+	c.databaseURL = "global-flag"
+	if got := c.resolveURL(dbURL); got != "global-flag" {
+		t.Errorf("Expected global --db-url 'global-flag' over DATABASE_URL, got '%s'", got)
+	}
+}
+
+// TestResolveURLEnvironment tests that an environment profile replaces the variables and config keys
+func TestResolveURLEnvironment(t *testing.T) {
+	t.Setenv("DATABASE_URL", "env-db")
+	t.Setenv("DATABASE_URL_STAGING", "")
+	t.Setenv("OWNER_DATABASE_URL_STAGING", "")
+	c := &cli{env: "staging", config: &fileConfig{
+		DatabaseURL:  "config-db",
+		Environments: map[string]databaseURLs{"staging": {DatabaseURL: "config-staging"}},
+	}}
+	dbURL, ownerURL := urlFlags[0], urlFlags[1]
+
+	if got := c.resolveURL(dbURL); got != "config-staging" {
+		t.Errorf("Expected config profile value 'config-staging', got '%s'", got)
+	}
+	if got := c.resolveURL(ownerURL); got != "config-staging" {
+		t.Errorf("Expected owner URL to fall back to the profile database URL, got '%s'", got)
+	}
+
+	t.Setenv("DATABASE_URL_STAGING", "env-staging")
+	if got := c.resolveURL(dbURL); got != "env-staging" {
+		t.Errorf("Expected DATABASE_URL_STAGING 'env-staging' over config profile, got '%s'", got)
+	}
+
+	c.env = "qa"
+	if got := c.resolveURL(dbURL); got != "" {
+		t.Errorf("Expected an undefined profile to never fall back to DATABASE_URL, got '%s'", got)
+	}
+}
+
+// TestCheckEnvironment tests that unknown and invalid environment profiles are refused
+func TestCheckEnvironment(t *testing.T) {
+	t.Setenv("DATABASE_URL_PROD_EU", "")
+	c := &cli{env: "staging", config: &fileConfig{Environments: map[string]databaseURLs{"staging": {}}}}
+	if err := c.checkEnvironment(); err != nil {
+		t.Errorf("Expected staging of the config file to be known, got %v", err)
+	}
+
+	c.env = "prod-eu"
+	if err := c.checkEnvironment(); err == nil || !strings.Contains(err.Error(), "DATABASE_URL_PROD_EU") {
+		t.Errorf("Expected unknown environment error naming DATABASE_URL_PROD_EU, got %v", err)
+	}
+	t.Setenv("DATABASE_URL_PROD_EU", "postgres://prod")
+	if err := c.checkEnvironment(); err != nil {
+		t.Errorf("Expected prod-eu defined by DATABASE_URL_PROD_EU to be known, got %v", err)
+	}
+
+	c.env = "../prod"
+	if err := c.checkEnvironment(); err == nil || !strings.Contains(err.Error(), "invalid environment") {
+		t.Errorf("Expected invalid environment error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package gormeasy
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// heartbeatSQLLength is the maximum length of the statement printed on heartbeat lines.
+const heartbeatSQLLength = 200
+
+// currentStatement is the statement a migration is executing, shared by a statementPool and
+// the transactions begun on it.
+type currentStatement struct {
+	mu  sync.Mutex
+	sql string
+}
+
+func (c *currentStatement) set(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sql = query
+}
+
+func (c *currentStatement) get() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sql
+}
+
+// statementPool is a gorm.ConnPool that records the statement it executes before forwarding
+// it to the real pool, so heartbeat lines can show what a long migration is waiting for.
+type statementPool struct {
+	pool    gorm.ConnPool
+	current *currentStatement
+}
+
+func (p *statementPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	p.current.set(query)
+	return p.pool.PrepareContext(ctx, query)
+}
+
+func (p *statementPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.current.set(query)
+	return p.pool.ExecContext(ctx, query, args...)
+}
+
+func (p *statementPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.current.set(query)
+	return p.pool.QueryContext(ctx, query, args...)
+}
+
+func (p *statementPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.current.set(query)
+	return p.pool.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction on the real pool and records its statements as well.
+func (p *statementPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var tx gorm.ConnPool
+	var err error
+	switch beginner := p.pool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statementTx{&statementPool{pool: tx, current: p.current}}, nil
+}
+
+// GetDBConn returns the *sql.DB of the real pool, for tx.DB().
+func (p *statementPool) GetDBConn() (*sql.DB, error) {
+	return (&gorm.DB{Config: &gorm.Config{ConnPool: p.pool}}).DB()
+}
+
+// statementTx is a transaction begun on a statementPool, or the pool of a migration running
+// in a transaction, so nested tx.Transaction calls use savepoints.
+type statementTx struct {
+	*statementPool
+}
+
+func (t *statementTx) Commit() error {
+	if committer, ok := t.pool.(gorm.TxCommitter); ok {
+		return committer.Commit()
+	}
+	return gorm.ErrInvalidTransaction
+}
+
+func (t *statementTx) Rollback() error {
+	if committer, ok := t.pool.(gorm.TxCommitter); ok {
+		return committer.Rollback()
+	}
+	return gorm.ErrInvalidTransaction
+}
+
+// withHeartbeat returns copies of migrations whose Migrate prints a heartbeat line with the
+// migration ID and the elapsed time every interval while it runs, and with showSQL the
+// statement it is executing, so a long backfill is not mistaken for a hang.
+func withHeartbeat(migrations []*Migration, interval time.Duration, showSQL bool) []*Migration {
+	beating := make([]*Migration, len(migrations))
+	for i, m := range migrations {
+		m := *m
+		if migrate := m.Migrate; migrate != nil {
+			m.Migrate = func(tx *gorm.DB) error {
+				var current *currentStatement
+				if showSQL {
+					current = &currentStatement{}
+					ctx := tx.Statement.Context
+					if ctx == nil {
+						ctx = context.Background()
+					}
+					// A new context clones the statement, so the pool of the caller is kept
+					tx = tx.Session(&gorm.Session{Context: ctx})
+					if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); ok {
+						tx.Statement.ConnPool = &statementTx{&statementPool{pool: tx.Statement.ConnPool, current: current}}
+					} else {
+						tx.Statement.ConnPool = &statementPool{pool: tx.Statement.ConnPool, current: current}
+					}
+				}
+				stop := startHeartbeat(m.ID, interval, current, clockOf(tx))
+				defer stop()
+				return migrate(tx)
+			}
+		}
+		beating[i] = &m
+	}
+	return beating
+}
+
+// startHeartbeat prints a heartbeat line for migration id every interval until stop is called.
+func startHeartbeat(id string, interval time.Duration, current *currentStatement, clock Clock) (stop func()) {
+	start := clock.Now()
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				out.Println(heartbeatLine(id, since(clock, start), current))
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		wg.Wait()
+	}
+}
+
+// heartbeatLine returns the heartbeat line of migration id running for elapsed, with the
+// statement it is executing on one line when current is not nil.
+func heartbeatLine(id string, elapsed time.Duration, current *currentStatement) string {
+	line := "⏳ " + id + " still running (" + elapsed.Round(time.Second).String() + ")"
+	if current == nil {
+		return line
+	}
+	stmt := strings.Join(strings.Fields(current.get()), " ")
+	if stmt == "" {
+		return line
+	}
+	if runes := []rune(stmt); len(runes) > heartbeatSQLLength {
+		stmt = string(runes[:heartbeatSQLLength]) + "..."
+	}
+	return line + ": " + stmt
+}
+
+// addHeartbeatFlags registers the --heartbeat and --heartbeat-sql flags and returns a function
+// applying them to opts.
+func addHeartbeatFlags(fs *flag.FlagSet) func(opts Options) Options {
+	heartbeat := fs.Duration("heartbeat", 0, "Print a line every interval while a migration runs, e.g. 30s (default Options.Heartbeat)")
+	heartbeatSQL := fs.Bool("heartbeat-sql", false, "Include the statement a migration is executing in heartbeat lines")
+	return func(opts Options) Options {
+		if *heartbeat > 0 {
+			opts.Heartbeat = *heartbeat
+		}
+		if *heartbeatSQL {
+			opts.HeartbeatSQL = true
+		}
+		return opts
+	}
+}
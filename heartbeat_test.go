@@ -0,0 +1,105 @@
+package gormeasy
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakePool is a gorm.ConnPool executing nothing, counting commits of its transactions
+type fakePool struct {
+	commits int
+}
+
+func (p *fakePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, nil
+}
+
+func (p *fakePool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func (p *fakePool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (p *fakePool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *fakePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{p}, nil
+}
+
+type fakeTx struct {
+	*fakePool
+}
+
+func (t *fakeTx) Commit() error   { t.commits++; return nil }
+func (t *fakeTx) Rollback() error { return nil }
+
+// TestStatementPool tests that the pool and its transactions record the executed statement
+func TestStatementPool(t *testing.T) {
+	inner := &fakePool{}
+	current := &currentStatement{}
+	pool := &statementPool{pool: inner, current: current}
+
+	pool.ExecContext(context.Background(), "UPDATE orders SET total = 0")
+	if current.get() != "UPDATE orders SET total = 0" {
+		t.Errorf("Expected the update to be recorded, got %q", current.get())
+	}
+	tx, err := pool.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	tx.QueryContext(context.Background(), "SELECT 1")
+	if current.get() != "SELECT 1" {
+		t.Errorf("Expected the query of the transaction to be recorded, got %q", current.get())
+	}
+	if err := tx.(gorm.TxCommitter).Commit(); err != nil || inner.commits != 1 {
+		t.Errorf("Expected the commit to be forwarded, got %d commits, %v", inner.commits, err)
+	}
+}
+
+// TestHeartbeatLine tests the heartbeat line with and without the current statement
+func TestHeartbeatLine(t *testing.T) {
+	if line := heartbeatLine("1", 90*time.Second, nil); line != "⏳ 1 still running (1m30s)" {
+		t.Errorf("Expected line without statement, got %q", line)
+	}
+	current := &currentStatement{}
+	if line := heartbeatLine("1", time.Second, current); line != "⏳ 1 still running (1s)" {
+		t.Errorf("Expected line without statement before the first one, got %q", line)
+	}
+	current.set("UPDATE orders\n    SET total = 0")
+	if line := heartbeatLine("1", time.Second, current); line != "⏳ 1 still running (1s): UPDATE orders SET total = 0" {
+		t.Errorf("Expected the statement on one line, got %q", line)
+	}
+	current.set(strings.Repeat("x", 300))
+	if line := heartbeatLine("1", time.Second, current); !strings.HasSuffix(line, strings.Repeat("x", 200)+"...") {
+		t.Errorf("Expected the statement to be truncated, got %q", line)
+	}
+}
+
+// TestWithHeartbeat tests that heartbeat lines are printed while a migration runs
+func TestWithHeartbeat(t *testing.T) {
+	var buf bytes.Buffer
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	migrations := withHeartbeat([]*Migration{{ID: "1", Migrate: func(*gorm.DB) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}}}, 5*time.Millisecond, false)
+	if err := migrations[0].Migrate(nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "⏳ 1 still running") {
+		t.Errorf("Expected heartbeat lines, got %q", buf.String())
+	}
+}
@@ -0,0 +1,88 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"slices"
+)
+
+// helpDoc is the command and flag tree printed by `help --json`, for tools that render the
+// CLI (e.g. a deploy UI building forms) without parsing the help text.
+type helpDoc struct {
+	Usage       string        `json:"usage"`
+	GlobalFlags []helpFlag    `json:"global_flags"`
+	Commands    []helpCommand `json:"commands"`
+}
+
+// helpCommand describes a command of the command table.
+type helpCommand struct {
+	Name    string     `json:"name"`
+	Aliases []string   `json:"aliases,omitempty"`
+	Summary string     `json:"summary"`
+	Flags   []helpFlag `json:"flags"`
+}
+
+// helpFlag describes a flag. Type is the flag package's name of the value type: bool, string,
+// int, duration, or value for flags such as --group that parse their own format.
+type helpFlag struct {
+	Name    string `json:"name"`
+	Short   string `json:"short,omitempty"`
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage"`
+}
+
+// printHelpJSON writes the command and flag tree as JSON to w. global holds the flags
+// accepted before the command.
+func printHelpJSON(w io.Writer, global *flag.FlagSet) error {
+	doc := helpDoc{
+		Usage:       os.Args[0] + " [global options] <command> [options]",
+		GlobalFlags: helpFlags(global),
+	}
+	for _, cmd := range commands {
+		fs := newFlagSet(cmd.name)
+		cmd.setup(&cli{}, fs)
+		doc.Commands = append(doc.Commands, helpCommand{
+			Name:    cmd.name,
+			Aliases: cmd.aliases,
+			Summary: cmd.summary,
+			Flags:   helpFlags(fs),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
+
+// isJSONHelp reports whether args, the arguments after the program name, run `help --json`.
+// Nothing but the JSON may be printed to stdout then.
+func isJSONHelp(args []string) bool {
+	i := slices.Index(args, "help")
+	return i >= 0 && (slices.Contains(args[i+1:], "--json") || slices.Contains(args[i+1:], "-json"))
+}
+
+// helpFlags returns the flags defined on fs in name order, with the shorthands of shortFlags
+// folded into their long flag.
+func helpFlags(fs *flag.FlagSet) []helpFlag {
+	flags := []helpFlag{}
+	fs.VisitAll(func(f *flag.Flag) {
+		for long, short := range shortFlags {
+			if f.Name == short && fs.Lookup(long) != nil {
+				return
+			}
+		}
+		typ, usage := flag.UnquoteUsage(f)
+		if typ == "" {
+			typ = "bool"
+		}
+		def := f.DefValue
+		if typ == "bool" && def == "false" {
+			def = ""
+		}
+		flags = append(flags, helpFlag{Name: f.Name, Short: shortFlags[f.Name], Type: typ, Default: def, Usage: usage})
+	})
+	return flags
+}
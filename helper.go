@@ -1,8 +1,15 @@
 package gormeasy
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 
 	"gorm.io/gorm"
@@ -10,39 +17,169 @@ import (
 
 // DropTable drops one or more tables from the database.
 // It validates that all table names are strings and that the tables exist before attempting to drop them.
+// On ClickHouse, tables are dropped with SYNC, so a rollback can recreate a replicated table
+// right away instead of failing on the replica path the dropped table still holds.
 // Returns an error if any table name is not a string or if any table does not exist.
 func DropTable(tx *gorm.DB, tableNames ...interface{}) error {
+	return DropTableWithOptions(tx, DropTableOptions{}, tableNames...)
+}
+
+// DropTableIfExists is like DropTable but skips tables that do not exist.
+func DropTableIfExists(tx *gorm.DB, tableNames ...interface{}) error {
+	return DropTableWithOptions(tx, DropTableOptions{IfExists: true}, tableNames...)
+}
+
+// DropTableOptions configures DropTableWithOptions.
+type DropTableOptions struct {
+	// IfExists skips tables that do not exist instead of returning an error.
+	IfExists bool
+	// GuardNonEmpty refuses to drop tables holding rows. Migrations run with
+	// Options.GuardNonEmptyDrop enable it for every drop.
+	GuardNonEmpty bool
+	// ForceDrop drops tables holding rows even when GuardNonEmpty or Options.GuardNonEmptyDrop is set.
+	ForceDrop bool
+}
+
+// guardNonEmptyDropKey is the context key marking the sessions of migrations run with
+// Options.GuardNonEmptyDrop.
+type guardNonEmptyDropKey struct{}
+
+// withGuardNonEmptyDrop returns a session of db whose DropTable calls refuse to drop tables
+// holding rows when guard is set.
+func withGuardNonEmptyDrop(db *gorm.DB, guard bool) *gorm.DB {
+	if !guard {
+		return db
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, guardNonEmptyDropKey{}, true))
+}
+
+// guardsNonEmptyDrop reports whether tx is a session of withGuardNonEmptyDrop.
+func guardsNonEmptyDrop(tx *gorm.DB) bool {
+	ctx := tx.Statement.Context
+	return ctx != nil && ctx.Value(guardNonEmptyDropKey{}) != nil
+}
+
+// DropTableWithOptions drops one or more tables like DropTable. With opts.GuardNonEmpty, or in
+// a migration run with Options.GuardNonEmptyDrop, it refuses to drop tables holding rows unless
+// opts.ForceDrop is set, so a Rollback registered for the wrong migration cannot drop a table
+// full of data.
+func DropTableWithOptions(tx *gorm.DB, opts DropTableOptions, tableNames ...interface{}) error {
+	var tables []interface{}
 	for _, tableName := range tableNames {
 		if reflect.TypeOf(tableName).Kind() != reflect.String {
 			return fmt.Errorf("table name must be a string")
 		}
 		if hasTable := tx.Migrator().HasTable(tableName); !hasTable {
+			if opts.IfExists {
+				continue
+			}
 			return fmt.Errorf("table %s does not exist", tableName)
 		}
+		if (opts.GuardNonEmpty || guardsNonEmptyDrop(tx)) && !opts.ForceDrop {
+			var rows []int
+			if err := tx.Table(tableName.(string)).Select("1").Limit(1).Scan(&rows).Error; err != nil {
+				return fmt.Errorf("failed to check rows of table %s: %w", tableName, err)
+			}
+			if len(rows) > 0 {
+				return fmt.Errorf("table %s is not empty, refusing to drop it (set DropTableOptions.ForceDrop to drop it anyway)", tableName)
+			}
+		}
+		tables = append(tables, tableName)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	if tx.Dialector.Name() == "clickhouse" {
+		for _, tableName := range tables {
+			dropSQL := fmt.Sprintf("DROP TABLE %s SYNC", quoteClickHouseName(tableName.(string)))
+			if err := tx.Exec(dropSQL).Error; err != nil {
+				return fmt.Errorf("failed to drop table %s: %w", tableName, err)
+			}
+		}
+		return nil
+	}
+	return tx.Migrator().DropTable(tables...)
+}
+
+// defaultProtectedDatabases are system databases that DeleteDatabase never deletes.
+var defaultProtectedDatabases = []string{"postgres", "template0", "template1", "mysql", "information_schema", "performance_schema", "sys"}
+
+// isProtectedDatabase reports whether dbName is a system database or listed in protected,
+// e.g. Options.ProtectedDatabases.
+func isProtectedDatabase(dbName string, protected []string) bool {
+	for _, name := range slices.Concat(defaultProtectedDatabases, protected) {
+		if strings.EqualFold(name, dbName) {
+			return true
+		}
 	}
-	return tx.Migrator().DropTable(tableNames...)
+	return false
+}
+
+// CreateDatabaseOptions sets the properties of a database created by CreateDatabaseWithOptions.
+// Empty fields use the server defaults.
+type CreateDatabaseOptions struct {
+	// Owner is the role owning the database (PostgreSQL).
+	Owner string
+	// Encoding is the character set encoding, e.g. UTF8 (PostgreSQL).
+	Encoding string
+	// Template is the database the new database is copied from, e.g. template0 (PostgreSQL).
+	Template string
+	// Locale sets both LC_COLLATE and LC_CTYPE, e.g. en_US.UTF-8 (PostgreSQL 13 and later).
+	Locale string
+	// Charset is the default character set, e.g. utf8mb4 (MySQL).
+	Charset string
+	// Collation is the default collation, e.g. utf8mb4_unicode_ci (MySQL).
+	Collation string
+}
+
+// postgres reports whether o only sets PostgreSQL options.
+func (o CreateDatabaseOptions) postgres() bool {
+	return o.Charset == "" && o.Collation == ""
+}
+
+// mysql reports whether o only sets MySQL options.
+func (o CreateDatabaseOptions) mysql() bool {
+	return o.Owner == "" && o.Encoding == "" && o.Template == "" && o.Locale == ""
 }
 
 // CreateDatabase creates a new database with the specified name.
-// It supports PostgreSQL and MySQL databases. SQLite is not supported as it uses file-based databases.
+// It supports PostgreSQL, MySQL, ClickHouse and SQLite databases. For SQLite it creates the database file,
+// see sqliteDatabasePath for how dbName is resolved to a file.
 // If the database already exists, it will print a warning and return nil without error.
 // Returns an error if the database type is not supported or if creation fails.
 func CreateDatabase(db *gorm.DB, dbName string) error {
+	return CreateDatabaseWithOptions(db, dbName, CreateDatabaseOptions{})
+}
+
+// CreateDatabaseWithOptions is like CreateDatabase but sets the owner, encoding, template and
+// locale (PostgreSQL) or the character set and collation (MySQL) of the new database, so it
+// matches organization standards without ALTERs after creating it. Options of another database
+// are an error, as are any options on ClickHouse and SQLite.
+func CreateDatabaseWithOptions(db *gorm.DB, dbName string, opts CreateDatabaseOptions) error {
 	dialectorName := db.Dialector.Name()
+	if opts != (CreateDatabaseOptions{}) && !(dialectorName == "postgres" && opts.postgres()) && !(dialectorName == "mysql" && opts.mysql()) {
+		return fmt.Errorf("database options are not supported for %s: owner, encoding, template and locale are PostgreSQL options, charset and collation are MySQL options", dialectorName)
+	}
 
 	switch dialectorName {
 	case "postgres":
-		return createPostgresDatabase(db, dbName)
+		return createPostgresDatabase(db, dbName, opts)
 	case "mysql":
-		return createMySQLDatabase(db, dbName)
+		return createMySQLDatabase(db, dbName, opts)
+	case "clickhouse":
+		return createClickHouseDatabase(db, dbName)
 	case "sqlite":
-		return fmt.Errorf("SQLite does not support CREATE DATABASE. SQLite uses file-based databases. Please create the database file manually or use a different database for this operation")
+		return createSQLiteDatabase(db, dbName)
 	default:
-		return fmt.Errorf("database creation is not supported for %s. Currently supported: PostgreSQL, MySQL", dialectorName)
+		return fmt.Errorf("database creation is not supported for %s. Currently supported: PostgreSQL, MySQL, ClickHouse, SQLite", dialectorName)
 	}
 }
 
-func createPostgresDatabase(db *gorm.DB, dbName string) error {
+func createPostgresDatabase(db *gorm.DB, dbName string, opts CreateDatabaseOptions) error {
 	var exists bool
 	// Escape single quotes in database name to prevent SQL injection
 	escapedName := strings.ReplaceAll(dbName, "'", "''")
@@ -52,19 +189,17 @@ func createPostgresDatabase(db *gorm.DB, dbName string) error {
 	}
 
 	if !exists {
-		escapedNameQuoted := fmt.Sprintf(`"%s"`, strings.ReplaceAll(dbName, `"`, `""`))
-		createSQL := fmt.Sprintf("CREATE DATABASE %s", escapedNameQuoted)
-		if err := db.Exec(createSQL).Error; err != nil {
+		if err := db.Exec(postgresCreateDatabaseSQL(dbName, opts)).Error; err != nil {
 			return fmt.Errorf("failed to create database: %w", err)
 		}
-		fmt.Printf("✅ Created database: %s\n", dbName)
+		out.Printf("✅ Created database: %s\n", dbName)
 	} else {
-		fmt.Printf("⚠️  Database already exists: %s\n", dbName)
+		out.Printf("⚠️  Database already exists: %s\n", dbName)
 	}
 	return nil
 }
 
-func createMySQLDatabase(db *gorm.DB, dbName string) error {
+func createMySQLDatabase(db *gorm.DB, dbName string, opts CreateDatabaseOptions) error {
 	var count int64
 	// Escape backticks in database name
 	escapedName := strings.ReplaceAll(dbName, "`", "``")
@@ -74,24 +209,75 @@ func createMySQLDatabase(db *gorm.DB, dbName string) error {
 	}
 
 	if count == 0 {
-		escapedNameQuoted := fmt.Sprintf("`%s`", strings.ReplaceAll(dbName, "`", "``"))
-		createSQL := fmt.Sprintf("CREATE DATABASE %s", escapedNameQuoted)
-		if err := db.Exec(createSQL).Error; err != nil {
+		if err := db.Exec(mysqlCreateDatabaseSQL(dbName, opts)).Error; err != nil {
 			return fmt.Errorf("failed to create database: %w", err)
 		}
-		fmt.Printf("✅ Created database: %s\n", dbName)
+		out.Printf("✅ Created database: %s\n", dbName)
 	} else {
-		fmt.Printf("⚠️  Database already exists: %s\n", dbName)
+		out.Printf("⚠️  Database already exists: %s\n", dbName)
 	}
 	return nil
 }
 
+// postgresCreateDatabaseSQL returns the CREATE DATABASE statement of dbName with opts.
+func postgresCreateDatabaseSQL(dbName string, opts CreateDatabaseOptions) string {
+	quoteIdent := func(name string) string { return `"` + strings.ReplaceAll(name, `"`, `""`) + `"` }
+	quoteString := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+
+	createSQL := "CREATE DATABASE " + quoteIdent(dbName)
+	if opts.Owner != "" {
+		createSQL += " OWNER " + quoteIdent(opts.Owner)
+	}
+	if opts.Template != "" {
+		createSQL += " TEMPLATE " + quoteIdent(opts.Template)
+	}
+	if opts.Encoding != "" {
+		createSQL += " ENCODING " + quoteString(opts.Encoding)
+	}
+	if opts.Locale != "" {
+		createSQL += " LOCALE " + quoteString(opts.Locale)
+	}
+	return createSQL
+}
+
+// mysqlCreateDatabaseSQL returns the CREATE DATABASE statement of dbName with opts.
+func mysqlCreateDatabaseSQL(dbName string, opts CreateDatabaseOptions) string {
+	quoteString := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+
+	createSQL := fmt.Sprintf("CREATE DATABASE `%s`", strings.ReplaceAll(dbName, "`", "``"))
+	if opts.Charset != "" {
+		createSQL += " CHARACTER SET " + quoteString(opts.Charset)
+	}
+	if opts.Collation != "" {
+		createSQL += " COLLATE " + quoteString(opts.Collation)
+	}
+	return createSQL
+}
+
 // DeleteDatabase deletes a database with the specified name.
-// It supports PostgreSQL and MySQL databases. SQLite is not supported as it uses file-based databases.
+// It supports PostgreSQL, MySQL, ClickHouse and SQLite databases.
 // For PostgreSQL, it terminates all active connections before dropping the database.
+// For SQLite, it removes the database file with its journal files. It refuses to remove the file
+// db itself is connected to, and files that are not SQLite databases.
+// System databases are never deleted, use DeleteDatabaseWithOptions to protect others.
 // If the database does not exist, it will print a warning and return nil without error.
 // Returns an error if the database type is not supported or if deletion fails.
 func DeleteDatabase(db *gorm.DB, dbName string) error {
+	return deleteDatabase(db, dbName, nil, false)
+}
+
+// DeleteDatabaseWithOptions is like DeleteDatabase but also refuses to delete the databases
+// of opts.ProtectedDatabases.
+func DeleteDatabaseWithOptions(db *gorm.DB, dbName string, opts Options) error {
+	return deleteDatabase(db, dbName, opts.ProtectedDatabases, false)
+}
+
+// deleteDatabase is DeleteDatabase, refusing to delete the databases in protected as well,
+// with force removing SQLite files that are not SQLite databases.
+func deleteDatabase(db *gorm.DB, dbName string, protected []string, force bool) error {
+	if isProtectedDatabase(dbName, protected) {
+		return fmt.Errorf("database %s is protected and cannot be deleted", dbName)
+	}
 	dialectorName := db.Dialector.Name()
 
 	switch dialectorName {
@@ -99,10 +285,12 @@ func DeleteDatabase(db *gorm.DB, dbName string) error {
 		return deletePostgresDatabase(db, dbName)
 	case "mysql":
 		return deleteMySQLDatabase(db, dbName)
+	case "clickhouse":
+		return deleteClickHouseDatabase(db, dbName)
 	case "sqlite":
-		return fmt.Errorf("SQLite does not support DROP DATABASE. SQLite uses file-based databases. Please delete the database file manually or use a different database for this operation")
+		return deleteSQLiteDatabase(db, dbName, force)
 	default:
-		return fmt.Errorf("database deletion is not supported for %s. Currently supported: PostgreSQL, MySQL", dialectorName)
+		return fmt.Errorf("database deletion is not supported for %s. Currently supported: PostgreSQL, MySQL, ClickHouse, SQLite", dialectorName)
 	}
 }
 
@@ -116,25 +304,135 @@ func deletePostgresDatabase(db *gorm.DB, dbName string) error {
 	}
 
 	if exists {
-		// Terminate all connections before dropping
 		escapedNameQuoted := fmt.Sprintf(`"%s"`, strings.ReplaceAll(dbName, `"`, `""`))
 		// First, terminate all connections to the database
-		terminateSQL := fmt.Sprintf(`
-			SELECT pg_terminate_backend(pg_stat_activity.pid)
-			FROM pg_stat_activity
-			WHERE pg_stat_activity.datname = '%s'
-			AND pid <> pg_backend_pid();
-		`, escapedName)
-		_ = db.Exec(terminateSQL) // Ignore errors for termination
+		terminatePostgresConnections(db, dbName)
 
 		dropSQL := fmt.Sprintf("DROP DATABASE %s", escapedNameQuoted)
 		if err := db.Exec(dropSQL).Error; err != nil {
 			return fmt.Errorf("failed to delete database: %w", err)
 		}
-		fmt.Printf("🗑️  Deleted database: %s\n", dbName)
+		out.Printf("🗑️  Deleted database: %s\n", dbName)
 	} else {
-		fmt.Printf("⚠️  Database does not exist: %s\n", dbName)
+		out.Printf("⚠️  Database does not exist: %s\n", dbName)
+	}
+	return nil
+}
+
+// terminatePostgresConnections terminates all other connections to the database dbName,
+// which DROP DATABASE and CREATE DATABASE ... TEMPLATE refuse to run with.
+func terminatePostgresConnections(db *gorm.DB, dbName string) {
+	// Escape single quotes in database name to prevent SQL injection
+	escapedName := strings.ReplaceAll(dbName, "'", "''")
+	terminateSQL := fmt.Sprintf(`
+		SELECT pg_terminate_backend(pg_stat_activity.pid)
+		FROM pg_stat_activity
+		WHERE pg_stat_activity.datname = '%s'
+		AND pid <> pg_backend_pid();
+	`, escapedName)
+	_ = db.Exec(terminateSQL) // Ignore errors for termination
+}
+
+// CloneDatabase creates the database target as a copy of the database source with
+// CREATE DATABASE ... TEMPLATE, e.g. to snapshot a migrated schema for fast test environments.
+// PostgreSQL copies the files of source, so cloning is much faster than migrating and seeding.
+// Other connections to source are terminated first, since PostgreSQL refuses to copy a
+// database in use; for that reason system databases are never cloned. It returns an
+// error if target already exists. Only PostgreSQL is supported.
+func CloneDatabase(db *gorm.DB, source, target string) error {
+	return cloneDatabase(db, source, target, nil)
+}
+
+// cloneDatabase is CloneDatabase, refusing to clone the databases in protected as well.
+func cloneDatabase(db *gorm.DB, source, target string, protected []string) error {
+	if dialectorName := db.Dialector.Name(); dialectorName != "postgres" {
+		return fmt.Errorf("database cloning is not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+	if isProtectedDatabase(source, protected) {
+		return fmt.Errorf("database %s is protected, refusing to terminate its connections to clone it", source)
+	}
+
+	var exists bool
+	checkSQL := "SELECT EXISTS(SELECT FROM pg_database WHERE datname = ?)"
+	if err := db.Raw(checkSQL, source).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("database %s does not exist", source)
+	}
+	if err := db.Raw(checkSQL, target).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("database %s already exists", target)
+	}
+
+	terminatePostgresConnections(db, source)
+	if err := db.Exec(postgresCreateDatabaseSQL(target, CreateDatabaseOptions{Template: source})).Error; err != nil {
+		return fmt.Errorf("failed to clone database: %w", err)
+	}
+	out.Printf("✅ Cloned database %s to %s\n", source, target)
+	return nil
+}
+
+// RenameDatabase renames the database dbName to newName, e.g. to swap a blue/green database
+// into place. On PostgreSQL, other connections to dbName are terminated first, since ALTER
+// DATABASE ... RENAME TO refuses to rename a database in use; ClickHouse renames with RENAME
+// DATABASE. MySQL and SQLite cannot rename databases: it returns an error explaining how to
+// move the data instead. System databases are never renamed, and newName must not exist.
+func RenameDatabase(db *gorm.DB, dbName, newName string) error {
+	return renameDatabase(db, dbName, newName, nil)
+}
+
+// renameDatabase is RenameDatabase, refusing to rename the databases in protected as well.
+func renameDatabase(db *gorm.DB, dbName, newName string, protected []string) error {
+	if isProtectedDatabase(dbName, protected) {
+		return fmt.Errorf("database %s is protected and cannot be renamed", dbName)
+	}
+	dialectorName := db.Dialector.Name()
+
+	switch dialectorName {
+	case "postgres":
+		return renamePostgresDatabase(db, dbName, newName)
+	case "clickhouse":
+		renameSQL := fmt.Sprintf("RENAME DATABASE %s TO %s", quoteClickHouseName(dbName), quoteClickHouseName(newName))
+		if err := db.Exec(renameSQL).Error; err != nil {
+			return fmt.Errorf("failed to rename database: %w", err)
+		}
+		out.Printf("✅ Renamed database %s to %s\n", dbName, newName)
+		return nil
+	case "mysql":
+		return fmt.Errorf("MySQL cannot rename databases. Create %s, move every table with RENAME TABLE %s.<table> TO %s.<table>, then delete %s, or dump %s with mysqldump and restore it into %s", newName, dbName, newName, dbName, dbName, newName)
+	case "sqlite":
+		return fmt.Errorf("SQLite databases are files. Close all connections and rename the database file of %s instead", dbName)
+	default:
+		return fmt.Errorf("database renaming is not supported for %s. Currently supported: PostgreSQL, ClickHouse. Dump %s and restore it into %s instead", dialectorName, dbName, newName)
+	}
+}
+
+func renamePostgresDatabase(db *gorm.DB, dbName, newName string) error {
+	var exists bool
+	checkSQL := "SELECT EXISTS(SELECT FROM pg_database WHERE datname = ?)"
+	if err := db.Raw(checkSQL, dbName).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("database %s does not exist", dbName)
+	}
+	if err := db.Raw(checkSQL, newName).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
 	}
+	if exists {
+		return fmt.Errorf("database %s already exists", newName)
+	}
+
+	terminatePostgresConnections(db, dbName)
+	quote := func(name string) string { return `"` + strings.ReplaceAll(name, `"`, `""`) + `"` }
+	renameSQL := fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", quote(dbName), quote(newName))
+	if err := db.Exec(renameSQL).Error; err != nil {
+		return fmt.Errorf("failed to rename database: %w", err)
+	}
+	out.Printf("✅ Renamed database %s to %s\n", dbName, newName)
 	return nil
 }
 
@@ -153,9 +451,182 @@ func deleteMySQLDatabase(db *gorm.DB, dbName string) error {
 		if err := db.Exec(dropSQL).Error; err != nil {
 			return fmt.Errorf("failed to delete database: %w", err)
 		}
-		fmt.Printf("🗑️  Deleted database: %s\n", dbName)
+		out.Printf("🗑️  Deleted database: %s\n", dbName)
+	} else {
+		out.Printf("⚠️  Database does not exist: %s\n", dbName)
+	}
+	return nil
+}
+
+func createClickHouseDatabase(db *gorm.DB, dbName string) error {
+	var count int64
+	checkSQL := fmt.Sprintf("SELECT count() FROM system.databases WHERE name = '%s'", escapeClickHouseString(dbName))
+	if err := db.Raw(checkSQL).Scan(&count).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+
+	if count == 0 {
+		createSQL := fmt.Sprintf("CREATE DATABASE %s", quoteClickHouseName(dbName))
+		if err := db.Exec(createSQL).Error; err != nil {
+			return fmt.Errorf("failed to create database: %w", err)
+		}
+		out.Printf("✅ Created database: %s\n", dbName)
+	} else {
+		out.Printf("⚠️  Database already exists: %s\n", dbName)
+	}
+	return nil
+}
+
+func deleteClickHouseDatabase(db *gorm.DB, dbName string) error {
+	var count int64
+	checkSQL := fmt.Sprintf("SELECT count() FROM system.databases WHERE name = '%s'", escapeClickHouseString(dbName))
+	if err := db.Raw(checkSQL).Scan(&count).Error; err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+
+	if count > 0 {
+		// SYNC waits until the tables are gone, so the database can be recreated right away
+		dropSQL := fmt.Sprintf("DROP DATABASE %s SYNC", quoteClickHouseName(dbName))
+		if err := db.Exec(dropSQL).Error; err != nil {
+			return fmt.Errorf("failed to delete database: %w", err)
+		}
+		out.Printf("🗑️  Deleted database: %s\n", dbName)
 	} else {
-		fmt.Printf("⚠️  Database does not exist: %s\n", dbName)
+		out.Printf("⚠️  Database does not exist: %s\n", dbName)
+	}
+	return nil
+}
+
+// quoteClickHouseName quotes a database or table name for ClickHouse with backticks,
+// quoting the database and table of a qualified name (analytics.events) separately.
+func quoteClickHouseName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = "`" + strings.ReplaceAll(part, "`", "``") + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+// escapeClickHouseString escapes s for a ClickHouse string literal, where backslashes are escapes.
+func escapeClickHouseString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s)
+}
+
+// sqliteHeader starts every SQLite database file.
+const sqliteHeader = "SQLite format 3\x00"
+
+func createSQLiteDatabase(db *gorm.DB, dbName string) error {
+	mainFile, err := sqliteMainFile(db)
+	if err != nil {
+		return err
+	}
+	return createSQLiteFile(sqliteDatabasePath(mainFile, dbName), dbName)
+}
+
+func deleteSQLiteDatabase(db *gorm.DB, dbName string, force bool) error {
+	mainFile, err := sqliteMainFile(db)
+	if err != nil {
+		return err
+	}
+	return deleteSQLiteFile(sqliteDatabasePath(mainFile, dbName), mainFile, dbName, force)
+}
+
+// sqliteMainFile returns the file of the database db is connected to, or "" for an in-memory database.
+func sqliteMainFile(db *gorm.DB) (string, error) {
+	var databases []struct {
+		Seq  int
+		Name string
+		File string
+	}
+	if err := db.Raw("PRAGMA database_list").Scan(&databases).Error; err != nil {
+		return "", fmt.Errorf("failed to read database list: %w", err)
+	}
+	for _, d := range databases {
+		if d.Name == "main" {
+			return d.File, nil
+		}
+	}
+	return "", nil
+}
+
+// sqliteDatabasePath returns the file of the SQLite database dbName. A bare name such as
+// app_regression is placed next to mainFile, the file of the owner connection, with its
+// extension (.db for an in-memory owner), so it matches the name databaseNameFromURL
+// extracts from the DSN. A path (e.g. ./data/app.sqlite) is used as is.
+func sqliteDatabasePath(mainFile, dbName string) string {
+	if strings.ContainsAny(dbName, `/\`) || filepath.Ext(dbName) != "" {
+		return dbName
+	}
+	if mainFile == "" {
+		return dbName + ".db"
 	}
+	ext := filepath.Ext(mainFile)
+	if ext == "" {
+		ext = ".db"
+	}
+	return filepath.Join(filepath.Dir(mainFile), dbName+ext)
+}
+
+// createSQLiteFile creates the empty database file path, which SQLite initializes on first use.
+func createSQLiteFile(path, dbName string) error {
+	if _, err := os.Stat(path); err == nil {
+		out.Printf("⚠️  Database already exists: %s\n", dbName)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create database directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+	out.Printf("✅ Created database: %s (%s)\n", dbName, path)
+	return nil
+}
+
+// deleteSQLiteFile removes the database file path and its journal files. It refuses to remove
+// mainFile, the database of the owner connection, and, unless force is set, files that are
+// neither empty nor start with the SQLite header.
+func deleteSQLiteFile(path, mainFile, dbName string, force bool) error {
+	if mainFile != "" && sameFile(path, mainFile) {
+		return fmt.Errorf("database %s is the database of owner-db-url, refusing to delete it", dbName)
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		out.Printf("⚠️  Database does not exist: %s\n", dbName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open database file: %w", err)
+	}
+	header := make([]byte, len(sqliteHeader))
+	n, _ := io.ReadFull(f, header)
+	f.Close()
+	if n > 0 && string(header[:n]) != sqliteHeader && !force {
+		return fmt.Errorf("%s is not a SQLite database, refusing to delete it (use --force to delete it anyway)", path)
+	}
+
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete database: %w", err)
+		}
+	}
+	out.Printf("🗑️  Deleted database: %s (%s)\n", dbName, path)
 	return nil
 }
+
+// sameFile reports whether the paths a and b name the same existing file.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
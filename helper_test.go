@@ -0,0 +1,217 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+)
+
+// openSQLite opens an empty SQLite database in a temporary directory of t.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return db
+}
+
+// TestIsProtectedDatabase tests the default and configured database denylist
+func TestIsProtectedDatabase(t *testing.T) {
+	protected := []string{"app_production"}
+	for _, name := range []string{"postgres", "template0", "TEMPLATE1", "app_production"} {
+		if !isProtectedDatabase(name, protected) {
+			t.Errorf("Expected %s to be protected", name)
+		}
+	}
+	if isProtectedDatabase("app_regression", protected) {
+		t.Error("Expected app_regression not to be protected")
+	}
+	if isProtectedDatabase("app_production", nil) {
+		t.Error("Expected app_production not to be protected without the list")
+	}
+}
+
+// TestDeleteDatabaseWithOptions tests that the library refuses to delete the databases of
+// Options.ProtectedDatabases
+func TestDeleteDatabaseWithOptions(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	err := DeleteDatabaseWithOptions(db, "app_production", Options{ProtectedDatabases: []string{"app_production"}})
+	if err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("Expected a protected database error, got %v", err)
+	}
+}
+
+// TestSQLiteDatabasePath tests resolving SQLite database names to files next to the owner database
+func TestSQLiteDatabasePath(t *testing.T) {
+	cases := []struct {
+		mainFile, dbName, expected string
+	}{
+		{"/data/owner.sqlite", "app_regression", "/data/app_regression.sqlite"},
+		{"/data/owner", "app_regression", "/data/app_regression.db"},
+		{"", "app_regression", "app_regression.db"},
+		{"/data/owner.db", "./tmp/app.db", "./tmp/app.db"},
+		{"/data/owner.db", "app.sqlite3", "app.sqlite3"},
+	}
+	for _, c := range cases {
+		if got := sqliteDatabasePath(c.mainFile, c.dbName); got != c.expected {
+			t.Errorf("Expected %s for %s next to %q, got %s", c.expected, c.dbName, c.mainFile, got)
+		}
+	}
+}
+
+// TestSQLiteFiles tests creating and deleting SQLite database files and the safety checks
+func TestSQLiteFiles(t *testing.T) {
+	dir := t.TempDir()
+	owner := filepath.Join(dir, "owner.db")
+	if err := os.WriteFile(owner, []byte(sqliteHeader), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "nested", "app.db")
+
+	if err := createSQLiteFile(path, "app"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the database file to be created, got %v", err)
+	}
+	if err := createSQLiteFile(path, "app"); err != nil {
+		t.Errorf("Expected an existing database to be kept without error, got %v", err)
+	}
+
+	if err := deleteSQLiteFile(owner, owner, "owner", true); err == nil {
+		t.Error("Expected an error deleting the database of the owner connection")
+	}
+
+	notSQLite := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notSQLite, []byte("important notes"), 0o644)
+	if err := deleteSQLiteFile(notSQLite, owner, "notes", false); err == nil {
+		t.Error("Expected an error deleting a file that is not a SQLite database")
+	}
+	if err := deleteSQLiteFile(notSQLite, owner, "notes", true); err != nil {
+		t.Errorf("Expected --force to delete the file, got %v", err)
+	}
+
+	os.WriteFile(path+"-wal", nil, 0o644)
+	if err := deleteSQLiteFile(path, owner, "app", false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, p := range []string{path, path + "-wal", notSQLite} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted, got %v", p, err)
+		}
+	}
+	if err := deleteSQLiteFile(path, owner, "app", false); err != nil {
+		t.Errorf("Expected a missing database to be skipped without error, got %v", err)
+	}
+}
+
+// TestClickHouseQuoting tests quoting ClickHouse names and string literals
+func TestClickHouseQuoting(t *testing.T) {
+	if got := quoteClickHouseName("analytics.events"); got != "`analytics`.`events`" {
+		t.Errorf("Expected `analytics`.`events`, got %s", got)
+	}
+	if got := quoteClickHouseName("we`ird"); got != "`we``ird`" {
+		t.Errorf("Expected `we``ird`, got %s", got)
+	}
+	if got := escapeClickHouseString(`it's\`); got != `it\'s\\` {
+		t.Errorf(`Expected it\'s\\, got %s`, got)
+	}
+}
+
+// TestCreateDatabaseSQL tests the CREATE DATABASE statements with database options
+func TestCreateDatabaseSQL(t *testing.T) {
+	opts := CreateDatabaseOptions{Owner: "app", Encoding: "UTF8", Template: "template0", Locale: "en_US.UTF-8"}
+	expected := `CREATE DATABASE "my""db" OWNER "app" TEMPLATE "template0" ENCODING 'UTF8' LOCALE 'en_US.UTF-8'`
+	if got := postgresCreateDatabaseSQL(`my"db`, opts); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := postgresCreateDatabaseSQL("app", CreateDatabaseOptions{}); got != `CREATE DATABASE "app"` {
+		t.Errorf(`Expected CREATE DATABASE "app", got %s`, got)
+	}
+
+	expected = "CREATE DATABASE `app` CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_unicode_ci'"
+	if got := mysqlCreateDatabaseSQL("app", CreateDatabaseOptions{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// TestCreateDatabaseWithOptionsUnsupported tests that options of another database are rejected
+func TestCreateDatabaseWithOptionsUnsupported(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := CreateDatabaseWithOptions(db, "app", CreateDatabaseOptions{Charset: "utf8mb4"}); err == nil || !strings.Contains(err.Error(), "database options are not supported") {
+		t.Errorf("Expected an unsupported options error, got %v", err)
+	}
+	if !(CreateDatabaseOptions{Owner: "app"}).postgres() || (CreateDatabaseOptions{Owner: "app"}).mysql() {
+		t.Error("Expected Owner to be a PostgreSQL-only option")
+	}
+}
+
+// TestCloneDatabase tests that cloning is refused on other databases and the statement copying the template
+func TestCloneDatabase(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := CloneDatabase(db, "app", "app_copy"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected an unsupported dialect error, got %v", err)
+	}
+	if got := postgresCreateDatabaseSQL("app_copy", CreateDatabaseOptions{Template: "app"}); got != `CREATE DATABASE "app_copy" TEMPLATE "app"` {
+		t.Errorf(`Expected CREATE DATABASE "app_copy" TEMPLATE "app", got %s`, got)
+	}
+}
+
+// TestRenameDatabaseRefused tests that protected databases and unsupported dialects are not renamed
+func TestRenameDatabaseRefused(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := RenameDatabase(db, "postgres", "postgres_old"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("Expected a protected database error, got %v", err)
+	}
+	if err := RenameDatabase(db, "app", "app_old"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected an unsupported dialect error, got %v", err)
+	}
+}
+
+// TestDropTableRequiresStringNames tests that non-string table names are rejected before touching the database
+func TestDropTableRequiresStringNames(t *testing.T) {
+	if err := DropTableIfExists(nil, 42); err == nil {
+		t.Error("Expected error for non-string table name, got nil")
+	}
+}
+
+// TestDropTableGuardNonEmpty tests that the guard refuses to drop tables holding rows, given
+// as an option or by Options.GuardNonEmptyDrop of the migrations, unless ForceDrop is set
+func TestDropTableGuardNonEmpty(t *testing.T) {
+	db := openSQLite(t)
+	for _, sql := range []string{"CREATE TABLE events (id integer)", "INSERT INTO events VALUES (1)", "CREATE TABLE empty_events (id integer)"} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := DropTableWithOptions(db, DropTableOptions{GuardNonEmpty: true}, "events")
+	if err == nil || !strings.Contains(err.Error(), "table events is not empty") {
+		t.Errorf("Expected a non-empty table error, got %v", err)
+	}
+	if err := DropTableWithOptions(db, DropTableOptions{GuardNonEmpty: true}, "empty_events"); err != nil {
+		t.Errorf("Expected an empty table to be dropped, got %v", err)
+	}
+
+	migrations := []*Migration{{
+		ID:       "20240101000000-create-events",
+		Migrate:  func(tx *gorm.DB) error { return nil },
+		Rollback: func(tx *gorm.DB) error { return DropTable(tx, "events") },
+	}}
+	opts := Options{GuardNonEmptyDrop: true}.withDefaults()
+	if err := getMigrator(db, migrations, opts).Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := getMigrator(db, migrations, opts).RollbackLast(); err == nil || !strings.Contains(err.Error(), "table events is not empty") {
+		t.Errorf("Expected the rollback to be refused, got %v", err)
+	}
+	if err := DropTableWithOptions(withGuardNonEmptyDrop(db, true), DropTableOptions{ForceDrop: true}, "events"); err != nil {
+		t.Errorf("Expected ForceDrop to drop the table, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MarkApplied records migrations as applied in the history table without running their Migrate function.
+// It is meant for adopting gormeasy on databases where some changes were applied manually.
+// IDs that are already recorded are skipped with a warning.
+func MarkApplied(db *gorm.DB, opts Options, ids ...string) error {
+	opts = opts.withDefaults()
+	defer invalidateStatusCaches()
+	if err := ensureHistoryTable(db, opts); err != nil {
+		return fmt.Errorf("failed to migrate migrations table: %w", err)
+	}
+
+	applied := getAppliedIDs(db, opts)
+	for _, id := range ids {
+		if applied[id] {
+			out.Printf("⚠️  Migration already applied: %s\n", id)
+			continue
+		}
+		if err := db.Table(opts.TableName).Create(map[string]any{opts.IDColumnName: id}).Error; err != nil {
+			return fmt.Errorf("failed to mark migration %s as applied: %w", id, err)
+		}
+		out.Printf("✅ Marked as applied: %s\n", id)
+	}
+	return nil
+}
+
+// MarkReverted removes migrations from the history table without running their Rollback function.
+// IDs that are not recorded are skipped with a warning.
+func MarkReverted(db *gorm.DB, opts Options, ids ...string) error {
+	opts = opts.withDefaults()
+	defer invalidateStatusCaches()
+	if err := ensureHistoryTable(db, opts); err != nil {
+		return fmt.Errorf("failed to migrate migrations table: %w", err)
+	}
+
+	applied := getAppliedIDs(db, opts)
+	for _, id := range ids {
+		if !applied[id] {
+			out.Printf("⚠️  Migration is not applied: %s\n", id)
+			continue
+		}
+		cond := fmt.Sprintf("%s = ?", db.Statement.Quote(opts.IDColumnName))
+		if err := db.Table(opts.TableName).Where(cond, id).Delete(historyModel(opts)).Error; err != nil {
+			return fmt.Errorf("failed to mark migration %s as reverted: %w", id, err)
+		}
+		out.Printf("✅ Marked as reverted: %s\n", id)
+	}
+	return nil
+}
+
+// Baseline records every migration up to and including toID as applied without running them,
+// so that subsequent `up` runs only execute the newer migrations.
+// It is meant for brownfield databases whose schema already matches those migrations.
+func Baseline(db *gorm.DB, opts Options, migrations []*Migration, toID string) error {
+	var ids []string
+	found := false
+	for _, m := range migrations {
+		ids = append(ids, m.ID)
+		if m.ID == toID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown migration ID: %s", toID)
+	}
+
+	if err := MarkApplied(db, opts, ids...); err != nil {
+		return err
+	}
+	out.Printf("✅ Baseline complete, %d migrations recorded up to %s.\n", len(ids), toID)
+	return nil
+}
+
+// checkKnownMigrations returns an error if any of ids is not defined in migrations.
+func checkKnownMigrations(migrations []*Migration, ids []string) error {
+	known := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("unknown migration ID: %s", id)
+		}
+	}
+	return nil
+}
+
+// historyEntry is an applied migration as recorded in the history table.
+type historyEntry struct {
+	ID        string
+	AppliedAt *time.Time
+	// Tables are the tables the migration touched, nil if it was applied before
+	// gormeasy recorded them.
+	Tables []string
+	// Duration is how long the migration took to apply, nil if it was applied before
+	// gormeasy recorded it.
+	Duration *time.Duration
+}
+
+// touched reports whether the migration is recorded to have touched table.
+func (e historyEntry) touched(table string) bool {
+	return slices.ContainsFunc(e.Tables, func(t string) bool { return strings.EqualFold(t, table) })
+}
+
+// readHistory returns the applied migrations of the history table of opts, ordered by ID.
+// Columns of newer feature versions missing from the table are read as empty.
+func readHistory(db *gorm.DB, opts Options) ([]historyEntry, error) {
+	if !db.Migrator().HasTable(opts.TableName) {
+		return nil, nil
+	}
+	id := db.Statement.Quote(opts.IDColumnName)
+	columns := []string{id + " AS id", "applied_at", touchedTablesColumn, durationColumn}
+	migrator := db.Table(opts.TableName).Migrator()
+	if !migrator.HasColumn(historyModel(opts), "applied_at") {
+		columns[1] = "NULL AS applied_at"
+	}
+	if !migrator.HasColumn(historyModel(opts), touchedTablesColumn) {
+		columns[2] = "NULL AS " + touchedTablesColumn
+	}
+	if !migrator.HasColumn(historyModel(opts), durationColumn) {
+		columns[3] = "NULL AS " + durationColumn
+	}
+
+	var rows []struct {
+		ID            string
+		AppliedAt     *time.Time
+		TouchedTables *string
+		DurationMs    *int64
+	}
+	if err := db.Table(opts.TableName).Select(columns).Order(id).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read migration table: %w", err)
+	}
+	entries := make([]historyEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = historyEntry{ID: row.ID, AppliedAt: row.AppliedAt}
+		if row.TouchedTables != nil {
+			entries[i].Tables = []string{}
+			if *row.TouchedTables != "" {
+				entries[i].Tables = strings.Split(*row.TouchedTables, ",")
+			}
+		}
+		if row.DurationMs != nil {
+			d := time.Duration(*row.DurationMs) * time.Millisecond
+			entries[i].Duration = &d
+		}
+	}
+	return entries, nil
+}
+
+// printHistory prints the applied migrations with their time and touched tables.
+// With a table, only the migrations that touched it are listed.
+func printHistory(entries []historyEntry, table string) {
+	if table != "" {
+		out.Printf("\n=== Migrations touching %s ===\n", table)
+	} else {
+		out.Println("\n=== Migration History ===")
+	}
+	listed, unrecorded := 0, 0
+	for _, e := range entries {
+		if e.Tables == nil {
+			unrecorded++
+		}
+		if table != "" && !e.touched(table) {
+			continue
+		}
+		appliedAt := "-"
+		if e.AppliedAt != nil {
+			appliedAt = e.AppliedAt.Local().Format(time.DateTime)
+		}
+		tables := "(not recorded)"
+		if e.Tables != nil {
+			tables = strings.Join(e.Tables, ", ")
+		}
+		out.Printf("  - %s  %s  %s\n", e.ID, appliedAt, tables)
+		listed++
+	}
+	if listed == 0 {
+		out.Println("  (none)")
+	}
+	if table != "" && unrecorded > 0 {
+		out.Printf("⚠️  %d migrations were applied before gormeasy recorded touched tables and are not listed.\n", unrecorded)
+	}
+}
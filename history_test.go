@@ -0,0 +1,93 @@
+package gormeasy
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestMarkApplied tests that marking migrations applied twice records them once
+func TestMarkApplied(t *testing.T) {
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	for run := 1; run <= 2; run++ {
+		if err := MarkApplied(db, opts, "001", "002"); err != nil {
+			t.Fatalf("Expected run %d to succeed, got %v", run, err)
+		}
+	}
+	if ids := slices.Sorted(maps.Keys(getAppliedIDs(db, opts))); !slices.Equal(ids, []string{"001", "002"}) {
+		t.Errorf("Expected [001 002] applied, got %v", ids)
+	}
+	if !strings.Contains(buf.String(), "Migration already applied: 001") {
+		t.Errorf("Expected a warning for the migration applied twice, got %q", buf.String())
+	}
+}
+
+// TestMarkReverted tests that reverting twice or reverting an unknown ID only warns
+func TestMarkReverted(t *testing.T) {
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	if err := MarkApplied(db, opts, "001", "002"); err != nil {
+		t.Fatal(err)
+	}
+	for run := 1; run <= 2; run++ {
+		if err := MarkReverted(db, opts, "002", "999"); err != nil {
+			t.Fatalf("Expected run %d to succeed, got %v", run, err)
+		}
+	}
+	if ids := slices.Sorted(maps.Keys(getAppliedIDs(db, opts))); !slices.Equal(ids, []string{"001"}) {
+		t.Errorf("Expected [001] applied, got %v", ids)
+	}
+	if strings.Count(buf.String(), "Migration is not applied: 999") != 2 || strings.Count(buf.String(), "Migration is not applied: 002") != 1 {
+		t.Errorf("Expected warnings for the unknown and the reverted migration, got %q", buf.String())
+	}
+}
+
+// TestCheckKnownMigrations tests that IDs missing from the migrations are reported
+func TestCheckKnownMigrations(t *testing.T) {
+	migrations := []*Migration{{ID: "001"}, {ID: "002"}}
+	if err := checkKnownMigrations(migrations, []string{"002", "001"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err := checkKnownMigrations(migrations, []string{"001", "003"})
+	if err == nil || !strings.Contains(err.Error(), "unknown migration ID: 003") {
+		t.Errorf("Expected an unknown migration ID error, got %v", err)
+	}
+}
+
+// TestBaseline tests that the migrations up to the baseline are recorded, and that an unknown
+// baseline records nothing
+func TestBaseline(t *testing.T) {
+	saved := out
+	out = &output{level: levelQuiet, w: &strings.Builder{}, errW: &strings.Builder{}}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	migrations := []*Migration{{ID: "001"}, {ID: "002"}, {ID: "003"}}
+	err := Baseline(db, opts, migrations, "004")
+	if err == nil || !strings.Contains(err.Error(), "unknown migration ID: 004") {
+		t.Errorf("Expected an unknown migration ID error, got %v", err)
+	}
+	if db.Migrator().HasTable(opts.TableName) && len(getAppliedIDs(db, opts)) > 0 {
+		t.Error("Expected no migration recorded for an unknown baseline")
+	}
+
+	if err := Baseline(db, opts, migrations, "002"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ids := slices.Sorted(maps.Keys(getAppliedIDs(db, opts))); !slices.Equal(ids, []string{"001", "002"}) {
+		t.Errorf("Expected [001 002] applied, got %v", ids)
+	}
+}
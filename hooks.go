@@ -0,0 +1,67 @@
+package gormeasy
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationEvent describes a migration passed to the lifecycle hooks of Options.
+type MigrationEvent struct {
+	// ID is the migration ID.
+	ID string
+	// Rollback is set when the migration is rolled back rather than applied.
+	Rollback bool
+	// Duration is how long the migration ran. It is zero in BeforeMigration.
+	Duration time.Duration
+	// Err is the error the migration failed with. It is only set in OnError.
+	Err error
+}
+
+// hasHooks reports whether any lifecycle hook is set in o.
+func (o Options) hasHooks() bool {
+	return o.BeforeMigration != nil || o.AfterMigration != nil || o.OnError != nil
+}
+
+// withHooks returns copies of migrations whose Migrate and Rollback call the lifecycle
+// hooks of opts. Without hooks, migrations is returned as is.
+func withHooks(migrations []*Migration, opts Options) []*Migration {
+	if !opts.hasHooks() {
+		return migrations
+	}
+	hooked := make([]*Migration, len(migrations))
+	for i, m := range migrations {
+		m := *m
+		if m.Migrate != nil {
+			m.Migrate = opts.hook(m.ID, false, m.Migrate)
+		}
+		if m.Rollback != nil {
+			m.Rollback = opts.hook(m.ID, true, m.Rollback)
+		}
+		hooked[i] = &m
+	}
+	return hooked
+}
+
+// hook wraps fn, the Migrate or Rollback function of migration id, with the lifecycle hooks.
+func (o Options) hook(id string, rollback bool, fn func(*gorm.DB) error) func(*gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		event := MigrationEvent{ID: id, Rollback: rollback}
+		if o.BeforeMigration != nil {
+			o.BeforeMigration(event)
+		}
+		clock := clockOf(tx)
+		start := clock.Now()
+		err := fn(tx)
+		event.Duration = since(clock, start)
+		if err != nil {
+			event.Err = err
+			if o.OnError != nil {
+				o.OnError(event)
+			}
+		} else if o.AfterMigration != nil {
+			o.AfterMigration(event)
+		}
+		return err
+	}
+}
@@ -0,0 +1,48 @@
+package gormeasy
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestWithHooks tests that the lifecycle hooks receive the migration ID, direction and error
+func TestWithHooks(t *testing.T) {
+	var events []string
+	opts := Options{
+		BeforeMigration: func(e MigrationEvent) { events = append(events, "before "+e.ID) },
+		AfterMigration:  func(e MigrationEvent) { events = append(events, "after "+e.ID) },
+		OnError: func(e MigrationEvent) {
+			if e.Err == nil || !e.Rollback {
+				t.Errorf("Expected a failed rollback event, got %+v", e)
+			}
+			events = append(events, "error "+e.ID)
+		},
+	}
+	migrations := withHooks([]*Migration{{
+		ID:       "1",
+		Migrate:  func(tx *gorm.DB) error { return nil },
+		Rollback: func(tx *gorm.DB) error { return errors.New("boom") },
+	}}, opts)
+
+	migrations[0].Migrate(nil)
+	if err := migrations[0].Rollback(nil); err == nil {
+		t.Error("Expected the rollback error to be returned")
+	}
+	expected := []string{"before 1", "after 1", "before 1", "error 1"}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Expected events %v, got %v", expected, events)
+			break
+		}
+	}
+
+	plain := []*Migration{{ID: "1"}}
+	if hooked := withHooks(plain, Options{}); &hooked[0] != &plain[0] {
+		t.Error("Expected migrations to be returned as is without hooks")
+	}
+}
@@ -0,0 +1,233 @@
+package gormeasy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// historyTools maps the migration tools import-history reads to their default history table.
+var historyTools = map[string]string{
+	"golang-migrate": "schema_migrations",
+	"goose":          "goose_db_version",
+	"flyway":         "flyway_schema_history",
+}
+
+// ImportHistory records the migrations another tool applied to db as applied in the gormeasy
+// history, without running them, to adopt gormeasy on a database managed by golang-migrate,
+// goose or flyway. It reads the history table of tool, table or else the tool's default, and
+// maps each applied version to a migration ID of migrations with mapping. Applied versions
+// missing from mapping are an error. With dryRun, the IDs are only printed.
+func ImportHistory(db *gorm.DB, opts Options, migrations []*Migration, tool, table string, mapping map[string]string, dryRun bool) error {
+	if _, ok := historyTools[tool]; !ok {
+		return fmt.Errorf("unknown tool %q, use golang-migrate, goose or flyway", tool)
+	}
+	if table == "" {
+		table = historyTools[tool]
+	}
+	if !db.Migrator().HasTable(table) {
+		return fmt.Errorf("%s history table %s does not exist", tool, table)
+	}
+	versions, err := readToolVersions(db, tool, table, mapping)
+	if err != nil {
+		return err
+	}
+	ids, err := mapVersions(versions, mapping)
+	if err != nil {
+		return err
+	}
+	if err := checkKnownMigrations(migrations, ids); err != nil {
+		return err
+	}
+	// Record them in the order of migrations, like up would have
+	var ordered []string
+	for _, m := range migrations {
+		if slices.Contains(ids, m.ID) {
+			ordered = append(ordered, m.ID)
+		}
+	}
+	ids = ordered
+
+	if dryRun {
+		out.Printf("Would import %d migrations applied by %s:\n", len(ids), tool)
+		for _, id := range ids {
+			out.Println("  -", id)
+		}
+		return nil
+	}
+	if err := MarkApplied(db, opts, ids...); err != nil {
+		return err
+	}
+	out.Printf("✅ Imported %d migrations applied by %s.\n", len(ids), tool)
+	return nil
+}
+
+// readToolVersions returns the versions applied according to the history table of tool.
+// golang-migrate only records the current version, so every version of mapping up to it counts
+// as applied.
+func readToolVersions(db *gorm.DB, tool, table string, mapping map[string]string) ([]string, error) {
+	quoted := db.Statement.Quote(table)
+	switch tool {
+	case "golang-migrate":
+		var rows []struct {
+			Version int64
+			Dirty   bool
+		}
+		if err := db.Raw("SELECT version, dirty FROM " + quoted).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		if rows[0].Dirty {
+			return nil, fmt.Errorf("%s is dirty at version %d, fix the failed migration first", table, rows[0].Version)
+		}
+		return migrateVersions(rows[0].Version, mapping)
+	case "goose":
+		var rows []gooseRow
+		if err := db.Raw("SELECT version_id, is_applied FROM " + quoted + " ORDER BY id").Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		return gooseVersions(rows), nil
+	default:
+		var rows []flywayRow
+		if err := db.Raw("SELECT version, type, success FROM " + quoted + " ORDER BY installed_rank").Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		return flywayVersions(rows), nil
+	}
+}
+
+// migrateVersions returns the versions of mapping up to the current golang-migrate version,
+// and the current version.
+func migrateVersions(current int64, mapping map[string]string) ([]string, error) {
+	var versions []string
+	for version := range mapping {
+		n, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid golang-migrate version %q in the mapping", version)
+		}
+		if n <= current {
+			versions = append(versions, version)
+		}
+	}
+	// The current version must be mapped itself
+	if current > 0 {
+		versions = append(versions, strconv.FormatInt(current, 10))
+	}
+	return versions, nil
+}
+
+// gooseRow is a row of the goose_db_version table.
+type gooseRow struct {
+	VersionID int64
+	IsApplied bool
+}
+
+// gooseVersions returns the applied versions of the goose history rows, in which the last
+// row of a version tells whether it is applied. Version 0 is the initial row of goose.
+func gooseVersions(rows []gooseRow) []string {
+	var versions []string
+	for _, row := range rows {
+		version := strconv.FormatInt(row.VersionID, 10)
+		versions = slices.DeleteFunc(versions, func(v string) bool { return v == version })
+		if row.IsApplied && row.VersionID != 0 {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// flywayRow is a row of the flyway_schema_history table.
+type flywayRow struct {
+	// Version is NULL for repeatable migrations.
+	Version *string
+	Type    string
+	Success bool
+}
+
+// flywayVersions returns the versions applied successfully according to the flyway history
+// rows, without repeatable migrations and the versions undone since.
+func flywayVersions(rows []flywayRow) []string {
+	var versions []string
+	for _, row := range rows {
+		if row.Version == nil || !row.Success {
+			continue
+		}
+		version := *row.Version
+		versions = slices.DeleteFunc(versions, func(v string) bool { return v == version })
+		if !strings.HasPrefix(row.Type, "UNDO") {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// normalizeVersion returns version without leading zeros, with _ read as . like flyway does,
+// so 000001 matches 1 and 1_1 matches 1.1.
+func normalizeVersion(version string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(version), "_", "."), ".")
+	for i, part := range parts {
+		if parts[i] = strings.TrimLeft(part, "0"); parts[i] == "" {
+			parts[i] = "0"
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// mapVersions returns the migration IDs mapping maps versions to, or an error listing the
+// versions it has no ID for.
+func mapVersions(versions []string, mapping map[string]string) ([]string, error) {
+	normalized := make(map[string]string, len(mapping))
+	for version, id := range mapping {
+		normalized[normalizeVersion(version)] = id
+	}
+	var ids, unmapped []string
+	for _, version := range versions {
+		id, ok := normalized[normalizeVersion(version)]
+		if !ok {
+			unmapped = append(unmapped, version)
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(unmapped) > 0 {
+		return nil, fmt.Errorf("no migration ID mapped for applied versions: %s", strings.Join(unmapped, ", "))
+	}
+	return ids, nil
+}
+
+// readVersionMapping reads a mapping file of "<version> <migration ID>" lines, e.g.
+// "000003 20240301000000-add-orders". Blank lines and lines starting with # are skipped.
+func readVersionMapping(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	mapping := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<version> <migration ID>\"", path, line)
+		}
+		mapping[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,66 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestToolVersions tests the applied versions read from the history of other migration tools
+func TestToolVersions(t *testing.T) {
+	mapping := map[string]string{"000001": "20240101000000-users", "000002": "20240201000000-orders", "000003": "20240301000000-lines"}
+	versions, err := migrateVersions(2, mapping)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ids, err := mapVersions(versions, mapping)
+	slices.Sort(ids)
+	if err != nil || strings.Join(ids, ",") != "20240101000000-users,20240201000000-orders" {
+		t.Errorf("Expected the migrations up to version 2, got %v, %v", ids, err)
+	}
+	if _, err := mapVersions([]string{"4"}, mapping); err == nil || !strings.Contains(err.Error(), "applied versions: 4") {
+		t.Errorf("Expected an unmapped version error, got %v", err)
+	}
+
+	goose := gooseVersions([]gooseRow{{0, true}, {1, true}, {2, true}, {3, true}, {3, false}})
+	if strings.Join(goose, ",") != "1,2" {
+		t.Errorf("Expected goose versions 1,2 without the rolled back 3, got %v", goose)
+	}
+
+	version := func(v string) *string { return &v }
+	flyway := flywayVersions([]flywayRow{
+		{Version: version("1"), Type: "BASELINE", Success: true},
+		{Version: version("1.1"), Type: "SQL", Success: true},
+		{Version: nil, Type: "SQL", Success: true},
+		{Version: version("2"), Type: "SQL", Success: false},
+		{Version: version("3"), Type: "SQL", Success: true},
+		{Version: version("3"), Type: "UNDO_SQL", Success: true},
+	})
+	if strings.Join(flyway, ",") != "1,1.1" {
+		t.Errorf("Expected flyway versions 1,1.1, got %v", flyway)
+	}
+	ids, err = mapVersions(flyway, map[string]string{"1": "a", "1_1": "b"})
+	if err != nil || strings.Join(ids, ",") != "a,b" {
+		t.Errorf("Expected 1_1 to match 1.1, got %v, %v", ids, err)
+	}
+}
+
+// TestReadVersionMapping tests reading a version to migration ID mapping file
+func TestReadVersionMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history-map.txt")
+	if err := os.WriteFile(path, []byte("# goose version to gormeasy ID\n1 20240101000000-users\n\n2 20240201000000-orders\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mapping, err := readVersionMapping(path)
+	if err != nil || len(mapping) != 2 || mapping["2"] != "20240201000000-orders" {
+		t.Errorf("Expected two mapped versions, got %v, %v", mapping, err)
+	}
+	if err := os.WriteFile(path, []byte("1\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := readVersionMapping(path); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("Expected a line error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CreateIndexConcurrently creates an index without blocking writes to the table.
+// On PostgreSQL it runs CREATE INDEX CONCURRENTLY on a connection outside the migration
+// transaction, verifies that the finished index is valid, and drops an invalid index left
+// behind by a failed earlier attempt before retrying. On other databases it falls back to
+// a regular CREATE INDEX. Columns are used as-is, so expressions like "lower(email)" work.
+// On PostgreSQL it returns an error inside a transaction, which may hold a lock on the table
+// the index build waits for: keep Options.UseTransaction disabled for such migrations.
+func CreateIndexConcurrently(tx *gorm.DB, name, table string, columns ...string) error {
+	return createIndexConcurrently(tx, false, name, table, columns)
+}
+
+// CreateUniqueIndexConcurrently is like CreateIndexConcurrently but creates a unique index.
+func CreateUniqueIndexConcurrently(tx *gorm.DB, name, table string, columns ...string) error {
+	return createIndexConcurrently(tx, true, name, table, columns)
+}
+
+// DropIndexConcurrently drops an index without blocking writes to its table, for use in Rollback.
+// On PostgreSQL it runs DROP INDEX CONCURRENTLY outside the migration transaction, and like
+// CreateIndexConcurrently returns an error inside a transaction.
+func DropIndexConcurrently(tx *gorm.DB, name string) error {
+	if tx.Dialector.Name() != "postgres" {
+		return tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", tx.Statement.Quote(name))).Error
+	}
+	conn, err := outsideTransaction(tx, "DROP INDEX CONCURRENTLY")
+	if err != nil {
+		return err
+	}
+	if err := conn.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", quotePostgresIdent(name))).Error; err != nil {
+		return fmt.Errorf("failed to drop index %s: %w", name, err)
+	}
+	out.Printf("🗑️  Dropped index: %s\n", name)
+	return nil
+}
+
+func createIndexConcurrently(tx *gorm.DB, unique bool, name, table string, columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("index %s needs at least one column", name)
+	}
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+
+	if tx.Dialector.Name() != "postgres" {
+		if tx.Migrator().HasIndex(table, name) {
+			return nil
+		}
+		createSQL := fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, tx.Statement.Quote(name), tx.Statement.Quote(table), strings.Join(columns, ", "))
+		return tx.Exec(createSQL).Error
+	}
+
+	conn, err := outsideTransaction(tx, "CREATE INDEX CONCURRENTLY")
+	if err != nil {
+		return err
+	}
+
+	valid, exists, err := postgresIndexState(conn, name)
+	if err != nil {
+		return err
+	}
+	if exists && valid {
+		out.Printf("⚠️  Index already exists: %s\n", name)
+		return nil
+	}
+	if exists {
+		// A failed CREATE INDEX CONCURRENTLY leaves an invalid index that must be dropped first
+		out.Printf("⚠️  Dropping invalid index left by a previous attempt: %s\n", name)
+		if err := conn.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", quotePostgresIdent(name))).Error; err != nil {
+			return fmt.Errorf("failed to drop invalid index %s: %w", name, err)
+		}
+	}
+
+	createSQL := fmt.Sprintf("CREATE %s CONCURRENTLY %s ON %s (%s)", kind, quotePostgresIdent(name), quotePostgresIdent(table), strings.Join(columns, ", "))
+	if err := conn.Exec(createSQL).Error; err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+
+	valid, _, err = postgresIndexState(conn, name)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		if err := conn.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", quotePostgresIdent(name))).Error; err != nil {
+			return fmt.Errorf("index %s is invalid after creation (e.g. duplicate values for a unique index), and dropping it failed: %w", name, err)
+		}
+		return fmt.Errorf("index %s is invalid after creation (e.g. duplicate values for a unique index), dropped it", name)
+	}
+	out.Printf("✅ Created index: %s\n", name)
+	return nil
+}
+
+// postgresIndexState reports whether the index exists in the current search path and is valid.
+func postgresIndexState(db *gorm.DB, name string) (valid, exists bool, err error) {
+	var states []bool
+	if err := db.Raw(`SELECT i.indisvalid FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE c.relname = ? AND pg_table_is_visible(c.oid)`, name).Scan(&states).Error; err != nil {
+		return false, false, fmt.Errorf("failed to check index %s: %w", name, err)
+	}
+	if len(states) == 0 {
+		return false, false, nil
+	}
+	return states[0], true, nil
+}
+
+// outsideTransaction returns a session of tx that runs statements on the connection pool,
+// for statements PostgreSQL refuses to run inside a transaction block, such as statement.
+// It returns an error when tx belongs to a transaction: the statement would wait for the
+// locks the transaction holds on the same table, and the transaction waits for the
+// statement. Sessions capturing SQL are returned unchanged.
+func outsideTransaction(tx *gorm.DB, statement string) (*gorm.DB, error) {
+	if isCapturing(tx) {
+		// Capture sessions never execute statements, keep recording them
+		return tx, nil
+	}
+	if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return nil, fmt.Errorf("%s cannot run inside a transaction, disable Options.UseTransaction", statement)
+	}
+	sqlDB, err := tx.DB()
+	if err != nil {
+		// Sessions pinned to a connection (e.g. with session timeouts) use the pool of the root DB
+		sqlDB, err = (&gorm.DB{Config: tx.Config}).DB()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection pool: %w", err)
+	}
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	conn := tx.Session(&gorm.Session{NewDB: true, Context: ctx})
+	conn.Statement.ConnPool = sqlDB
+	return conn, nil
+}
@@ -0,0 +1,54 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestCreateIndexConcurrentlyRequiresColumns tests that an index without columns is rejected
+func TestCreateIndexConcurrentlyRequiresColumns(t *testing.T) {
+	if err := CreateIndexConcurrently(nil, "idx_orders_user_id", "orders"); err == nil {
+		t.Error("Expected error for index without columns, got nil")
+	}
+}
+
+// TestCreateIndexConcurrentlyFallback tests the regular CREATE INDEX on other databases,
+// which is skipped when the index exists
+func TestCreateIndexConcurrentlyFallback(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.Exec("CREATE TABLE orders (id integer PRIMARY KEY, user_id integer)").Error; err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := CreateIndexConcurrently(db, "idx_orders_user_id", "orders", "user_id"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if !db.Migrator().HasIndex("orders", "idx_orders_user_id") {
+		t.Error("Expected the index to be created")
+	}
+	if err := DropIndexConcurrently(db, "idx_orders_user_id"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if db.Migrator().HasIndex("orders", "idx_orders_user_id") {
+		t.Error("Expected the index to be dropped")
+	}
+}
+
+// TestOutsideTransactionRefusesTransactions tests that statements needing their own connection
+// are refused inside a transaction, which could hold a lock they wait for
+func TestOutsideTransactionRefusesTransactions(t *testing.T) {
+	db := openSQLite(t)
+	if _, err := outsideTransaction(db, "CREATE INDEX CONCURRENTLY"); err != nil {
+		t.Errorf("Expected no error outside a transaction, got %v", err)
+	}
+	db.Transaction(func(tx *gorm.DB) error {
+		_, err := outsideTransaction(tx, "CREATE INDEX CONCURRENTLY")
+		if err == nil || !strings.Contains(err.Error(), "CREATE INDEX CONCURRENTLY cannot run inside a transaction") {
+			t.Errorf("Expected a transaction error, got %v", err)
+		}
+		return nil
+	})
+}
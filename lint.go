@@ -0,0 +1,198 @@
+package gormeasy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// LintFinding is a lock-heavy operation found in a pending migration.
+type LintFinding struct {
+	// MigrationID is the ID of the migration executing the statement.
+	MigrationID string
+	// Statement is the offending SQL statement.
+	Statement string
+	// Rule names the rule that matched, e.g. "not-null-without-default".
+	Rule string
+	// Message explains why the statement is unsafe and how to avoid it.
+	Message string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s [%s] %s\n    %s", f.MigrationID, f.Rule, f.Message, f.Statement)
+}
+
+// lintRule matches statements that block reads or writes on large tables.
+type lintRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// unless skips statements that match it, e.g. ones using the safe variant
+	unless  *regexp.Regexp
+	message string
+}
+
+// lintRules are checked against each statement, collapsed to single spaces and upper case.
+var lintRules = []lintRule{
+	{
+		name:    "not-null-without-default",
+		pattern: regexp.MustCompile(`\bADD (COLUMN )?.*\bNOT NULL\b`),
+		unless:  regexp.MustCompile(`\bDEFAULT\b|\bADD (CONSTRAINT|CHECK|FOREIGN KEY|PRIMARY KEY|UNIQUE|INDEX|KEY)\b`),
+		message: "adding a NOT NULL column without a default fails on non-empty tables; add it nullable, backfill, then set NOT NULL",
+	},
+	{
+		name:    "set-not-null",
+		pattern: regexp.MustCompile(`\bALTER (COLUMN )?\S+ SET NOT NULL\b`),
+		message: "SET NOT NULL scans the whole table under an exclusive lock; add a CHECK (... IS NOT NULL) NOT VALID constraint and validate it first",
+	},
+	{
+		name:    "column-type-change",
+		pattern: regexp.MustCompile(`\bALTER (COLUMN )?\S+ (SET DATA )?TYPE\b|\bMODIFY (COLUMN )?\S+|\bCHANGE (COLUMN )?\S+ \S+`),
+		message: "changing a column type usually rewrites the table under an exclusive lock; add a new column and backfill it instead",
+	},
+	{
+		name:    "volatile-default",
+		pattern: regexp.MustCompile(`\bADD (COLUMN )?.*\bDEFAULT \(?(NOW|RANDOM|CLOCK_TIMESTAMP|GEN_RANDOM_UUID|UUID_GENERATE_V4|UUID)\(`),
+		message: "adding a column with a volatile default rewrites the table; add it without a default and backfill it",
+	},
+	{
+		name:    "table-rewrite",
+		pattern: regexp.MustCompile(`^VACUUM FULL\b|^CLUSTER\b|\bSET TABLESPACE\b|^OPTIMIZE TABLE\b|\bENGINE ?=`),
+		message: "this statement rewrites the table under an exclusive lock",
+	},
+	{
+		name:    "constraint-without-not-valid",
+		pattern: regexp.MustCompile(`^ALTER TABLE .*\bADD (CONSTRAINT \S+ )?(FOREIGN KEY|CHECK)\b`),
+		unless:  regexp.MustCompile(`\bNOT VALID\b`),
+		message: "adding a constraint validates every row under a lock; add it NOT VALID and run VALIDATE CONSTRAINT separately",
+	},
+	{
+		name:    "lock-table",
+		pattern: regexp.MustCompile(`^LOCK (TABLE|TABLES)\b`),
+		message: "explicit table locks block concurrent queries for the rest of the transaction",
+	},
+}
+
+var (
+	createIndexPattern = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX (CONCURRENTLY )?.*?\bON (ONLY )?([^\s(]+)`)
+	createTablePattern = regexp.MustCompile(`^CREATE TABLE (IF NOT EXISTS )?([^\s(]+)`)
+)
+
+// Lint captures the SQL of the pending migrations without executing it and reports
+// lock-heavy operations: NOT NULL columns without a default, column type changes, index
+// builds on existing tables without CONCURRENTLY, validated constraints and table rewrites.
+// It returns no findings when every pending migration looks safe for zero-downtime deploys.
+func Lint(db *gorm.DB, migrations []*Migration, opts Options) ([]LintFinding, error) {
+	status, err := GetStatus(db, migrations, opts)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool, len(status.Pending))
+	for _, id := range status.Pending {
+		pending[id] = true
+	}
+	var selected []*Migration
+	for _, m := range migrations {
+		if pending[m.ID] {
+			selected = append(selected, m)
+		}
+	}
+
+	captured, err := captureSQL(db, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tables created by pending migrations are empty, so statements on them are safe
+	created := make(map[string]bool)
+	tableExists := func(table string) bool {
+		return !created[table] && db.Migrator().HasTable(table)
+	}
+	var findings []LintFinding
+	for _, c := range captured {
+		for _, stmt := range c.Statements {
+			for _, f := range lintStatement(stmt, tableExists) {
+				f.MigrationID = c.ID
+				findings = append(findings, f)
+			}
+			if m := createTablePattern.FindStringSubmatch(normalizeStatement(stmt)); m != nil {
+				created[unquoteTableName(stmt, m[2])] = true
+			}
+		}
+	}
+	return findings, nil
+}
+
+// lintStatement checks a single statement against the lint rules.
+// tableExists reports whether a table already exists and may hold data.
+func lintStatement(stmt string, tableExists func(table string) bool) []LintFinding {
+	normalized := normalizeStatement(stmt)
+	var findings []LintFinding
+	add := func(rule, message string) {
+		findings = append(findings, LintFinding{Statement: strings.TrimSpace(stmt), Rule: rule, Message: message})
+	}
+
+	if m := createIndexPattern.FindStringSubmatch(normalized); m != nil && m[2] == "" {
+		if tableExists(unquoteTableName(stmt, m[4])) {
+			add("index-without-concurrently", "building an index without CONCURRENTLY blocks writes to the table; use CreateIndexConcurrently")
+		}
+	}
+	for _, rule := range lintRules {
+		if !rule.pattern.MatchString(normalized) {
+			continue
+		}
+		if rule.unless != nil && rule.unless.MatchString(normalized) {
+			continue
+		}
+		// Columns of a new table need no default
+		if strings.HasPrefix(normalized, "CREATE TABLE ") {
+			continue
+		}
+		add(rule.name, rule.message)
+	}
+	return findings
+}
+
+// normalizeStatement upper-cases stmt and collapses whitespace, so rules can match keywords
+// regardless of formatting. Comments are dropped.
+func normalizeStatement(stmt string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(stmt, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		b.WriteString(line + " ")
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(b.String()), " "))
+}
+
+// unquoteTableName returns the table name matched in the normalized statement with its
+// original case and without quotes or schema.
+// Only whole words are matched, so a table "A" is not found inside "TABLE".
+func unquoteTableName(stmt, upperName string) string {
+	name := upperName
+	upper := strings.ToUpper(stmt)
+	for offset := 0; ; {
+		i := strings.Index(upper[offset:], upperName)
+		if i < 0 {
+			break
+		}
+		i += offset
+		end := i + len(upperName)
+		if (i == 0 || !isIdentByte(upper[i-1])) && (end == len(upper) || !isIdentByte(upper[end])) {
+			name = stmt[i:end]
+			break
+		}
+		offset = i + 1
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "\"`[]")
+}
+
+// isIdentByte reports whether c can be part of an unquoted upper-case identifier.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,100 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestLintStatement tests the lock-heavy statement rules
+func TestLintStatement(t *testing.T) {
+	existing := func(table string) bool { return table == "users" }
+
+	cases := []struct {
+		stmt string
+		rule string
+	}{
+		{`ALTER TABLE "users" ADD "age" bigint NOT NULL`, "not-null-without-default"},
+		{`ALTER TABLE "users" ADD COLUMN "age" bigint NOT NULL DEFAULT 0`, ""},
+		{`ALTER TABLE users ADD CONSTRAINT age_not_null CHECK (age IS NOT NULL) NOT VALID`, ""},
+		{`ALTER TABLE users ALTER COLUMN age SET NOT NULL`, "set-not-null"},
+		{`ALTER TABLE "users" ALTER COLUMN "age" TYPE bigint`, "column-type-change"},
+		{"ALTER TABLE `users` MODIFY COLUMN `age` bigint", "column-type-change"},
+		{`ALTER TABLE users ADD created_at timestamptz DEFAULT now()`, "volatile-default"},
+		{`VACUUM FULL users`, "table-rewrite"},
+		{`ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)`, "constraint-without-not-valid"},
+		{`ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) NOT VALID`, ""},
+		{`LOCK TABLE users IN ACCESS EXCLUSIVE MODE`, "lock-table"},
+		{`CREATE INDEX "idx_users_age" ON "users" ("age")`, "index-without-concurrently"},
+		{`CREATE INDEX CONCURRENTLY idx_users_age ON users (age)`, ""},
+		{`CREATE UNIQUE INDEX idx_posts_slug ON posts (slug)`, ""},
+		{`CREATE TABLE "posts" ("id" bigserial, "title" text NOT NULL, PRIMARY KEY ("id"))`, ""},
+		{"-- add age\nALTER TABLE users\n  ADD age bigint\n  NOT NULL", "not-null-without-default"},
+	}
+	for _, c := range cases {
+		findings := lintStatement(c.stmt, existing)
+		if c.rule == "" {
+			if len(findings) != 0 {
+				t.Errorf("Expected no findings for %q, got %v", c.stmt, findings)
+			}
+			continue
+		}
+		if len(findings) != 1 || findings[0].Rule != c.rule {
+			t.Errorf("Expected rule %s for %q, got %v", c.rule, c.stmt, findings)
+		}
+	}
+}
+
+// TestIsReadStatement tests which statements the capture pool forwards to the database
+func TestIsReadStatement(t *testing.T) {
+	cases := map[string]bool{
+		"SELECT count(*) FROM information_schema.tables":     true,
+		"  -- comment\nSELECT 1":                             true,
+		"(SELECT 1) UNION (SELECT 2)":                        true,
+		"SHOW CREATE TABLE `users`":                          true,
+		"PRAGMA table_info(users)":                           true,
+		"PRAGMA foreign_keys = ON":                           false,
+		"WITH t AS (SELECT 1) SELECT * FROM t":               true,
+		"WITH t AS (SELECT 1) DELETE FROM users":             false,
+		"INSERT INTO users (name) VALUES ('a') RETURNING id": false,
+		"CREATE TABLE users (id int)":                        false,
+	}
+	for query, want := range cases {
+		if got := isReadStatement(query); got != want {
+			t.Errorf("Expected isReadStatement(%q) to be %v, got %v", query, want, got)
+		}
+	}
+}
+
+// TestCaptureSQL tests that migrations are captured instead of executed
+func TestCaptureSQL(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	migrations := []*Migration{
+		{ID: "1", Migrate: func(tx *gorm.DB) error {
+			return tx.Transaction(func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE users ADD age bigint NOT NULL").Error
+			})
+		}},
+		{ID: "2", Migrate: func(tx *gorm.DB) error {
+			return tx.Exec("UPDATE users SET age = ?", 1).Error
+		}},
+	}
+
+	captured, err := captureSQL(db, migrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(captured) != 2 {
+		t.Fatalf("Expected 2 captured migrations, got %d", len(captured))
+	}
+	if len(captured[0].Statements) != 1 || captured[0].Statements[0] != "ALTER TABLE users ADD age bigint NOT NULL" {
+		t.Errorf("Expected the ALTER TABLE statement, got %v", captured[0].Statements)
+	}
+	if len(captured[1].Statements) != 1 || captured[1].Statements[0] != "UPDATE users SET age = 1" {
+		t.Errorf("Expected the UPDATE statement with its argument, got %v", captured[1].Statements)
+	}
+}
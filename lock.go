@@ -0,0 +1,57 @@
+package gormeasy
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockName returns the name of the advisory lock guarding the history table of opts.
+func migrationLockName(opts Options) string {
+	return "gormeasy:" + opts.TableName
+}
+
+// advisoryLockKey converts a lock name to the 64-bit key used by PostgreSQL advisory locks.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// withMigrationLock runs fn while holding a database-wide advisory lock named after the
+// history table of opts, so concurrent replicas serialize DDL on the history table instead of
+// racing (and deadlocking on some MySQL versions). Advisory locks belong to a session, so fn
+// receives a session pinned to the connection holding the lock. Dialects without advisory
+// locks (SQLite) run fn directly.
+func withMigrationLock(db *gorm.DB, opts Options, fn func(conn *gorm.DB) error) error {
+	name := migrationLockName(opts)
+	switch db.Dialector.Name() {
+	case "postgres":
+		return db.Connection(func(conn *gorm.DB) error {
+			conn = conn.Session(&gorm.Session{NewDB: true})
+			key := advisoryLockKey(name)
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", key).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", key)
+			return fn(conn)
+		})
+	case "mysql":
+		return db.Connection(func(conn *gorm.DB) error {
+			conn = conn.Session(&gorm.Session{NewDB: true})
+			var acquired *int
+			// A negative timeout waits until the lock is released
+			if err := conn.Raw("SELECT GET_LOCK(?, -1)", name).Scan(&acquired).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			if acquired == nil || *acquired != 1 {
+				return fmt.Errorf("failed to acquire migration lock %s", name)
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", name)
+			return fn(conn)
+		})
+	default:
+		return fn(db)
+	}
+}
@@ -0,0 +1,16 @@
+package gormeasy
+
+import (
+	"testing"
+)
+
+// TestAdvisoryLockKey tests that lock keys are stable and differ per history table
+func TestAdvisoryLockKey(t *testing.T) {
+	key := advisoryLockKey(migrationLockName(Options{}.withDefaults()))
+	if key != advisoryLockKey("gormeasy:migrations") {
+		t.Error("Expected the same key for the same lock name")
+	}
+	if key == advisoryLockKey(migrationLockName(Options{TableName: "data_migrations"})) {
+		t.Error("Expected different keys for different history tables")
+	}
+}
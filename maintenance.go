@@ -0,0 +1,156 @@
+package gormeasy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaintenanceTask is a recurring database chore, such as creating next month's partitions or
+// refreshing materialized views, defined next to the migrations and run by `maintenance run`.
+type MaintenanceTask struct {
+	// Name identifies the task in the --task flag and the gormeasy_maintenance table.
+	Name string
+	// Every is the minimum time between two successful runs. A cron entry can call
+	// `maintenance run` often (e.g. every 5 minutes); each task only runs when it is due.
+	// Zero runs the task on every maintenance run.
+	Every time.Duration
+	// Run performs the task.
+	Run func(db *gorm.DB) error
+}
+
+// maintenanceRun records the last runs of a maintenance task.
+type maintenanceRun struct {
+	Name          string `gorm:"primaryKey;size:255"`
+	LastRunAt     time.Time
+	LastSuccessAt *time.Time
+	LastError     string `gorm:"size:1024"`
+}
+
+// TableName returns the table name of maintenance runs.
+func (maintenanceRun) TableName() string {
+	return "gormeasy_maintenance"
+}
+
+// due reports whether a task last run as recorded in run is due at now.
+func (t *MaintenanceTask) due(run *maintenanceRun, now time.Time) bool {
+	return run == nil || run.LastSuccessAt == nil || now.Sub(*run.LastSuccessAt) >= t.Every
+}
+
+// readMaintenanceRuns returns the recorded runs by task name.
+func readMaintenanceRuns(db *gorm.DB) (map[string]*maintenanceRun, error) {
+	runs := make(map[string]*maintenanceRun)
+	if !db.Migrator().HasTable(&maintenanceRun{}) {
+		return runs, nil
+	}
+	var rows []*maintenanceRun
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read maintenance runs: %w", err)
+	}
+	for _, row := range rows {
+		runs[row.Name] = row
+	}
+	return runs, nil
+}
+
+// RunMaintenance runs the tasks that are due, in order. A failing task does not stop the
+// others; the errors of all failed tasks are returned together. Runs are serialized with an
+// advisory lock, so overlapping cron runs don't run a task twice.
+func RunMaintenance(db *gorm.DB, tasks []*MaintenanceTask) error {
+	return runMaintenance(db, tasks, false)
+}
+
+// runMaintenance runs the due tasks, or all tasks when force is set.
+func runMaintenance(db *gorm.DB, tasks []*MaintenanceTask, force bool) error {
+	if err := db.AutoMigrate(&maintenanceRun{}); err != nil {
+		return fmt.Errorf("failed to migrate maintenance table: %w", err)
+	}
+	return withMigrationLock(db, Options{TableName: maintenanceRun{}.TableName()}, func(conn *gorm.DB) error {
+		runs, err := readMaintenanceRuns(conn)
+		if err != nil {
+			return err
+		}
+
+		clock := clockOf(db)
+		var errs []error
+		for _, t := range tasks {
+			if t.Run == nil {
+				return fmt.Errorf("maintenance task %s has no Run function", t.Name)
+			}
+			start := clock.Now()
+			if run := runs[t.Name]; !force && !t.due(run, start) {
+				out.Printf("⏭️  Skipping %s (next run in %s)\n", t.Name, run.LastSuccessAt.Add(t.Every).Sub(start).Round(time.Second))
+				continue
+			}
+
+			out.Printf("🔧 Running %s...\n", t.Name)
+			run := maintenanceRun{Name: t.Name, LastRunAt: start}
+			columns := []string{"last_run_at", "last_error"}
+			if err := t.Run(conn); err != nil {
+				out.Errorln("❌ Maintenance task", t.Name, "failed:", err)
+				run.LastError = err.Error()
+				errs = append(errs, fmt.Errorf("maintenance task %s failed: %w", t.Name, err))
+			} else {
+				out.Printf("✅ %s done in %s\n", t.Name, since(clock, start).Round(time.Millisecond))
+				run.LastSuccessAt = &start
+				columns = append(columns, "last_success_at")
+			}
+			err := conn.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns(columns),
+			}).Create(&run).Error
+			if err != nil {
+				return fmt.Errorf("failed to record run of maintenance task %s: %w", t.Name, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// printMaintenanceTasks prints the schedule and last runs of tasks.
+func printMaintenanceTasks(db *gorm.DB, tasks []*MaintenanceTask) error {
+	runs, err := readMaintenanceRuns(db)
+	if err != nil {
+		return err
+	}
+	now := clockOf(db).Now()
+	out.Println("\n=== Maintenance Tasks ===")
+	for _, t := range tasks {
+		every := "every run"
+		if t.Every > 0 {
+			every = "every " + t.Every.String()
+		}
+		run := runs[t.Name]
+		switch {
+		case run == nil:
+			out.Printf("  - %s (%s): never run\n", t.Name, every)
+		case run.LastError != "":
+			out.Printf("❌ %s (%s): failed at %s: %s\n", t.Name, every, run.LastRunAt.Local().Format(time.DateTime), run.LastError)
+		case t.due(run, now):
+			out.Printf("  - %s (%s): due, last run %s\n", t.Name, every, run.LastRunAt.Local().Format(time.DateTime))
+		default:
+			out.Printf("✅ %s (%s): next run in %s\n", t.Name, every, run.LastSuccessAt.Add(t.Every).Sub(now).Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// selectMaintenanceTasks returns the tasks with the given names, or all tasks when names is empty.
+func selectMaintenanceTasks(tasks []*MaintenanceTask, names []string) ([]*MaintenanceTask, error) {
+	if len(names) == 0 {
+		return tasks, nil
+	}
+	var selected []*MaintenanceTask
+	for _, name := range names {
+		i := slices.IndexFunc(tasks, func(t *MaintenanceTask) bool { return t.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown maintenance task: %s", name)
+		}
+		selected = append(selected, tasks[i])
+	}
+	return selected, nil
+}
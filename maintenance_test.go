@@ -0,0 +1,56 @@
+package gormeasy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMaintenanceTaskDue tests when a task is due based on its last successful run
+func TestMaintenanceTaskDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	hourAgo := now.Add(-time.Hour)
+	task := &MaintenanceTask{Name: "matview-refresh", Every: 2 * time.Hour}
+
+	if !task.due(nil, now) {
+		t.Error("Expected a task that never ran to be due")
+	}
+	if !task.due(&maintenanceRun{LastRunAt: hourAgo, LastError: "boom"}, now) {
+		t.Error("Expected a task without a successful run to be due")
+	}
+	if task.due(&maintenanceRun{LastRunAt: hourAgo, LastSuccessAt: &hourAgo}, now) {
+		t.Error("Expected a task that succeeded an hour ago not to be due")
+	}
+	task.Every = time.Hour
+	if !task.due(&maintenanceRun{LastRunAt: hourAgo, LastSuccessAt: &hourAgo}, now) {
+		t.Error("Expected a task to be due once Every has passed")
+	}
+}
+
+// TestSelectMaintenanceTasks tests selecting tasks by name
+func TestSelectMaintenanceTasks(t *testing.T) {
+	tasks := []*MaintenanceTask{{Name: "partition-ensure"}, {Name: "matview-refresh"}}
+	selected, err := selectMaintenanceTasks(tasks, []string{"matview-refresh"})
+	if err != nil || len(selected) != 1 || selected[0].Name != "matview-refresh" {
+		t.Errorf("Expected [matview-refresh], got %v (%v)", selected, err)
+	}
+	if _, err := selectMaintenanceTasks(tasks, []string{"vacuum"}); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+}
+
+// TestRegistryTasks tests registering maintenance tasks and rejecting duplicate names
+func TestRegistryTasks(t *testing.T) {
+	var r Registry
+	r.RegisterTask(&MaintenanceTask{Name: "partition-ensure"})
+	r.RegisterTask(&MaintenanceTask{Name: "matview-refresh"})
+	if tasks := r.Tasks(); len(tasks) != 2 || tasks[0].Name != "partition-ensure" {
+		t.Errorf("Expected tasks in registration order, got %v", tasks)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a duplicate task name, got none")
+		}
+	}()
+	r.RegisterTask(&MaintenanceTask{Name: "matview-refresh"})
+}
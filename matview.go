@@ -0,0 +1,51 @@
+package gormeasy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshMaterializedViews refreshes the PostgreSQL materialized views views (optionally
+// schema-qualified), or all materialized views outside the system schemas when views is
+// empty, printing how long each took. With concurrently, views are refreshed with
+// REFRESH MATERIALIZED VIEW CONCURRENTLY, which doesn't block reads but requires a unique
+// index on the view. A failing view does not stop the others; all errors are returned together.
+func RefreshMaterializedViews(db *gorm.DB, concurrently bool, views ...string) error {
+	if dialectorName := db.Dialector.Name(); dialectorName != "postgres" {
+		return fmt.Errorf("materialized views are not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+	if len(views) == 0 {
+		if err := db.Raw(`SELECT schemaname || '.' || matviewname FROM pg_matviews
+			WHERE schemaname NOT IN ('pg_catalog', 'information_schema') ORDER BY schemaname, matviewname`).Scan(&views).Error; err != nil {
+			return fmt.Errorf("failed to list materialized views: %w", err)
+		}
+		if len(views) == 0 {
+			out.Println("No materialized views found")
+			return nil
+		}
+	}
+
+	clock := clockOf(db)
+	var errs []error
+	for _, view := range views {
+		start := clock.Now()
+		if err := db.Exec(refreshMaterializedViewSQL(view, concurrently)).Error; err != nil {
+			out.Errorln("❌ Failed to refresh", view+":", err)
+			errs = append(errs, fmt.Errorf("failed to refresh materialized view %s: %w", view, err))
+			continue
+		}
+		out.Printf("✅ Refreshed %s in %s\n", view, since(clock, start).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
+
+// refreshMaterializedViewSQL returns the statement refreshing the materialized view view.
+func refreshMaterializedViewSQL(view string, concurrently bool) string {
+	if concurrently {
+		return "REFRESH MATERIALIZED VIEW CONCURRENTLY " + quoteQualifiedPostgresName(view)
+	}
+	return "REFRESH MATERIALIZED VIEW " + quoteQualifiedPostgresName(view)
+}
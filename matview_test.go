@@ -0,0 +1,23 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestRefreshMaterializedViewSQL tests the refresh statements with and without CONCURRENTLY
+func TestRefreshMaterializedViewSQL(t *testing.T) {
+	if got, expected := refreshMaterializedViewSQL("reporting.daily_sales", true), `REFRESH MATERIALIZED VIEW CONCURRENTLY "reporting"."daily_sales"`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got, expected := refreshMaterializedViewSQL("daily_sales", false), `REFRESH MATERIALIZED VIEW "daily_sales"`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := RefreshMaterializedViews(db, false, "daily_sales"); err == nil {
+		t.Error("Expected error on a non-PostgreSQL database, got nil")
+	}
+}
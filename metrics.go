@@ -0,0 +1,112 @@
+package gormeasy
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// runMetrics records the outcome of the migration runs in this process.
+type runMetrics struct {
+	mu           sync.Mutex
+	runs         uint64
+	failures     uint64
+	lastDuration time.Duration
+	lastSuccess  time.Time
+}
+
+// migrationMetrics are the run metrics exported by MetricsHandler and PublishExpvar.
+var migrationMetrics = &runMetrics{}
+
+// record adds a migration run that took duration and failed with err, or succeeded at end.
+func (m *runMetrics) record(duration time.Duration, end time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	m.lastDuration = duration
+	if err != nil {
+		m.failures++
+	} else {
+		m.lastSuccess = end
+	}
+}
+
+// metricsSnapshot is the state exported by MetricsHandler and PublishExpvar.
+type metricsSnapshot struct {
+	Applied             int     `json:"applied"`
+	Pending             int     `json:"pending"`
+	Gated               int     `json:"gated"`
+	Runs                uint64  `json:"runs"`
+	Failures            uint64  `json:"failures"`
+	LastDurationSeconds float64 `json:"last_duration_seconds"`
+	// LastSuccessTimestamp is the Unix time of the last successful run, 0 if there was none.
+	LastSuccessTimestamp float64 `json:"last_success_timestamp"`
+	Error                string  `json:"error,omitempty"`
+}
+
+// snapshotMetrics combines the run metrics with the migration counts of cache.
+func snapshotMetrics(cache *StatusCache) metricsSnapshot {
+	migrationMetrics.mu.Lock()
+	s := metricsSnapshot{
+		Runs:                migrationMetrics.runs,
+		Failures:            migrationMetrics.failures,
+		LastDurationSeconds: migrationMetrics.lastDuration.Seconds(),
+	}
+	if !migrationMetrics.lastSuccess.IsZero() {
+		s.LastSuccessTimestamp = float64(migrationMetrics.lastSuccess.UnixMilli()) / 1000
+	}
+	migrationMetrics.mu.Unlock()
+
+	status, err := cache.Status()
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.Applied = len(status.Applied)
+	s.Pending = len(status.Pending)
+	s.Gated = len(status.Gated)
+	return s
+}
+
+// writePrometheus writes s in the Prometheus text exposition format.
+func (s metricsSnapshot) writePrometheus(w io.Writer) {
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+	}
+	if s.Error == "" {
+		metric("gormeasy_migrations_applied", "gauge", "Number of applied migrations.", float64(s.Applied))
+		metric("gormeasy_migrations_pending", "gauge", "Number of pending migrations.", float64(s.Pending))
+		metric("gormeasy_migrations_gated", "gauge", "Number of pending migrations held back by an off feature flag.", float64(s.Gated))
+	}
+	metric("gormeasy_migration_runs_total", "counter", "Number of migration runs in this process.", float64(s.Runs))
+	metric("gormeasy_migration_failures_total", "counter", "Number of failed migration runs in this process.", float64(s.Failures))
+	metric("gormeasy_last_migration_duration_seconds", "gauge", "Duration of the last migration run.", s.LastDurationSeconds)
+	metric("gormeasy_last_migration_success_timestamp_seconds", "gauge", "Unix time of the last successful migration run.", s.LastSuccessTimestamp)
+	statusUp := 1.0
+	if s.Error != "" {
+		statusUp = 0
+	}
+	metric("gormeasy_status_up", "gauge", "Whether the migration status could be read.", statusUp)
+}
+
+// MetricsHandler returns an http.Handler serving migration metrics in the Prometheus text
+// format, so /metrics can be scraped without a client library: the applied, pending and gated
+// migration counts from cache, and the run count, failure count, duration and last success time
+// of the migration runs in this process. The counts are left out when the status cannot be read.
+func MetricsHandler(cache *StatusCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		snapshotMetrics(cache).writePrometheus(w)
+	})
+}
+
+// PublishExpvar publishes the metrics of MetricsHandler as the expvar variable "gormeasy",
+// served as JSON on /debug/vars. Like expvar.Publish it panics when called more than once.
+func PublishExpvar(cache *StatusCache) {
+	expvar.Publish("gormeasy", expvar.Func(func() any {
+		return snapshotMetrics(cache)
+	}))
+}
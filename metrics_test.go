@@ -0,0 +1,41 @@
+package gormeasy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetricsHandler tests the Prometheus output of migration counts and run metrics
+func TestMetricsHandler(t *testing.T) {
+	saved := migrationMetrics
+	migrationMetrics = &runMetrics{}
+	defer func() { migrationMetrics = saved }()
+
+	end := time.Unix(1700000000, 0)
+	migrationMetrics.record(1500*time.Millisecond, end, nil)
+	migrationMetrics.record(time.Second, end.Add(time.Minute), errors.New("boom"))
+
+	cache := cachedStatus(&MigrationStatus{Applied: []string{"1", "2"}, Pending: []string{"3"}, Gated: []string{"4"}})
+	rec := httptest.NewRecorder()
+	MetricsHandler(cache).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"gormeasy_migrations_applied 2",
+		"gormeasy_migrations_pending 1",
+		"gormeasy_migrations_gated 1",
+		"gormeasy_migration_runs_total 2",
+		"gormeasy_migration_failures_total 1",
+		"gormeasy_last_migration_duration_seconds 1",
+		"gormeasy_last_migration_success_timestamp_seconds 1.7e+09",
+		"# TYPE gormeasy_migration_runs_total counter",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
@@ -2,13 +2,20 @@ package gormeasy
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 // MigrationsHistory represents a record in the migrations table that tracks applied migrations.
-// It stores the migration ID as the primary key.
+// It stores the migration ID as the primary key and describes the default table layout;
+// custom table and column names configured through Options are handled by historyModel.
 type MigrationsHistory struct {
 	ID string `gorm:"primaryKey"`
 }
@@ -19,68 +26,424 @@ func (MigrationsHistory) TableName() string {
 	return "migrations"
 }
 
-// Migration is a type alias for gormigrate.Migration.
-// It represents a single database migration with its ID, Up, and Down functions.
-type Migration = gormigrate.Migration
+// Migration represents a single database migration with its ID, Migrate and Rollback functions.
+type Migration struct {
+	// ID uniquely identifies the migration and is recorded in the history table.
+	ID string
+	// Migrate applies the migration.
+	Migrate gormigrate.MigrateFunc
+	// Rollback reverts the migration.
+	Rollback gormigrate.RollbackFunc
+	// Group is the module the migration belongs to, selected by the --group flags.
+	// When empty, the migration belongs to group g if its ID starts with "g-"
+	// (e.g. "billing-20240101000000-create-invoices" is in group "billing").
+	Group string
+	// DependsOn lists migration IDs that must be applied before this migration,
+	// e.g. a billing migration adding a foreign key to a table created by an auth migration.
+	// Migrations are reordered to satisfy dependencies; otherwise the slice order is kept.
+	DependsOn []string
+	// OnlyDialects restricts the migration to the given GORM dialects ("postgres", "mysql",
+	// "sqlite", ...). On other dialects Migrate and Rollback are skipped but the migration
+	// is still recorded, so one migration set serves e.g. PostgreSQL and SQLite-based tests.
+	OnlyDialects []string
+	// GateFlag holds the migration back until the feature flag of that name is on, as reported
+	// by Options.FlagProvider, so schema changes roll out together with the feature using them.
+	// A held back migration stays pending and is applied by the first up after the flag is on.
+	GateFlag string
+	// Destructive marks a migration that drops or rewrites data. With --backup-dir, up backs
+	// up the database before applying pending migrations when one of them is destructive.
+	Destructive bool
+	// Objects are the functions, triggers and views created by the migration, set by
+	// ObjectMigration and listed by the list-objects command.
+	Objects []DBObject
+}
+
+// FromGormigrate converts gormigrate migrations to gormeasy migrations. Migration used to be
+// an alias of gormigrate.Migration; callers building []*gormigrate.Migration pass them through
+// FromGormigrate to Start.
+func FromGormigrate(migrations ...*gormigrate.Migration) []*Migration {
+	converted := make([]*Migration, len(migrations))
+	for i, m := range migrations {
+		converted[i] = &Migration{ID: m.ID, Migrate: m.Migrate, Rollback: m.Rollback}
+	}
+	return converted
+}
+
+// toGormigrate converts m to the gormigrate migration run by the migrator.
+func (m *Migration) toGormigrate() *gormigrate.Migration {
+	gm := &gormigrate.Migration{ID: m.ID, Migrate: m.Migrate, Rollback: m.Rollback}
+	if len(m.OnlyDialects) > 0 {
+		gm.Migrate = m.skipOtherDialects(gm.Migrate)
+		if gm.Rollback != nil {
+			gm.Rollback = m.skipOtherDialects(gm.Rollback)
+		}
+	}
+	return gm
+}
+
+// skipOtherDialects wraps fn so that it only runs on the dialects listed in OnlyDialects.
+func (m *Migration) skipOtherDialects(fn func(*gorm.DB) error) func(*gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if dialect := tx.Dialector.Name(); !slices.Contains(m.OnlyDialects, dialect) {
+			out.Printf("⏭️  Skipping %s on %s (only %s)\n", m.ID, dialect, strings.Join(m.OnlyDialects, ", "))
+			return nil
+		}
+		return fn(tx)
+	}
+}
+
+// inGroup reports whether m belongs to group.
+func (m *Migration) inGroup(group string) bool {
+	if m.Group != "" {
+		return m.Group == group
+	}
+	return strings.HasPrefix(m.ID, group+"-")
+}
+
+// filterGroups returns the migrations belonging to any of groups, in their original order.
+// With no groups, all migrations are returned.
+func filterGroups(migrations []*Migration, groups []string) []*Migration {
+	if len(groups) == 0 {
+		return migrations
+	}
+	var filtered []*Migration
+	for _, m := range migrations {
+		for _, group := range groups {
+			if m.inGroup(group) {
+				filtered = append(filtered, m)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// sortMigrations returns the migrations ordered so that every migration comes after its dependencies.
+// It fails fast on dependency cycles and dependencies on unknown migration IDs.
+func sortMigrations(migrations []*Migration) ([]*Migration, error) {
+	byID := make(map[string]*Migration, len(migrations))
+	ids := make([]string, 0, len(migrations))
+	deps := make(map[string][]string)
+	for _, m := range migrations {
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+		if len(m.DependsOn) > 0 {
+			deps[m.ID] = m.DependsOn
+		}
+	}
+	if len(deps) == 0 {
+		return migrations, nil
+	}
+
+	orderedIDs, err := topologicalOrder("migration", ids, deps)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]*Migration, len(orderedIDs))
+	for i, id := range orderedIDs {
+		ordered[i] = byID[id]
+	}
+	return ordered, nil
+}
 
-func getMigrator(db *gorm.DB, migrations []*Migration) *gormigrate.Gormigrate {
+// checkSelectedDependencies fails when a pending migration of selected depends on a migration
+// that is neither applied nor part of selected, e.g. when running a single group with --group.
+func checkSelectedDependencies(selected []*Migration, applied map[string]bool) error {
+	inSelection := make(map[string]bool, len(selected))
+	for _, m := range selected {
+		inSelection[m.ID] = true
+	}
+	for _, m := range selected {
+		if applied[m.ID] {
+			continue
+		}
+		for _, dep := range m.DependsOn {
+			if !applied[dep] && !inSelection[dep] {
+				return fmt.Errorf("migration %s depends on %s, which is not applied", m.ID, dep)
+			}
+		}
+	}
+	return nil
+}
+
+func getMigrator(db *gorm.DB, migrations []*Migration, opts Options) *gormigrate.Gormigrate {
+	db = withClock(withGuardNonEmptyDrop(withSQLVars(db, opts.SQLVars), opts.GuardNonEmptyDrop), opts.Clock)
+	list := make([]*gormigrate.Migration, len(migrations))
+	for i, m := range migrations {
+		list[i] = m.toGormigrate()
+	}
 	return gormigrate.New(db, &gormigrate.Options{
-		TableName:                 "migrations",
-		IDColumnName:              "id",
-		IDColumnSize:              255,
-		UseTransaction:            false, // Must disable transaction to prevent data loss during table recreation
-		ValidateUnknownMigrations: true,
-	}, migrations)
+		TableName:                 opts.TableName,
+		IDColumnName:              opts.IDColumnName,
+		IDColumnSize:              opts.IDColumnSize,
+		UseTransaction:            opts.UseTransaction, // Disabled by default to prevent data loss during table recreation
+		ValidateUnknownMigrations: opts.UnknownMigrations == UnknownMigrationsError,
+	}, list)
+}
+
+// historyModel returns a pointer to a struct describing the migrations history table,
+// with the ID column named and sized according to opts. It matches the layout gormigrate uses,
+// plus the applied_at column added in feature version 2, which the database fills itself,
+// the touched_tables column added in feature version 3 and the duration_ms column added in
+// feature version 4.
+func historyModel(opts Options) any {
+	fields := []reflect.StructField{
+		{
+			Name: "ID",
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"primaryKey;column:%s;size:%d"`, opts.IDColumnName, opts.IDColumnSize)),
+		},
+		{
+			Name: "AppliedAt",
+			Type: reflect.TypeOf((*time.Time)(nil)),
+			Tag:  `gorm:"column:applied_at;type:timestamp;default:CURRENT_TIMESTAMP"`,
+		},
+		{
+			Name: "TouchedTables",
+			Type: reflect.TypeOf((*string)(nil)),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s"`, touchedTablesColumn)),
+		},
+		{
+			Name: "DurationMs",
+			Type: reflect.TypeOf((*int64)(nil)),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%s"`, durationColumn)),
+		},
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
+
+// missingHistoryColumns returns the fields of historyModel missing from the existing history table.
+// SQLite cannot add a column with a non-constant default, so applied_at is never added there.
+func missingHistoryColumns(db *gorm.DB, opts Options) []string {
+	migrator := db.Table(opts.TableName).Migrator()
+	var missing []string
+	if db.Dialector.Name() != "sqlite" && !migrator.HasColumn(historyModel(opts), "applied_at") {
+		missing = append(missing, "AppliedAt")
+	}
+	if !migrator.HasColumn(historyModel(opts), touchedTablesColumn) {
+		missing = append(missing, "TouchedTables")
+	}
+	if !migrator.HasColumn(historyModel(opts), durationColumn) {
+		missing = append(missing, "DurationMs")
+	}
+	return missing
+}
+
+// historyTableCurrent reports whether the history table exists and was upgraded to this
+// FeatureVersion, or a newer one.
+func historyTableCurrent(db *gorm.DB, opts Options) (bool, error) {
+	if !db.Migrator().HasTable(opts.TableName) {
+		return false, nil
+	}
+	stamped, err := stampedVersion(db, opts, metadataKeyFeatureVersion)
+	return stamped >= FeatureVersion, err
+}
+
+// ensureHistoryTable creates the migrations history table if it does not exist yet, or else
+// upgrades a table of an older feature version once: it adds the columns of the newer
+// versions, then stamps FeatureVersion on the metadata table, so later runs only read the
+// stamp. The upgrade runs behind the migration lock, so replicas booting at the same time
+// don't run concurrent AutoMigrates on the table.
+func ensureHistoryTable(db *gorm.DB, opts Options) error {
+	if current, err := historyTableCurrent(db, opts); err != nil || current {
+		return err
+	}
+	return withMigrationLock(db, opts, func(conn *gorm.DB) error {
+		// Another replica may have upgraded the table while we waited for the lock
+		if current, err := historyTableCurrent(conn, opts); err != nil || current {
+			return err
+		}
+		return upgradeHistoryTable(conn, opts)
+	})
+}
+
+// upgradeHistoryTable creates or upgrades the history table on db and stamps FeatureVersion,
+// without taking the migration lock. The lock is held on a connection of its own, so callers
+// inside a transaction use it directly to keep the history table in the transaction.
+func upgradeHistoryTable(db *gorm.DB, opts Options) error {
+	if !db.Migrator().HasTable(opts.TableName) {
+		if err := db.Table(opts.TableName).AutoMigrate(historyModel(opts)); err != nil {
+			return err
+		}
+	} else {
+		for _, field := range missingHistoryColumns(db, opts) {
+			if err := db.Table(opts.TableName).Migrator().AddColumn(historyModel(opts), field); err != nil {
+				return err
+			}
+		}
+	}
+	return stampVersion(db, opts, metadataKeyFeatureVersion, FeatureVersion)
 }
 
 // RunMigrations executes migrations and compares the differences before and after execution.
 func RunMigrations(db *gorm.DB, migrations []*Migration) error {
-	if err := db.AutoMigrate(&MigrationsHistory{}); err != nil {
+	return RunMigrationsWithOptions(db, migrations, Options{})
+}
+
+// RunMigrationsWithOptions is like RunMigrations but uses the history table and
+// migrator settings from opts.
+func RunMigrationsWithOptions(db *gorm.DB, migrations []*Migration, opts Options) error {
+	return runMigrations(db, migrations, migrations, opts)
+}
+
+// runMigrations applies the pending migrations of selected, a subset of all (e.g. one group).
+// Unknown applied migrations are checked against all, so other groups are not reported as unknown.
+func runMigrations(db *gorm.DB, all, selected []*Migration, opts Options) (err error) {
+	opts = opts.withDefaults()
+	tr := tracer(opts)
+	db, span := startSpan(db, tr, "gormeasy.RunMigrations", attribute.String("gormeasy.table", opts.TableName))
+	defer func() { endSpan(span, err) }()
+	if len(selected) == len(all) {
+		sorted, err := sortMigrations(all)
+		if err != nil {
+			return err
+		}
+		all, selected = sorted, sorted
+	}
+	if err := checkFeatureVersion(db, opts); err != nil {
+		return err
+	}
+	if err := ensureHistoryTable(db, opts); err != nil {
 		return fmt.Errorf("failed to migrate migrations table: %w", err)
 	}
+	if err := stampMinVersion(db, opts); err != nil {
+		return err
+	}
 
-	m := getMigrator(db, migrations)
+	defer invalidateStatusCaches()
 
-	before := getAppliedIDs(db)
+	migratorOpts := opts
+	if len(selected) != len(all) {
+		// Applied migrations of other groups are unknown to a migrator of the subset
+		migratorOpts.UnknownMigrations = UnknownMigrationsIgnore
+	}
+	before := getAppliedIDs(db, opts)
+	if unknown := findUnknownMigrations(all, before); len(unknown) > 0 {
+		switch opts.UnknownMigrations {
+		case UnknownMigrationsError:
+			return fmt.Errorf("database has applied migrations unknown to the code: %s (use --allow-unknown to run anyway)", strings.Join(unknown, ", "))
+		case UnknownMigrationsWarn:
+			out.Println("⚠️  Applied migrations unknown to the code:")
+			for _, id := range unknown {
+				out.Println("  -", id)
+			}
+		}
+	}
+	gated, err := gatedMigrations(db, selected, before, opts.FlagProvider)
+	if err != nil {
+		return err
+	}
+	if len(gated) > 0 {
+		selected = slices.DeleteFunc(slices.Clone(selected), func(m *Migration) bool {
+			if flag, ok := gated[m.ID]; ok {
+				out.Printf("⏭️  Holding back %s (flag %s is off)\n", m.ID, flag)
+				return true
+			}
+			return false
+		})
+	}
+	if err := checkSelectedDependencies(selected, before); err != nil {
+		return err
+	}
 
-	fmt.Println("Running migrations...")
+	out.Println("Running migrations...")
 
-	if err := m.Migrate(); err != nil {
+	// Applied migrations are skipped on a retry, so it resumes at the failed migration
+	start := opts.Clock.Now()
+	touched := make(map[string][]string)
+	durations := make(map[string]time.Duration)
+	recorded := recordTouchedTables(selected, touched, opts.TableName, metadataTableName(opts))
+	recorded = withHooks(withHooks(traceMigrations(recorded, tr), timingHooks(durations, opts.SlowMigration)), opts)
+	if opts.Heartbeat > 0 {
+		recorded = withHeartbeat(recorded, opts.Heartbeat, opts.HeartbeatSQL)
+	}
+	// Without a transaction a failed migration may be partially applied, so it is not retried
+	retryOpts := opts
+	if !opts.UseTransaction {
+		retryOpts.MaxRetries = 0
+	}
+	err = retry(retryOpts, "Migration", func() error {
+		return withSessionTimeouts(db, opts, func(conn *gorm.DB) error {
+			return getMigrator(conn, recorded, migratorOpts).Migrate()
+		})
+	})
+	end := opts.Clock.Now()
+	migrationMetrics.record(end.Sub(start), end, err)
+	if saveErr := saveTouchedTables(db, opts, touched); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if saveErr := saveDurations(db, opts, durations); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err != nil {
 		return fmt.Errorf("migrate failed: %w", err)
 	}
 
-	after := getAppliedIDs(db)
+	after := getAppliedIDs(db, opts)
 	diff := findNewMigrations(before, after)
+	span.SetAttributes(attribute.Int("gormeasy.migrations.applied", len(diff)))
 
 	if len(diff) == 0 {
-		fmt.Println("✅ Migration complete (no change)")
+		out.Println("✅ Migration complete (no change)")
 		return nil
 	}
 
-	fmt.Println("✅ Migration complete.")
-	fmt.Println("🆕 New migrations applied:")
+	out.Println("✅ Migration complete.")
+	out.Println("🆕 New migrations applied:")
 	for _, id := range diff {
-		fmt.Println("  -", id)
+		out.Println("  -", id)
 	}
 
-	printMigrationStatus(db, migrations, false)
+	printMigrationStatus(db, selected, opts, false)
+	return nil
+}
+
+// checkDuplicateIDs returns an error listing every migration ID that appears more than once.
+func checkDuplicateIDs(migrations []*Migration) error {
+	seen := make(map[string]bool, len(migrations))
+	var duplicates []string
+	for _, m := range migrations {
+		if seen[m.ID] {
+			duplicates = append(duplicates, m.ID)
+		}
+		seen[m.ID] = true
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate migration IDs: %s", strings.Join(duplicates, ", "))
+	}
 	return nil
 }
 
 // getAppliedIDs reads the set of migration IDs from the migrations table in the current database.
-func getAppliedIDs(db *gorm.DB) map[string]bool {
-	var applied []MigrationsHistory
+func getAppliedIDs(db *gorm.DB, opts Options) map[string]bool {
+	var applied []string
 	ids := make(map[string]bool)
-	if err := db.Find(&applied).Error; err != nil {
-		fmt.Println("Failed to read migration table:", err)
+	if err := db.Table(opts.TableName).Pluck(opts.IDColumnName, &applied).Error; err != nil {
+		out.Errorln("Failed to read migration table:", err)
 		return ids
 	}
-	for _, m := range applied {
-		ids[m.ID] = true
+	for _, id := range applied {
+		ids[id] = true
 	}
 	return ids
 }
 
+// findUnknownMigrations returns the applied migration IDs that are not defined in migrations, sorted.
+func findUnknownMigrations(migrations []*Migration, applied map[string]bool) []string {
+	defined := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		defined[m.ID] = true
+	}
+	var unknown []string
+	for id := range applied {
+		if !defined[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
 // findNewMigrations returns the migration IDs that are new in after compared to before.
 func findNewMigrations(before, after map[string]bool) []string {
 	var diff []string
@@ -93,12 +456,12 @@ func findNewMigrations(before, after map[string]bool) []string {
 }
 
 // printMigrationStatus prints the current migration status (Applied / Pending).
-func printMigrationStatus(db *gorm.DB, migrations []*Migration, forcePrint bool) {
-	if err := db.AutoMigrate(&MigrationsHistory{}); err != nil {
-		fmt.Println("Failed to migrate migrations table:", err)
-		return
+// It only reads the history table and never creates it, so status checks take no locks.
+func printMigrationStatus(db *gorm.DB, migrations []*Migration, opts Options, forcePrint bool) {
+	applied := make(map[string]bool)
+	if db.Migrator().HasTable(opts.TableName) {
+		applied = getAppliedIDs(db, opts)
 	}
-	applied := getAppliedIDs(db)
 
 	appliedCount := 0
 	pendingCount := 0
@@ -111,26 +474,26 @@ func printMigrationStatus(db *gorm.DB, migrations []*Migration, forcePrint bool)
 	}
 
 	if appliedCount == len(migrations) && pendingCount == 0 && !forcePrint {
-		fmt.Println("✅ All migrations are up to date.")
+		out.Println("✅ All migrations are up to date.")
 		return
 	}
 
-	fmt.Println("\n=== Migration Status ===")
+	out.Println("\n=== Migration Status ===")
 
 	if appliedCount > 0 {
-		fmt.Println("✅ Applied migrations:")
+		out.Println("✅ Applied migrations:")
 		for _, m := range migrations {
 			if applied[m.ID] {
-				fmt.Println("  -", m.ID)
+				out.Println("  -", m.ID)
 			}
 		}
 	}
 
 	if pendingCount > 0 {
-		fmt.Println("\n❌ Pending migrations:")
+		out.Println("\n❌ Pending migrations:")
 		for _, m := range migrations {
 			if !applied[m.ID] {
-				fmt.Println("  -", m.ID)
+				out.Println("  -", m.ID)
 			}
 		}
 	}
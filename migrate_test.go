@@ -0,0 +1,195 @@
+package gormeasy
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestFindUnknownMigrations tests that applied IDs missing from the code are reported sorted
+func TestFindUnknownMigrations(t *testing.T) {
+	migrations := []*Migration{{ID: "1-create-users"}, {ID: "2-create-orders"}}
+	applied := map[string]bool{
+		"1-create-users":   true,
+		"3-branch-b":       true,
+		"2-create-orders":  true,
+		"0-removed-branch": true,
+	}
+
+	got := findUnknownMigrations(migrations, applied)
+	want := []string{"0-removed-branch", "3-branch-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestOptionsDefaultUnknownMigrations tests that unknown migrations fail by default
+func TestOptionsDefaultUnknownMigrations(t *testing.T) {
+	if got := (Options{}).withDefaults().UnknownMigrations; got != UnknownMigrationsError {
+		t.Errorf("Expected %s, got %s", UnknownMigrationsError, got)
+	}
+}
+
+// TestFilterGroups tests group selection by Group field and ID prefix, preserving order
+func TestFilterGroups(t *testing.T) {
+	migrations := []*Migration{
+		{ID: "common-1-create-users"},
+		{ID: "billing-2-create-invoices"},
+		{ID: "3-create-sessions", Group: "auth"},
+		{ID: "billing-4-add-invoice-index"},
+		{ID: "billing-5-moved", Group: "common"},
+	}
+
+	var ids []string
+	for _, m := range filterGroups(migrations, []string{"billing", "auth"}) {
+		ids = append(ids, m.ID)
+	}
+	want := []string{"billing-2-create-invoices", "3-create-sessions", "billing-4-add-invoice-index"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+
+	if got := filterGroups(migrations, nil); len(got) != len(migrations) {
+		t.Errorf("Expected all %d migrations without groups, got %d", len(migrations), len(got))
+	}
+}
+
+// TestSortMigrationsDependsOn tests that dependencies are moved before their dependents
+func TestSortMigrationsDependsOn(t *testing.T) {
+	migrations := []*Migration{
+		{ID: "billing-1-create-invoices", DependsOn: []string{"auth-2-create-accounts"}},
+		{ID: "common-1-create-settings"},
+		{ID: "auth-2-create-accounts"},
+	}
+
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var ids []string
+	for _, m := range sorted {
+		ids = append(ids, m.ID)
+	}
+	want := []string{"auth-2-create-accounts", "billing-1-create-invoices", "common-1-create-settings"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+}
+
+// TestSortMigrationsErrors tests that cycles and unknown dependencies fail fast
+func TestSortMigrationsErrors(t *testing.T) {
+	cycle := []*Migration{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+	}
+	if _, err := sortMigrations(cycle); err == nil {
+		t.Error("Expected error for dependency cycle, got nil")
+	}
+
+	missing := []*Migration{{ID: "a", DependsOn: []string{"missing"}}}
+	if _, err := sortMigrations(missing); err == nil {
+		t.Error("Expected error for unknown dependency, got nil")
+	}
+}
+
+// TestCheckSelectedDependencies tests that a group cannot run before the migrations it depends on
+func TestCheckSelectedDependencies(t *testing.T) {
+	selected := []*Migration{{ID: "billing-1", DependsOn: []string{"auth-1"}}}
+	if err := checkSelectedDependencies(selected, map[string]bool{}); err == nil {
+		t.Error("Expected error for unapplied dependency outside the selection, got nil")
+	}
+	if err := checkSelectedDependencies(selected, map[string]bool{"auth-1": true}); err != nil {
+		t.Errorf("Expected no error once the dependency is applied, got %v", err)
+	}
+}
+
+// TestHistoryModelCustomTable tests that migrations are recorded in the history table and ID
+// column configured in Options
+func TestHistoryModelCustomTable(t *testing.T) {
+	saved := out
+	out = &output{level: levelQuiet, w: io.Discard, errW: io.Discard}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	opts := Options{TableName: "schema_migrations", IDColumnName: "version", IDColumnSize: 64}
+	migrations := []*Migration{{ID: "001", Migrate: func(tx *gorm.DB) error { return nil }}}
+	if err := RunMigrationsWithOptions(db, migrations, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if db.Migrator().HasTable("migrations") {
+		t.Error("Expected the default history table not to be created")
+	}
+	columns, err := db.Migrator().ColumnTypes("schema_migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) == 0 || columns[0].Name() != "version" {
+		t.Fatalf("Expected the version ID column first, got %d columns", len(columns))
+	}
+	// SQLite stores every string as text, so the size is read from the model
+	s, err := schema.Parse(historyModel(opts.withDefaults()), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := s.FieldsByDBName["version"].Size; size != 64 {
+		t.Errorf("Expected the version column to have size 64, got %d", size)
+	}
+	var versions []string
+	if err := db.Table("schema_migrations").Pluck("version", &versions).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, []string{"001"}) {
+		t.Errorf("Expected [001] recorded, got %v", versions)
+	}
+}
+
+// TestOnlyDialectsSkipsOtherDialects tests that dialect-restricted migrations are skipped elsewhere
+func TestOnlyDialectsSkipsOtherDialects(t *testing.T) {
+	ran := false
+	m := &Migration{
+		ID:           "1-create-extension",
+		OnlyDialects: []string{"postgres"},
+		Migrate: func(tx *gorm.DB) error {
+			ran = true
+			return nil
+		},
+	}
+
+	migrate := m.toGormigrate().Migrate
+	if err := migrate(&gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ran {
+		t.Error("Expected migration to be skipped on a non-postgres dialect")
+	}
+}
+
+// TestDataOptions tests that the data migration track uses its own history table
+func TestDataOptions(t *testing.T) {
+	opts := Options{TableName: "schema_migrations"}.dataOptions()
+	if opts.TableName != "data_migrations" {
+		t.Errorf("Expected data history table 'data_migrations', got '%s'", opts.TableName)
+	}
+}
+
+// TestFromGormigrate tests that gormigrate migrations keep their ID and functions
+func TestFromGormigrate(t *testing.T) {
+	called := false
+	converted := FromGormigrate(&gormigrate.Migration{ID: "1-create-users", Migrate: func(*gorm.DB) error {
+		called = true
+		return nil
+	}})
+	if len(converted) != 1 || converted[0].ID != "1-create-users" || converted[0].Rollback != nil {
+		t.Fatalf("Expected migration 1-create-users without rollback, got %+v", converted)
+	}
+	converted[0].Migrate(nil)
+	if !called {
+		t.Error("Expected the gormigrate Migrate function to be kept")
+	}
+}
@@ -0,0 +1,139 @@
+package gormeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// notifyURLEnvVar is the environment variable holding the webhook URL of run notifications.
+const notifyURLEnvVar = "GORMEASY_NOTIFY_URL"
+
+// notifyTimeout bounds posting a notification, so a slow webhook doesn't hold up a deployment.
+const notifyTimeout = 10 * time.Second
+
+// notification is the JSON body posted to the webhook. Text is the summary shown by Slack
+// and compatible incoming webhooks; the other fields are for webhooks that parse the run.
+type notification struct {
+	Text       string   `json:"text"`
+	Command    string   `json:"command"`
+	Database   string   `json:"database,omitempty"`
+	Applied    []string `json:"applied,omitempty"`
+	RolledBack []string `json:"rolled_back,omitempty"`
+	Duration   float64  `json:"duration_seconds"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// notifier collects the migrations run by a CLI command and posts a summary to a webhook.
+// A nil notifier, used when no webhook URL is configured, does nothing.
+type notifier struct {
+	url        string
+	command    string
+	clock      Clock
+	start      time.Time
+	applied    []string
+	rolledBack []string
+	// summary is an extra line for commands that don't run migrations through the hooks
+	summary string
+}
+
+// notifier returns the notifier of command, or nil when no webhook URL is configured.
+func (c *cli) notifier(command string) *notifier {
+	if c.notifyURL == "" {
+		return nil
+	}
+	return &notifier{url: c.notifyURL, command: command, clock: c.opts.Clock, start: c.opts.Clock.Now()}
+}
+
+// watch returns opts with an AfterMigration hook recording the migrations applied and rolled back.
+func (n *notifier) watch(opts Options) Options {
+	if n == nil {
+		return opts
+	}
+	after := opts.AfterMigration
+	opts.AfterMigration = func(e MigrationEvent) {
+		if e.Rollback {
+			n.rolledBack = append(n.rolledBack, e.ID)
+		} else {
+			n.applied = append(n.applied, e.ID)
+		}
+		if after != nil {
+			after(e)
+		}
+	}
+	return opts
+}
+
+// send posts the summary of the run on database, which failed with err unless it is nil.
+// A failing webhook only prints a warning: the run itself already happened.
+func (n *notifier) send(database string, err error) {
+	if n == nil {
+		return
+	}
+	body, marshalErr := json.Marshal(n.notification(database, err))
+	if marshalErr != nil {
+		out.Errorln("⚠️  Failed to encode notification:", marshalErr)
+		return
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	resp, postErr := client.Post(n.url, "application/json", bytes.NewReader(body))
+	if postErr != nil {
+		// Webhook URLs carry their secret in the path, so only the cause is printed
+		var urlErr *url.Error
+		if errors.As(postErr, &urlErr) {
+			postErr = urlErr.Err
+		}
+		out.Errorln("⚠️  Failed to send notification:", postErr)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		out.Errorln("⚠️  Failed to send notification:", resp.Status)
+	}
+}
+
+// notification returns the summary of the run on database.
+func (n *notifier) notification(database string, err error) notification {
+	duration := since(n.clock, n.start)
+	msg := notification{
+		Command:    n.command,
+		Database:   database,
+		Applied:    n.applied,
+		RolledBack: n.rolledBack,
+		Duration:   duration.Seconds(),
+	}
+
+	var text strings.Builder
+	target := "gormeasy " + n.command
+	if database != "" {
+		target += " on " + database
+	}
+	if err != nil {
+		msg.Error = err.Error()
+		fmt.Fprintf(&text, "❌ %s failed after %s: %s", target, duration.Round(time.Millisecond), err)
+	} else {
+		fmt.Fprintf(&text, "✅ %s succeeded in %s", target, duration.Round(time.Millisecond))
+	}
+	if len(n.applied) > 0 {
+		fmt.Fprintf(&text, "\nApplied %d migrations:", len(n.applied))
+		for _, id := range n.applied {
+			text.WriteString("\n• " + id)
+		}
+	}
+	if len(n.rolledBack) > 0 {
+		fmt.Fprintf(&text, "\nRolled back %d migrations:", len(n.rolledBack))
+		for _, id := range n.rolledBack {
+			text.WriteString("\n• " + id)
+		}
+	}
+	if n.summary != "" {
+		text.WriteString("\n" + n.summary)
+	}
+	msg.Text = text.String()
+	return msg
+}
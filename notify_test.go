@@ -0,0 +1,50 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestNotifier tests that the notification lists the migrations run and the error of the run
+func TestNotifier(t *testing.T) {
+	var got notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Expected a JSON body, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	c := &cli{notifyURL: server.URL, opts: Options{}.withDefaults()}
+	n := c.notifier("up")
+	var hooked []string
+	opts := n.watch(Options{AfterMigration: func(e MigrationEvent) { hooked = append(hooked, e.ID) }})
+	opts.AfterMigration(MigrationEvent{ID: "20240101000000-create-users"})
+	opts.AfterMigration(MigrationEvent{ID: "20240102000000-create-orders"})
+	n.send("app", errors.New("boom"))
+
+	if len(hooked) != 2 {
+		t.Errorf("Expected the existing AfterMigration hook to be called, got %v", hooked)
+	}
+	if got.Command != "up" || got.Database != "app" || got.Error != "boom" || len(got.Applied) != 2 {
+		t.Errorf("Expected a failed up on app with 2 applied migrations, got %+v", got)
+	}
+	for _, part := range []string{"❌ gormeasy up on app failed", "Applied 2 migrations:", "• 20240102000000-create-orders"} {
+		if !strings.Contains(got.Text, part) {
+			t.Errorf("Expected text to contain %q, got %q", part, got.Text)
+		}
+	}
+
+	var none *notifier
+	if (&cli{}).notifier("up") != none {
+		t.Error("Expected no notifier without a webhook URL")
+	}
+	if opts := none.watch(Options{}); opts.AfterMigration != nil {
+		t.Error("Expected a nil notifier to leave the options as is")
+	}
+	none.send("app", nil)
+}
@@ -0,0 +1,185 @@
+package gormeasy
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options configures how gormeasy records and runs migrations.
+// The zero value matches the behavior of Start.
+type Options struct {
+	// TableName is the table that records applied migrations. Defaults to "migrations".
+	TableName string
+	// IDColumnName is the column of TableName that stores the migration ID. Defaults to "id".
+	IDColumnName string
+	// IDColumnSize is the size of the migration ID column. Defaults to 255.
+	IDColumnSize int
+	// UseTransaction runs all pending migrations inside a single transaction.
+	// It is disabled by default: not every database supports DDL inside transactions,
+	// and table recreation must not lose data when a later statement fails.
+	UseTransaction bool
+	// UnknownMigrations controls what `up` does when the history table contains IDs
+	// that are not defined in code. Defaults to UnknownMigrationsError.
+	UnknownMigrations UnknownMigrations
+	// Seeds are the seed data sets run by the `seed` command.
+	Seeds []*Seed
+	// DataMigrations are long-running data fixes and backfills run by `up-data`, separately
+	// from schema migrations so they don't block schema deployment.
+	DataMigrations []*Migration
+	// Backfills are the backfills managed by the `backfill run`, `backfill status` and
+	// `backfill pause` commands, separately from the schema and data migrations.
+	Backfills []*BackfillOptions
+	// MaintenanceTasks are the recurring database chores run by `maintenance run`.
+	MaintenanceTasks []*MaintenanceTask
+	// Partitions are the time-partitioned PostgreSQL tables whose upcoming partitions
+	// `partitions ensure` creates. Start adds the partitions key of gormeasy.json.
+	Partitions []*TimePartitions
+	// Anonymize are the rules replacing personal data, applied by `anonymize`, `clone-db --anonymize`
+	// and `export-fixtures --anonymize`. Start adds the anonymize key of gormeasy.json.
+	Anonymize []AnonymizeRule
+	// AnonymizeSecret keys the HMAC from which anonymized values are derived, so they cannot be
+	// reversed by hashing guessed emails or names. The GORMEASY_ANONYMIZE_SECRET environment
+	// variable overrides it. Without a secret, a random one is used per run, so anonymized
+	// values differ between runs and between anonymize and export-fixtures.
+	AnonymizeSecret string
+	// DataTableName is the table that records applied data migrations. Defaults to "data_migrations".
+	DataTableName string
+	// Extensions are the PostgreSQL extensions (e.g. "pgcrypto", "uuid-ossp") that create-db and
+	// regression enable right after creating a database, before any migration runs.
+	Extensions []string
+	// ProtectedDatabases are database names (e.g. production databases) that delete-db,
+	// regression and DeleteDatabaseWithOptions refuse to delete, in addition to system
+	// databases such as postgres, template0 and template1.
+	ProtectedDatabases []string
+	// ProtectedHosts are host name patterns of database servers (e.g. "db.prod.internal" or
+	// "*.prod.internal") that delete-db, down --all, regression, anonymize, truncate,
+	// load-fixtures and copy-data refuse to run against unless --allow-protected is given.
+	// Start adds the protected_hosts key of gormeasy.json and the comma-separated
+	// GORMEASY_PROTECTED_HOSTS environment variable.
+	ProtectedHosts []string
+	// ProtectedURLPattern is a regular expression marking protected connection URLs like
+	// ProtectedHosts, e.g. "prod". The protected_url_pattern key of gormeasy.json overrides it.
+	ProtectedURLPattern string
+	// GuardNonEmptyDrop makes DropTable and DropTableIfExists refuse to drop tables holding rows
+	// in the migrations run with these options, catching a Rollback registered for the wrong
+	// migration. Rollbacks meant to drop data use DropTableWithOptions with ForceDrop. Start
+	// enables it as well when the guard_non_empty_drop key of gormeasy.json is true.
+	GuardNonEmptyDrop bool
+	// StatementTimeout aborts any statement of a migration running longer than this
+	// (PostgreSQL statement_timeout, MySQL max_execution_time for SELECTs). Zero disables it.
+	StatementTimeout time.Duration
+	// LockTimeout aborts a migration statement waiting longer than this for a lock, so a
+	// migration queued behind a long transaction doesn't block every other query on the table
+	// (PostgreSQL lock_timeout, MySQL lock_wait_timeout, SQLite busy_timeout). Zero disables it.
+	LockTimeout time.Duration
+	// SlowMigration highlights the migrations that up and regression apply or roll back in this
+	// long or longer, and status --timings the applied migrations that took this long, to spot
+	// deploy risks early. Zero disables it. The --slow flag overrides it.
+	SlowMigration time.Duration
+	// Heartbeat makes up print a line with the migration ID and the elapsed time every
+	// interval while a migration runs, so a long backfill is not mistaken for a hang. Zero
+	// disables it. The --heartbeat flag overrides it.
+	Heartbeat time.Duration
+	// HeartbeatSQL adds the statement the migration is executing to heartbeat lines. Set by
+	// the --heartbeat-sql flag.
+	HeartbeatSQL bool
+	// MaxRetries is how often connecting to the database and running the pending migrations
+	// are retried after transient errors such as refused connections, serialization failures
+	// and deadlocks. Errors in the migrations themselves are never retried, and the migrations
+	// are only retried with UseTransaction, since a failed migration may otherwise be partially
+	// applied. Defaults to 0.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled after every attempt up to 30s.
+	// Defaults to 1s.
+	RetryBackoff time.Duration
+	// MinVersion is the minimum gormeasy FeatureVersion the project requires. Older binaries
+	// refuse to run up, and up stamps it on the history table so older binaries without this
+	// setting refuse as well. Defaults to 0 (no requirement).
+	MinVersion int
+	// SQLVars are the template variables of SQL migrations run with ExecSQLTemplate or
+	// loaded with LoadSQLMigrations, e.g. {"Schema": "billing", "Env": "staging"}.
+	// Start merges the sql_vars key of gormeasy.json into them, the config file winning.
+	SQLVars map[string]string
+	// FlagProvider looks up the feature flags of migrations with a GateFlag. Start lets the
+	// flags key of gormeasy.json override it. Without a provider every flag is off.
+	FlagProvider FlagProvider
+	// TracerProvider creates the OpenTelemetry spans of migration runs: one span per run of the
+	// pending migrations and a child span per migration, with the migration ID as attribute and
+	// errors recorded. Defaults to no tracing.
+	TracerProvider trace.TracerProvider
+	// BeforeMigration is called before each migration is applied or rolled back by up, down,
+	// RunMigrationsWithOptions and the admin handler, e.g. for audit logging.
+	BeforeMigration func(MigrationEvent)
+	// AfterMigration is called after each migration was applied or rolled back successfully,
+	// with its duration.
+	AfterMigration func(MigrationEvent)
+	// OnError is called when a migration fails, with its duration and error, e.g. to page the
+	// on-call engineer for failed production migrations.
+	OnError func(MigrationEvent)
+	// NotifyURL is a webhook (e.g. a Slack incoming webhook) that the up, down and regression
+	// commands post a summary to: the migrations applied or rolled back, the duration and the
+	// error of a failed run. The --notify-url flag, else the GORMEASY_NOTIFY_URL environment
+	// variable, else the notify_url key of gormeasy.json overrides it. Defaults to no notifications.
+	NotifyURL string
+	// QuietEnvFiles silences the warning Start prints when no .env file exists, for platforms
+	// injecting the environment variables themselves. The GORMEASY_QUIET_ENV_FILES environment
+	// variable does the same.
+	QuietEnvFiles bool
+	// Clock provides the current time for timestamps and durations. Helpers without Options,
+	// such as Backfill and SnapshotTable, read it from the session of the migration they run
+	// in. Defaults to the system clock.
+	Clock Clock
+	// IDGenerator creates the IDs of migrations created by `new` and `init`.
+	// Defaults to TimestampIDGenerator using Clock.
+	IDGenerator IDGenerator
+}
+
+// dataOptions returns the options of the data migration track, which records its history in DataTableName.
+func (o Options) dataOptions() Options {
+	o = o.withDefaults()
+	o.TableName = o.DataTableName
+	return o
+}
+
+// UnknownMigrations is the strictness applied to migrations recorded in the history table
+// but not defined in code, which happens when branches are deployed out of order.
+type UnknownMigrations string
+
+const (
+	// UnknownMigrationsError makes `up` fail and list the unknown IDs.
+	UnknownMigrationsError UnknownMigrations = "error"
+	// UnknownMigrationsWarn prints the unknown IDs and runs the pending migrations.
+	UnknownMigrationsWarn UnknownMigrations = "warn"
+	// UnknownMigrationsIgnore runs the pending migrations silently.
+	UnknownMigrationsIgnore UnknownMigrations = "ignore"
+)
+
+// withDefaults returns a copy of o with empty fields set to their default values.
+func (o Options) withDefaults() Options {
+	if o.TableName == "" {
+		o.TableName = "migrations"
+	}
+	if o.IDColumnName == "" {
+		o.IDColumnName = "id"
+	}
+	if o.IDColumnSize == 0 {
+		o.IDColumnSize = 255
+	}
+	if o.DataTableName == "" {
+		o.DataTableName = "data_migrations"
+	}
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = time.Second
+	}
+	if o.Clock == nil {
+		o.Clock = systemClock{}
+	}
+	if o.IDGenerator == nil {
+		o.IDGenerator = TimestampIDGenerator{Clock: o.Clock}
+	}
+	if o.UnknownMigrations == "" {
+		o.UnknownMigrations = UnknownMigrationsError
+	}
+	return o
+}
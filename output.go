@@ -0,0 +1,171 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// outputLevel controls how much gormeasy prints while running commands.
+type outputLevel int
+
+const (
+	// levelQuiet only prints errors.
+	levelQuiet outputLevel = iota
+	// levelNormal prints progress and status messages.
+	levelNormal
+	// levelVerbose additionally prints every SQL statement executed by GORM.
+	levelVerbose
+)
+
+// output is the shared output controller used by all commands and helpers.
+// Every message printed by gormeasy goes through it so that flags like --quiet,
+// --verbose and --plain are respected consistently.
+type output struct {
+	level outputLevel
+	plain bool
+	w     io.Writer
+	errW  io.Writer
+}
+
+var out = &output{
+	level: levelNormal,
+	plain: os.Getenv("NO_COLOR") != "" || !stdoutIsTerminal(),
+	w:     os.Stdout,
+	errW:  os.Stderr,
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal. Output redirected to a file or a
+// CI log collector is plain by default.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// plainReplacer maps the emoji used in messages to ASCII tags for plain output.
+// Longer patterns come first so the padding after wide emoji is collapsed.
+var plainReplacer = strings.NewReplacer(
+	"⚠️  ", "[WARN] ",
+	"⚠️", "[WARN]",
+	"🗑️  ", "[DELETED] ",
+	"🗑️", "[DELETED]",
+	"⏭️  ", "[SKIP] ",
+	"⏭️", "[SKIP]",
+	"⏸️  ", "[PAUSED] ",
+	"⏸️", "[PAUSED]",
+	"✅", "[OK]",
+	"❌", "[X]",
+	"🆕", "[NEW]",
+	"🎉", "[DONE]",
+	"🔧", "[RUN]",
+	"🌱", "[SEED]",
+	"💾", "[BACKUP]",
+	"📸", "[SNAPSHOT]",
+	"🐢", "[SLOW]",
+	"⏳", "[RUNNING]",
+	"👀", "[WATCH]",
+	"🔄", "[CHANGED]",
+)
+
+// format converts a message for the current output mode.
+func (o *output) format(s string) string {
+	if !o.plain {
+		return s
+	}
+	return plainReplacer.Replace(s)
+}
+
+// Println prints an informational message. It is suppressed in quiet mode.
+func (o *output) Println(a ...any) {
+	if o.level < levelNormal {
+		return
+	}
+	io.WriteString(o.w, o.format(fmt.Sprintln(a...)))
+}
+
+// Printf prints a formatted informational message. It is suppressed in quiet mode.
+func (o *output) Printf(format string, a ...any) {
+	if o.level < levelNormal {
+		return
+	}
+	io.WriteString(o.w, o.format(fmt.Sprintf(format, a...)))
+}
+
+// Verbosef prints a formatted message only in verbose mode.
+func (o *output) Verbosef(format string, a ...any) {
+	if o.level < levelVerbose {
+		return
+	}
+	io.WriteString(o.w, o.format(fmt.Sprintf(format, a...)))
+}
+
+// Errorln prints an error message. Errors are always printed, even in quiet mode.
+func (o *output) Errorln(a ...any) {
+	io.WriteString(o.errW, o.format(fmt.Sprintln(a...)))
+}
+
+// gormLogLevel returns the GORM logger level matching the current output level.
+func (o *output) gormLogLevel() logger.LogLevel {
+	switch o.level {
+	case levelQuiet:
+		return logger.Error
+	case levelVerbose:
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// applyLogger returns a session of db whose GORM logger follows the current output level.
+// In verbose mode every SQL statement executed during migrations is printed.
+// In plain mode the logger is wrapped by plainLogger, and GORM's default logger is replaced
+// by one without ANSI colors.
+func (o *output) applyLogger(db *gorm.DB) *gorm.DB {
+	if db.Logger == nil {
+		return db
+	}
+	if o.plain {
+		l := db.Logger
+		if l == logger.Default {
+			l = logger.New(log.New(o.w, "\r\n", log.LstdFlags), logger.Config{
+				SlowThreshold:             200 * time.Millisecond,
+				IgnoreRecordNotFoundError: false,
+				Colorful:                  false,
+			})
+		}
+		return db.Session(&gorm.Session{Logger: plainLogger{l.LogMode(o.gormLogLevel())}})
+	}
+	if o.level == levelNormal {
+		return db
+	}
+	return db.Session(&gorm.Session{Logger: db.Logger.LogMode(o.gormLogLevel())})
+}
+
+// plainLogger wraps the GORM logger of db in plain mode, keeping the user's own logger while
+// replacing the emoji of the messages logged through it.
+type plainLogger struct {
+	logger.Interface
+}
+
+func (l plainLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return plainLogger{l.Interface.LogMode(level)}
+}
+
+func (l plainLogger) Info(ctx context.Context, msg string, data ...any) {
+	l.Interface.Info(ctx, plainReplacer.Replace(msg), data...)
+}
+
+func (l plainLogger) Warn(ctx context.Context, msg string, data ...any) {
+	l.Interface.Warn(ctx, plainReplacer.Replace(msg), data...)
+}
+
+func (l plainLogger) Error(ctx context.Context, msg string, data ...any) {
+	l.Interface.Error(ctx, plainReplacer.Replace(msg), data...)
+}
@@ -0,0 +1,96 @@
+package gormeasy
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestOutputPlainMode tests that plain mode replaces emoji with ASCII tags
+func TestOutputPlainMode(t *testing.T) {
+	var buf bytes.Buffer
+	o := &output{level: levelNormal, plain: true, w: &buf, errW: &buf}
+
+	o.Println("✅ Migration complete.")
+	o.Printf("⚠️  Database already exists: %s\n", "app")
+	o.Printf("🗑️  Deleted database: %s\n", "app")
+
+	expected := "[OK] Migration complete.\n[WARN] Database already exists: app\n[DELETED] Deleted database: app\n"
+	if buf.String() != expected {
+		t.Errorf("Expected plain output %q, got %q", expected, buf.String())
+	}
+}
+
+// TestPlainReplacer tests the ASCII tags of the emoji printed by the commands
+func TestPlainReplacer(t *testing.T) {
+	for message, want := range map[string]string{
+		"🌱 Seeding users...":                            "[SEED] Seeding users...",
+		"💾 Backing up app to app.dump...":               "[BACKUP] Backing up app to app.dump...",
+		"📸 Snapshot of users saved in: snapshots/users": "[SNAPSHOT] Snapshot of users saved in: snapshots/users",
+		"🐢 up 001 took 2s (slower than 1s)":             "[SLOW] up 001 took 2s (slower than 1s)",
+		"⏳ 001 still running (1m0s)":                    "[RUNNING] 001 still running (1m0s)",
+		"👀 Watching the schema every 2s":                "[WATCH] Watching the schema every 2s",
+		"🔄 Schema changed, regenerating models":         "[CHANGED] Schema changed, regenerating models",
+	} {
+		if got := plainReplacer.Replace(message); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
+
+// TestOutputQuietMode tests that quiet mode only prints errors
+func TestOutputQuietMode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	o := &output{level: levelQuiet, w: &stdout, errW: &stderr}
+
+	o.Println("Running migrations...")
+	o.Verbosef("SELECT 1\n")
+	o.Errorln("Failed to read migration table:", "boom")
+
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no stdout in quiet mode, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "boom") {
+		t.Errorf("Expected error to be printed in quiet mode, got %q", stderr.String())
+	}
+}
+
+// TestOutputDefaultKeepsEmoji tests that emoji are kept when plain mode is off
+func TestOutputDefaultKeepsEmoji(t *testing.T) {
+	var buf bytes.Buffer
+	o := &output{level: levelNormal, w: &buf, errW: &buf}
+
+	o.Println("✅ All migrations are up to date.")
+
+	if !strings.Contains(buf.String(), "✅") {
+		t.Errorf("Expected emoji to be kept, got %q", buf.String())
+	}
+}
+
+// recordingLogger is a GORM logger recording the messages logged through it
+type recordingLogger struct {
+	logger.Interface
+	messages *[]string
+}
+
+func (l recordingLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+func (l recordingLogger) Warn(_ context.Context, msg string, _ ...any) {
+	*l.messages = append(*l.messages, msg)
+}
+
+// TestApplyLoggerWrapsCustomLogger tests that plain mode keeps the user's own GORM logger
+func TestApplyLoggerWrapsCustomLogger(t *testing.T) {
+	var messages []string
+	db := openSQLite(t).Session(&gorm.Session{Logger: recordingLogger{Interface: logger.Discard, messages: &messages}})
+	o := &output{level: levelNormal, plain: true, w: &bytes.Buffer{}, errW: &bytes.Buffer{}}
+
+	o.applyLogger(db).Logger.Warn(context.Background(), "⚠️  slow query")
+	if len(messages) != 1 || messages[0] != "[WARN] slow query" {
+		t.Errorf("Expected the custom logger to get the plain message, got %q", messages)
+	}
+}
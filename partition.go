@@ -0,0 +1,195 @@
+package gormeasy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PartitionInterval is the time span covered by each partition of TimePartitions.
+type PartitionInterval string
+
+const (
+	// PartitionDaily creates one partition per day, named like events_p20240115.
+	PartitionDaily PartitionInterval = "day"
+	// PartitionMonthly creates one partition per month, named like events_p202401.
+	PartitionMonthly PartitionInterval = "month"
+	// PartitionYearly creates one partition per year, named like events_p2024.
+	PartitionYearly PartitionInterval = "year"
+)
+
+// defaultPartitionsAhead is the number of future partitions created when TimePartitions.Ahead is zero.
+const defaultPartitionsAhead = 3
+
+// TimePartitions declares a PostgreSQL table partitioned by range on a date or timestamp
+// column, whose upcoming partitions `partitions ensure` creates ahead of time.
+type TimePartitions struct {
+	// Table is the partitioned parent table, created with PARTITION BY RANGE (column).
+	Table string `json:"table"`
+	// Interval is the time span of each partition. Defaults to PartitionMonthly.
+	Interval PartitionInterval `json:"interval"`
+	// Ahead is the number of partitions created after the current one. Defaults to 3.
+	Ahead int `json:"ahead"`
+}
+
+// partitionRange is a partition of TimePartitions covering [From, To).
+type partitionRange struct {
+	Name     string
+	From, To time.Time
+}
+
+// ranges returns the current partition at now and the Ahead partitions after it.
+func (p *TimePartitions) ranges(now time.Time) ([]partitionRange, error) {
+	ahead := p.Ahead
+	if ahead == 0 {
+		ahead = defaultPartitionsAhead
+	}
+	now = now.UTC()
+	var (
+		start  time.Time
+		next   func(time.Time) time.Time
+		layout string
+	)
+	switch p.Interval {
+	case PartitionDaily:
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+		layout = "20060102"
+	case PartitionMonthly, "":
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+		layout = "200601"
+	case PartitionYearly:
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+		layout = "2006"
+	default:
+		return nil, fmt.Errorf("unknown partition interval %q of table %s, use day, month or year", p.Interval, p.Table)
+	}
+
+	ranges := make([]partitionRange, 0, ahead+1)
+	for range ahead + 1 {
+		end := next(start)
+		ranges = append(ranges, partitionRange{Name: p.Table + "_p" + start.Format(layout), From: start, To: end})
+		start = end
+	}
+	return ranges, nil
+}
+
+// EnsurePartitions creates the current and upcoming partitions of each of partitions that
+// don't exist yet. Run it from cron through `partitions ensure`, or as a MaintenanceTask,
+// so inserts never fail for lack of a partition. Bounds are dates, interpreted in the
+// session time zone for timestamptz columns.
+func EnsurePartitions(db *gorm.DB, partitions []*TimePartitions) error {
+	var errs []error
+	for _, p := range partitions {
+		ranges, err := p.ranges(clockOf(db).Now())
+		if err != nil {
+			return err
+		}
+		for _, r := range ranges {
+			if err := CreateRangePartition(db, p.Table, r.Name, r.From.Format(time.DateOnly), r.To.Format(time.DateOnly)); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CreateRangePartition creates the partition name of the PostgreSQL table parent holding the
+// rows from from (inclusive) to to (exclusive), e.g. "2024-01-01" and "2024-02-01", unless
+// name exists. MINVALUE and MAXVALUE are passed unquoted for open-ended partitions.
+func CreateRangePartition(tx *gorm.DB, parent, name, from, to string) error {
+	return createPartition(tx, parent, name, fmt.Sprintf("FROM (%s) TO (%s)", partitionBound(from), partitionBound(to)))
+}
+
+// CreateListPartition creates the partition name of the PostgreSQL table parent holding the
+// rows whose partition key is one of values, unless name exists.
+func CreateListPartition(tx *gorm.DB, parent, name string, values ...string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("partition %s needs at least one value", name)
+	}
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quotePostgresString(value)
+	}
+	return createPartition(tx, parent, name, fmt.Sprintf("IN (%s)", strings.Join(quoted, ", ")))
+}
+
+// createPartition creates the partition name of parent with the given FOR VALUES bound.
+func createPartition(tx *gorm.DB, parent, name, bound string) error {
+	if dialectorName := tx.Dialector.Name(); dialectorName != "postgres" {
+		return fmt.Errorf("partitions are not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+	var exists bool
+	if err := tx.Raw("SELECT to_regclass(?) IS NOT NULL", quoteQualifiedPostgresName(name)).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check partition %s: %w", name, err)
+	}
+	if exists {
+		out.Verbosef("Partition already exists: %s\n", name)
+		return nil
+	}
+	if err := tx.Exec(createPartitionSQL(parent, name, bound)).Error; err != nil {
+		return fmt.Errorf("failed to create partition %s: %w", name, err)
+	}
+	out.Printf("✅ Created partition: %s\n", name)
+	return nil
+}
+
+// createPartitionSQL returns the statement creating the partition name of parent.
+func createPartitionSQL(parent, name, bound string) string {
+	return fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES %s", quoteQualifiedPostgresName(name), quoteQualifiedPostgresName(parent), bound)
+}
+
+// partitionBound quotes value as a range partition bound, except MINVALUE and MAXVALUE.
+func partitionBound(value string) string {
+	if upper := strings.ToUpper(value); upper == "MINVALUE" || upper == "MAXVALUE" {
+		return upper
+	}
+	return quotePostgresString(value)
+}
+
+// printPartitions prints the existing partitions of each of partitions with their bounds.
+func printPartitions(db *gorm.DB, partitions []*TimePartitions) error {
+	for _, p := range partitions {
+		var rows []struct {
+			Name  string
+			Bound string
+		}
+		err := db.Raw(`SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+			FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+			WHERE i.inhparent = to_regclass(?) ORDER BY c.relname`, quoteQualifiedPostgresName(p.Table)).Scan(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to list partitions of %s: %w", p.Table, err)
+		}
+		out.Printf("\n=== Partitions of %s ===\n", p.Table)
+		for _, row := range rows {
+			out.Printf("  - %s  %s\n", row.Name, row.Bound)
+		}
+		if len(rows) == 0 {
+			out.Println("  (none)")
+		}
+	}
+	return nil
+}
+
+// selectPartitions returns the partitioned tables with the given names, or all when names is empty.
+func selectPartitions(partitions []*TimePartitions, names []string) ([]*TimePartitions, error) {
+	if len(names) == 0 {
+		return partitions, nil
+	}
+	var selected []*TimePartitions
+	for _, name := range names {
+		i := slices.IndexFunc(partitions, func(p *TimePartitions) bool { return p.Table == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown partitioned table: %s", name)
+		}
+		selected = append(selected, partitions[i])
+	}
+	return selected, nil
+}
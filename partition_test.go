@@ -0,0 +1,47 @@
+package gormeasy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPartitionRanges tests the names and bounds of the current and upcoming partitions
+func TestPartitionRanges(t *testing.T) {
+	now := time.Date(2024, 11, 15, 10, 0, 0, 0, time.UTC)
+	ranges, err := (&TimePartitions{Table: "events", Ahead: 2}).ranges(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"events_p202411", "events_p202412", "events_p202501"}
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected %d partitions, got %d", len(expected), len(ranges))
+	}
+	for i, r := range ranges {
+		if r.Name != expected[i] {
+			t.Errorf("Expected partition %s, got %s", expected[i], r.Name)
+		}
+	}
+	if from, to := ranges[2].From.Format(time.DateOnly), ranges[2].To.Format(time.DateOnly); from != "2025-01-01" || to != "2025-02-01" {
+		t.Errorf("Expected bounds 2025-01-01 to 2025-02-01, got %s to %s", from, to)
+	}
+
+	ranges, err = (&TimePartitions{Table: "logs", Interval: PartitionDaily}).ranges(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ranges) != defaultPartitionsAhead+1 || ranges[0].Name != "logs_p20241115" {
+		t.Errorf("Expected %d daily partitions starting with logs_p20241115, got %v", defaultPartitionsAhead+1, ranges)
+	}
+
+	if _, err := (&TimePartitions{Table: "events", Interval: "week"}).ranges(now); err == nil {
+		t.Error("Expected error for an unknown interval, got nil")
+	}
+}
+
+// TestCreatePartitionSQL tests the statements creating range and list partitions
+func TestCreatePartitionSQL(t *testing.T) {
+	bound := "FROM (" + partitionBound("2024-01-01") + ") TO (" + partitionBound("maxvalue") + ")"
+	if got, expected := createPartitionSQL("events", "events_p202401", bound), `CREATE TABLE "events_p202401" PARTITION OF "events" FOR VALUES FROM ('2024-01-01') TO (MAXVALUE)`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
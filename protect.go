@@ -0,0 +1,62 @@
+package gormeasy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// protectedHostsEnvVar extends Options.ProtectedHosts with comma-separated host patterns.
+const protectedHostsEnvVar = "GORMEASY_PROTECTED_HOSTS"
+
+// protectedEnvironment marks the database servers (e.g. production) that destructive
+// commands refuse to touch unless --allow-protected is given.
+type protectedEnvironment struct {
+	// hosts are host name patterns in path.Match syntax, e.g. *.prod.internal
+	hosts []string
+	// urlPattern matches the whole connection URL, if set
+	urlPattern *regexp.Regexp
+}
+
+// newProtectedEnvironment combines the protected hosts of opts, the config file and the
+// environment, and compiles the URL pattern of opts or the config file.
+func newProtectedEnvironment(opts Options, cfg *fileConfig, env string) (protectedEnvironment, error) {
+	p := protectedEnvironment{hosts: append(append(append([]string{}, opts.ProtectedHosts...), cfg.ProtectedHosts...), splitList(env)...)}
+	for _, host := range p.hosts {
+		if _, err := path.Match(host, ""); err != nil {
+			return p, fmt.Errorf("invalid protected host pattern %q: %w", host, err)
+		}
+	}
+	pattern := opts.ProtectedURLPattern
+	if cfg.ProtectedURLPattern != "" {
+		pattern = cfg.ProtectedURLPattern
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return p, fmt.Errorf("invalid protected URL pattern: %w", err)
+		}
+		p.urlPattern = re
+	}
+	return p, nil
+}
+
+// check returns an error when dsn points at a protected environment and allow is not set.
+// action describes the refused operation, e.g. "delete-db".
+func (p protectedEnvironment) check(action, dsn string, allow bool) error {
+	if allow || dsn == "" {
+		return nil
+	}
+	if host := strings.ToLower(hostFromURL(dsn)); host != "" {
+		for _, pattern := range p.hosts {
+			if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+				return fmt.Errorf("%s refused: %s is a protected host (%s), pass --allow-protected to continue", action, host, pattern)
+			}
+		}
+	}
+	if p.urlPattern != nil && p.urlPattern.MatchString(dsn) {
+		return fmt.Errorf("%s refused: %s matches the protected URL pattern, pass --allow-protected to continue", action, redactURL(dsn))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Ready reports whether every migration in migrations is applied to db, using the default
+// history table. Services can call it at startup and refuse traffic until the schema is current.
+// Use GetStatus for custom Options.
+func Ready(db *gorm.DB, migrations []*Migration) (bool, error) {
+	status, err := GetStatus(db, migrations, Options{})
+	if err != nil {
+		return false, err
+	}
+	return status.UpToDate(), nil
+}
+
+// readyResponse is the JSON body written by ReadyHandler.
+type readyResponse struct {
+	Ready   bool     `json:"ready"`
+	Pending []string `json:"pending,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// ReadyHandler returns an http.Handler for readiness probes such as /readyz. It responds
+// 200 when every migration known to cache is applied, and 503 with the pending migration
+// IDs otherwise or when the status cannot be read. The status comes from cache, so frequent
+// probes don't query the database each time.
+func ReadyHandler(cache *StatusCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp readyResponse
+		status, err := cache.Status()
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Ready = status.UpToDate()
+			resp.Pending = status.Pending
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !resp.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
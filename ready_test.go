@@ -0,0 +1,40 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// cachedStatus returns a StatusCache holding status until the next invalidation.
+func cachedStatus(status *MigrationStatus) *StatusCache {
+	c := NewStatusCache(nil, nil, Options{}, time.Hour)
+	c.status = status
+	c.generation = statusGeneration.Load()
+	c.expires = time.Now().Add(time.Hour)
+	return c
+}
+
+// TestReadyHandler tests the readiness responses for current and outdated schemas
+func TestReadyHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ReadyHandler(cachedStatus(&MigrationStatus{Applied: []string{"1"}})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	ReadyHandler(cachedStatus(&MigrationStatus{Applied: []string{"1"}, Pending: []string{"2"}})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", rec.Code)
+	}
+	var resp readyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Expected JSON body, got %v", err)
+	}
+	if resp.Ready || len(resp.Pending) != 1 || resp.Pending[0] != "2" {
+		t.Errorf("Expected not ready with pending [2], got %+v", resp)
+	}
+}
@@ -0,0 +1,77 @@
+package gormeasy
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Registry collects migrations registered from per-file init() functions,
+// so that each migration can live in its own file instead of one giant slice.
+// The zero value is ready to use.
+type Registry struct {
+	mu         sync.Mutex
+	migrations []*Migration
+	// locations maps each registered ID to the file:line of its Register call
+	locations map[string]string
+	tasks     []*MaintenanceTask
+}
+
+// Register adds a migration to the registry. It is safe to call from init().
+// It panics when the ID is already registered, reporting the file and line of both
+// registrations, so copy-pasted timestamps are caught at startup rather than mid-deploy.
+func (r *Registry) Register(m *Migration) {
+	location := "unknown location"
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.locations[m.ID]; ok {
+		panic(fmt.Sprintf("gormeasy: duplicate migration ID %q registered at %s, already registered at %s", m.ID, location, previous))
+	}
+	if r.locations == nil {
+		r.locations = make(map[string]string)
+	}
+	r.locations[m.ID] = location
+	r.migrations = append(r.migrations, m)
+}
+
+// Sorted returns the registered migrations sorted by ID, ready to be passed to Start.
+// Registration order depends on file names, so IDs should start with a sortable
+// timestamp (e.g. "20240101000000-create-users") to get a stable migration order.
+func (r *Registry) Sorted() []*Migration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := make([]*Migration, len(r.migrations))
+	copy(sorted, r.migrations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// RegisterTask adds a maintenance task to the registry, so recurring chores can be defined
+// in their own files next to the migrations. It is safe to call from init().
+// It panics when a task with the same name is already registered.
+func (r *Registry) RegisterTask(t *MaintenanceTask) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, registered := range r.tasks {
+		if registered.Name == t.Name {
+			panic(fmt.Sprintf("gormeasy: duplicate maintenance task %q", t.Name))
+		}
+	}
+	r.tasks = append(r.tasks, t)
+}
+
+// Tasks returns the registered maintenance tasks in registration order, ready to be passed
+// as Options.MaintenanceTasks.
+func (r *Registry) Tasks() []*MaintenanceTask {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tasks)
+}
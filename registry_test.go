@@ -0,0 +1,60 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRegistrySorted tests that registered migrations are returned sorted by ID
+func TestRegistrySorted(t *testing.T) {
+	var r Registry
+	r.Register(&Migration{ID: "20240103000000-create-products"})
+	r.Register(&Migration{ID: "20240101000000-create-users"})
+	r.Register(&Migration{ID: "20240102000000-create-orders"})
+
+	expectedIDs := []string{
+		"20240101000000-create-users",
+		"20240102000000-create-orders",
+		"20240103000000-create-products",
+	}
+
+	sorted := r.Sorted()
+	if len(sorted) != len(expectedIDs) {
+		t.Fatalf("Expected %d migrations, got %d", len(expectedIDs), len(sorted))
+	}
+	for i, expectedID := range expectedIDs {
+		if sorted[i].ID != expectedID {
+			t.Errorf("Migration %d: expected ID '%s', got '%s'", i, expectedID, sorted[i].ID)
+		}
+	}
+}
+
+// TestRegistryDuplicateIDPanics tests that registering the same ID twice panics with both locations
+func TestRegistryDuplicateIDPanics(t *testing.T) {
+	var r Registry
+	r.Register(&Migration{ID: "20240101000000-create-users"})
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			t.Fatal("Expected a panic for duplicate ID, got none")
+		}
+		msg := fmt.Sprint(recovered)
+		if strings.Count(msg, "registry_test.go:") != 2 {
+			t.Errorf("Expected both registration locations in panic message, got %s", msg)
+		}
+	}()
+	r.Register(&Migration{ID: "20240101000000-create-users"})
+}
+
+// TestCheckDuplicateIDs tests duplicate detection on a plain migration slice
+func TestCheckDuplicateIDs(t *testing.T) {
+	migrations := []*Migration{{ID: "a"}, {ID: "b"}, {ID: "a"}}
+	if err := checkDuplicateIDs(migrations); err == nil {
+		t.Error("Expected error for duplicate ID, got nil")
+	}
+	if err := checkDuplicateIDs(migrations[:2]); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
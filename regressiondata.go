@@ -0,0 +1,127 @@
+package gormeasy
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// regressionData configures the data-preservation checks of regression: the database is
+// seeded after the first up, the last steps migrations are rolled back and applied again,
+// and the row counts before and after are compared.
+type regressionData struct {
+	// seeds are run after the first up.
+	seeds []*Seed
+	// fixtures is the directory of fixture files loaded after the first up.
+	fixtures string
+	// steps is the number of migrations rolled back and applied again.
+	steps int
+	// preserved are the tables whose row counts must not change, all tables but empty when nil.
+	preserved []string
+	// empty are the tables expected to be empty after the cycle.
+	empty []string
+}
+
+// run seeds db, rolls back and applies again the last d.steps migrations of m, and returns
+// an error listing the tables whose row counts don't match the expectations.
+func (d *regressionData) run(db *gorm.DB, m *gormigrate.Gormigrate, opts Options) error {
+	if len(d.seeds) > 0 {
+		if err := RunSeeds(db, d.seeds); err != nil {
+			return err
+		}
+	}
+	if d.fixtures != "" {
+		if err := LoadFixtures(db, os.DirFS(d.fixtures), "."); err != nil {
+			return err
+		}
+	}
+	before, err := countRows(db, opts)
+	if err != nil {
+		return err
+	}
+
+	for range d.steps {
+		if err := m.RollbackLast(); err != nil {
+			if err == gormigrate.ErrNoRunMigration {
+				break
+			}
+			return fmt.Errorf("failed to rollback with data: %w", err)
+		}
+	}
+	if err := m.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate again with data: %w", err)
+	}
+	after, err := countRows(db, opts)
+	if err != nil {
+		return err
+	}
+
+	out.Printf("\n=== Row Counts after rolling back and applying %d migrations ===\n", d.steps)
+	changed := false
+	for _, table := range slices.Sorted(maps.Keys(before)) {
+		if before[table] != after[table] {
+			out.Printf("  - %s: %d -> %d rows\n", table, before[table], after[table])
+			changed = true
+		}
+	}
+	if !changed {
+		out.Println("  (no changes)")
+	}
+	if problems := d.check(before, after); len(problems) > 0 {
+		return fmt.Errorf("data was not preserved:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	out.Println("✅ Data preserved")
+	return nil
+}
+
+// check returns the tables whose row counts after don't match the expectations of d, given
+// the row counts before. Tables missing after the cycle count as empty.
+func (d *regressionData) check(before, after map[string]int64) []string {
+	var problems []string
+	for _, table := range d.empty {
+		if after[table] > 0 {
+			problems = append(problems, fmt.Sprintf("%s: expected to be empty, has %d rows", table, after[table]))
+		}
+	}
+	preserved := d.preserved
+	if preserved == nil {
+		for table := range before {
+			if !slices.Contains(d.empty, table) {
+				preserved = append(preserved, table)
+			}
+		}
+	}
+	slices.Sort(preserved)
+	for _, table := range preserved {
+		if before[table] != after[table] {
+			problems = append(problems, fmt.Sprintf("%s: had %d rows, has %d", table, before[table], after[table]))
+		}
+	}
+	return problems
+}
+
+// countRows returns the number of rows of every table of db but the gormeasy history tables.
+func countRows(db *gorm.DB, opts Options) (map[string]int64, error) {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	history := historyTables(opts)
+	counts := make(map[string]int64)
+	for _, table := range tables {
+		if slices.Contains(history, table) {
+			continue
+		}
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
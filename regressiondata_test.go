@@ -0,0 +1,28 @@
+package gormeasy
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestRegressionDataCheck tests comparing row counts before and after a down and up with data
+func TestRegressionDataCheck(t *testing.T) {
+	before := map[string]int64{"users": 3, "orders": 5, "search_cache": 7}
+	after := map[string]int64{"users": 3, "orders": 4, "search_cache": 7}
+
+	d := &regressionData{empty: []string{"search_cache"}}
+	expected := []string{"search_cache: expected to be empty, has 7 rows", "orders: had 5 rows, has 4"}
+	if problems := d.check(before, after); !slices.Equal(problems, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems)
+	}
+
+	d = &regressionData{preserved: []string{"users"}}
+	if problems := d.check(before, after); len(problems) != 0 {
+		t.Errorf("Expected only users to be checked, got %v", problems)
+	}
+
+	delete(after, "users")
+	if problems := d.check(before, after); !slices.Equal(problems, []string{"users: had 3 rows, has 0"}) {
+		t.Errorf("Expected a dropped table to count as empty, got %v", problems)
+	}
+}
@@ -0,0 +1,173 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Phases of a regression run, reported as test suites.
+const (
+	regressionPhaseUp      = "up"
+	regressionPhaseData    = "data"
+	regressionPhaseDown    = "down"
+	regressionPhaseUpAgain = "up-again"
+)
+
+// regressionReport collects one test case per migration applied or rolled back by regression,
+// written as JUnit XML or JSON by --report-format and --report-out for CI dashboards.
+type regressionReport struct {
+	// phase is the phase of the migrations running now.
+	phase    string
+	Database string           `json:"database"`
+	Passed   bool             `json:"passed"`
+	Duration float64          `json:"duration_seconds"`
+	Error    string           `json:"error,omitempty"`
+	Cases    []regressionCase `json:"cases"`
+}
+
+// regressionCase is a migration applied or rolled back in a phase of regression.
+type regressionCase struct {
+	Phase     string  `json:"phase"`
+	ID        string  `json:"id"`
+	Direction string  `json:"direction"`
+	Duration  float64 `json:"duration_seconds"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// name returns the test case name of c, e.g. "20240101000000-create-users (down)".
+func (c regressionCase) name() string {
+	return fmt.Sprintf("%s (%s)", c.ID, c.Direction)
+}
+
+// setPhase sets the phase of the migrations running next. It does nothing on a nil report.
+func (r *regressionReport) setPhase(phase string) {
+	if r != nil {
+		r.phase = phase
+	}
+}
+
+// hooks returns the options whose lifecycle hooks record each migration in r.
+func (r *regressionReport) hooks() Options {
+	record := func(e MigrationEvent) {
+		c := regressionCase{Phase: r.phase, ID: e.ID, Direction: "up", Duration: e.Duration.Seconds()}
+		if e.Rollback {
+			c.Direction = "down"
+		}
+		if e.Err != nil {
+			c.Error = e.Err.Error()
+		}
+		r.Cases = append(r.Cases, c)
+	}
+	return Options{AfterMigration: record, OnError: record}
+}
+
+// finish records the outcome of the run, which took duration and failed with err when not nil.
+// An error outside of a migration, e.g. of the data checks, is reported as a regression case.
+func (r *regressionReport) finish(duration time.Duration, err error) {
+	r.Duration = duration.Seconds()
+	r.Passed = err == nil
+	if err == nil {
+		return
+	}
+	r.Error = err.Error()
+	if !slices.ContainsFunc(r.Cases, func(c regressionCase) bool { return c.Error != "" }) {
+		r.Cases = append(r.Cases, regressionCase{Phase: r.phase, ID: "regression", Direction: r.phase, Error: r.Error})
+	}
+}
+
+// write writes the report to path in format, "junit" or "json".
+func (r *regressionReport) write(format, path string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(r, "", "  ")
+	case "junit":
+		data, err = r.junit()
+	default:
+		return fmt.Errorf("unknown report format %q, use junit or json", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode regression report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write regression report: %w", err)
+	}
+	out.Println("Regression report written to:", path)
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit returns the report as JUnit XML, with one test suite per phase.
+func (r *regressionReport) junit() ([]byte, error) {
+	suites := junitTestSuites{Name: "regression " + r.Database, Time: junitTime(r.Duration)}
+	for _, c := range r.Cases {
+		i := slices.IndexFunc(suites.Suites, func(s junitTestSuite) bool { return s.Name == c.Phase })
+		if i < 0 {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: c.Phase})
+			i = len(suites.Suites) - 1
+		}
+		s := &suites.Suites[i]
+		tc := junitTestCase{ClassName: "regression." + c.Phase, Name: c.name(), Time: junitTime(c.Duration)}
+		if c.Error != "" {
+			tc.Failure = &junitFailure{Message: c.Error, Text: c.Error}
+			s.Failures++
+			suites.Failures++
+		}
+		s.Cases = append(s.Cases, tc)
+		s.Tests++
+		suites.Tests++
+	}
+	for i := range suites.Suites {
+		var seconds float64
+		for _, c := range r.Cases {
+			if c.Phase == suites.Suites[i].Name {
+				seconds += c.Duration
+			}
+		}
+		suites.Suites[i].Time = junitTime(seconds)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// junitTime formats seconds like JUnit reports do.
+func junitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,101 @@
+package gormeasy
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testRegressionReport returns a report of a run whose second migration fails to roll back
+func testRegressionReport() *regressionReport {
+	report := &regressionReport{Database: "app_regression"}
+	migrations := withHooks([]*Migration{
+		{ID: "001", Migrate: func(*gorm.DB) error { return nil }, Rollback: func(*gorm.DB) error { return nil }},
+		{ID: "002", Migrate: func(*gorm.DB) error { return nil }, Rollback: func(*gorm.DB) error { return errors.New("column in use") }},
+	}, report.hooks())
+
+	report.setPhase(regressionPhaseUp)
+	for _, m := range migrations {
+		_ = m.Migrate(nil)
+	}
+	report.setPhase(regressionPhaseDown)
+	err := migrations[1].Rollback(nil)
+	report.finish(2*time.Second, err)
+	return report
+}
+
+// TestRegressionReportCases tests that each migration up and down is recorded in its phase
+func TestRegressionReportCases(t *testing.T) {
+	report := testRegressionReport()
+	if report.Passed || report.Error != "column in use" || report.Duration != 2 {
+		t.Errorf("Expected failed run of 2s, got %+v", report)
+	}
+	var names []string
+	for _, c := range report.Cases {
+		names = append(names, c.Phase+" "+c.name()+" "+c.Error)
+	}
+	expected := "up 001 (up) |up 002 (up) |down 002 (down) column in use"
+	if got := strings.Join(names, "|"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestRegressionReportFinish tests that an error outside of a migration is reported as a case
+func TestRegressionReportFinish(t *testing.T) {
+	report := &regressionReport{}
+	report.setPhase(regressionPhaseData)
+	report.finish(time.Second, errors.New("data was not preserved"))
+	if len(report.Cases) != 1 || report.Cases[0].ID != "regression" || report.Cases[0].Phase != regressionPhaseData {
+		t.Errorf("Expected a regression case in the data phase, got %+v", report.Cases)
+	}
+
+	var nilReport *regressionReport
+	nilReport.setPhase(regressionPhaseUp)
+}
+
+// TestRegressionReportJUnit tests the JUnit XML report
+func TestRegressionReportJUnit(t *testing.T) {
+	data, err := testRegressionReport().junit()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{
+		`<testsuites name="regression app_regression" tests="3" failures="1" time="2.000">`,
+		`<testsuite name="up" tests="2" failures="0" time="0.000">`,
+		`<testcase classname="regression.up" name="001 (up)" time="0.000"></testcase>`,
+		`<testsuite name="down" tests="1" failures="1" time="0.000">`,
+		`<failure message="column in use">column in use</failure>`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected report to contain %s, got:\n%s", expected, data)
+		}
+	}
+}
+
+// TestRegressionReportJSON tests writing the JSON report
+func TestRegressionReportJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regression.json")
+	if err := testRegressionReport().write("json", path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report regressionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Database != "app_regression" || len(report.Cases) != 3 || report.Cases[2].Direction != "down" {
+		t.Errorf("Expected 3 cases of app_regression, got %+v", report)
+	}
+	if err := testRegressionReport().write("xml", path); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// regressionBootstrap brings the regression database of --since to the baseline without
+// replaying the migrations up to it: from a schema file written by dump-schema, or by cloning
+// a template database already at the baseline.
+type regressionBootstrap struct {
+	baseline   string
+	schemaFile string
+	template   string
+	// pendingFrom is the database of --only-pending in a --plan-only run, which does not
+	// connect to find the baseline.
+	pendingFrom string
+}
+
+// regressionBaseline splits migrations for regression --since: the ID of the last migration
+// before since, which regression migrates to without testing, and the tested migrations from
+// since on. The baseline is empty when since is the first migration.
+func regressionBaseline(migrations []*Migration, since string) (string, []*Migration, error) {
+	i := slices.IndexFunc(migrations, func(m *Migration) bool { return m.ID == since })
+	if i < 0 {
+		return "", nil, fmt.Errorf("unknown migration ID: %s", since)
+	}
+	if i == 0 {
+		return "", migrations, nil
+	}
+	return migrations[i-1].ID, migrations[i:], nil
+}
+
+// checkBaseline returns an error unless exactly the migrations up to baseline are applied to db,
+// so the bootstrapped database neither misses a baseline migration nor skips a tested one.
+func checkBaseline(db *gorm.DB, migrations []*Migration, opts Options, baseline string) error {
+	applied := make(map[string]bool)
+	if db.Migrator().HasTable(opts.TableName) {
+		applied = getAppliedIDs(db, opts)
+	}
+	i := slices.IndexFunc(migrations, func(m *Migration) bool { return m.ID == baseline })
+	for j, m := range migrations {
+		if j <= i && !applied[m.ID] {
+			return fmt.Errorf("the baseline %s is not reached, migration %s is not applied", baseline, m.ID)
+		}
+		if j > i && applied[m.ID] {
+			return fmt.Errorf("migration %s is already applied, only the migrations up to the baseline %s may be", m.ID, baseline)
+		}
+	}
+	return nil
+}
+
+// firstPending returns the ID of the first of migrations not in applied, empty when all are applied.
+// Regression --only-pending tests it and every migration after it, applied or not.
+func firstPending(migrations []*Migration, applied map[string]bool) string {
+	for _, m := range migrations {
+		if !applied[m.ID] {
+			return m.ID
+		}
+	}
+	return ""
+}
+
+// rollbackToBaseline rolls back the migrations of m applied after baseline, all of them when
+// baseline is empty.
+func rollbackToBaseline(m *gormigrate.Gormigrate, baseline string) error {
+	if baseline == "" {
+		return rollbackAllMigrations(m)
+	}
+	return m.RollbackTo(baseline)
+}
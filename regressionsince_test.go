@@ -0,0 +1,53 @@
+package gormeasy
+
+import "testing"
+
+// TestRegressionBaseline tests splitting the migrations at --since
+func TestRegressionBaseline(t *testing.T) {
+	migrations := []*Migration{{ID: "001"}, {ID: "002"}, {ID: "003"}}
+	baseline, tested, err := regressionBaseline(migrations, "002")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if baseline != "001" || len(tested) != 2 || tested[0].ID != "002" {
+		t.Errorf("Expected baseline 001 and 2 tested migrations, got %s and %d", baseline, len(tested))
+	}
+
+	baseline, tested, err = regressionBaseline(migrations, "001")
+	if err != nil || baseline != "" || len(tested) != 3 {
+		t.Errorf("Expected no baseline and 3 tested migrations, got %q, %d, %v", baseline, len(tested), err)
+	}
+	if _, _, err := regressionBaseline(migrations, "004"); err == nil {
+		t.Error("Expected error for unknown migration, got nil")
+	}
+}
+
+// TestFirstPending tests finding the first migration not applied
+func TestFirstPending(t *testing.T) {
+	migrations := []*Migration{{ID: "001"}, {ID: "002"}, {ID: "003"}}
+	if id := firstPending(migrations, map[string]bool{"001": true, "003": true}); id != "002" {
+		t.Errorf("Expected 002, got %q", id)
+	}
+	if id := firstPending(migrations, map[string]bool{"001": true, "002": true, "003": true}); id != "" {
+		t.Errorf("Expected no pending migration, got %q", id)
+	}
+}
+
+// TestCheckBaseline tests that exactly the migrations up to the baseline must be applied
+func TestCheckBaseline(t *testing.T) {
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	migrations := []*Migration{{ID: "001"}, {ID: "002"}, {ID: "003"}}
+	if err := checkBaseline(db, migrations, opts, "002"); err == nil {
+		t.Error("Expected an error for a database without migrations, got nil")
+	}
+	if err := MarkApplied(db, opts, "001", "002"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkBaseline(db, migrations, opts, "002"); err != nil {
+		t.Errorf("Expected the baseline to be reached, got %v", err)
+	}
+	if err := checkBaseline(db, migrations, opts, "001"); err == nil {
+		t.Error("Expected an error for a tested migration already applied, got nil")
+	}
+}
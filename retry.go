@@ -0,0 +1,109 @@
+package gormeasy
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxRetryBackoff caps the delay between two attempts.
+const maxRetryBackoff = 30 * time.Second
+
+// sleep pauses between attempts. Tests replace it to avoid waiting.
+var sleep = time.Sleep
+
+// transientSQLStates are SQLSTATE codes of errors that may succeed when retried:
+// serialization failures, deadlocks, lock timeouts, a server starting up and too many connections.
+var transientSQLStates = []string{"40001", "40P01", "55P03", "57P03", "53300"}
+
+// transientMessages are fragments of transient error messages from drivers that don't
+// expose a SQLSTATE, such as the MySQL driver.
+var transientMessages = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"bad connection",
+	"no such host",
+	"i/o timeout",
+	"deadlock",
+	"could not serialize access",
+	"lock wait timeout exceeded",
+	"too many connections",
+	"the database system is starting up",
+}
+
+// isTransientError reports whether err is a connection or concurrency error that may
+// succeed when retried, as opposed to an error in the migration itself.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		// Class 08 is connection exceptions
+		if strings.HasPrefix(state, "08") {
+			return true
+		}
+		for _, s := range transientSQLStates {
+			if state == s {
+				return true
+			}
+		}
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs fn until it succeeds, fails with a non-transient error or Options.MaxRetries
+// retries are used up. The delay starts at Options.RetryBackoff and doubles after every
+// attempt, up to maxRetryBackoff.
+func retry(opts Options, action string, fn func() error) error {
+	delay := opts.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > opts.MaxRetries || !isTransientError(err) {
+			return err
+		}
+		out.Printf("⚠️  %s failed: %v, retrying in %s (%d/%d)\n", action, err, delay, attempt, opts.MaxRetries)
+		sleep(delay)
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// retryOpen returns open retrying transient connection errors according to opts,
+// so commands started before the database (or its proxy) is ready wait for it.
+func retryOpen(open func(string) (*gorm.DB, error), opts Options) func(string) (*gorm.DB, error) {
+	return func(url string) (*gorm.DB, error) {
+		var db *gorm.DB
+		err := retry(opts, "Connecting to the database", func() error {
+			var err error
+			db, err = open(url)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return withClock(db, opts.Clock), nil
+	}
+}
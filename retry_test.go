@@ -0,0 +1,109 @@
+package gormeasy
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sqlStateError is a driver error exposing a SQLSTATE like pgconn.PgError.
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// TestIsTransientError tests the classification of retryable errors
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("failed to connect: %w", syscall.ECONNREFUSED), true},
+		{fmt.Errorf("migrate failed: %w", sqlStateError("40001")), true},
+		{sqlStateError("08006"), true},
+		{sqlStateError("42P07"), false},
+		{errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{errors.New(`relation "users" already exists`), false},
+	}
+	for _, c := range cases {
+		if got := isTransientError(c.err); got != c.want {
+			t.Errorf("Expected isTransientError(%v) to be %v, got %v", c.err, c.want, got)
+		}
+	}
+}
+
+// TestRetry tests that transient errors are retried with a growing delay
+func TestRetry(t *testing.T) {
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { sleep = time.Sleep }()
+
+	opts := Options{MaxRetries: 3}.withDefaults()
+	attempts := 0
+	err := retry(opts, "Test", func() error {
+		attempts++
+		if attempts < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Errorf("Expected delays [1s 2s], got %v", delays)
+	}
+
+	attempts = 0
+	err = retry(opts, "Test", func() error {
+		attempts++
+		return errors.New("syntax error")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected a non-transient error to fail after 1 attempt, got %d attempts and %v", attempts, err)
+	}
+
+	attempts = 0
+	err = retry(opts, "Test", func() error {
+		attempts++
+		return driver.ErrBadConn
+	})
+	if err == nil || attempts != 4 {
+		t.Errorf("Expected to give up after 4 attempts, got %d attempts and %v", attempts, err)
+	}
+}
+
+// TestRunMigrationsRetriesOnlyInTransaction tests that a migration failing with a transient
+// error is only retried when it runs in a transaction, since it may be partially applied
+func TestRunMigrationsRetriesOnlyInTransaction(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	for _, useTransaction := range []bool{false, true} {
+		attempts := 0
+		migrations := []*Migration{{ID: "20240101000000-flaky", Migrate: func(tx *gorm.DB) error {
+			attempts++
+			if attempts == 1 {
+				return driver.ErrBadConn
+			}
+			return nil
+		}}}
+		err := runMigrations(openSQLite(t), migrations, migrations, Options{MaxRetries: 3, UseTransaction: useTransaction})
+		if useTransaction && (err != nil || attempts != 2) {
+			t.Errorf("Expected a retry in a transaction, got %d attempts and %v", attempts, err)
+		}
+		if !useTransaction && (err == nil || attempts != 1) {
+			t.Errorf("Expected no retry without a transaction, got %d attempts and %v", attempts, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// RoleOptions describes the application role created by CreateRole.
+type RoleOptions struct {
+	// Name is the role (PostgreSQL) or user (MySQL) the application connects as.
+	Name string
+	// Password is the login password of the role. It is never printed, not even with --verbose.
+	Password string
+	// Database is the database the role is granted on. Defaults to the database of the connection.
+	Database string
+	// Schema is the PostgreSQL schema the role is granted on. Defaults to "public".
+	Schema string
+	// Host is the MySQL host the user may connect from. Defaults to "%" (any host).
+	Host string
+	// Options names the gormeasy history tables, which the PostgreSQL role may only read.
+	Options Options
+}
+
+// CreateRole creates a least-privilege application role, or updates its password when it
+// exists, and grants it read and write access to the data of the database: SELECT, INSERT,
+// UPDATE and DELETE on all tables, and on PostgreSQL usage of the schema and its sequences.
+// The role cannot create, alter or drop tables; migrations keep running as the owner. On
+// PostgreSQL, writes to the gormeasy history tables that exist are revoked, so the role
+// cannot rewrite the migration history; run it again after up creates new history tables.
+//
+// On PostgreSQL, db must be connected to the target database as the role that runs the
+// migrations, since the default privileges for tables created later are set for that role.
+func CreateRole(db *gorm.DB, opts RoleOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("role name is required")
+	}
+	if opts.Password == "" {
+		return fmt.Errorf("role password is required")
+	}
+	if opts.Database == "" {
+		opts.Database = db.Migrator().CurrentDatabase()
+	}
+	if opts.Schema == "" {
+		opts.Schema = "public"
+	}
+	if opts.Host == "" {
+		opts.Host = "%"
+	}
+
+	dialectorName := db.Dialector.Name()
+	var exists bool
+	switch dialectorName {
+	case "postgres":
+		if err := db.Raw("SELECT EXISTS(SELECT FROM pg_roles WHERE rolname = ?)", opts.Name).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check role existence: %w", err)
+		}
+	case "mysql":
+		// CREATE USER IF NOT EXISTS followed by ALTER USER needs no check
+	default:
+		return fmt.Errorf("role creation is not supported for %s. Currently supported: PostgreSQL, MySQL", dialectorName)
+	}
+
+	var history []string
+	if dialectorName == "postgres" {
+		if err := db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_name IN ? ORDER BY table_name",
+			opts.Schema, historyTables(opts.Options)).Scan(&history).Error; err != nil {
+			return fmt.Errorf("failed to list history tables: %w", err)
+		}
+	}
+
+	login, grants := roleStatements(dialectorName, opts, history, exists)
+	// The login statements carry the password and must not reach the SQL log
+	quiet := db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	for _, stmt := range login {
+		if err := quiet.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create role %s: %w", opts.Name, err)
+		}
+	}
+	for _, stmt := range grants {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to grant access to role %s: %w", opts.Name, err)
+		}
+	}
+	if exists {
+		out.Printf("✅ Updated role: %s\n", opts.Name)
+	} else {
+		out.Printf("✅ Created role: %s\n", opts.Name)
+	}
+	out.Printf("✅ Granted read and write access on %s to %s\n", opts.Database, opts.Name)
+	return nil
+}
+
+// roleStatements returns the statements creating (or, when exists is set, updating) the login of
+// the role of opts, which contain the password, and the statements granting it access. On
+// PostgreSQL the role may only read the history tables.
+func roleStatements(dialect string, opts RoleOptions, history []string, exists bool) (login, grants []string) {
+	quoteString := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+
+	if dialect == "mysql" {
+		// MySQL string literals treat backslashes as escapes
+		quoteMySQLString := func(s string) string { return quoteString(strings.ReplaceAll(s, `\`, `\\`)) }
+		quoteBacktick := func(name string) string { return "`" + strings.ReplaceAll(name, "`", "``") + "`" }
+		user := quoteMySQLString(opts.Name) + "@" + quoteMySQLString(opts.Host)
+		login = []string{
+			fmt.Sprintf("CREATE USER IF NOT EXISTS %s IDENTIFIED BY %s", user, quoteMySQLString(opts.Password)),
+			fmt.Sprintf("ALTER USER %s IDENTIFIED BY %s", user, quoteMySQLString(opts.Password)),
+		}
+		grants = []string{fmt.Sprintf("GRANT SELECT, INSERT, UPDATE, DELETE ON %s.* TO %s", quoteBacktick(opts.Database), user)}
+		return login, grants
+	}
+
+	quoteIdent := func(name string) string { return `"` + strings.ReplaceAll(name, `"`, `""`) + `"` }
+	role, schema := quoteIdent(opts.Name), quoteIdent(opts.Schema)
+	create := "CREATE ROLE %s LOGIN PASSWORD %s"
+	if exists {
+		create = "ALTER ROLE %s WITH LOGIN PASSWORD %s"
+	}
+	login = []string{fmt.Sprintf(create, role, quoteString(opts.Password))}
+	grants = []string{
+		fmt.Sprintf("GRANT CONNECT ON DATABASE %s TO %s", quoteIdent(opts.Database), role),
+		fmt.Sprintf("GRANT USAGE ON SCHEMA %s TO %s", schema, role),
+		fmt.Sprintf("GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %s TO %s", schema, role),
+		fmt.Sprintf("GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA %s TO %s", schema, role),
+		fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO %s", schema, role),
+		fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT USAGE, SELECT ON SEQUENCES TO %s", schema, role),
+	}
+	if len(history) > 0 {
+		tables := make([]string, len(history))
+		for i, table := range history {
+			tables[i] = schema + "." + quoteIdent(table)
+		}
+		grants = append(grants, fmt.Sprintf("REVOKE INSERT, UPDATE, DELETE ON %s FROM %s", strings.Join(tables, ", "), role))
+	}
+	return login, grants
+}
@@ -0,0 +1,47 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRoleStatements tests the statements creating the application role on each dialect
+func TestRoleStatements(t *testing.T) {
+	opts := RoleOptions{Name: "app", Password: "it's secret", Database: "shop", Schema: "public", Host: "%"}
+
+	login, grants := roleStatements("postgres", opts, nil, false)
+	if len(login) != 1 || login[0] != `CREATE ROLE "app" LOGIN PASSWORD 'it''s secret'` {
+		t.Errorf("Expected CREATE ROLE with the escaped password, got %v", login)
+	}
+	for _, stmt := range grants {
+		if strings.Contains(stmt, "secret") {
+			t.Errorf("Expected the password only in the login statements, got %s", stmt)
+		}
+	}
+	if grants[0] != `GRANT CONNECT ON DATABASE "shop" TO "app"` {
+		t.Errorf(`Expected GRANT CONNECT ON DATABASE "shop" TO "app", got %s`, grants[0])
+	}
+	_, grants = roleStatements("postgres", opts, []string{"migrations", "migrations_meta"}, false)
+	if revoke := grants[len(grants)-1]; revoke != `REVOKE INSERT, UPDATE, DELETE ON "public"."migrations", "public"."migrations_meta" FROM "app"` {
+		t.Errorf("Expected writes to the history tables to be revoked, got %s", revoke)
+	}
+	if login, _ := roleStatements("postgres", opts, nil, true); !strings.HasPrefix(login[0], `ALTER ROLE "app" WITH LOGIN PASSWORD`) {
+		t.Errorf("Expected an existing role to be altered, got %v", login)
+	}
+
+	login, grants = roleStatements("mysql", opts, nil, false)
+	if len(login) != 2 || !strings.HasPrefix(login[0], "CREATE USER IF NOT EXISTS 'app'@'%'") {
+		t.Errorf("Expected CREATE USER IF NOT EXISTS and ALTER USER, got %v", login)
+	}
+	if len(grants) != 1 || grants[0] != "GRANT SELECT, INSERT, UPDATE, DELETE ON `shop`.* TO 'app'@'%'" {
+		t.Errorf("Expected a grant on shop.*, got %v", grants)
+	}
+
+	opts.Password = `it's a \' secret\`
+	if login, _ := roleStatements("mysql", opts, nil, false); !strings.HasSuffix(login[0], `IDENTIFIED BY 'it''s a \\'' secret\\'`) {
+		t.Errorf("Expected the backslashes of the password to be escaped, got %v", login)
+	}
+	if login, _ := roleStatements("postgres", opts, nil, false); !strings.HasSuffix(login[0], `PASSWORD 'it''s a \'' secret\'`) {
+		t.Errorf("Expected the backslashes to be kept on postgres, got %v", login)
+	}
+}
@@ -0,0 +1,61 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestScaffoldProject tests the files created by init and that running it again keeps them
+func TestScaffoldProject(t *testing.T) {
+	var buf strings.Builder
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	dir := t.TempDir()
+	ids := TimestampIDGenerator{Clock: fixedClock(time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC))}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("bin/\n.env"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffoldProject(dir, ids); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for path, want := range map[string]string{
+		"migrations/migrations.go":                  "var Registry gormeasy.Registry",
+		"migrations/20240301123045-create-users.go": `ID: "20240301123045-create-users"`,
+		"migrations/regression_test.go":             "func TestRegression(t *testing.T)",
+		".env.example":                              "OWNER_DATABASE_URL=",
+		"db.mk":                                     "db-regression:",
+		".gitignore":                                "bin/\n.env\n.env.local\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Errorf("Expected %s to be created, got %v", path, err)
+			continue
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %s to contain %q, got %q", path, want, content)
+		}
+	}
+
+	edited := filepath.Join(dir, "db.mk")
+	if err := os.WriteFile(edited, []byte("DB_NAME ?= shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := scaffoldProject(dir, ids); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if content, _ := os.ReadFile(edited); string(content) != "DB_NAME ?= shop\n" {
+		t.Errorf("Expected db.mk not to be overwritten, got %q", content)
+	}
+	if strings.Contains(buf.String(), "Created:") || strings.Count(buf.String(), "File already exists, skipped") != 5 {
+		t.Errorf("Expected every file to be skipped, got %q", buf.String())
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, ".gitignore")); string(content) != "bin/\n.env\n.env.local\n" {
+		t.Errorf("Expected .gitignore entries not to be repeated, got %q", content)
+	}
+}
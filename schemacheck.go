@@ -0,0 +1,102 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AssertModelsMatch compares GORM model definitions with the live database schema.
+// It reports missing tables, missing columns and columns whose database type does not
+// match the Go field type, so services can fail their health check when hand-written
+// structs drift away from what the migrations actually created.
+// Returns nil when every model matches, otherwise an error listing all mismatches.
+func AssertModelsMatch(db *gorm.DB, models ...interface{}) error {
+	var problems []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		table := stmt.Schema.Table
+
+		if !db.Migrator().HasTable(table) {
+			problems = append(problems, fmt.Sprintf("table %s does not exist", table))
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		columns := make(map[string]gorm.ColumnType, len(columnTypes))
+		for _, ct := range columnTypes {
+			columns[strings.ToLower(ct.Name())] = ct
+		}
+
+		for _, dbName := range stmt.Schema.DBNames {
+			field := stmt.Schema.FieldsByDBName[dbName]
+			if field.IgnoreMigration {
+				continue
+			}
+			ct, ok := columns[strings.ToLower(dbName)]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: column does not exist", table, dbName))
+				continue
+			}
+			if !columnTypeMatches(field, ct.DatabaseTypeName()) {
+				problems = append(problems, fmt.Sprintf("%s.%s: model type %s does not match database type %s",
+					table, dbName, field.DataType, strings.ToLower(ct.DatabaseTypeName())))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("models do not match database schema:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// columnTypeMatches reports whether a model field can be stored in a column of the given database type.
+// Types that cannot be classified are treated as matching to avoid false positives.
+func columnTypeMatches(field *schema.Field, databaseType string) bool {
+	modelFamily := typeFamily(string(field.DataType))
+	dbFamily := typeFamily(databaseType)
+	if modelFamily == "" || dbFamily == "" || modelFamily == dbFamily {
+		return true
+	}
+	// MySQL stores booleans as TINYINT(1)
+	if modelFamily == "bool" && dbFamily == "int" {
+		return true
+	}
+	return false
+}
+
+// typeFamily groups GORM data types and database column types into comparable families.
+func typeFamily(dataType string) string {
+	t := strings.ToLower(strings.TrimSpace(dataType))
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.TrimPrefix(t, "_")
+
+	switch t {
+	case string(schema.Bool), "boolean":
+		return "bool"
+	case string(schema.Int), string(schema.Uint), "integer", "smallint", "bigint", "tinyint", "mediumint",
+		"int2", "int4", "int8", "serial", "bigserial", "smallserial":
+		return "int"
+	case string(schema.Float), "float4", "float8", "real", "double", "numeric", "decimal":
+		return "float"
+	case string(schema.String), "varchar", "char", "character", "bpchar", "text", "tinytext", "mediumtext",
+		"longtext", "nvarchar", "nchar", "citext", "uuid", "json", "jsonb", "enum":
+		return "string"
+	case string(schema.Time), "timestamp", "timestamptz", "datetime", "datetime2", "date", "timetz":
+		return "time"
+	case string(schema.Bytes), "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return "bytes"
+	}
+	return ""
+}
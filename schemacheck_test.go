@@ -0,0 +1,37 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// TestColumnTypeMatches tests the comparison between model field types and database column types
+func TestColumnTypeMatches(t *testing.T) {
+	cases := []struct {
+		modelType schema.DataType
+		dbType    string
+		expected  bool
+	}{
+		{schema.String, "VARCHAR", true},
+		{schema.String, "TEXT", true},
+		{"uuid", "UUID", true},
+		{"varchar(64)", "varchar", true},
+		{schema.Int, "INT8", true},
+		{schema.Uint, "bigint", true},
+		{schema.Bool, "tinyint", true},
+		{schema.Time, "TIMESTAMPTZ", true},
+		{schema.Float, "NUMERIC", true},
+		{schema.String, "INT4", false},
+		{schema.Int, "TEXT", false},
+		{schema.Time, "VARCHAR", false},
+		{schema.String, "geometry", true},
+	}
+
+	for _, c := range cases {
+		field := &schema.Field{DataType: c.modelType}
+		if got := columnTypeMatches(field, c.dbType); got != c.expected {
+			t.Errorf("columnTypeMatches(%s, %s): expected %v, got %v", c.modelType, c.dbType, c.expected, got)
+		}
+	}
+}
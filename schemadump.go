@@ -0,0 +1,316 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// schemaDump is the DDL of a database schema.
+type schemaDump struct {
+	// SQL is the script recreating the schema.
+	SQL string
+	// Tables lists the dumped tables in creation order.
+	Tables []string
+	// Drops lists the statements dropping each dumped object, in creation order.
+	Drops []string
+}
+
+// rollbackSQL returns the script dropping every dumped object in reverse creation order.
+func (d *schemaDump) rollbackSQL() string {
+	var b strings.Builder
+	for i := len(d.Drops) - 1; i >= 0; i-- {
+		b.WriteString(d.Drops[i] + "\n")
+	}
+	return b.String()
+}
+
+// dumpSchema reads the DDL of the current database schema: the tables, sequences and views,
+// and on PostgreSQL the extensions, enum types, functions and triggers, so drift detection
+// sees every object a migration may create. Tables listed in exclude (such as the migrations
+// history table) are left out. PostgreSQL is read from pg_catalog, MySQL with SHOW CREATE
+// TABLE and SQLite from sqlite_master.
+func dumpSchema(db *gorm.DB, exclude ...string) (*schemaDump, error) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return dumpPostgresSchema(db, exclude)
+	case "mysql":
+		return dumpMySQLSchema(db, exclude)
+	case "sqlite":
+		return dumpSQLiteSchema(db, exclude)
+	default:
+		return nil, fmt.Errorf("schema dump is not supported for %s. Currently supported: PostgreSQL, MySQL, SQLite", db.Dialector.Name())
+	}
+}
+
+// quotePostgresIdent quotes a PostgreSQL identifier.
+func quotePostgresIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteMySQLIdent quotes a MySQL identifier.
+func quoteMySQLIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// joinSQLSections returns the non-empty sections of a schema script in order, separated by
+// blank lines.
+func joinSQLSections(sections ...string) string {
+	var nonEmpty []string
+	for _, section := range sections {
+		if section = strings.TrimSpace(section); section != "" {
+			nonEmpty = append(nonEmpty, section)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n") + "\n"
+}
+
+func dumpPostgresSchema(db *gorm.DB, exclude []string) (*schemaDump, error) {
+	types, typeDrops, err := dumpTypes(db)
+	if err != nil {
+		return nil, err
+	}
+	functions, functionDrops, err := dumpFunctions(db)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	dump := &schemaDump{Drops: slices.Concat(typeDrops, functionDrops)}
+
+	type sequence struct {
+		OID       uint32
+		Name      string
+		Start     int64
+		Increment int64
+	}
+	var sequences []sequence
+	// Sequences created implicitly by identity columns are recreated by the column itself
+	if err := db.Raw(`SELECT c.oid, c.relname AS name, s.seqstart AS start, s.seqincrement AS increment
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_sequence s ON s.seqrelid = c.oid
+		WHERE c.relkind = 'S' AND n.nspname = current_schema()
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = c.oid AND d.deptype = 'i')
+		ORDER BY c.relname`).Scan(&sequences).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sequences: %w", err)
+	}
+	for _, s := range sequences {
+		fmt.Fprintf(&b, "CREATE SEQUENCE %s START WITH %d INCREMENT BY %d;\n", quotePostgresIdent(s.Name), s.Start, s.Increment)
+		dump.Drops = append(dump.Drops, fmt.Sprintf("DROP SEQUENCE IF EXISTS %s;", quotePostgresIdent(s.Name)))
+	}
+	if len(sequences) > 0 {
+		b.WriteString("\n")
+	}
+
+	type table struct {
+		OID  uint32
+		Name string
+	}
+	var tables []table
+	if err := db.Raw(`SELECT c.oid, c.relname AS name
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND n.nspname = current_schema()
+		ORDER BY c.relname`).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var foreignKeys []string
+	for _, t := range tables {
+		if slices.Contains(exclude, t.Name) {
+			continue
+		}
+		dump.Tables = append(dump.Tables, t.Name)
+		// Foreign keys of other tables may reference it
+		dump.Drops = append(dump.Drops, fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE;", quotePostgresIdent(t.Name)))
+
+		type column struct {
+			Name     string
+			Type     string
+			NotNull  bool
+			Default  *string
+			Identity string
+		}
+		var columns []column
+		if err := db.Raw(`SELECT a.attname AS name, format_type(a.atttypid, a.atttypmod) AS type, a.attnotnull AS not_null,
+			pg_get_expr(d.adbin, d.adrelid) AS "default", a.attidentity::text AS identity
+			FROM pg_attribute a
+			LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+			WHERE a.attrelid = ? AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`, t.OID).Scan(&columns).Error; err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", t.Name, err)
+		}
+
+		var lines []string
+		for _, c := range columns {
+			line := quotePostgresIdent(c.Name) + " " + c.Type
+			switch c.Identity {
+			case "a":
+				line += " GENERATED ALWAYS AS IDENTITY"
+			case "d":
+				line += " GENERATED BY DEFAULT AS IDENTITY"
+			}
+			if c.Default != nil {
+				line += " DEFAULT " + *c.Default
+			}
+			if c.NotNull {
+				line += " NOT NULL"
+			}
+			lines = append(lines, line)
+		}
+
+		type constraint struct {
+			Name       string
+			Type       string
+			Definition string
+		}
+		var constraints []constraint
+		if err := db.Raw(`SELECT conname AS name, contype::text AS type, pg_get_constraintdef(oid) AS definition
+			FROM pg_constraint
+			WHERE conrelid = ? AND contype IN ('p', 'u', 'c', 'x', 'f')
+			ORDER BY contype DESC, conname`, t.OID).Scan(&constraints).Error; err != nil {
+			return nil, fmt.Errorf("failed to read constraints of %s: %w", t.Name, err)
+		}
+		for _, c := range constraints {
+			if c.Type == "f" {
+				// Foreign keys are added after all tables exist
+				foreignKeys = append(foreignKeys, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;",
+					quotePostgresIdent(t.Name), quotePostgresIdent(c.Name), c.Definition))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("CONSTRAINT %s %s", quotePostgresIdent(c.Name), c.Definition))
+		}
+
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n    %s\n);\n", quotePostgresIdent(t.Name), strings.Join(lines, ",\n    "))
+
+		var indexes []string
+		if err := db.Raw(`SELECT pg_get_indexdef(i.indexrelid)
+			FROM pg_index i
+			WHERE i.indrelid = ? AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid)
+			ORDER BY i.indexrelid`, t.OID).Scan(&indexes).Error; err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", t.Name, err)
+		}
+		for _, index := range indexes {
+			b.WriteString(index + ";\n")
+		}
+		b.WriteString("\n")
+	}
+
+	for _, s := range sequences {
+		var owner struct {
+			Table  string
+			Column string
+		}
+		if err := db.Raw(`SELECT c.relname AS "table", a.attname AS "column"
+			FROM pg_depend d
+			JOIN pg_class c ON c.oid = d.refobjid
+			JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+			WHERE d.objid = ? AND d.deptype = 'a'`, s.OID).Scan(&owner).Error; err != nil {
+			return nil, fmt.Errorf("failed to read owner of sequence %s: %w", s.Name, err)
+		}
+		if owner.Table != "" && !slices.Contains(exclude, owner.Table) {
+			fmt.Fprintf(&b, "ALTER SEQUENCE %s OWNED BY %s.%s;\n",
+				quotePostgresIdent(s.Name), quotePostgresIdent(owner.Table), quotePostgresIdent(owner.Column))
+		}
+	}
+
+	for _, fk := range foreignKeys {
+		b.WriteString(fk + "\n")
+	}
+
+	views, viewDrops, err := dumpViews(db)
+	if err != nil {
+		return nil, err
+	}
+	triggers, triggerDrops, err := dumpTriggers(db)
+	if err != nil {
+		return nil, err
+	}
+	dump.Drops = slices.Concat(dump.Drops, viewDrops, triggerDrops)
+	dump.SQL = joinSQLSections(types, functions, b.String(), views, triggers)
+	return dump, nil
+}
+
+func dumpMySQLSchema(db *gorm.DB, exclude []string) (*schemaDump, error) {
+	var tables []string
+	if err := db.Raw(`SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	tables = slices.DeleteFunc(tables, func(t string) bool { return slices.Contains(exclude, t) })
+
+	// Create referenced tables first, since MySQL checks foreign keys in CREATE TABLE
+	type reference struct {
+		TableName           string
+		ReferencedTableName string
+	}
+	var references []reference
+	if err := db.Raw(`SELECT DISTINCT TABLE_NAME AS table_name, REFERENCED_TABLE_NAME AS referenced_table_name
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL`).Scan(&references).Error; err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	deps := make(map[string][]string)
+	for _, r := range references {
+		if r.TableName != r.ReferencedTableName && slices.Contains(tables, r.ReferencedTableName) {
+			deps[r.TableName] = append(deps[r.TableName], r.ReferencedTableName)
+		}
+	}
+	ordered, err := topologicalOrder("table", tables, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	var drops []string
+	for _, table := range ordered {
+		var name, ddl string
+		if err := db.Raw("SHOW CREATE TABLE "+quoteMySQLIdent(table)).Row().Scan(&name, &ddl); err != nil {
+			return nil, fmt.Errorf("failed to read DDL of %s: %w", table, err)
+		}
+		b.WriteString(ddl + ";\n\n")
+		drops = append(drops, fmt.Sprintf("DROP TABLE IF EXISTS %s;", quoteMySQLIdent(table)))
+	}
+	views, viewDrops, err := dumpViews(db)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaDump{SQL: joinSQLSections(b.String(), views), Tables: ordered, Drops: slices.Concat(drops, viewDrops)}, nil
+}
+
+func dumpSQLiteSchema(db *gorm.DB, exclude []string) (*schemaDump, error) {
+	type object struct {
+		Type    string
+		Name    string
+		TblName string
+		SQL     string
+	}
+	var objects []object
+	if err := db.Raw(`SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY rowid`).Scan(&objects).Error; err != nil {
+		return nil, fmt.Errorf("failed to read sqlite_master: %w", err)
+	}
+
+	dump := &schemaDump{}
+	var b strings.Builder
+	for _, o := range objects {
+		if slices.Contains(exclude, o.TblName) {
+			continue
+		}
+		switch o.Type {
+		case "table":
+			dump.Tables = append(dump.Tables, o.Name)
+			dump.Drops = append(dump.Drops, fmt.Sprintf("DROP TABLE IF EXISTS %s;", quotePostgresIdent(o.Name)))
+		case "view", "trigger":
+			// Indexes are dropped with their table
+			dump.Drops = append(dump.Drops, fmt.Sprintf("DROP %s IF EXISTS %s;", strings.ToUpper(o.Type), quotePostgresIdent(o.Name)))
+		}
+		b.WriteString(o.SQL + ";\n\n")
+	}
+	dump.SQL = strings.TrimSpace(b.String()) + "\n"
+	return dump, nil
+}
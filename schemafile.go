@@ -0,0 +1,302 @@
+package gormeasy
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// schemaFileApplied starts the header lines of a schema file naming the migrations applied
+// to the dumped database, one per line.
+const schemaFileApplied = "-- Applied migration: "
+
+// DumpSchema writes the DDL of the current database schema to path as a single SQL file: the
+// tables with their constraints and indexes, the sequences and the views, and on PostgreSQL
+// the extensions, enum types, functions and triggers. Stored routines and triggers of MySQL
+// are not dumped. The gormeasy history tables are left out, and every applied migration is
+// recorded in the header. The schema is read from the catalog, without pg_dump or mysqldump.
+func DumpSchema(db *gorm.DB, opts Options, migrations []*Migration, path string) error {
+	opts = opts.withDefaults()
+	dump, err := dumpSchema(db, historyTables(opts)...)
+	if err != nil {
+		return err
+	}
+	var appliedIDs []string
+	if db.Migrator().HasTable(opts.TableName) {
+		applied := getAppliedIDs(db, opts)
+		// In migration order, then the migrations unknown to migrations
+		for _, m := range migrations {
+			if applied[m.ID] {
+				appliedIDs = append(appliedIDs, m.ID)
+				delete(applied, m.ID)
+			}
+		}
+		unknown := slices.Sorted(maps.Keys(applied))
+		appliedIDs = append(appliedIDs, unknown...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create dir for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(schemaFile(db.Dialector.Name(), appliedIDs, dump.SQL)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	out.Printf("✅ Schema of %d tables written to %s\n", len(dump.Tables), path)
+	return nil
+}
+
+// schemaFile returns the schema file of the dialect database with the applied migrations,
+// with the non-empty SQL sections in order.
+func schemaFile(dialect string, applied []string, sections ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Schema of the %s database, written by gormeasy dump-schema.\n", dialect)
+	for _, id := range applied {
+		b.WriteString(schemaFileApplied + id + "\n")
+	}
+	for _, section := range sections {
+		if section = strings.TrimSpace(section); section != "" {
+			b.WriteString("\n" + section + "\n")
+		}
+	}
+	return b.String()
+}
+
+// dumpTypes returns the SQL creating the extensions and the enum types of the current schema
+// on PostgreSQL, which the tables may use, and the statements dropping the enum types.
+// Extensions are created if missing and never dropped, as other schemas may use them.
+// Other databases have none.
+func dumpTypes(db *gorm.DB) (string, []string, error) {
+	if db.Dialector.Name() != "postgres" {
+		return "", nil, nil
+	}
+	var b strings.Builder
+	var extensions []string
+	if err := db.Raw("SELECT extname FROM pg_extension WHERE extname <> 'plpgsql' ORDER BY extname").Scan(&extensions).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	for _, extension := range extensions {
+		fmt.Fprintf(&b, "CREATE EXTENSION IF NOT EXISTS %s;\n", quotePostgresIdent(extension))
+	}
+
+	var enums []struct {
+		Name   string
+		Labels string
+	}
+	// Types of extensions are created by the extension itself
+	if err := db.Raw(`SELECT t.typname AS name, string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder) AS labels
+		FROM pg_type t
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE n.nspname = current_schema()
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = t.oid AND d.deptype = 'e')
+		GROUP BY t.typname
+		ORDER BY t.typname`).Scan(&enums).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to list enum types: %w", err)
+	}
+	var drops []string
+	for _, e := range enums {
+		fmt.Fprintf(&b, "CREATE TYPE %s AS ENUM (%s);\n", quotePostgresIdent(e.Name), e.Labels)
+		drops = append(drops, fmt.Sprintf("DROP TYPE IF EXISTS %s;", quotePostgresIdent(e.Name)))
+	}
+	return b.String(), drops, nil
+}
+
+// dumpFunctions returns the SQL creating the functions and procedures of the current schema
+// on PostgreSQL, before the tables whose defaults and triggers may call them, and the
+// statements dropping them. Function bodies are not checked while loading, as they may select
+// from tables created later.
+func dumpFunctions(db *gorm.DB) (string, []string, error) {
+	if db.Dialector.Name() != "postgres" {
+		return "", nil, nil
+	}
+	var functions []struct {
+		Name       string
+		Kind       string
+		Arguments  string
+		Definition string
+	}
+	if err := db.Raw(`SELECT p.proname AS name, p.prokind::text AS kind,
+		pg_get_function_identity_arguments(p.oid) AS arguments, pg_get_functiondef(p.oid) AS definition
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE p.prokind IN ('f', 'p') AND n.nspname = current_schema()
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')
+		ORDER BY p.oid`).Scan(&functions).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to list functions: %w", err)
+	}
+	if len(functions) == 0 {
+		return "", nil, nil
+	}
+	var b strings.Builder
+	var drops []string
+	b.WriteString("SET check_function_bodies = false;\n\n")
+	for _, f := range functions {
+		b.WriteString(strings.TrimSpace(f.Definition) + ";\n\n")
+		kind := "FUNCTION"
+		if f.Kind == "p" {
+			kind = "PROCEDURE"
+		}
+		drops = append(drops, fmt.Sprintf("DROP %s IF EXISTS %s(%s);", kind, quotePostgresIdent(f.Name), f.Arguments))
+	}
+	return b.String(), drops, nil
+}
+
+// dumpTriggers returns the SQL creating the triggers of the tables of the current schema on
+// PostgreSQL, after the tables and views, and the statements dropping them. SQLite triggers
+// are part of dumpSchema.
+func dumpTriggers(db *gorm.DB) (string, []string, error) {
+	if db.Dialector.Name() != "postgres" {
+		return "", nil, nil
+	}
+	var triggers []struct {
+		Name       string
+		Table      string
+		Definition string
+	}
+	if err := db.Raw(`SELECT t.tgname AS name, c.relname AS "table", pg_get_triggerdef(t.oid) AS definition
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal AND n.nspname = current_schema()
+		ORDER BY t.oid`).Scan(&triggers).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+	var b strings.Builder
+	var drops []string
+	for _, t := range triggers {
+		b.WriteString(t.Definition + ";\n")
+		drops = append(drops, fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s;", quotePostgresIdent(t.Name), quotePostgresIdent(t.Table)))
+	}
+	return b.String(), drops, nil
+}
+
+// mysqlDefiner matches the DEFINER clause of SHOW CREATE VIEW, naming a user that may not
+// exist where the schema is loaded.
+var mysqlDefiner = regexp.MustCompile(` DEFINER=\S+`)
+
+// dumpViews returns the SQL creating the views of the current schema, and on PostgreSQL the
+// materialized views, without data, with their indexes, and the statements dropping them.
+// SQLite views are part of dumpSchema.
+func dumpViews(db *gorm.DB) (string, []string, error) {
+	var b strings.Builder
+	var drops []string
+	switch db.Dialector.Name() {
+	case "postgres":
+		var views []struct {
+			OID        uint32
+			Name       string
+			Kind       string
+			Definition string
+		}
+		// In creation order, so views are created after the views they select from
+		if err := db.Raw(`SELECT c.oid, c.relname AS name, c.relkind::text AS kind, pg_get_viewdef(c.oid) AS definition
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('v', 'm') AND n.nspname = current_schema()
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = c.oid AND d.deptype = 'e')
+			ORDER BY c.oid`).Scan(&views).Error; err != nil {
+			return "", nil, fmt.Errorf("failed to list views: %w", err)
+		}
+		for _, v := range views {
+			definition := strings.TrimSuffix(strings.TrimSpace(v.Definition), ";")
+			if v.Kind == "v" {
+				fmt.Fprintf(&b, "CREATE VIEW %s AS\n%s;\n\n", quotePostgresIdent(v.Name), definition)
+				drops = append(drops, fmt.Sprintf("DROP VIEW IF EXISTS %s;", quotePostgresIdent(v.Name)))
+				continue
+			}
+			fmt.Fprintf(&b, "CREATE MATERIALIZED VIEW %s AS\n%s\nWITH NO DATA;\n", quotePostgresIdent(v.Name), definition)
+			drops = append(drops, fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s;", quotePostgresIdent(v.Name)))
+			var indexes []string
+			if err := db.Raw("SELECT pg_get_indexdef(indexrelid) FROM pg_index WHERE indrelid = ? ORDER BY indexrelid", v.OID).
+				Scan(&indexes).Error; err != nil {
+				return "", nil, fmt.Errorf("failed to read indexes of %s: %w", v.Name, err)
+			}
+			for _, index := range indexes {
+				b.WriteString(index + ";\n")
+			}
+			b.WriteString("\n")
+		}
+	case "mysql":
+		var views []string
+		if err := db.Raw("SELECT TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME").
+			Scan(&views).Error; err != nil {
+			return "", nil, fmt.Errorf("failed to list views: %w", err)
+		}
+		for _, view := range views {
+			var name, ddl, charset, collation string
+			if err := db.Raw("SHOW CREATE VIEW "+quoteMySQLIdent(view)).Row().Scan(&name, &ddl, &charset, &collation); err != nil {
+				return "", nil, fmt.Errorf("failed to read DDL of %s: %w", view, err)
+			}
+			b.WriteString(mysqlDefiner.ReplaceAllString(ddl, "") + ";\n\n")
+			drops = append(drops, fmt.Sprintf("DROP VIEW IF EXISTS %s;", quoteMySQLIdent(view)))
+		}
+	}
+	return b.String(), drops, nil
+}
+
+// LoadSchema bootstraps an empty database from the schema file at path written by DumpSchema,
+// and records exactly the migrations named in its header as applied, so test databases skip
+// replaying every migration. Other migrations stay pending for the next up. The schema
+// and the history are written in one transaction where the database supports transactional DDL.
+func LoadSchema(db *gorm.DB, opts Options, migrations []*Migration, path string) error {
+	opts = opts.withDefaults()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	appliedIDs := schemaFileAppliedIDs(string(content))
+	if err := checkKnownMigrations(migrations, appliedIDs); err != nil {
+		return fmt.Errorf("%s records an %w", path, err)
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	history := historyTables(opts)
+	tables = slices.DeleteFunc(tables, func(table string) bool { return slices.Contains(history, table) })
+	if len(tables) > 0 {
+		return fmt.Errorf("the database already has tables (%s), load-schema only bootstraps empty databases", strings.Join(tables, ", "))
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := ExecSQL(tx, string(content)); err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if len(appliedIDs) == 0 {
+			return nil
+		}
+		// ensureHistoryTable would create the history table on the connection of the
+		// migration lock, outside the transaction
+		if err := upgradeHistoryTable(tx, opts); err != nil {
+			return fmt.Errorf("failed to migrate migrations table: %w", err)
+		}
+		return MarkApplied(tx, opts, appliedIDs...)
+	})
+	if err != nil {
+		return err
+	}
+	out.Println("✅ Schema loaded from:", path)
+	return nil
+}
+
+// schemaFileAppliedIDs returns the applied migrations recorded in the header of a schema
+// file, none when the dumped database had none applied.
+func schemaFileAppliedIDs(sql string) []string {
+	var ids []string
+	for _, line := range strings.Split(sql, "\n") {
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		if id, ok := strings.CutPrefix(line, schemaFileApplied); ok {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,98 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestSchemaFile tests the header and the sections of a dumped schema file
+func TestSchemaFile(t *testing.T) {
+	sql := schemaFile("postgres", []string{"20240101000000-create-users"},
+		"CREATE TYPE \"mood\" AS ENUM ('happy', 'sad');\n",
+		"CREATE TABLE \"users\" (\n    \"id\" bigint NOT NULL\n);\n",
+		"",
+		"CREATE VIEW \"active_users\" AS\nSELECT id FROM users;\n\n")
+	expected := "-- Schema of the postgres database, written by gormeasy dump-schema.\n" +
+		"-- Applied migration: 20240101000000-create-users\n" +
+		"\nCREATE TYPE \"mood\" AS ENUM ('happy', 'sad');\n" +
+		"\nCREATE TABLE \"users\" (\n    \"id\" bigint NOT NULL\n);\n" +
+		"\nCREATE VIEW \"active_users\" AS\nSELECT id FROM users;\n"
+	if sql != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, sql)
+	}
+
+	if sql := schemaFile("sqlite", nil, "CREATE TABLE users (id integer);"); sql != "-- Schema of the sqlite database, written by gormeasy dump-schema.\n\nCREATE TABLE users (id integer);\n" {
+		t.Errorf("Expected no migration line without applied migrations, got:\n%s", sql)
+	}
+}
+
+// TestSchemaFileAppliedIDs tests reading the applied migrations from the header of a schema file
+func TestSchemaFileAppliedIDs(t *testing.T) {
+	ids := []string{"20240101000000-create-users", "20240301000000-add-orders"}
+	sql := schemaFile("postgres", ids, "CREATE TABLE users (id bigint);\n-- Applied migration: 20990101000000-later\n")
+	if got := schemaFileAppliedIDs(sql); !slices.Equal(got, ids) {
+		t.Errorf("Expected %v, got %v", ids, got)
+	}
+	if got := schemaFileAppliedIDs(schemaFile("postgres", nil, "CREATE TABLE users (id bigint);\n-- Applied migration: 20990101000000-later\n")); got != nil {
+		t.Errorf("Expected no migration outside the header, got %v", got)
+	}
+}
+
+// TestLoadSchemaMarksAppliedIDs tests that a schema round trip records exactly the applied
+// migrations, leaving a skipped older migration pending
+func TestLoadSchemaMarksAppliedIDs(t *testing.T) {
+	migrations := []*Migration{
+		{ID: "20240101000000-create-users", Migrate: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE users (id integer PRIMARY KEY)").Error
+		}},
+		{ID: "20240201000000-backfill"},
+		{ID: "20240301000000-create-orders", Migrate: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE orders (id integer PRIMARY KEY)").Error
+		}},
+	}
+	source := openSQLite(t)
+	for _, m := range []*Migration{migrations[0], migrations[2]} {
+		if err := m.Migrate(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := MarkApplied(source, Options{}, migrations[0].ID, migrations[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := DumpSchema(source, Options{}, migrations, path); err != nil {
+		t.Fatal(err)
+	}
+
+	target := openSQLite(t)
+	if err := LoadSchema(target, Options{}, migrations, path); err != nil {
+		t.Fatal(err)
+	}
+	applied := getAppliedIDs(target, Options{}.withDefaults())
+	if !applied[migrations[0].ID] || applied[migrations[1].ID] || !applied[migrations[2].ID] {
+		t.Errorf("Expected only the dumped migrations to be applied, got %v", applied)
+	}
+	if !target.Migrator().HasTable("orders") {
+		t.Error("Expected the orders table to be loaded")
+	}
+}
+
+// TestLoadSchemaUnknownMigration tests that a schema file recording an unknown migration is rejected
+func TestLoadSchemaUnknownMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(schemaFile("postgres", []string{"20990101000000-unknown"}, "CREATE TABLE users (id bigint);")), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	migrations := []*Migration{{ID: "20240101000000-create-users"}}
+	err := LoadSchema(db, Options{}, migrations, path)
+	if err == nil || !strings.Contains(err.Error(), "unknown migration ID: 20990101000000-unknown") {
+		t.Errorf("Expected an unknown migration error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// The helpers in this file change the schema of existing tables by name, without model
+// structs, so migrations don't have to be raw SQL strings. They are idempotent: a helper
+// whose change is already in place does nothing, so a migration that failed halfway can be
+// run again.
+
+// ForeignKey describes a foreign key constraint added by AddForeignKey.
+type ForeignKey struct {
+	// Name is the constraint name, e.g. "fk_orders_user".
+	Name string
+	// Table and Columns are the referencing table and columns.
+	Table   string
+	Columns []string
+	// RefTable and RefColumns are the referenced table and columns.
+	RefTable   string
+	RefColumns []string
+	// OnDelete and OnUpdate are the referential actions, e.g. "CASCADE" or "SET NULL".
+	// Empty uses the database default (NO ACTION).
+	OnDelete string
+	OnUpdate string
+}
+
+// AddColumnIfNotExists adds column to table with the given type and constraints, e.g.
+// "varchar(32) NOT NULL DEFAULT 'new'", unless the column exists.
+func AddColumnIfNotExists(tx *gorm.DB, table, column, definition string) error {
+	if tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	addSQL := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", tx.Statement.Quote(table), tx.Statement.Quote(column), definition)
+	if err := tx.Exec(addSQL).Error; err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// DropColumnIfExists drops column from table if it exists. On SQLite versions without
+// DROP COLUMN, GORM recreates the table without the column.
+func DropColumnIfExists(tx *gorm.DB, table, column string) error {
+	if !tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	if err := tx.Migrator().DropColumn(table, column); err != nil {
+		return fmt.Errorf("failed to drop column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// RenameColumn renames column oldName of table to newName with RENAME COLUMN, which needs
+// MySQL 8.0 or later. It does nothing when newName exists and oldName doesn't, i.e. the
+// column was already renamed.
+func RenameColumn(tx *gorm.DB, table, oldName, newName string) error {
+	if !tx.Migrator().HasColumn(table, oldName) && tx.Migrator().HasColumn(table, newName) {
+		return nil
+	}
+	renameSQL := fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tx.Statement.Quote(table), tx.Statement.Quote(oldName), tx.Statement.Quote(newName))
+	if err := tx.Exec(renameSQL).Error; err != nil {
+		return fmt.Errorf("failed to rename column %s.%s to %s: %w", table, oldName, newName, err)
+	}
+	return nil
+}
+
+// RenameTable renames table oldName to newName. It does nothing when newName exists and
+// oldName doesn't, i.e. the table was already renamed.
+func RenameTable(tx *gorm.DB, oldName, newName string) error {
+	if !tx.Migrator().HasTable(oldName) && tx.Migrator().HasTable(newName) {
+		return nil
+	}
+	if err := tx.Migrator().RenameTable(oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename table %s to %s: %w", oldName, newName, err)
+	}
+	return nil
+}
+
+// AddForeignKey adds the foreign key constraint fk unless a constraint of that name exists
+// on its table. SQLite can't add constraints to existing tables and ClickHouse has no
+// foreign keys, so both return an error.
+func AddForeignKey(tx *gorm.DB, fk ForeignKey) error {
+	switch dialect := tx.Dialector.Name(); dialect {
+	case "sqlite", "clickhouse":
+		return fmt.Errorf("adding foreign key %s is not supported on %s, declare it when creating table %s", fk.Name, dialect, fk.Table)
+	}
+	if fk.Name == "" || len(fk.Columns) == 0 || len(fk.Columns) != len(fk.RefColumns) {
+		return fmt.Errorf("foreign key %q needs a name and as many columns as referenced columns", fk.Name)
+	}
+	if tx.Migrator().HasConstraint(fk.Table, fk.Name) {
+		return nil
+	}
+	if err := tx.Exec(foreignKeySQL(tx, fk)).Error; err != nil {
+		return fmt.Errorf("failed to add foreign key %s: %w", fk.Name, err)
+	}
+	return nil
+}
+
+// quoteColumns returns the quoted columns of names, separated by commas.
+func quoteColumns(tx *gorm.DB, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = tx.Statement.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// foreignKeySQL returns the ALTER TABLE statement adding fk.
+func foreignKeySQL(tx *gorm.DB, fk ForeignKey) string {
+	addSQL := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		tx.Statement.Quote(fk.Table), tx.Statement.Quote(fk.Name), quoteColumns(tx, fk.Columns), tx.Statement.Quote(fk.RefTable), quoteColumns(tx, fk.RefColumns))
+	if fk.OnDelete != "" {
+		addSQL += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		addSQL += " ON UPDATE " + fk.OnUpdate
+	}
+	return addSQL
+}
+
+// DropConstraintIfExists drops the constraint name (a foreign key, check or unique
+// constraint) from table if it exists.
+func DropConstraintIfExists(tx *gorm.DB, table, name string) error {
+	if !tx.Migrator().HasConstraint(table, name) {
+		return nil
+	}
+	if err := tx.Migrator().DropConstraint(table, name); err != nil {
+		return fmt.Errorf("failed to drop constraint %s: %w", name, err)
+	}
+	return nil
+}
+
+// CreateUniqueIndex creates the unique index name on the columns of table unless it exists.
+// It locks the table against writes while the index is built; use
+// CreateUniqueIndexConcurrently for large PostgreSQL tables.
+func CreateUniqueIndex(tx *gorm.DB, name, table string, columns ...string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("index %s needs at least one column", name)
+	}
+	if tx.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	if err := tx.Exec(uniqueIndexSQL(tx, name, table, columns)).Error; err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+	return nil
+}
+
+// uniqueIndexSQL returns the statement creating the unique index name on the columns of table.
+func uniqueIndexSQL(tx *gorm.DB, name, table string, columns []string) string {
+	return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", tx.Statement.Quote(name), tx.Statement.Quote(table), quoteColumns(tx, columns))
+}
@@ -0,0 +1,52 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestForeignKeySQL tests the statement adding a foreign key
+func TestForeignKeySQL(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got := foreignKeySQL(db, ForeignKey{
+		Name:       "fk_orders_user",
+		Table:      "orders",
+		Columns:    []string{"user_id"},
+		RefTable:   "users",
+		RefColumns: []string{"id"},
+		OnDelete:   "CASCADE",
+	})
+	expected := "ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	if err := AddForeignKey(db, ForeignKey{Name: "fk_orders_user", Table: "orders", Columns: []string{"user_id"}, RefTable: "users"}); err == nil {
+		t.Error("Expected error for a foreign key without referenced columns, got nil")
+	}
+}
+
+// TestCreateUniqueIndexRequiresColumns tests that a unique index without columns is rejected
+func TestCreateUniqueIndexRequiresColumns(t *testing.T) {
+	if err := CreateUniqueIndex(nil, "idx_users_email", "users"); err == nil {
+		t.Error("Expected error for index without columns, got nil")
+	}
+}
+
+// TestUniqueIndexSQL tests that every column of a unique index is quoted
+func TestUniqueIndexSQL(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got := uniqueIndexSQL(db, "idx_orders_user_number", "orders", []string{"user_id", "order"})
+	expected := "CREATE UNIQUE INDEX `idx_orders_user_number` ON `orders` (`user_id`, `order`)"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,132 @@
+package gormeasy
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultSeedBatchSize is the batch size used by InsertBatches when the seed does not set one.
+const defaultSeedBatchSize = 1000
+
+// progressInterval is the minimum time between two progress lines of InsertBatches and Backfill.
+const progressInterval = 2 * time.Second
+
+// Seed is a named set of seed data written in Go.
+// Seeds can depend on other seeds, e.g. an orders seed that needs the users seed to run first.
+type Seed struct {
+	// Name identifies the seed and is referenced by the DependsOn of other seeds.
+	Name string
+	// DependsOn lists the names of the seeds that must run before this one.
+	DependsOn []string
+	// Transaction runs the whole seed inside a single transaction, so a failing seed leaves no partial data.
+	Transaction bool
+	// BatchSize is the number of rows inserted per statement by tx.Create and InsertBatches.
+	// Defaults to 1000 for InsertBatches and to GORM's default for tx.Create.
+	BatchSize int
+	// Run inserts the seed data.
+	Run func(tx *gorm.DB) error
+}
+
+// RunSeeds runs the seeds in dependency order.
+// Seeds without dependencies between them run in the order they are given.
+// Returns an error if a seed depends on an unknown seed, if the dependencies form a cycle,
+// or if any seed fails.
+func RunSeeds(db *gorm.DB, seeds []*Seed) error {
+	ordered, err := sortSeeds(seeds)
+	if err != nil {
+		return err
+	}
+
+	clock := clockOf(db)
+	out.Println("Running seeds...")
+	for _, s := range ordered {
+		if s.Run == nil {
+			return fmt.Errorf("seed %s has no Run function", s.Name)
+		}
+		start := clock.Now()
+		out.Printf("🌱 Seeding %s...\n", s.Name)
+		if err := runSeed(db, s); err != nil {
+			return fmt.Errorf("seed %s failed: %w", s.Name, err)
+		}
+		out.Printf("✅ Seeded %s in %s\n", s.Name, since(clock, start).Round(time.Millisecond))
+	}
+	out.Println("✅ Seeding complete.")
+	return nil
+}
+
+// runSeed runs a single seed with its batch size and transaction settings.
+func runSeed(db *gorm.DB, s *Seed) error {
+	tx := db.Session(&gorm.Session{CreateBatchSize: s.BatchSize})
+	if !s.Transaction {
+		return s.Run(tx)
+	}
+	return tx.Transaction(func(tx *gorm.DB) error {
+		return s.Run(tx)
+	})
+}
+
+// InsertBatches inserts a slice of rows in batches and prints progress while doing so.
+// The batch size comes from the BatchSize of the seed running tx, or 1000 when not set.
+// Use it instead of tx.Create for large seed datasets (100k+ rows).
+func InsertBatches(tx *gorm.DB, rows interface{}) error {
+	value := reflect.ValueOf(rows)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+
+	batchSize := tx.CreateBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultSeedBatchSize
+	}
+
+	total := value.Len()
+	clock := clockOf(tx)
+	lastReport := clock.Now()
+	for start := 0; start < total; start += batchSize {
+		end := min(start+batchSize, total)
+		batch := value.Slice(start, end)
+		// Pass a pointer to a fresh slice so GORM can write back generated primary keys
+		ptr := reflect.New(batch.Type())
+		ptr.Elem().Set(batch)
+		if err := tx.Create(ptr.Interface()).Error; err != nil {
+			return fmt.Errorf("failed to insert rows %d-%d: %w", start+1, end, err)
+		}
+		if since(clock, lastReport) >= progressInterval || end == total {
+			out.Printf("  - %d/%d rows\n", end, total)
+			lastReport = clock.Now()
+		}
+	}
+	return nil
+}
+
+// sortSeeds returns the seeds ordered so that every seed comes after its dependencies.
+func sortSeeds(seeds []*Seed) ([]*Seed, error) {
+	byName := make(map[string]*Seed, len(seeds))
+	names := make([]string, 0, len(seeds))
+	deps := make(map[string][]string, len(seeds))
+	for _, s := range seeds {
+		if s.Name == "" {
+			return nil, fmt.Errorf("seed name is required")
+		}
+		byName[s.Name] = s
+		names = append(names, s.Name)
+		deps[s.Name] = s.DependsOn
+	}
+
+	order, err := topologicalOrder("seed", names, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*Seed, 0, len(order))
+	for _, name := range order {
+		ordered = append(ordered, byName[name])
+	}
+	return ordered, nil
+}
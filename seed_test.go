@@ -0,0 +1,123 @@
+package gormeasy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TestSortSeedsDependencyOrder tests that seeds run after the seeds they depend on
+func TestSortSeedsDependencyOrder(t *testing.T) {
+	seeds := []*Seed{
+		{Name: "orders", DependsOn: []string{"users", "products"}},
+		{Name: "users"},
+		{Name: "products"},
+		{Name: "settings"},
+	}
+
+	ordered, err := sortSeeds(seeds)
+	if err != nil {
+		t.Fatalf("Failed to sort seeds: %v", err)
+	}
+
+	var names []string
+	for _, s := range ordered {
+		names = append(names, s.Name)
+	}
+	expected := "users,products,orders,settings"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Expected seed order %s, got %s", expected, got)
+	}
+}
+
+// TestSortSeedsCycle tests that dependency cycles are reported
+func TestSortSeedsCycle(t *testing.T) {
+	seeds := []*Seed{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	}
+
+	_, err := sortSeeds(seeds)
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+}
+
+// TestSortSeedsMissingDependency tests that unknown dependencies are reported
+func TestSortSeedsMissingDependency(t *testing.T) {
+	seeds := []*Seed{
+		{Name: "orders", DependsOn: []string{"users"}},
+	}
+
+	_, err := sortSeeds(seeds)
+	if err == nil || !strings.Contains(err.Error(), "depends on unknown seed users") {
+		t.Errorf("Expected missing dependency error, got %v", err)
+	}
+}
+
+// seedUser is a row inserted by the seeds of the RunSeeds tests
+type seedUser struct {
+	ID   int
+	Name string
+}
+
+// TestRunSeedsTwice tests that seeds inserting in batches with an upsert can run again
+// without duplicating rows
+func TestRunSeedsTwice(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.AutoMigrate(&seedUser{}); err != nil {
+		t.Fatal(err)
+	}
+	seeds := []*Seed{{
+		Name:        "users",
+		Transaction: true,
+		BatchSize:   2,
+		Run: func(tx *gorm.DB) error {
+			users := []seedUser{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}
+			return InsertBatches(tx.Clauses(clause.OnConflict{DoNothing: true}), users)
+		},
+	}}
+	for run := 1; run <= 2; run++ {
+		if err := RunSeeds(db, seeds); err != nil {
+			t.Fatalf("Expected run %d to succeed, got %v", run, err)
+		}
+		var count int64
+		if err := db.Model(&seedUser{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 5 {
+			t.Errorf("Expected 5 users after run %d, got %d", run, count)
+		}
+	}
+}
+
+// TestRunSeedsTransaction tests that a failing seed with Transaction leaves no partial data
+func TestRunSeedsTransaction(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.AutoMigrate(&seedUser{}); err != nil {
+		t.Fatal(err)
+	}
+	seeds := []*Seed{{
+		Name:        "users",
+		Transaction: true,
+		Run: func(tx *gorm.DB) error {
+			if err := tx.Create(&seedUser{ID: 1, Name: "a"}).Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	}}
+	if err := RunSeeds(db, seeds); err == nil || !strings.Contains(err.Error(), "seed users failed") {
+		t.Errorf("Expected the seed to fail, got %v", err)
+	}
+	var count int64
+	if err := db.Model(&seedUser{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no users after the rollback, got %d", count)
+	}
+}
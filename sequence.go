@@ -0,0 +1,57 @@
+package gormeasy
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ResetSequences sets the sequence of every serial and identity column of PostgreSQL tables
+// outside the system schemas to MAX(column) + 1, so inserts don't collide with rows that were
+// inserted with explicit IDs, e.g. by a bulk import, restore-data or seeds. MySQL and SQLite
+// move their auto-increment counters past explicit IDs by themselves, so there is nothing to do.
+func ResetSequences(db *gorm.DB) error {
+	switch dialectorName := db.Dialector.Name(); dialectorName {
+	case "postgres":
+	case "mysql", "sqlite":
+		out.Printf("✅ %s keeps auto-increment counters in sync, nothing to reset\n", dialectorName)
+		return nil
+	default:
+		return fmt.Errorf("resetting sequences is not supported for %s. Currently supported: PostgreSQL", dialectorName)
+	}
+
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		Sequence   string
+	}
+	err := db.Raw(`SELECT table_name, column_name, sequence FROM (
+			SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS table_name, a.attname AS column_name,
+				pg_get_serial_sequence(quote_ident(n.nspname) || '.' || quote_ident(c.relname), a.attname) AS sequence
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+				AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%'
+		) columns WHERE sequence IS NOT NULL ORDER BY table_name, column_name`).Scan(&columns).Error
+	if err != nil {
+		return fmt.Errorf("failed to list sequences: %w", err)
+	}
+
+	for _, col := range columns {
+		var next int64
+		if err := db.Raw(resetSequenceSQL(col.Sequence, col.TableName, col.ColumnName)).Scan(&next).Error; err != nil {
+			return fmt.Errorf("failed to reset sequence %s: %w", col.Sequence, err)
+		}
+		out.Printf("  - %s.%s: next value %d\n", col.TableName, col.ColumnName, next)
+	}
+	out.Printf("✅ Reset %d sequences\n", len(columns))
+	return nil
+}
+
+// resetSequenceSQL returns the query setting sequence to continue after the largest value
+// of column in table, a quoted and schema-qualified name. It returns the next value.
+func resetSequenceSQL(sequence, table, column string) string {
+	return fmt.Sprintf("SELECT setval(%s, COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+		quotePostgresString(sequence), quotePostgresIdent(column), table)
+}
@@ -0,0 +1,22 @@
+package gormeasy
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestResetSequenceSQL tests the query moving a sequence past the largest ID
+func TestResetSequenceSQL(t *testing.T) {
+	got := resetSequenceSQL("public.users_id_seq", "public.users", "id")
+	expected := `SELECT setval('public.users_id_seq', COALESCE((SELECT MAX("id") FROM public.users), 0) + 1, false)`
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	if err := ResetSequences(db); err == nil {
+		t.Error("Expected error on an unsupported database, got nil")
+	}
+}
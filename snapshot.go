@@ -0,0 +1,270 @@
+package gormeasy
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// csvNull marks NULL values in snapshot CSV files, like PostgreSQL COPY does.
+const csvNull = `\N`
+
+// csvBinaryPrefix starts binary values in snapshot CSV files, hex-encoded like PostgreSQL
+// writes bytea.
+const csvBinaryPrefix = `\x`
+
+// csvTimeFormat is the format of time values in snapshot CSV files.
+const csvTimeFormat = "2006-01-02 15:04:05.999999999Z07:00"
+
+// snapshotBatchSize is the number of rows inserted per statement when restoring a snapshot.
+const snapshotBatchSize = 1000
+
+// snapshotManifest describes the content of a data snapshot directory.
+type snapshotManifest struct {
+	Tables []string `json:"tables"`
+	// Types maps every table to the database types of its columns, e.g. "BYTEA", so values
+	// are restored with their type instead of as strings.
+	Types     map[string]map[string]string `json:"types,omitempty"`
+	CreatedAt time.Time                    `json:"created_at"`
+}
+
+// SnapshotData writes the rows of the given tables to CSV files in dir, one file per table,
+// together with a manifest.json that records the table order used by RestoreData.
+// List parent tables before the tables referencing them so the snapshot can be restored
+// without foreign key violations.
+func SnapshotData(db *gorm.DB, tables []string, dir string) error {
+	if len(tables) == 0 {
+		return fmt.Errorf("at least one table is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", dir, err)
+	}
+
+	types := make(map[string]map[string]string, len(tables))
+	for _, table := range tables {
+		count, columnTypes, err := snapshotTable(db, table, filepath.Join(dir, table+".csv"))
+		if err != nil {
+			return fmt.Errorf("failed to snapshot table %s: %w", table, err)
+		}
+		types[table] = columnTypes
+		out.Printf("  - %s: %d rows\n", table, count)
+	}
+
+	manifest, err := json.MarshalIndent(snapshotManifest{Tables: tables, Types: types, CreatedAt: clockOf(db).Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), manifest, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	out.Println("✅ Snapshot saved in:", dir)
+	return nil
+}
+
+// snapshotTable writes the rows of table to the CSV file path and returns their number and
+// the database types of the columns.
+func snapshotTable(db *gorm.DB, table, path string) (int, map[string]string, error) {
+	rows, err := db.Table(table).Rows()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, nil, err
+	}
+	columns := make([]string, len(columnTypes))
+	types := make(map[string]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+		types[ct.Name()] = strings.ToUpper(ct.DatabaseTypeName())
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(columns); err != nil {
+		return 0, nil, err
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, nil, err
+		}
+		for i, v := range values {
+			record[i] = formatCSVValue(v, isBinaryType(types[columns[i]]))
+		}
+		if err := w.Write(record); err != nil {
+			return count, nil, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, nil, err
+	}
+	w.Flush()
+	return count, types, w.Error()
+}
+
+// formatCSVValue converts a scanned database value to its CSV representation. Values of
+// binary columns are hex-encoded, since CSV cannot hold arbitrary bytes.
+func formatCSVValue(v any, binary bool) string {
+	switch v := v.(type) {
+	case nil:
+		return csvNull
+	case []byte:
+		if binary {
+			return csvBinaryPrefix + hex.EncodeToString(v)
+		}
+		return string(v)
+	case time.Time:
+		return v.Format(csvTimeFormat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseCSVValue converts the CSV representation s of a value of a column of the database
+// type dbType back to a value of that type. Unknown types, and values that don't parse,
+// are restored as strings, which the database converts itself.
+func parseCSVValue(s, dbType string) any {
+	if s == csvNull {
+		return nil
+	}
+	switch {
+	case isBinaryType(dbType):
+		if hexValue, ok := strings.CutPrefix(s, csvBinaryPrefix); ok {
+			if b, err := hex.DecodeString(hexValue); err == nil {
+				return b
+			}
+		}
+	case slices.Contains([]string{"BOOL", "BOOLEAN"}, dbType):
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case strings.Contains(dbType, "INT") || strings.Contains(dbType, "SERIAL"):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case slices.Contains([]string{"FLOAT", "FLOAT4", "FLOAT8", "REAL", "DOUBLE", "DOUBLE PRECISION"}, dbType):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case slices.Contains([]string{"DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ"}, dbType):
+		if t, err := time.Parse(csvTimeFormat, s); err == nil {
+			return t
+		}
+	}
+	return s
+}
+
+// isBinaryType reports whether dbType, a database type name like "BYTEA", holds bytes.
+func isBinaryType(dbType string) bool {
+	return dbType == "BYTEA" || strings.Contains(dbType, "BLOB") || strings.Contains(dbType, "BINARY")
+}
+
+// RestoreData restores a snapshot written by SnapshotData.
+// Existing rows of the snapshot tables are deleted first (children before parents),
+// then the rows are inserted in the order recorded in the manifest, all in one transaction.
+func RestoreData(db *gorm.DB, dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i := len(manifest.Tables) - 1; i >= 0; i-- {
+			table := manifest.Tables[i]
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s", tx.Statement.Quote(table))).Error; err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", table, err)
+			}
+		}
+		for _, table := range manifest.Tables {
+			count, err := restoreTable(tx, table, filepath.Join(dir, table+".csv"), manifest.Types[table])
+			if err != nil {
+				return fmt.Errorf("failed to restore table %s: %w", table, err)
+			}
+			out.Printf("  - %s: %d rows\n", table, count)
+		}
+		out.Println("✅ Snapshot restored from:", dir)
+		return nil
+	})
+}
+
+// restoreTable inserts the rows of the CSV file path into table, converting the values to
+// the database types of the columns in types.
+func restoreTable(tx *gorm.DB, table, path string, types map[string]string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	columns, err := r.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	count := 0
+	batch := make([]map[string]any, 0, snapshotBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(table).Create(&batch).Error; err != nil {
+			return err
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column] = parseCSVValue(record[i], types[column])
+		}
+		batch = append(batch, row)
+		if len(batch) == snapshotBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	return count, flush()
+}
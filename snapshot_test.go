@@ -0,0 +1,61 @@
+package gormeasy
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSnapshotRoundTrip tests that a snapshot restores binary, numeric, boolean, time and
+// NULL values unchanged
+func TestSnapshotRoundTrip(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.Exec(`CREATE TABLE files (id INTEGER PRIMARY KEY, name TEXT, content BLOB,
+		size REAL, public BOOLEAN, created_at DATETIME)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	content := []byte{0x00, 0xff, '\r', '\n', ',', '"', 0x80}
+	createdAt := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	rows := []map[string]any{
+		{"id": 1, "name": "logo.png", "content": content, "size": 1.5, "public": true, "created_at": createdAt},
+		{"id": 2, "name": nil, "content": nil, "size": nil, "public": false, "created_at": nil},
+	}
+	if err := db.Table("files").Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	if err := SnapshotData(db, []string{"files"}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("UPDATE files SET content = x'00', public = false, name = 'changed'").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreData(db, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	type file struct {
+		ID        int
+		Name      *string
+		Content   []byte
+		Size      *float64
+		Public    bool
+		CreatedAt *time.Time
+	}
+	var files []file
+	if err := db.Table("files").Order("id").Find(&files).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(files))
+	}
+	f := files[0]
+	if !bytes.Equal(f.Content, content) || f.Size == nil || *f.Size != 1.5 || !f.Public || f.CreatedAt == nil || !f.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected the first row to be restored unchanged, got %+v", f)
+	}
+	if f := files[1]; f.Name != nil || f.Content != nil || f.Size != nil || f.Public || f.CreatedAt != nil {
+		t.Errorf("Expected NULL values to be restored, got %+v", f)
+	}
+}
@@ -0,0 +1,477 @@
+package gormeasy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specLockFile is the file in the migrations directory recording the specs as of the last compile.
+const specLockFile = "schema.lock.yaml"
+
+// schemaSpec is a declarative description of tables, read from the YAML files of the specs directory.
+//
+//	tables:
+//	  - name: users
+//	    columns:
+//	      - {name: id, type: bigserial, primary_key: true}
+//	      - {name: email, type: varchar(255), not_null: true}
+//	      - {name: role, type: varchar(64), default: "'customer'"}
+//	    indexes:
+//	      - {columns: [email], unique: true}
+type schemaSpec struct {
+	Tables []tableSpec `yaml:"tables"`
+}
+
+type tableSpec struct {
+	Name    string       `yaml:"name"`
+	Columns []columnSpec `yaml:"columns"`
+	Indexes []indexSpec  `yaml:"indexes,omitempty"`
+}
+
+type columnSpec struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`
+	PrimaryKey bool   `yaml:"primary_key,omitempty"`
+	NotNull    bool   `yaml:"not_null,omitempty"`
+	// Default is a SQL expression, string literals need their own quotes.
+	Default string `yaml:"default,omitempty"`
+}
+
+type indexSpec struct {
+	// Name defaults to idx_<table>_<columns>.
+	Name    string   `yaml:"name,omitempty"`
+	Columns []string `yaml:"columns"`
+	Unique  bool     `yaml:"unique,omitempty"`
+}
+
+// table returns the table spec with the given name, or nil if there is none.
+func (s *schemaSpec) table(name string) *tableSpec {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+func (t *tableSpec) column(name string) *columnSpec {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (t *tableSpec) index(name string) *indexSpec {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			return &t.Indexes[i]
+		}
+	}
+	return nil
+}
+
+// primaryKey returns the primary key columns of t.
+func (t *tableSpec) primaryKey() []string {
+	var columns []string
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			columns = append(columns, c.Name)
+		}
+	}
+	return columns
+}
+
+// loadSpecs reads and validates every .yaml and .yml file of dir, in file name order.
+func loadSpecs(dir string) (*schemaSpec, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read specs dir %s: %w", dir, err)
+	}
+	spec := &schemaSpec{}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var file schemaSpec
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		spec.Tables = append(spec.Tables, file.Tables...)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// validate checks the spec for missing names and types, duplicates and unknown index
+// columns, and fills in default index names.
+func (s *schemaSpec) validate() error {
+	tables := make(map[string]bool)
+	for ti := range s.Tables {
+		t := &s.Tables[ti]
+		if t.Name == "" {
+			return fmt.Errorf("table %d has no name", ti+1)
+		}
+		if tables[t.Name] {
+			return fmt.Errorf("table %s is defined more than once", t.Name)
+		}
+		tables[t.Name] = true
+		if len(t.Columns) == 0 {
+			return fmt.Errorf("table %s has no columns", t.Name)
+		}
+
+		columns := make(map[string]bool)
+		for _, c := range t.Columns {
+			if c.Name == "" || c.Type == "" {
+				return fmt.Errorf("table %s: every column needs a name and a type", t.Name)
+			}
+			if columns[c.Name] {
+				return fmt.Errorf("table %s: column %s is defined more than once", t.Name, c.Name)
+			}
+			columns[c.Name] = true
+		}
+
+		indexes := make(map[string]bool)
+		for i := range t.Indexes {
+			index := &t.Indexes[i]
+			if len(index.Columns) == 0 {
+				return fmt.Errorf("table %s: index %d has no columns", t.Name, i+1)
+			}
+			for _, c := range index.Columns {
+				if !columns[c] {
+					return fmt.Errorf("table %s: index on unknown column %s", t.Name, c)
+				}
+			}
+			if index.Name == "" {
+				index.Name = "idx_" + t.Name + "_" + strings.Join(index.Columns, "_")
+			}
+			if indexes[index.Name] {
+				return fmt.Errorf("table %s: index %s is defined more than once", t.Name, index.Name)
+			}
+			indexes[index.Name] = true
+		}
+	}
+	return nil
+}
+
+// loadSpecLock reads the specs recorded by the last compile. A missing file is an empty schema.
+func loadSpecLock(path string) (*schemaSpec, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &schemaSpec{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	spec := &schemaSpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// specDialect generates the SQL of spec changes for one database.
+type specDialect struct {
+	name string
+}
+
+func (d specDialect) quote(name string) string {
+	if d.name == "mysql" {
+		return quoteMySQLIdent(name)
+	}
+	return quotePostgresIdent(name)
+}
+
+func (d specDialect) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = d.quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (d specDialect) columnDefinition(c columnSpec) string {
+	def := d.quote(c.Name) + " " + c.Type
+	if c.NotNull {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
+
+func (d specDialect) createTable(t tableSpec) []string {
+	lines := make([]string, 0, len(t.Columns)+1)
+	for _, c := range t.Columns {
+		lines = append(lines, d.columnDefinition(c))
+	}
+	if pk := t.primaryKey(); len(pk) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+d.quoteList(pk)+")")
+	}
+	stmts := []string{fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", d.quote(t.Name), strings.Join(lines, ",\n    "))}
+	for _, index := range t.Indexes {
+		stmts = append(stmts, d.createIndex(t.Name, index))
+	}
+	return stmts
+}
+
+func (d specDialect) createIndex(table string, index indexSpec) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, d.quote(index.Name), d.quote(table), d.quoteList(index.Columns))
+}
+
+func (d specDialect) dropIndex(table string, index indexSpec) string {
+	if d.name == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s", d.quote(index.Name), d.quote(table))
+	}
+	return "DROP INDEX " + d.quote(index.Name)
+}
+
+// alterColumn returns the statements changing column from to to.
+func (d specDialect) alterColumn(table string, from, to columnSpec) ([]string, error) {
+	prefix := fmt.Sprintf("ALTER TABLE %s ", d.quote(table))
+	switch d.name {
+	case "postgres":
+		column := d.quote(to.Name)
+		var stmts []string
+		if from.Type != to.Type {
+			stmts = append(stmts, fmt.Sprintf("%sALTER COLUMN %s TYPE %s", prefix, column, to.Type))
+		}
+		if from.Default != to.Default {
+			if to.Default == "" {
+				stmts = append(stmts, fmt.Sprintf("%sALTER COLUMN %s DROP DEFAULT", prefix, column))
+			} else {
+				stmts = append(stmts, fmt.Sprintf("%sALTER COLUMN %s SET DEFAULT %s", prefix, column, to.Default))
+			}
+		}
+		if from.NotNull != to.NotNull {
+			action := "DROP NOT NULL"
+			if to.NotNull {
+				action = "SET NOT NULL"
+			}
+			stmts = append(stmts, fmt.Sprintf("%sALTER COLUMN %s %s", prefix, column, action))
+		}
+		return stmts, nil
+	case "mysql":
+		return []string{prefix + "MODIFY COLUMN " + d.columnDefinition(to)}, nil
+	default:
+		return nil, fmt.Errorf("%s cannot alter column %s.%s, write a migration by hand", d.name, table, to.Name)
+	}
+}
+
+// specChange is one change between two specs with the statements applying and reverting it.
+type specChange struct {
+	up   []string
+	down []string
+}
+
+// diffSpecs returns the statements migrating the schema described by from to the one
+// described by to, and the statements reverting them. Renames are not detected: a renamed
+// table or column is dropped and created again.
+func diffSpecs(from, to *schemaSpec, dialect string) (up, down []string, err error) {
+	switch dialect {
+	case "postgres", "mysql", "sqlite":
+	default:
+		return nil, nil, fmt.Errorf("compile does not support %s. Currently supported: postgres, mysql, sqlite", dialect)
+	}
+	d := specDialect{name: dialect}
+	var changes []specChange
+
+	for _, t := range to.Tables {
+		old := from.table(t.Name)
+		if old == nil {
+			changes = append(changes, specChange{
+				up:   d.createTable(t),
+				down: []string{"DROP TABLE " + d.quote(t.Name)},
+			})
+			continue
+		}
+		tableChanges, err := diffTable(d, *old, t)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, tableChanges...)
+	}
+	for i := len(from.Tables) - 1; i >= 0; i-- {
+		t := from.Tables[i]
+		if to.table(t.Name) == nil {
+			changes = append(changes, specChange{
+				up:   []string{"DROP TABLE " + d.quote(t.Name)},
+				down: d.createTable(t),
+			})
+		}
+	}
+
+	for _, c := range changes {
+		up = append(up, c.up...)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		down = append(down, changes[i].down...)
+	}
+	return up, down, nil
+}
+
+// diffTable returns the changes of a table present in both specs.
+func diffTable(d specDialect, from, to tableSpec) ([]specChange, error) {
+	if !slices.Equal(from.primaryKey(), to.primaryKey()) {
+		return nil, fmt.Errorf("table %s: compile cannot change a primary key, write a migration by hand", to.Name)
+	}
+	prefix := fmt.Sprintf("ALTER TABLE %s ", d.quote(to.Name))
+	var changes []specChange
+
+	// Indexes are dropped before the columns they use
+	for _, index := range from.Indexes {
+		if next := to.index(index.Name); next == nil || !slices.Equal(next.Columns, index.Columns) || next.Unique != index.Unique {
+			changes = append(changes, specChange{
+				up:   []string{d.dropIndex(to.Name, index)},
+				down: []string{d.createIndex(to.Name, index)},
+			})
+		}
+	}
+	for _, c := range from.Columns {
+		if to.column(c.Name) == nil {
+			changes = append(changes, specChange{
+				up:   []string{prefix + "DROP COLUMN " + d.quote(c.Name)},
+				down: []string{prefix + "ADD COLUMN " + d.columnDefinition(c)},
+			})
+		}
+	}
+	for _, c := range to.Columns {
+		old := from.column(c.Name)
+		if old == nil {
+			changes = append(changes, specChange{
+				up:   []string{prefix + "ADD COLUMN " + d.columnDefinition(c)},
+				down: []string{prefix + "DROP COLUMN " + d.quote(c.Name)},
+			})
+			continue
+		}
+		if *old == c {
+			continue
+		}
+		up, err := d.alterColumn(to.Name, *old, c)
+		if err != nil {
+			return nil, err
+		}
+		down, err := d.alterColumn(to.Name, c, *old)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, specChange{up: up, down: down})
+	}
+	for _, index := range to.Indexes {
+		if old := from.index(index.Name); old == nil || !slices.Equal(old.Columns, index.Columns) || old.Unique != index.Unique {
+			changes = append(changes, specChange{
+				up:   []string{d.createIndex(to.Name, index)},
+				down: []string{d.dropIndex(to.Name, index)},
+			})
+		}
+	}
+	return changes, nil
+}
+
+const compiledMigration = `// Code generated by gormeasy compile. DO NOT EDIT.
+
+package {{PACKAGE}}
+
+import (
+	"github.com/ymzuiku/gormeasy"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Registry.Register(&gormeasy.Migration{
+		ID: "{{ID}}",
+		Migrate: func(tx *gorm.DB) error {
+			return gormeasy.ExecSQL(tx, {{UP}})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return gormeasy.ExecSQL(tx, {{DOWN}})
+		},
+	})
+}
+`
+
+// sqlLiteral returns a Go string literal of the script, a raw string when possible.
+func sqlLiteral(stmts []string) string {
+	script := strings.Join(stmts, ";\n") + ";"
+	if strings.Contains(script, "`") {
+		return strconv.Quote(script)
+	}
+	return "`\n" + script + "\n`"
+}
+
+// compileSpecs diffs the specs in specDir against the specs recorded by the last compile in
+// migrationsDir and writes the difference as a new migration of the migrations package,
+// then records the current specs. It returns the path of the migration, or an empty string
+// when nothing changed.
+func compileSpecs(specDir, migrationsDir, dialect, name string, ids IDGenerator) (string, error) {
+	spec, err := loadSpecs(specDir)
+	if err != nil {
+		return "", err
+	}
+	lockPath := filepath.Join(migrationsDir, specLockFile)
+	last, err := loadSpecLock(lockPath)
+	if err != nil {
+		return "", err
+	}
+	up, down, err := diffSpecs(last, spec, dialect)
+	if err != nil {
+		return "", err
+	}
+	if len(up) == 0 {
+		out.Println("✅ Specs are unchanged since the last compile")
+		return "", nil
+	}
+
+	absDir, err := filepath.Abs(migrationsDir)
+	if err != nil {
+		return "", err
+	}
+	id := ids.NewID(name)
+	path := filepath.Join(migrationsDir, id+".go")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("file already exists: %s", path)
+	}
+	content := strings.NewReplacer(
+		"{{PACKAGE}}", filepath.Base(absDir),
+		"{{ID}}", id,
+		"{{UP}}", sqlLiteral(up),
+		"{{DOWN}}", sqlLiteral(down),
+	).Replace(compiledMigration)
+
+	lock, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode specs: %w", err)
+	}
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create dir %s: %w", migrationsDir, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.WriteFile(lockPath, lock, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", lockPath, err)
+	}
+	out.Println("✅ Created:", path)
+	out.Println("✅ Updated:", lockPath)
+	return path, nil
+}
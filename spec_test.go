@@ -0,0 +1,155 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func usersSpec() *schemaSpec {
+	return &schemaSpec{Tables: []tableSpec{{
+		Name: "users",
+		Columns: []columnSpec{
+			{Name: "id", Type: "bigserial", PrimaryKey: true},
+			{Name: "email", Type: "varchar(255)", NotNull: true},
+		},
+		Indexes: []indexSpec{{Name: "idx_users_email", Columns: []string{"email"}, Unique: true}},
+	}}}
+}
+
+// TestDiffSpecsCreateTable tests that new tables are created and dropped on rollback
+func TestDiffSpecsCreateTable(t *testing.T) {
+	up, down, err := diffSpecs(&schemaSpec{}, usersSpec(), "postgres")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{
+		"CREATE TABLE \"users\" (\n    \"id\" bigserial,\n    \"email\" varchar(255) NOT NULL,\n    PRIMARY KEY (\"id\")\n)",
+		`CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email")`,
+	}
+	if !slices.Equal(up, want) {
+		t.Errorf("Expected %q, got %q", want, up)
+	}
+	if !slices.Equal(down, []string{`DROP TABLE "users"`}) {
+		t.Errorf("Expected DROP TABLE, got %q", down)
+	}
+}
+
+// TestDiffSpecsAlterTable tests added, changed and removed columns and indexes
+func TestDiffSpecsAlterTable(t *testing.T) {
+	next := usersSpec()
+	users := &next.Tables[0]
+	users.Columns[1].Default = "''"
+	users.Columns = append(users.Columns, columnSpec{Name: "name", Type: "text"})
+	users.Indexes = nil
+
+	up, down, err := diffSpecs(usersSpec(), next, "postgres")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantUp := []string{
+		`DROP INDEX "idx_users_email"`,
+		`ALTER TABLE "users" ALTER COLUMN "email" SET DEFAULT ''`,
+		`ALTER TABLE "users" ADD COLUMN "name" text`,
+	}
+	if !slices.Equal(up, wantUp) {
+		t.Errorf("Expected %q, got %q", wantUp, up)
+	}
+	wantDown := []string{
+		`ALTER TABLE "users" DROP COLUMN "name"`,
+		`ALTER TABLE "users" ALTER COLUMN "email" DROP DEFAULT`,
+		`CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email")`,
+	}
+	if !slices.Equal(down, wantDown) {
+		t.Errorf("Expected %q, got %q", wantDown, down)
+	}
+
+	up, _, err = diffSpecs(usersSpec(), next, "mysql")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if up[1] != "ALTER TABLE `users` MODIFY COLUMN `email` varchar(255) NOT NULL DEFAULT ''" {
+		t.Errorf("Expected MODIFY COLUMN on MySQL, got %q", up[1])
+	}
+
+	if _, _, err := diffSpecs(usersSpec(), next, "sqlite"); err == nil {
+		t.Error("Expected error altering a column on SQLite, got nil")
+	}
+}
+
+// TestDiffSpecsUnchanged tests that identical specs produce no statements
+func TestDiffSpecsUnchanged(t *testing.T) {
+	up, down, err := diffSpecs(usersSpec(), usersSpec(), "postgres")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(up) != 0 || len(down) != 0 {
+		t.Errorf("Expected no statements, got %q and %q", up, down)
+	}
+}
+
+// TestSpecValidate tests spec validation and default index names
+func TestSpecValidate(t *testing.T) {
+	spec := &schemaSpec{Tables: []tableSpec{{
+		Name:    "orders",
+		Columns: []columnSpec{{Name: "user_id", Type: "bigint"}},
+		Indexes: []indexSpec{{Columns: []string{"user_id"}}},
+	}}}
+	if err := spec.validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if spec.Tables[0].Indexes[0].Name != "idx_orders_user_id" {
+		t.Errorf("Expected default index name 'idx_orders_user_id', got '%s'", spec.Tables[0].Indexes[0].Name)
+	}
+
+	spec.Tables[0].Indexes[0].Columns = []string{"missing"}
+	if err := spec.validate(); err == nil {
+		t.Error("Expected error for an index on an unknown column, got nil")
+	}
+}
+
+// TestCompileSpecs tests that compile writes a migration and the lock file only when specs change
+func TestCompileSpecs(t *testing.T) {
+	dir := t.TempDir()
+	specDir := filepath.Join(dir, "schema")
+	migrationsDir := filepath.Join(dir, "migrations")
+	if err := os.MkdirAll(specDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	spec := `tables:
+  - name: users
+    columns:
+      - {name: id, type: bigserial, primary_key: true}
+      - {name: email, type: varchar(255), not_null: true}
+`
+	if err := os.WriteFile(filepath.Join(specDir, "users.yaml"), []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ids := TimestampIDGenerator{Clock: fixedClock(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))}
+
+	path, err := compileSpecs(specDir, migrationsDir, "postgres", "create users", ids)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filepath.Base(path) != "20240301000000-create-users.go" {
+		t.Errorf("Expected migration file 20240301000000-create-users.go, got %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `CREATE TABLE "users"`) || !strings.Contains(string(content), `DROP TABLE "users";`) {
+		t.Errorf("Expected the migration to create and drop users, got:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(migrationsDir, specLockFile)); err != nil {
+		t.Errorf("Expected %s to be written, got %v", specLockFile, err)
+	}
+
+	path, err = compileSpecs(specDir, migrationsDir, "postgres", "again", ids)
+	if err != nil || path != "" {
+		t.Errorf("Expected no migration for unchanged specs, got %q and %v", path, err)
+	}
+}
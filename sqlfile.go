@@ -0,0 +1,146 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm"
+)
+
+// sqlVarsKey is the context key of the template variables of SQL migrations.
+type sqlVarsKey struct{}
+
+// withSQLVars returns a session of db carrying vars for ExecSQLTemplate.
+func withSQLVars(db *gorm.DB, vars map[string]string) *gorm.DB {
+	if len(vars) == 0 {
+		return db
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, sqlVarsKey{}, vars))
+}
+
+// renderSQL executes script as a Go template with vars as data, e.g. {{ .Schema }}.
+// The env function reads environment variables: {{ env "APP_ROLE" }}. Unknown variables
+// are an error, so a typo never produces an empty identifier.
+func renderSQL(script string, vars map[string]string) (string, error) {
+	if !strings.Contains(script, "{{") {
+		return script, nil
+	}
+	tmpl, err := template.New("sql").Option("missingkey=error").Funcs(template.FuncMap{"env": os.Getenv}).Parse(script)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse SQL template: %w", err)
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render SQL template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// ExecSQLTemplate renders script as a Go template and executes it with ExecSQL. Variables
+// like {{ .Schema }} or {{ .Env }} come from Options.SQLVars and the sql_vars key of
+// gormeasy.json, resolved when the migration runs, so the same SQL works across schemas
+// and environments.
+func ExecSQLTemplate(tx *gorm.DB, script string) error {
+	var vars map[string]string
+	if tx.Statement.Context != nil {
+		vars, _ = tx.Statement.Context.Value(sqlVarsKey{}).(map[string]string)
+	}
+	rendered, err := renderSQL(script, vars)
+	if err != nil {
+		return err
+	}
+	return ExecSQL(tx, rendered)
+}
+
+// ExecSQLFile reads the SQL file name from fsys, typically an embed.FS, and executes it with
+// ExecSQLTemplate, so view and function definitions can live in reviewed SQL files referenced
+// from Go migrations:
+//
+//	//go:embed sql
+//	var sqlFiles embed.FS
+//
+//	Migrate: func(tx *gorm.DB) error {
+//		return gormeasy.ExecSQLFile(tx, sqlFiles, "sql/001_views.sql")
+//	},
+func ExecSQLFile(tx *gorm.DB, fsys fs.FS, name string) error {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := ExecSQLTemplate(tx, string(content)); err != nil {
+		return fmt.Errorf("failed to execute %s: %w", name, err)
+	}
+	return nil
+}
+
+// LoadSQLMigrations reads SQL-file migrations from dir of fsys, typically an embed.FS.
+// Each migration is a <ID>.up.sql file with an optional <ID>.down.sql file for the rollback.
+// Both are executed with ExecSQLTemplate, so they may use template variables.
+// Migrations are returned sorted by ID.
+func LoadSQLMigrations(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SQL migrations dir %s: %w", dir, err)
+	}
+
+	byID := make(map[string]*Migration)
+	var downs []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		script := string(content)
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			id := strings.TrimSuffix(name, ".up.sql")
+			byID[id] = &Migration{ID: id, Migrate: func(tx *gorm.DB) error {
+				return ExecSQLTemplate(tx, script)
+			}}
+		case strings.HasSuffix(name, ".down.sql"):
+			downs = append(downs, name)
+		default:
+			return nil, fmt.Errorf("SQL migration %s must be named <ID>.up.sql or <ID>.down.sql", name)
+		}
+	}
+
+	for _, name := range downs {
+		id := strings.TrimSuffix(name, ".down.sql")
+		m, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%s has no matching %s.up.sql", name, id)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		script := string(content)
+		m.Rollback = func(tx *gorm.DB) error {
+			return ExecSQLTemplate(tx, script)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byID))
+	for _, m := range byID {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].ID < migrations[j].ID })
+	return migrations, nil
+}
@@ -0,0 +1,97 @@
+package gormeasy
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestRenderSQL tests template variables in SQL scripts
+func TestRenderSQL(t *testing.T) {
+	t.Setenv("APP_ROLE", "app_rw")
+	got, err := renderSQL(`CREATE TABLE {{ .Schema }}.users (id int); GRANT SELECT ON {{ .Schema }}.users TO {{ env "APP_ROLE" }};`, map[string]string{"Schema": "billing"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := `CREATE TABLE billing.users (id int); GRANT SELECT ON billing.users TO app_rw;`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if _, err := renderSQL("SELECT * FROM {{ .Shcema }}.users", map[string]string{"Schema": "billing"}); err == nil {
+		t.Error("Expected error for an unknown variable, got nil")
+	}
+
+	plain := "SELECT '{' || '}'"
+	if got, err := renderSQL(plain, nil); err != nil || got != plain {
+		t.Errorf("Expected scripts without templates unchanged, got %q, %v", got, err)
+	}
+}
+
+// TestLoadSQLMigrations tests loading up and down SQL files
+func TestLoadSQLMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/2-orders.up.sql":  {Data: []byte("CREATE TABLE orders (id int)")},
+		"sql/1-users.up.sql":   {Data: []byte("CREATE TABLE {{ .Schema }}.users (id int)")},
+		"sql/1-users.down.sql": {Data: []byte("DROP TABLE {{ .Schema }}.users")},
+		"sql/README.md":        {Data: []byte("notes")},
+	}
+	migrations, err := LoadSQLMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].ID != "1-users" || migrations[1].ID != "2-orders" {
+		t.Fatalf("Expected migrations [1-users 2-orders], got %v", migrations)
+	}
+	if migrations[0].Rollback == nil || migrations[1].Rollback != nil {
+		t.Error("Expected only 1-users to have a rollback")
+	}
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	captured, err := captureSQL(withSQLVars(db, map[string]string{"Schema": "billing"}), migrations[:1])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stmts := captured[0].Statements; len(stmts) != 1 || stmts[0] != "CREATE TABLE billing.users (id int)" {
+		t.Errorf("Expected the rendered CREATE TABLE, got %v", stmts)
+	}
+
+	fsys["sql/3-missing.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE missing")}
+	if _, err := LoadSQLMigrations(fsys, "sql"); err == nil {
+		t.Error("Expected error for a down file without up file, got nil")
+	}
+}
+
+// TestExecSQLFile tests that SQL files are split into statements, keeping function bodies intact
+func TestExecSQLFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/001_views.sql": {Data: []byte(`CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+  NEW.updated_at = now();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE VIEW active_users AS SELECT * FROM users WHERE active;`)},
+	}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	captured, err := captureSQL(db, []*Migration{{ID: "1", Migrate: func(tx *gorm.DB) error {
+		return ExecSQLFile(tx, fsys, "sql/001_views.sql")
+	}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stmts := captured[0].Statements; len(stmts) != 2 {
+		t.Errorf("Expected 2 statements, got %d: %v", len(stmts), stmts)
+	}
+
+	if err := ExecSQLFile(db, fsys, "sql/missing.sql"); err == nil {
+		t.Error("Expected error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,125 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ExecSQL splits a SQL script into statements and executes them one by one.
+// Quoted strings, quoted identifiers, comments and PostgreSQL dollar-quoted bodies
+// ($$ ... $$) are handled, so function definitions containing semicolons are kept intact.
+func ExecSQL(tx *gorm.DB, script string) error {
+	for i, stmt := range splitSQLStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("statement %d failed: %w\n%s", i+1, err, stmt)
+		}
+	}
+	return nil
+}
+
+// splitSQLStatements splits a SQL script on top-level semicolons.
+// Empty statements and statements containing only comments are dropped.
+func splitSQLStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		hasCode    bool
+	)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" && hasCode {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); {
+		ch := script[i]
+		switch {
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := i + 1
+			for end < len(script) {
+				if script[end] == ch {
+					// A doubled quote is an escaped quote inside the literal
+					if end+1 < len(script) && script[end+1] == ch {
+						end += 2
+						continue
+					}
+					break
+				}
+				if script[end] == '\\' && ch == '\'' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(script))
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+		case ch == '$':
+			tag := dollarQuoteTag(script[i:])
+			if tag == "" {
+				current.WriteByte(ch)
+				hasCode = true
+				i++
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				end = len(script)
+			} else {
+				end = i + len(tag) + end + len(tag)
+			}
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+		case ch == ';':
+			flush()
+			i++
+		default:
+			current.WriteByte(ch)
+			if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarQuoteTag returns the PostgreSQL dollar-quote tag ($$ or $name$) at the start of s,
+// or an empty string if s does not start with one.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		isIdent := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 1 && c >= '0' && c <= '9')
+		if !isIdent {
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package gormeasy
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitSQLStatements tests that scripts are split on top-level semicolons only
+func TestSplitSQLStatements(t *testing.T) {
+	script := `-- create users
+CREATE TABLE users (id int, name text DEFAULT 'a;b');
+/* comment; with semicolon */
+INSERT INTO users VALUES (1, 'it''s; fine');
+CREATE FUNCTION f() RETURNS trigger AS $body$
+BEGIN
+  RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+-- trailing comment only;
+`
+	got := splitSQLStatements(script)
+	want := []string{
+		"-- create users\nCREATE TABLE users (id int, name text DEFAULT 'a;b')",
+		"/* comment; with semicolon */\nINSERT INTO users VALUES (1, 'it''s; fine')",
+		"CREATE FUNCTION f() RETURNS trigger AS $body$\nBEGIN\n  RETURN NEW;\nEND;\n$body$ LANGUAGE plpgsql",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+// TestDollarQuoteTag tests recognition of PostgreSQL dollar-quote tags
+func TestDollarQuoteTag(t *testing.T) {
+	cases := map[string]string{
+		"$$ body":   "$$",
+		"$fn$ body": "$fn$",
+		"$1, $2":    "",
+		"$ x":       "",
+	}
+	for input, want := range cases {
+		if got := dollarQuoteTag(input); got != want {
+			t.Errorf("Expected tag %q for %q, got %q", want, input, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package gormeasy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm"
+)
+
+// squashTemplate is the Go file written by the squash command.
+var squashTemplate = template.Must(template.New("squash").Parse(`// Code generated by gormeasy squash. DO NOT EDIT.
+// It replaces the migrations up to and including {{.To}}.
+
+package {{.Package}}
+
+import (
+	_ "embed"
+
+	"github.com/ymzuiku/gormeasy"
+	"gorm.io/gorm"
+)
+
+//go:embed {{.SQLFile}}
+var baselineSchema string
+
+//go:embed {{.RollbackFile}}
+var baselineRollback string
+
+// Baseline creates the schema produced by all squashed migrations.
+// It must be the first migration passed to gormeasy.Start.
+var Baseline = &gormeasy.Migration{
+	ID: {{printf "%q" .ID}},
+	Migrate: func(tx *gorm.DB) error {
+		return gormeasy.ExecSQL(tx, baselineSchema)
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return gormeasy.ExecSQL(tx, baselineRollback)
+	},
+}
+`))
+
+// migrationsUpTo returns the IDs of migrations up to and including toID.
+func migrationsUpTo(migrations []*Migration, toID string) ([]string, error) {
+	var ids []string
+	for _, m := range migrations {
+		ids = append(ids, m.ID)
+		if m.ID == toID {
+			return ids, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown migration ID: %s", toID)
+}
+
+// writeSquashedMigration dumps the schema of db and writes a baseline migration replacing
+// the migrations up to and including toID. db must be migrated exactly up to toID,
+// e.g. a fresh database on which `up` ran with only those migrations applied.
+func writeSquashedMigration(db *gorm.DB, opts Options, migrations []*Migration, toID, baselineID, outPath string) error {
+	opts = opts.withDefaults()
+	squashed, err := migrationsUpTo(migrations, toID)
+	if err != nil {
+		return err
+	}
+
+	applied := getAppliedIDs(db, opts)
+	for _, id := range squashed {
+		if !applied[id] {
+			return fmt.Errorf("migration %s is not applied, the database must be migrated up to %s", id, toID)
+		}
+	}
+	for _, m := range migrations[len(squashed):] {
+		if applied[m.ID] {
+			return fmt.Errorf("migration %s is applied after %s, the database must be migrated exactly up to %s", m.ID, toID, toID)
+		}
+	}
+
+	dump, err := dumpSchema(db, historyTables(opts)...)
+	if err != nil {
+		return err
+	}
+
+	sqlPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".sql"
+	// The rollback drops every object of the baseline, so it can be applied again
+	rollbackPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + "_rollback.sql"
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("failed to create dir for %s: %w", outPath, err)
+	}
+	if err := os.WriteFile(sqlPath, []byte(dump.SQL), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sqlPath, err)
+	}
+	if err := os.WriteFile(rollbackPath, []byte(dump.rollbackSQL()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rollbackPath, err)
+	}
+
+	absOut, err := filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outPath, err)
+	}
+	defer f.Close()
+	if err := squashTemplate.Execute(f, map[string]any{
+		"To":           toID,
+		"Package":      filepath.Base(filepath.Dir(absOut)),
+		"SQLFile":      filepath.Base(sqlPath),
+		"RollbackFile": filepath.Base(rollbackPath),
+		"ID":           baselineID,
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPath, err)
+	}
+
+	out.Println("✅ Squashed", len(squashed), "migrations into:", outPath)
+	out.Println("✅ Baseline schema written to:", sqlPath)
+	out.Println("✅ Baseline rollback written to:", rollbackPath)
+	out.Println()
+	out.Println("Next steps:")
+	out.Println("  1. Register Baseline as the first migration and delete the squashed migrations")
+	out.Printf("  2. On every existing database run: squash --to %s --id %s --apply\n", toID, strconv.Quote(baselineID))
+	return nil
+}
+
+// SquashHistory rewrites the history table of an existing database after a squash:
+// it records baselineID as applied and removes the squashed migrations up to and including toID.
+// Every squashed migration must be applied, otherwise the database is not at the baseline schema.
+func SquashHistory(db *gorm.DB, opts Options, migrations []*Migration, toID, baselineID string) error {
+	opts = opts.withDefaults()
+	defer invalidateStatusCaches()
+	squashed, err := migrationsUpTo(migrations, toID)
+	if err != nil {
+		return err
+	}
+
+	applied := getAppliedIDs(db, opts)
+	if applied[baselineID] {
+		out.Printf("⚠️  Baseline already applied: %s\n", baselineID)
+		return nil
+	}
+	for _, id := range squashed {
+		if !applied[id] {
+			return fmt.Errorf("migration %s is not applied, the database is not at the baseline schema", id)
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(opts.TableName).Create(map[string]any{opts.IDColumnName: baselineID}).Error; err != nil {
+			return fmt.Errorf("failed to record baseline %s: %w", baselineID, err)
+		}
+		cond := fmt.Sprintf("%s IN ?", tx.Statement.Quote(opts.IDColumnName))
+		if err := tx.Table(opts.TableName).Where(cond, squashed).Delete(historyModel(opts)).Error; err != nil {
+			return fmt.Errorf("failed to remove squashed migrations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	out.Printf("✅ Replaced %d squashed migrations with baseline %s.\n", len(squashed), baselineID)
+	return nil
+}
@@ -0,0 +1,87 @@
+package gormeasy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestSquashedMigrationRollback tests that the squashed baseline can be applied, rolled back
+// and applied again, as regression does
+func TestSquashedMigrationRollback(t *testing.T) {
+	saved := out
+	out = &output{level: levelQuiet, w: &strings.Builder{}, errW: &strings.Builder{}}
+	defer func() { out = saved }()
+
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	migrations := []*Migration{
+		{ID: "001", Migrate: func(tx *gorm.DB) error {
+			return ExecSQL(tx, `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+				CREATE INDEX idx_users_name ON users (name);
+				CREATE VIEW user_names AS SELECT name FROM users;`)
+		}},
+		{ID: "002", Migrate: func(tx *gorm.DB) error {
+			return ExecSQL(tx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id))")
+		}},
+	}
+	if err := RunMigrationsWithOptions(db, migrations, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// gormeasy's own bookkeeping must stay out of the baseline
+	if err := db.Exec("CREATE TABLE " + opts.DataTableName + " (id TEXT PRIMARY KEY)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "migrations")
+	if err := writeSquashedMigration(db, opts, migrations, "002", "000-baseline", filepath.Join(dir, "baseline.go")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "baseline.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "//go:embed baseline_rollback.sql") {
+		t.Errorf("Expected the rollback file to be embedded, got %s", code)
+	}
+	schema, err := os.ReadFile(filepath.Join(dir, "baseline.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(schema), opts.DataTableName) {
+		t.Errorf("Expected %s to be left out of the baseline, got %s", opts.DataTableName, schema)
+	}
+	rollback, err := os.ReadFile(filepath.Join(dir, "baseline_rollback.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DROP TABLE IF EXISTS \"orders\";\nDROP VIEW IF EXISTS \"user_names\";\nDROP TABLE IF EXISTS \"users\";\n"
+	if string(rollback) != want {
+		t.Errorf("Expected rollback %q, got %q", want, rollback)
+	}
+
+	baseline := &Migration{
+		ID:       "000-baseline",
+		Migrate:  func(tx *gorm.DB) error { return ExecSQL(tx, string(schema)) },
+		Rollback: func(tx *gorm.DB) error { return ExecSQL(tx, string(rollback)) },
+	}
+	fresh := openSQLite(t)
+	m := getMigrator(fresh, []*Migration{baseline}, opts)
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("Expected up to succeed, got %v", err)
+	}
+	if err := m.RollbackLast(); err != nil {
+		t.Fatalf("Expected down to succeed, got %v", err)
+	}
+	var objects int64
+	fresh.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name IN ('users', 'orders', 'user_names', 'idx_users_name')").Scan(&objects)
+	if objects != 0 {
+		t.Errorf("Expected every baseline object dropped, %d left", objects)
+	}
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("Expected up after down to succeed, got %v", err)
+	}
+}
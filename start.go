@@ -1,11 +1,21 @@
 package gormeasy
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
 	"gorm.io/gorm"
 )
 
@@ -26,293 +36,1926 @@ type Params struct {
 // The migrations parameter should contain all migration definitions to be managed.
 // The getGormFromURL function is used to create a GORM database connection from a connection URL string.
 func Start(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error)) error {
+	return StartWithOptions(migrations, getGormFromURL, Options{})
+}
+
+// cli holds the state shared by all command handlers.
+type cli struct {
+	migrations     []*Migration
+	getGormFromURL func(string) (*gorm.DB, error)
+	opts           Options
+	// databaseURL is the global --db-url flag given before the command.
+	databaseURL string
+	// env is the environment profile of the database URLs selected with --env, see urlFlags.
+	env string
+	// config is the project config file, see urlFlags for how its values are used.
+	config *fileConfig
+	// notifyURL is the webhook notified after up, down and regression, see notifier.
+	notifyURL string
+	// protected marks the servers destructive commands refuse to touch without --allow-protected.
+	protected protectedEnvironment
+}
+
+// command is an entry of the CLI command table.
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	// setup registers the command flags on fs and returns the function running
+	// the command once the flags are parsed.
+	setup func(c *cli, fs *flag.FlagSet) func() error
+}
+
+// commands is the table of all CLI commands, in the order they are listed by help.
+var commands = []command{
+	{name: "create-db", summary: "Create a PostgreSQL, MySQL, ClickHouse or SQLite database if it does not exist", setup: (*cli).handleCreateDB},
+	{name: "delete-db", summary: "Delete a PostgreSQL, MySQL, ClickHouse or SQLite database if it exists", setup: (*cli).handleDeleteDB},
+	{name: "rename-db", summary: "Rename a database, terminating its connections, e.g. for blue/green database swaps", setup: (*cli).handleRenameDB},
+	{name: "clone-db", summary: "Create a copy of a PostgreSQL database with CREATE DATABASE ... TEMPLATE", setup: (*cli).handleCloneDB},
+	{name: "create-role", summary: "Create a least-privilege application role with its password from the environment and grant it on a database", setup: (*cli).handleCreateRole},
+	{name: "wait-db", summary: "Wait until the database accepts queries, e.g. in an initContainer before up", setup: (*cli).handleWaitDB},
+	{name: "up", aliases: []string{"migrate"}, summary: "Migrate the database up", setup: (*cli).handleUp},
+	{name: "down", aliases: []string{"rollback"}, summary: "Migrate the database down", setup: (*cli).handleDown},
+	{name: "up-data", summary: "Run pending data migrations after all schema migrations are applied", setup: (*cli).handleUpData},
+	{name: "status-data", summary: "Show the current data migration status", setup: (*cli).handleStatusData},
+	{name: "backfill", summary: "Run, inspect or pause the backfills configured in Options.Backfills: backfill run|status|pause", setup: (*cli).handleBackfill},
+	{name: "maintenance", summary: "Run the due maintenance tasks of Options.MaintenanceTasks, e.g. from cron: maintenance run|list", setup: (*cli).handleMaintenance},
+	{name: "partitions", summary: "Create the upcoming partitions of the tables in Options.Partitions, e.g. from cron: partitions ensure|list", setup: (*cli).handlePartitions},
+	{name: "refresh-matviews", summary: "Refresh materialized views, all of them or --views, optionally --concurrently", setup: (*cli).handleRefreshMatviews},
+	{name: "fix-sequences", summary: "Reset serial and identity sequences to MAX(id)+1, e.g. after a bulk import", setup: (*cli).handleFixSequences},
+	{name: "anonymize", summary: "Replace personal data in place with the rules of Options.Anonymize, e.g. on a copy of production", setup: (*cli).handleAnonymize},
+	{name: "truncate", summary: "Delete the rows of all or --tables tables in foreign key order, keeping the migration history", setup: (*cli).handleTruncate},
+	{name: "gen", summary: "Generate GORM models from database", setup: (*cli).handleGen},
+	{name: "erd", summary: "Write an entity-relationship diagram of the tables in Mermaid, DBML or PlantUML", setup: (*cli).handleERD},
+	{name: "status", summary: "Show the current migration status", setup: (*cli).handleStatus},
+	{name: "history", summary: "List applied migrations with the tables they touched, e.g. --table=users", setup: (*cli).handleHistory},
+	{name: "list-objects", summary: "List the functions, triggers and views created by migrations and the migration owning each", setup: (*cli).handleListObjects},
+	{name: "lint", summary: "Report lock-heavy operations in pending migrations", setup: (*cli).handleLint},
+	{name: "regression", summary: "Run regression test for all migrations and rollbacks", setup: (*cli).handleRegression},
+	{name: "example", summary: "Run the bundled example migrations on a disposable database and print the schema", setup: (*cli).handleExample},
+	{name: "seed", summary: "Run the seeds configured in Options.Seeds", setup: (*cli).handleSeed},
+	{name: "load-fixtures", summary: "Replace the rows of fixture tables with the YAML, JSON or CSV fixtures of --dir", setup: (*cli).handleLoadFixtures},
+	{name: "export-fixtures", summary: "Save the rows of selected tables to YAML or JSON fixtures, optionally limited and masked", setup: (*cli).handleExportFixtures},
+	{name: "snapshot-data", summary: "Save the data of selected tables to CSV files", setup: (*cli).handleSnapshotData},
+	{name: "restore-data", summary: "Replace table data with a snapshot saved by snapshot-data", setup: (*cli).handleRestoreData},
+	{name: "copy-data", summary: "Copy the rows of all or --tables tables from --source-db-url to a database with the same schema", setup: (*cli).handleCopyData},
+	{name: "mark-applied", summary: "Record migrations as applied without running them", setup: func(c *cli, fs *flag.FlagSet) func() error {
+		return c.handleMark(fs, MarkApplied)
+	}},
+	{name: "mark-reverted", summary: "Remove migrations from the history without rolling them back", setup: func(c *cli, fs *flag.FlagSet) func() error {
+		return c.handleMark(fs, MarkReverted)
+	}},
+	{name: "verify", summary: "Check that migrations still produce the schema recorded in schema.sum", setup: (*cli).handleVerify},
+	{name: "export-sql", aliases: []string{"export"}, summary: "Write the SQL executed by migrations to files, to freeze struct-based migrations", setup: (*cli).handleExportSQL},
+	{name: "init", summary: "Scaffold migrations/, .env.example, db.mk and a regression test", setup: (*cli).handleInit},
+	{name: "new", summary: "Create an empty migration file in the migrations package", setup: (*cli).handleNew},
+	{name: "compile", summary: "Compile YAML table specs into a migration of the changes since the last compile", setup: (*cli).handleCompile},
+	{name: "dump-schema", summary: "Write the DDL of the database to a single SQL file, for review or to bootstrap databases", setup: (*cli).handleDumpSchema},
+	{name: "load-schema", summary: "Bootstrap an empty database from a dump-schema file and record its migrations as applied", setup: (*cli).handleLoadSchema},
+	{name: "baseline", summary: "Record all migrations up to an ID as applied on an existing database", setup: (*cli).handleBaseline},
+	{name: "import-history", summary: "Record the migrations applied by golang-migrate, goose or flyway as applied, with a version to ID mapping", setup: (*cli).handleImportHistory},
+	{name: "squash", summary: "Consolidate old migrations into a single baseline migration", setup: (*cli).handleSquash},
+}
+
+// shortFlags maps long flag names to their single-letter shorthands.
+// A shorthand is registered on every flag set that defines the long flag.
+var shortFlags = map[string]string{
+	"db-url":  "d",
+	"db-name": "n",
+}
+
+// findCommand returns the command with the given name or alias, or nil if there is none.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name || slices.Contains(commands[i].aliases, name) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// addShortFlags registers the shorthands of shortFlags for the flags defined on fs.
+// Both names share the same value, so `-d url` and `--db-url url` are equivalent.
+func addShortFlags(fs *flag.FlagSet) {
+	var long []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := shortFlags[f.Name]; ok {
+			long = append(long, f)
+		}
+	})
+	for _, f := range long {
+		fs.Var(f.Value, shortFlags[f.Name], "Shorthand for --"+f.Name)
+	}
+}
+
+// StartWithOptions is like Start but lets callers customize the migrations history table,
+// transaction behavior and unknown-migration strictness through opts.
+//
+// Arguments are parsed in two levels: global flags (--db-url and the output flags) may come
+// before the command, command flags come after it, e.g. `myapp --db-url=... up --no-exit`.
+func StartWithOptions(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error), opts Options) error {
+	c := &cli{
+		migrations:     migrations,
+		getGormFromURL: getGormFromURL,
+		opts:           opts.withDefaults(),
+	}
+	if err := checkDuplicateIDs(migrations); err != nil {
+		return err
+	}
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	c.migrations = sorted
 
-	if err := godotenv.Load(); err != nil {
-		// If .env file doesn't exist, just log warning and continue using environment variables
-		fmt.Printf("Warning: .env file not found: %v\n", err)
+	// The .env files are loaded before anything else, also for the application's own commands
+	envFiles, env := envFileArgs(os.Args[1:])
+	missingEnvFiles, err := loadEnvFiles(envFiles, cmp.Or(env, os.Getenv(envEnvVar)))
+	if err != nil {
+		return err
+	}
+	if c.opts.QuietEnvFiles || os.Getenv(quietEnvFilesEnvVar) != "" || isJSONHelp(os.Args[1:]) {
+		missingEnvFiles = nil
 	}
 
 	// If no arguments provided, silently return to allow the application to continue
 	if len(os.Args) < 2 {
+		warnMissingEnvFiles(missingEnvFiles)
 		return nil
 	}
 
-	command := os.Args[1]
+	// Flags the application defines itself are not known here, so parse errors are silent
+	global := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	global.StringVar(&c.databaseURL, "db-url", "", "Default database connection URL for every command")
+	configPath := global.String("config", "", "Path of the config file (default gormeasy.json)")
+	env = ""
+	addEnvFlags(global, &env)
+	maxRetries := global.Int("max-retries", -1, "Retry connecting and migrating this many times on transient errors (default Options.MaxRetries)")
+	notifyURL := global.String("notify-url", "", "Webhook URL notified after up, down and regression (default $GORMEASY_NOTIFY_URL)")
+	addOutputFlags(global)
+	addShortFlags(global)
+	if err := global.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			printHelp()
+			os.Exit(0)
+		}
+		// A flag of the application before its own command is fine, but not before ours
+		if cmd := firstCommand(os.Args[1:]); cmd != nil {
+			return fmt.Errorf("%s: %w", cmd.name, err)
+		}
+		warnMissingEnvFiles(missingEnvFiles)
+		return nil
+	}
+	args := global.Args()
+	if len(args) == 0 {
+		warnMissingEnvFiles(missingEnvFiles)
+		return nil
+	}
 
 	// Handle help
-	if command == "help" || command == "--help" || command == "-h" {
+	if args[0] == "help" {
+		if isJSONHelp(args) {
+			if err := printHelpJSON(os.Stdout, global); err != nil {
+				return err
+			}
+			os.Exit(0)
+		}
 		printHelp()
 		os.Exit(0)
 	}
 
-	// Parse command-specific flags
-	switch command {
-	case "create-db":
-		return handleCreateDB(getGormFromURL)
-	case "delete-db":
-		return handleDeleteDB(getGormFromURL)
-	case "up":
-		return handleUp(migrations, getGormFromURL)
-	case "down":
-		return handleDown(migrations, getGormFromURL)
-	case "gen":
-		return handleGen(getGormFromURL)
-	case "status":
-		return handleStatus(migrations, getGormFromURL)
-	case "regression":
-		return handleRegression(migrations, getGormFromURL)
-	default:
+	cmd := findCommand(args[0])
+	if cmd == nil {
 		// Unknown command, silently return to allow the application to continue
+		warnMissingEnvFiles(missingEnvFiles)
 		return nil
 	}
+	if *configPath != "" {
+		c.config, err = loadConfig(*configPath, true)
+	} else {
+		c.config, err = loadConfig(defaultConfigPath, false)
+	}
+	if err != nil {
+		return err
+	}
+	if *maxRetries >= 0 {
+		c.opts.MaxRetries = *maxRetries
+	}
+	c.getGormFromURL = retryOpen(getGormFromURL, c.opts)
+	c.opts.ProtectedDatabases = slices.Concat(c.opts.ProtectedDatabases, c.config.ProtectedDatabases)
+	c.opts.GuardNonEmptyDrop = c.opts.GuardNonEmptyDrop || c.config.GuardNonEmptyDrop
+	c.opts.Partitions = slices.Concat(c.opts.Partitions, c.config.Partitions)
+	c.opts.Anonymize = slices.Concat(c.opts.Anonymize, c.config.Anonymize)
+	c.opts.AnonymizeSecret = cmp.Or(os.Getenv(anonymizeSecretEnvVar), c.opts.AnonymizeSecret)
+	if c.protected, err = newProtectedEnvironment(c.opts, c.config, os.Getenv(protectedHostsEnvVar)); err != nil {
+		return err
+	}
+	c.opts.MinVersion = max(c.opts.MinVersion, c.config.MinVersion)
+	if len(c.config.SQLVars) > 0 {
+		c.opts.SQLVars = maps.Clone(c.opts.SQLVars)
+		if c.opts.SQLVars == nil {
+			c.opts.SQLVars = make(map[string]string)
+		}
+		maps.Copy(c.opts.SQLVars, c.config.SQLVars)
+	}
+	if len(c.config.Flags) > 0 {
+		c.opts.FlagProvider = overrideFlags{static: c.config.Flags, next: c.opts.FlagProvider}
+	}
+	c.notifyURL = cmp.Or(*notifyURL, os.Getenv(notifyURLEnvVar), c.config.NotifyURL, c.opts.NotifyURL)
+
+	fs := newFlagSet(cmd.name)
+	run := cmd.setup(c, fs)
+	// --env and --env-file are also accepted after the command, e.g. `myapp up --env staging`
+	addEnvFlags(fs, &env)
+	addShortFlags(fs)
+	fs.Parse(args[1:])
+	warnMissingEnvFiles(missingEnvFiles)
+	c.env = cmp.Or(env, os.Getenv(envEnvVar))
+	if err := c.checkEnvironment(); err != nil {
+		return err
+	}
+	c.resolveURLFlags(fs)
+	return run()
+}
+
+// firstCommand returns the command of the first argument of args that is not a flag, or nil
+// when it is no gormeasy command.
+func firstCommand(args []string) *command {
+	for _, arg := range args {
+		if arg == "--" {
+			return nil
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return findCommand(arg)
+		}
+	}
+	return nil
 }
 
 func printHelp() {
+	names := make([]string, len(commands))
+	width := 0
+	for i, cmd := range commands {
+		names[i] = cmd.name
+		if len(cmd.aliases) > 0 {
+			names[i] += " (" + strings.Join(cmd.aliases, ", ") + ")"
+		}
+		width = max(width, len(names[i]))
+	}
+
 	fmt.Println("easymigrate - Manage PostgreSQL databases and migrations")
 	fmt.Println()
+	fmt.Printf("Usage: %s [global options] <command> [options]\n", os.Args[0])
+	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  create-db    Create a PostgreSQL database if it does not exist")
-	fmt.Println("  delete-db    Delete a PostgreSQL database if it exists")
-	fmt.Println("  up           Migrate the database up")
-	fmt.Println("  down         Migrate the database down")
-	fmt.Println("  gen          Generate GORM models from database")
-	fmt.Println("  status       Show the current migration status")
-	fmt.Println("  regression   Run regression test for all migrations and rollbacks")
+	for i, cmd := range commands {
+		fmt.Printf("  %-*s  %s\n", width, names[i], cmd.summary)
+	}
 	fmt.Println()
-	fmt.Println("Use 'command -h' for command-specific help")
+	fmt.Println("Global options (before the command):")
+	fmt.Println("  -d, --db-url    Default database connection URL for every command")
+	fmt.Println("  --config        Path of the config file (default gormeasy.json)")
+	fmt.Println("  --env           Environment profile of the database URLs and .env files, e.g. staging (default $GORMEASY_ENV)")
+	fmt.Println("  --env-file      Load environment variables from this file instead of the .env files, repeatable")
+	fmt.Println("  --max-retries   Retry connecting and migrating on transient errors (default Options.MaxRetries)")
+	fmt.Println("  --notify-url    Webhook URL notified after up, down and regression (default $GORMEASY_NOTIFY_URL)")
+	fmt.Println()
+	fmt.Println("Output options (accepted before or after the command):")
+	fmt.Println("  --quiet      Only print errors")
+	fmt.Println("  --verbose    Print every SQL statement executed by GORM")
+	fmt.Println("  --plain      Print plain ASCII output without emoji (also enabled by NO_COLOR or when stdout is not a terminal)")
+	fmt.Println()
+	fmt.Println("Use 'command -h' for command-specific help, 'help --json' for all commands and flags as JSON")
 }
 
-func handleCreateDB(getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("create-db", flag.ExitOnError)
-	dbName := fs.String("db-name", "", "Name of the database to create")
-	ownerDBURL := fs.String("owner-db-url", os.Getenv("OWNER_DATABASE_URL"), "Development database connection URL")
+// newFlagSet creates the flag set for a command with the shared usage text and
+// the output flags (--quiet, --verbose, --plain) that every command accepts.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	addOutputFlags(fs)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s create-db [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [options]\n", os.Args[0], name)
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fs.PrintDefaults()
 	}
-	fs.Parse(os.Args[2:])
+	return fs
+}
 
-	if *dbName == "" {
-		return fmt.Errorf("db-name is required")
-	}
+// addOutputFlags registers --quiet, --verbose and --plain on fs.
+func addOutputFlags(fs *flag.FlagSet) {
+	fs.BoolFunc("quiet", "Only print errors", func(string) error {
+		out.level = levelQuiet
+		return nil
+	})
+	fs.BoolFunc("verbose", "Print every SQL statement executed by GORM", func(string) error {
+		out.level = levelVerbose
+		return nil
+	})
+	fs.BoolFunc("plain", "Print plain ASCII output without emoji or colors (also enabled by NO_COLOR or when stdout is not a terminal)", func(string) error {
+		out.plain = true
+		return nil
+	})
+}
 
-	db, err := getGorm(*ownerDBURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
+func (c *cli) handleCreateDB(fs *flag.FlagSet) func() error {
+	dbName := fs.String("db-name", "", "Name of the database to create")
+	ownerDBURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	var opts CreateDatabaseOptions
+	fs.StringVar(&opts.Owner, "owner", "", "Role owning the new database (PostgreSQL)")
+	fs.StringVar(&opts.Encoding, "encoding", "", "Character set encoding of the new database, e.g. UTF8 (PostgreSQL)")
+	fs.StringVar(&opts.Template, "template", "", "Database to copy the new database from, e.g. template0 (PostgreSQL)")
+	fs.StringVar(&opts.Locale, "locale", "", "Collation and character classification of the new database, e.g. en_US.UTF-8 (PostgreSQL)")
+	fs.StringVar(&opts.Charset, "charset", "", "Default character set of the new database, e.g. utf8mb4 (MySQL)")
+	fs.StringVar(&opts.Collation, "collation", "", "Default collation of the new database, e.g. utf8mb4_unicode_ci (MySQL)")
+	withExtensions := fs.String("with-extensions", "", "Comma-separated PostgreSQL extensions to enable in the new database, in addition to Options.Extensions")
 
-	if err := CreateDatabase(db, *dbName); err != nil {
-		return err
-	}
+	return func() error {
+		if *dbName == "" {
+			return fmt.Errorf("db-name is required")
+		}
 
-	os.Exit(0)
-	return nil
+		db, err := getGorm(*ownerDBURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+
+		if err := CreateDatabaseWithOptions(db, *dbName, opts); err != nil {
+			return err
+		}
+		if err := createExtensions(*ownerDBURL, *dbName, mergeExtensions(c.opts.Extensions, *withExtensions), c.getGormFromURL); err != nil {
+			return err
+		}
+
+		os.Exit(0)
+		return nil
+	}
 }
 
-func handleDeleteDB(getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("delete-db", flag.ExitOnError)
+func (c *cli) handleDeleteDB(fs *flag.FlagSet) func() error {
 	dbName := fs.String("db-name", "", "Name of the database to delete")
-	ownerDBURL := fs.String("owner-db-url", os.Getenv("OWNER_DATABASE_URL"), "Development database connection URL")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s delete-db [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
-	}
-	fs.Parse(os.Args[2:])
+	ownerDBURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	force := fs.Bool("force", false, "Delete the SQLite database file even if it does not look like a SQLite database")
+	yes := addYesFlag(fs)
+	allowProtected := fs.Bool("allow-protected", false, "Run even if owner-db-url points at a protected host")
+	backupDir := fs.String("backup-dir", "", "Back up the database into this directory before deleting it")
 
-	if *dbName == "" {
-		return fmt.Errorf("db-name is required")
-	}
-	if *ownerDBURL == "" {
-		return fmt.Errorf("owner-db-url is required")
-	}
+	return func() error {
+		if *dbName == "" {
+			return fmt.Errorf("db-name is required")
+		}
+		if *ownerDBURL == "" {
+			return fmt.Errorf("owner-db-url is required")
+		}
+		if err := c.protected.check("delete-db", *ownerDBURL, *allowProtected); err != nil {
+			return err
+		}
+		if err := confirmDestructive("delete database "+*dbName, *dbName, *yes); err != nil {
+			return err
+		}
 
-	db, err := getGorm(*ownerDBURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
+		db, err := getGorm(*ownerDBURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if *backupDir != "" {
+			if err := c.backupBeforeDelete(db, *ownerDBURL, *dbName, *backupDir); err != nil {
+				return err
+			}
+		}
 
-	if err := DeleteDatabase(db, *dbName); err != nil {
-		return err
-	}
+		if err := deleteDatabase(db, *dbName, c.opts.ProtectedDatabases, *force); err != nil {
+			return err
+		}
 
-	os.Exit(0)
-	return nil
+		os.Exit(0)
+		return nil
+	}
 }
 
-func handleUp(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("up", flag.ExitOnError)
-	databaseURL := fs.String("db-url", os.Getenv("DATABASE_URL"), "Development database connection URL")
-	noExit := fs.Bool("no-exit", false, "When success, do not exit")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s up [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
-	}
-	fs.Parse(os.Args[2:])
+func (c *cli) handleRenameDB(fs *flag.FlagSet) func() error {
+	dbName := fs.String("db-name", "", "Name of the database to rename")
+	newName := fs.String("new-name", "", "New name of the database")
+	ownerDBURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+
+	return func() error {
+		if *dbName == "" {
+			return fmt.Errorf("db-name is required")
+		}
+		if *newName == "" {
+			return fmt.Errorf("new-name is required")
+		}
+		if databaseNameFromURL(*ownerDBURL) == *dbName {
+			return fmt.Errorf("db-name %s is the database of owner-db-url, connect to another database to rename it", *dbName)
+		}
+
+		db, err := getGorm(*ownerDBURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := renameDatabase(db, *dbName, *newName, c.opts.ProtectedDatabases); err != nil {
+			return err
+		}
 
-	db, err := getGorm(*databaseURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-	err = RunMigrations(db, migrations)
-	if err != nil {
-		printMigrationStatus(db, migrations, false)
-		return err
-	}
-	printMigrationStatus(db, migrations, false)
-	if !*noExit {
 		os.Exit(0)
+		return nil
 	}
-	return nil
 }
 
-func handleDown(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("down", flag.ExitOnError)
-	databaseURL := fs.String("db-url", os.Getenv("DATABASE_URL"), "Development database connection URL")
-	id := fs.String("id", "", "Rollback to specific migration ID")
-	all := fs.Bool("all", false, "Rollback all migrations")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s down [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
-	}
-	fs.Parse(os.Args[2:])
+func (c *cli) handleCloneDB(fs *flag.FlagSet) func() error {
+	source := fs.String("source", "", "Name of the database to copy")
+	dbName := fs.String("db-name", "", "Name of the database to create")
+	ownerDBURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	anonymize := fs.Bool("anonymize", false, "Anonymize the copy with the rules of Options.Anonymize")
 
-	db, err := getGorm(*databaseURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-	m := getMigrator(db, migrations)
-	if *id != "" {
-		if err := m.RollbackTo(*id); err != nil {
-			printMigrationStatus(db, migrations, false)
-			return fmt.Errorf("failed to rollback to migration: %w", err)
+	return func() error {
+		if *source == "" {
+			return fmt.Errorf("source is required")
 		}
-		fmt.Printf("✅ Rollback to migration: %s complete.\n", *id)
-	} else if *all {
-		if err := rollbackAllMigrations(m); err != nil {
-			printMigrationStatus(db, migrations, false)
-			return fmt.Errorf("failed to rollback all migrations: %w", err)
+		if *dbName == "" {
+			return fmt.Errorf("db-name is required")
 		}
-		fmt.Printf("✅ Rollback all migrations complete.\n")
-	} else {
-		if err := m.RollbackLast(); err != nil {
-			printMigrationStatus(db, migrations, false)
-			return fmt.Errorf("rollback failed: %w", err)
+		if databaseNameFromURL(*ownerDBURL) == *source {
+			return fmt.Errorf("source %s is the database of owner-db-url, connect to another database to clone it", *source)
+		}
+
+		db, err := getGorm(*ownerDBURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
 		}
-		fmt.Println("✅ Rollback last complete.")
+		if *anonymize {
+			if err := validateAnonymizeRules(c.opts.Anonymize); err != nil {
+				return err
+			}
+		}
+		if err := cloneDatabase(db, *source, *dbName, c.opts.ProtectedDatabases); err != nil {
+			return err
+		}
+		if *anonymize {
+			cloneURL, err := withDatabaseName(*ownerDBURL, *dbName)
+			if err != nil {
+				return err
+			}
+			clone, err := getGorm(cloneURL, c.getGormFromURL)
+			if err == nil {
+				err = Anonymize(clone, c.opts.Anonymize, c.opts.AnonymizeSecret)
+				if sqlDB, dbErr := clone.DB(); dbErr == nil {
+					sqlDB.Close()
+				}
+			}
+			if err != nil {
+				// The clone still holds the personal data, never leave it behind
+				if dropErr := DeleteDatabaseWithOptions(db, *dbName, c.opts); dropErr != nil {
+					return fmt.Errorf("failed to anonymize %s: %w; dropping it failed too, delete it before handing it out: %v", *dbName, err, dropErr)
+				}
+				return fmt.Errorf("failed to anonymize %s, the clone was dropped: %w", *dbName, err)
+			}
+		}
+
+		os.Exit(0)
+		return nil
 	}
-	printMigrationStatus(db, migrations, false)
-	os.Exit(0)
-	return nil
 }
 
-func handleGen(getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("gen", flag.ExitOnError)
-	databaseURL := fs.String("db-url", os.Getenv("DATABASE_URL"), "Development database connection URL")
-	out := fs.String("out", "", "Output path for generated models")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s gen [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
-	}
-	fs.Parse(os.Args[2:])
+func (c *cli) handleCreateRole(fs *flag.FlagSet) func() error {
+	ownerDBURL := fs.String("owner-db-url", "", "Database connection URL of the role running the migrations (default $OWNER_DATABASE_URL)")
+	dbName := fs.String("db-name", "", "Database to grant the role on (default the database of owner-db-url)")
+	var opts RoleOptions
+	fs.StringVar(&opts.Name, "role", "", "Name of the application role")
+	passwordEnv := fs.String("password-env", "APP_DATABASE_PASSWORD", "Environment variable holding the password of the role")
+	fs.StringVar(&opts.Schema, "schema", "public", "Schema to grant the role on (PostgreSQL)")
+	fs.StringVar(&opts.Host, "host", "%", "Host the user may connect from (MySQL)")
 
-	if *out == "" {
-		return fmt.Errorf("out is required")
-	}
+	return func() error {
+		if opts.Name == "" {
+			return fmt.Errorf("role is required")
+		}
+		opts.Password = os.Getenv(*passwordEnv)
+		if opts.Password == "" {
+			return fmt.Errorf("password of role %s is required, set the %s environment variable", opts.Name, *passwordEnv)
+		}
 
-	db, err := getGorm(*databaseURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-	if err := generateGormCode(db, *out); err != nil {
-		return fmt.Errorf("failed to generate GORM code: %w", err)
+		dbURL := *ownerDBURL
+		if *dbName != "" && *dbName != databaseNameFromURL(dbURL) {
+			var err error
+			if dbURL, err = withDatabaseName(dbURL, *dbName); err != nil {
+				return err
+			}
+		}
+		db, err := getGorm(dbURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		opts.Database = *dbName
+		opts.Options = c.opts
+		if err := CreateRole(db, opts); err != nil {
+			return err
+		}
+
+		os.Exit(0)
+		return nil
 	}
-	os.Exit(0)
-	return nil
 }
 
-func handleStatus(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("status", flag.ExitOnError)
-	databaseURL := fs.String("db-url", os.Getenv("DATABASE_URL"), "Development database connection URL")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s status [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
+func (c *cli) handleWaitDB(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	timeout := fs.Duration("timeout", time.Minute, "Give up after waiting this long")
+	interval := fs.Duration("interval", 2*time.Second, "Time between two attempts")
+
+	return func() error {
+		err := waitForDatabase(func() (*gorm.DB, error) {
+			return getGorm(*databaseURL, c.getGormFromURL)
+		}, *timeout, *interval, c.opts.Clock)
+		if err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
 	}
-	fs.Parse(os.Args[2:])
+}
 
-	db, err := getGorm(*databaseURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+func (c *cli) handleUp(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	noExit := fs.Bool("no-exit", false, "When success, do not exit")
+	allowUnknown := fs.Bool("allow-unknown", false, "Warn instead of failing when the database has applied migrations unknown to the code")
+	group := fs.String("group", "", "Comma-separated migration groups to operate on (default all)")
+	safe := fs.Bool("safe", false, "Refuse to migrate when lint finds lock-heavy operations in pending migrations")
+	backupDir := fs.String("backup-dir", "", "Back up the database into this directory first when a pending migration is destructive")
+	withTimeouts := addTimeoutFlags(fs)
+	withSlow := addSlowFlag(fs)
+	withHeartbeat := addHeartbeatFlags(fs)
+
+	return func() error {
+		selected, err := c.selectGroups(*group)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if *safe {
+			if err := checkLint(db, selected, c.opts); err != nil {
+				return err
+			}
+		}
+		if *backupDir != "" {
+			status, err := GetStatus(db, selected, c.opts)
+			if err != nil {
+				return err
+			}
+			if ids := pendingDestructive(status, selected); len(ids) > 0 {
+				out.Printf("Pending destructive migrations: %s\n", strings.Join(ids, ", "))
+				if _, err := backupDatabase(db, *databaseURL, *backupDir, "up"); err != nil {
+					return err
+				}
+			}
+		}
+		opts := withHeartbeat(withSlow(withTimeouts(c.opts)))
+		if *allowUnknown && opts.UnknownMigrations == UnknownMigrationsError {
+			opts.UnknownMigrations = UnknownMigrationsWarn
+		}
+		n := c.notifier("up")
+		err = runMigrations(db, c.migrations, selected, n.watch(opts))
+		n.send(databaseNameFromURL(*databaseURL), err)
+		if err != nil {
+			printMigrationStatus(db, selected, c.opts, false)
+			return err
+		}
+		printMigrationStatus(db, selected, c.opts, false)
+		if !*noExit {
+			os.Exit(0)
+		}
+		return nil
 	}
-	printMigrationStatus(db, migrations, false)
-	os.Exit(0)
-	return nil
 }
 
-func handleRegression(migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error)) error {
-	fs := flag.NewFlagSet("regression", flag.ExitOnError)
-	ownerDatabaseURL := fs.String("owner-db-url", os.Getenv("OWNER_DATABASE_URL"), "Development database connection URL")
-	devDatabaseURL := fs.String("regression-db-url", os.Getenv("REGRESSION_DATABASE_URL"), "Target database connection URL")
-	regressionDatabaseName := fs.String("db-name", "", "Regression test database name")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s regression [options]\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fs.PrintDefaults()
+func (c *cli) handleDown(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	id := fs.String("id", "", "Rollback to specific migration ID")
+	all := fs.Bool("all", false, "Rollback all migrations")
+	group := fs.String("group", "", "Comma-separated migration groups to operate on (default all)")
+	withTimeouts := addTimeoutFlags(fs)
+	yes := addYesFlag(fs)
+	allowProtected := fs.Bool("allow-protected", false, "Run --all even if db-url points at a protected host")
+	backupDir := fs.String("backup-dir", "", "Back up the database into this directory before rolling back")
+
+	return func() error {
+		selected, err := c.selectGroups(*group)
+		if err != nil {
+			return err
+		}
+		if *all && *id == "" {
+			if err := c.protected.check("down --all", *databaseURL, *allowProtected); err != nil {
+				return err
+			}
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if *all && *id == "" {
+			if err := confirmDestructive("roll back all migrations of "+db.Migrator().CurrentDatabase(), db.Migrator().CurrentDatabase(), *yes); err != nil {
+				return err
+			}
+		}
+		if *backupDir != "" {
+			if _, err := backupDatabase(db, *databaseURL, *backupDir, "down"); err != nil {
+				return err
+			}
+		}
+		n := c.notifier("down")
+		opts := n.watch(withTimeouts(c.opts))
+		defer invalidateStatusCaches()
+		err = withSessionTimeouts(db, opts, func(conn *gorm.DB) error {
+			m := getMigrator(conn, withHooks(selected, opts), opts)
+			if *id != "" {
+				if err := m.RollbackTo(*id); err != nil {
+					return fmt.Errorf("failed to rollback to migration: %w", err)
+				}
+				out.Printf("✅ Rollback to migration: %s complete.\n", *id)
+			} else if *all {
+				if err := rollbackAllMigrations(m); err != nil {
+					return fmt.Errorf("failed to rollback all migrations: %w", err)
+				}
+				out.Printf("✅ Rollback all migrations complete.\n")
+			} else {
+				if err := m.RollbackLast(); err != nil {
+					return fmt.Errorf("rollback failed: %w", err)
+				}
+				out.Println("✅ Rollback last complete.")
+			}
+			return nil
+		})
+		n.send(databaseNameFromURL(*databaseURL), err)
+		printMigrationStatus(db, selected, c.opts, false)
+		if err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
 	}
-	fs.Parse(os.Args[2:])
+}
 
-	if *ownerDatabaseURL == "" {
-		return fmt.Errorf("owner-db-url is required")
+func (c *cli) handleUpData(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	noExit := fs.Bool("no-exit", false, "When success, do not exit")
+
+	return func() error {
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := RunDataMigrations(db, c.migrations, c.opts); err != nil {
+			return err
+		}
+		if !*noExit {
+			os.Exit(0)
+		}
+		return nil
 	}
+}
+
+func (c *cli) handleStatusData(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
 
-	if *devDatabaseURL == "" {
-		return fmt.Errorf("regression-db-url is required")
+	return func() error {
+		if len(c.opts.DataMigrations) == 0 {
+			return fmt.Errorf("no data migrations configured, set Options.DataMigrations")
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		printMigrationStatus(db, c.opts.DataMigrations, c.opts.dataOptions(), false)
+		os.Exit(0)
+		return nil
 	}
+}
+
+func (c *cli) handleBackfill(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	names := fs.String("name", "", "Comma-separated backfills to operate on (default all)")
+
+	return func() error {
+		// The action comes before its flags: backfill run --name=order-totals
+		action := fs.Arg(0)
+		if fs.NArg() > 0 {
+			fs.Parse(fs.Args()[1:])
+		}
+		selected, err := c.selectBackfills(*names)
+		if err != nil {
+			return err
+		}
 
-	if *regressionDatabaseName == "" {
-		return fmt.Errorf("db-name is required")
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		switch action {
+		case "run":
+			if err := runBackfills(db, selected); err != nil {
+				return err
+			}
+		case "status":
+			if err := printBackfillStatus(db, selected); err != nil {
+				return err
+			}
+		case "pause":
+			for _, b := range selected {
+				if err := PauseBackfill(db, b.Name); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unknown backfill action %q, use run, status or pause", action)
+		}
+		os.Exit(0)
+		return nil
 	}
+}
 
-	ownerDB, err := getGorm(*ownerDatabaseURL, getGormFromURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+// selectBackfills returns the configured backfills with the comma-separated names, or all when names is empty.
+func (c *cli) selectBackfills(names string) ([]*BackfillOptions, error) {
+	if len(c.opts.Backfills) == 0 {
+		return nil, fmt.Errorf("no backfills configured, set Options.Backfills")
 	}
-	if err = DeleteDatabase(ownerDB, *regressionDatabaseName); err != nil {
-		return err
+	if names == "" {
+		return c.opts.Backfills, nil
 	}
-	if err = CreateDatabase(ownerDB, *regressionDatabaseName); err != nil {
-		return err
+	var selected []*BackfillOptions
+	for _, name := range splitList(names) {
+		i := slices.IndexFunc(c.opts.Backfills, func(b *BackfillOptions) bool { return b.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown backfill: %s", name)
+		}
+		selected = append(selected, c.opts.Backfills[i])
 	}
+	return selected, nil
+}
 
-	devDB, err := getGorm(*devDatabaseURL, getGormFromURL)
-	if err != nil {
-		return err
-	}
-	m := getMigrator(devDB, migrations)
+func (c *cli) handleMaintenance(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	tasks := fs.String("task", "", "Comma-separated maintenance tasks to operate on (default all)")
+	force := fs.Bool("force", false, "Run the tasks even if they are not due")
 
-	if err = m.Migrate(); err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
-	}
-	printMigrationStatus(devDB, migrations, true)
+	return func() error {
+		// The action comes before its flags: maintenance run --task=partition-ensure
+		action := fs.Arg(0)
+		if fs.NArg() > 0 {
+			fs.Parse(fs.Args()[1:])
+		}
+		if len(c.opts.MaintenanceTasks) == 0 {
+			return fmt.Errorf("no maintenance tasks configured, set Options.MaintenanceTasks")
+		}
+		selected, err := selectMaintenanceTasks(c.opts.MaintenanceTasks, splitList(*tasks))
+		if err != nil {
+			return err
+		}
 
-	if err = rollbackAllMigrations(m); err != nil {
-		return fmt.Errorf("failed to rollback all migrations: %w", err)
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		switch action {
+		case "run":
+			if err := runMaintenance(db, selected, *force); err != nil {
+				return err
+			}
+		case "list":
+			if err := printMaintenanceTasks(db, selected); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown maintenance action %q, use run or list", action)
+		}
+		os.Exit(0)
+		return nil
 	}
-	printMigrationStatus(devDB, migrations, true)
+}
 
-	if err = m.Migrate(); err != nil {
-		return fmt.Errorf("failed to migrate again database: %w", err)
+func (c *cli) handlePartitions(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	tables := fs.String("table", "", "Comma-separated partitioned tables to operate on (default all)")
+
+	return func() error {
+		// The action comes before its flags: partitions ensure --table=events
+		action := fs.Arg(0)
+		if fs.NArg() > 0 {
+			fs.Parse(fs.Args()[1:])
+		}
+		if len(c.opts.Partitions) == 0 {
+			return fmt.Errorf("no partitioned tables configured, set Options.Partitions or the partitions key of gormeasy.json")
+		}
+		selected, err := selectPartitions(c.opts.Partitions, splitList(*tables))
+		if err != nil {
+			return err
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		switch action {
+		case "ensure":
+			if err := EnsurePartitions(db, selected); err != nil {
+				return err
+			}
+		case "list":
+			if err := printPartitions(db, selected); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown partitions action %q, use ensure or list", action)
+		}
+		os.Exit(0)
+		return nil
 	}
+}
 
-	printMigrationStatus(devDB, migrations, true)
+func (c *cli) handleRefreshMatviews(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	views := fs.String("views", "", "Comma-separated materialized views to refresh (default all)")
+	concurrently := fs.Bool("concurrently", false, "Refresh with CONCURRENTLY, which doesn't block reads but needs a unique index on each view")
 
-	fmt.Println("✅ Regression test complete, migration all up and all down, and migrate again, all pass.")
+	return func() error {
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := RefreshMaterializedViews(db, *concurrently, splitList(*views)...); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
 
-	os.Exit(0)
-	return nil
+func (c *cli) handleFixSequences(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+
+	return func() error {
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := ResetSequences(db); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleAnonymize(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	yes := addYesFlag(fs)
+	allowProtected := fs.Bool("allow-protected", false, "Run even if db-url points at a protected host")
+
+	return func() error {
+		if err := validateAnonymizeRules(c.opts.Anonymize); err != nil {
+			return err
+		}
+		if err := c.protected.check("anonymize", *databaseURL, *allowProtected); err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		dbName := db.Migrator().CurrentDatabase()
+		if err := confirmDestructive("overwrite personal data in "+dbName, dbName, *yes); err != nil {
+			return err
+		}
+		if err := Anonymize(db, c.opts.Anonymize, c.opts.AnonymizeSecret); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleTruncate(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	tables := fs.String("tables", "", "Comma-separated tables to truncate (default all but the migration history)")
+	cascade := fs.Bool("cascade", false, "Also truncate the tables referencing the selected tables, after confirmation")
+	yes := addYesFlag(fs)
+	allowProtected := fs.Bool("allow-protected", false, "Run even if db-url points at a protected host")
+
+	return func() error {
+		if err := c.protected.check("truncate", *databaseURL, *allowProtected); err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		plan, err := planTruncate(db, c.opts, splitList(*tables))
+		if err != nil {
+			return err
+		}
+		selected := plan.tables
+		if len(plan.referencing) > 0 {
+			if !*cascade {
+				return fmt.Errorf("tables %s reference the selected tables, pass --cascade to truncate them too", strings.Join(plan.referencing, ", "))
+			}
+			if err := confirmDestructive("also truncate "+strings.Join(plan.referencing, ", "), db.Migrator().CurrentDatabase(), *yes); err != nil {
+				return err
+			}
+			selected = plan.withReferencing()
+		}
+		if err := truncate(db, selected, plan.edges); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleGen(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	out := fs.String("out", "", "Output path for generated models")
+	queryOut := fs.String("query-out", "", "Also generate the gen query API of the models into this directory")
+	queryMode := fs.String("query-mode", "default", "Comma-separated gen modes of the query API: default, interface, without-context")
+	fieldNullable := fs.Bool("field-nullable", false, "Generate pointers for nullable columns")
+	fieldCoverable := fs.Bool("field-coverable", false, "Generate pointers for columns with a default value")
+	fieldSignable := fs.Bool("field-signable", false, "Generate unsigned types for unsigned integer columns")
+	typeMap := fs.String("type-map", "", "Comma-separated dbtype=GoType mappings, e.g. jsonb=datatypes.JSON,numeric=github.com/shopspring/decimal.Decimal")
+	jsonTag := fs.String("json-tag", "", "Naming of JSON tags: snake, camel or pascal (default snake)")
+	jsonOmitEmpty := fs.Bool("json-omitempty", false, "Add omitempty to JSON tags")
+	schemas := fs.String("schemas", "", "Comma-separated Postgres schemas to generate models of, each into model/<schema> (default the current schema)")
+	enums := fs.Bool("enums", false, "Generate Go enum types for Postgres enum types and CHECK (column IN (...)) constraints")
+	relations := fs.Bool("relations", false, "Generate BelongsTo and HasMany association fields from foreign keys")
+	includeViews := fs.Bool("include-views", false, "Also generate read-only models of views and materialized views")
+	tsOut := fs.String("ts-out", "", "Also write TypeScript interfaces of the models into this directory")
+	tsZod := fs.Bool("ts-zod", false, "Add zod schemas to the TypeScript interfaces of --ts-out")
+	protoOut := fs.String("proto-out", "", "Also write protobuf messages of the models into this directory")
+	protoPackage := fs.String("proto-package", "", "Protobuf package of the --proto-out messages (default the Go package name)")
+	protoNumbers := fs.String("proto-numbers", "", "JSON file keeping the protobuf field numbers across runs (default <proto-out>/proto-numbers.json)")
+	openAPIOut := fs.String("openapi-out", "", "Also write OpenAPI 3 component schemas of the models into this directory")
+	watch := fs.Bool("watch", false, "Keep running and regenerate the models whenever the schema changes")
+	watchInterval := fs.Duration("watch-interval", 2*time.Second, "How often --watch checks the schema for changes")
+	preserve := fs.Bool("preserve", false, "Only delete generated files from the output directories, keeping hand-written files like *_custom.go")
+
+	return func() error {
+		if *out == "" {
+			return fmt.Errorf("out is required")
+		}
+		mode, err := parseGenMode(*queryMode)
+		if err != nil {
+			return err
+		}
+		opts := genOptions{queryOut: *queryOut, mode: mode}
+		if c.config != nil {
+			opts.genConfig = c.config.Gen
+		}
+		opts.FieldNullable = opts.FieldNullable || *fieldNullable
+		opts.FieldCoverable = opts.FieldCoverable || *fieldCoverable
+		opts.FieldSignable = opts.FieldSignable || *fieldSignable
+		opts.JSONOmitEmpty = opts.JSONOmitEmpty || *jsonOmitEmpty
+		opts.Preserve = opts.Preserve || *preserve
+		opts.Enums = opts.Enums || *enums
+		opts.Relations = opts.Relations || *relations
+		opts.IncludeViews = opts.IncludeViews || *includeViews
+		if *jsonTag != "" {
+			opts.JSONTag = *jsonTag
+		}
+		if *tsOut != "" {
+			opts.TSOut = *tsOut
+		}
+		opts.TSZod = opts.TSZod || *tsZod
+		if *protoOut != "" {
+			opts.ProtoOut = *protoOut
+		}
+		if *protoPackage != "" {
+			opts.ProtoPackage = *protoPackage
+		}
+		if *protoNumbers != "" {
+			opts.ProtoNumbers = *protoNumbers
+		}
+		if *openAPIOut != "" {
+			opts.OpenAPIOut = *openAPIOut
+		}
+		if *schemas != "" {
+			opts.Schemas = splitList(*schemas)
+		}
+		flagTypes, err := parseTypeMap(*typeMap)
+		if err != nil {
+			return err
+		}
+		opts.TypeMap = maps.Clone(opts.TypeMap)
+		if opts.TypeMap == nil {
+			opts.TypeMap = flagTypes
+		} else {
+			maps.Copy(opts.TypeMap, flagTypes)
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if *watch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			fingerprint := func() (string, error) { return genFingerprint(db, c.opts.withDefaults(), opts.Schemas) }
+			generate := func() error { return recoverGen(func() error { return generateGormCode(db, *out, opts) }) }
+			if err := watchSchema(ctx, *watchInterval, fingerprint, generate); err != nil {
+				return fmt.Errorf("failed to generate GORM code: %w", err)
+			}
+			os.Exit(0)
+			return nil
+		}
+		if err := generateGormCode(db, *out, opts); err != nil {
+			return fmt.Errorf("failed to generate GORM code: %w", err)
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleERD(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	outPath := fs.String("out", "", "Output file of the diagram, e.g. schema.mmd (default stdout)")
+	format := fs.String("format", "", "Diagram format: mermaid, dbml or plantuml (default from the --out extension, else mermaid)")
+	schema := fs.String("schema", "", "Postgres schema of the tables (default the current schema)")
+	tables := fs.String("tables", "", "Comma-separated table globs to include, e.g. users,billing_* (default all)")
+
+	return func() error {
+		diagramFormat, err := erdFormat(*format, *outPath)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		diagram, err := readERD(db, c.opts, *schema, splitList(*tables))
+		if err != nil {
+			return err
+		}
+		text := erdFormats[diagramFormat](diagram)
+		if *outPath == "" {
+			fmt.Print(text)
+			os.Exit(0)
+		}
+		if dir := filepath.Dir(*outPath); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create dir %s: %w", dir, err)
+			}
+		}
+		if err := os.WriteFile(*outPath, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", *outPath, err)
+		}
+		out.Printf("✅ ERD of %d tables written to: %s\n", len(diagram.Tables), *outPath)
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleStatus(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	group := fs.String("group", "", "Comma-separated migration groups to operate on (default all)")
+	timings := fs.Bool("timings", false, "Also print how long each applied migration took")
+	withSlow := addSlowFlag(fs)
+
+	return func() error {
+		selected, err := c.selectGroups(*group)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		printMigrationStatus(db, selected, c.opts, false)
+		if *timings {
+			entries, err := readHistory(db, c.opts)
+			if err != nil {
+				return err
+			}
+			printTimings(entries, withSlow(c.opts).SlowMigration)
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleHistory(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	table := fs.String("table", "", "Only list migrations that touched this table")
+
+	return func() error {
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		entries, err := readHistory(db, c.opts)
+		if err != nil {
+			return err
+		}
+		printHistory(entries, *table)
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleListObjects(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	group := fs.String("group", "", "Comma-separated migration groups to operate on (default all)")
+
+	return func() error {
+		selected, err := c.selectGroups(*group)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		status, err := GetStatus(db, selected, c.opts)
+		if err != nil {
+			return err
+		}
+		printObjectOwners(selected, status)
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleLint(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	group := fs.String("group", "", "Comma-separated migration groups to operate on (default all)")
+
+	return func() error {
+		selected, err := c.selectGroups(*group)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := checkLint(db, selected, c.opts); err != nil {
+			return err
+		}
+		out.Println("✅ No lock-heavy operations found in pending migrations")
+		os.Exit(0)
+		return nil
+	}
+}
+
+// checkLint prints the lint findings of the pending migrations and returns an error if there are any.
+func checkLint(db *gorm.DB, migrations []*Migration, opts Options) error {
+	findings, err := Lint(db, migrations, opts)
+	if err != nil {
+		return fmt.Errorf("failed to lint migrations: %w", err)
+	}
+	for _, f := range findings {
+		out.Println("⚠️  " + f.String())
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d lock-heavy operation(s) in pending migrations", len(findings))
+	}
+	return nil
+}
+
+func (c *cli) handleRegression(fs *flag.FlagSet) func() error {
+	ownerDatabaseURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	devDatabaseURL := fs.String("regression-db-url", "", "Target database connection URL (default $REGRESSION_DATABASE_URL)")
+	regressionDatabaseName := fs.String("db-name", "", "Regression test database name")
+	planOnly := fs.Bool("plan-only", false, "Print the operations of the regression run with redacted URLs, without connecting")
+	allowProtected := fs.Bool("allow-protected", false, "Run even if owner-db-url or regression-db-url points at a protected host")
+	template := fs.String("template", "", "After a successful run, replace this database with a copy of the migrated regression database, for CloneForTest (PostgreSQL)")
+	seed := fs.Bool("seed", false, "Run the seeds of Options.Seeds after the first up and check that the data survives a down and up")
+	fixtures := fs.String("fixtures", "", "Load the fixtures of this directory after the first up and check that the data survives a down and up")
+	dataSteps := fs.Int("data-steps", 1, "Number of migrations rolled back and applied again with data")
+	preserve := fs.String("preserve", "", "Comma-separated tables whose row counts must not change (default all but --expect-empty)")
+	expectEmpty := fs.String("expect-empty", "", "Comma-separated tables expected to be empty after the down and up with data")
+	docker := fs.String("docker", "", "Run against a throwaway postgres or mysql Docker container instead of owner-db-url and regression-db-url")
+	dockerImage := fs.String("docker-image", "", "Image of the --docker container (default postgres:16-alpine or mysql:8.4)")
+	reportFormat := fs.String("report-format", "junit", "Format of the --report-out report: junit or json")
+	reportOut := fs.String("report-out", "", "Write a report with one test case per migration up and down to this file")
+	sinceID := fs.String("since", "", "Only test the migrations from this ID on, starting from the baseline of --baseline-schema or --baseline-template")
+	baselineSchema := fs.String("baseline-schema", "", "With --since, load this schema file of dump-schema, recording the migrations up to the baseline")
+	baselineTemplate := fs.String("baseline-template", "", "With --since, clone this database, migrated up to the baseline, instead of creating an empty one (PostgreSQL)")
+	onlyPending := fs.Bool("only-pending", false, "Like --since, from the first migration not applied to the db-url database")
+	databaseURL := fs.String("db-url", "", "Database whose pending migrations --only-pending tests (default $DATABASE_URL)")
+	withSlow := addSlowFlag(fs)
+
+	return func() error {
+		var container *dockerDatabase
+		if *docker != "" {
+			if *planOnly || *template != "" {
+				return fmt.Errorf("plan-only and template cannot be used with docker")
+			}
+			if *regressionDatabaseName == "" {
+				*regressionDatabaseName = "gormeasy_regression"
+			}
+			var err error
+			if container, err = startDockerDatabase(*docker, *dockerImage, c.getGormFromURL); err != nil {
+				return err
+			}
+			defer container.stop()
+			*ownerDatabaseURL = container.ownerURL
+			if *devDatabaseURL, err = withDatabaseName(container.ownerURL, *regressionDatabaseName); err != nil {
+				return err
+			}
+		}
+		if *ownerDatabaseURL == "" {
+			return fmt.Errorf("owner-db-url is required")
+		}
+		var data *regressionData
+		if *seed || *fixtures != "" {
+			data = &regressionData{fixtures: *fixtures, steps: *dataSteps, preserved: splitList(*preserve), empty: splitList(*expectEmpty)}
+			if *seed {
+				if len(c.opts.Seeds) == 0 {
+					return fmt.Errorf("no seeds configured, set Options.Seeds")
+				}
+				data.seeds = c.opts.Seeds
+			}
+		} else if *preserve != "" || *expectEmpty != "" {
+			return fmt.Errorf("preserve and expect-empty need --seed or --fixtures")
+		}
+		bootstrap, tested := regressionBootstrap{schemaFile: *baselineSchema, template: *baselineTemplate}, c.migrations
+		if *onlyPending && *sinceID != "" {
+			return fmt.Errorf("since and only-pending cannot be used together")
+		}
+		if *onlyPending && *planOnly {
+			// The first pending migration is only known once connected
+			bootstrap.pendingFrom = *databaseURL
+		} else if *onlyPending {
+			db, err := getGorm(*databaseURL, c.getGormFromURL)
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			applied := make(map[string]bool)
+			if db.Migrator().HasTable(c.opts.TableName) {
+				applied = getAppliedIDs(db, c.opts)
+			}
+			if *sinceID = firstPending(c.migrations, applied); *sinceID == "" {
+				out.Println("✅ No pending migrations to test.")
+				container.stop()
+				os.Exit(0)
+			}
+		}
+		if *sinceID != "" {
+			var err error
+			if bootstrap.baseline, tested, err = regressionBaseline(c.migrations, *sinceID); err != nil {
+				return err
+			}
+		} else if (*baselineSchema != "" || *baselineTemplate != "") && !*onlyPending {
+			return fmt.Errorf("baseline-schema and baseline-template need --since or --only-pending")
+		}
+		if *baselineSchema != "" && *baselineTemplate != "" {
+			return fmt.Errorf("baseline-schema and baseline-template cannot be used together")
+		}
+		if bootstrap.baseline != "" && *baselineSchema == "" && *baselineTemplate == "" {
+			return fmt.Errorf("since needs --baseline-schema or --baseline-template to start from the baseline %s", bootstrap.baseline)
+		}
+		if *baselineTemplate != "" && container != nil {
+			return fmt.Errorf("baseline-template cannot be used with docker, use --baseline-schema")
+		}
+
+		if *devDatabaseURL == "" {
+			return fmt.Errorf("regression-db-url is required")
+		}
+
+		if *regressionDatabaseName == "" {
+			return fmt.Errorf("db-name is required")
+		}
+		var report *regressionReport
+		if *reportOut != "" {
+			if *reportFormat != "junit" && *reportFormat != "json" {
+				return fmt.Errorf("unknown report format %q, use junit or json", *reportFormat)
+			}
+			report = &regressionReport{Database: *regressionDatabaseName}
+		}
+		if err := checkRegressionTargets(*ownerDatabaseURL, *devDatabaseURL, c.resolveURL(urlFlags[0]), *regressionDatabaseName); err != nil {
+			return err
+		}
+		for _, u := range []string{*ownerDatabaseURL, *devDatabaseURL} {
+			if err := c.protected.check("regression", u, *allowProtected); err != nil {
+				return err
+			}
+		}
+		if *baselineTemplate == *regressionDatabaseName {
+			return fmt.Errorf("baseline-template must differ from db-name %s", *regressionDatabaseName)
+		}
+		if *template != "" {
+			if *template == *regressionDatabaseName {
+				return fmt.Errorf("template must differ from db-name %s", *regressionDatabaseName)
+			}
+			if err := checkDisposableDatabase(*ownerDatabaseURL, c.resolveURL(urlFlags[0]), *template); err != nil {
+				return err
+			}
+		}
+		if *planOnly {
+			if err := printRegressionPlan(*ownerDatabaseURL, *devDatabaseURL, *regressionDatabaseName, tested, bootstrap, data, *template, c.opts.ProtectedDatabases); err != nil {
+				return err
+			}
+			os.Exit(0)
+		}
+
+		ownerDB, err := getGorm(*ownerDatabaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err = DeleteDatabaseWithOptions(ownerDB, *regressionDatabaseName, c.opts); err != nil {
+			return err
+		}
+		if bootstrap.baseline != "" && bootstrap.template != "" {
+			err = cloneDatabase(ownerDB, bootstrap.template, *regressionDatabaseName, c.opts.ProtectedDatabases)
+		} else {
+			err = CreateDatabase(ownerDB, *regressionDatabaseName)
+		}
+		if err != nil {
+			return err
+		}
+
+		devDB, err := getGorm(*devDatabaseURL, c.getGormFromURL)
+		if err != nil {
+			return err
+		}
+		if len(c.opts.Extensions) > 0 {
+			if err := EnsureExtension(devDB, c.opts.Extensions...); err != nil {
+				return err
+			}
+		}
+		if bootstrap.baseline != "" && bootstrap.schemaFile != "" {
+			if err := LoadSchema(devDB, c.opts, c.migrations, bootstrap.schemaFile); err != nil {
+				return err
+			}
+		}
+		n := c.notifier("regression")
+		start := c.opts.Clock.Now()
+		err = runRegression(devDB, c.migrations, withSlow(c.opts), data, report, bootstrap.baseline)
+		if report != nil {
+			report.finish(since(c.opts.Clock, start), err)
+			if werr := report.write(*reportFormat, *reportOut); werr != nil {
+				err = errors.Join(err, werr)
+			}
+		}
+		if n != nil {
+			n.summary = fmt.Sprintf("%d migrations applied, rolled back and applied again", len(tested))
+		}
+		n.send(*regressionDatabaseName, err)
+		if err != nil {
+			return err
+		}
+		if *template != "" {
+			if err = DeleteDatabaseWithOptions(ownerDB, *template, c.opts); err != nil {
+				return err
+			}
+			if err = cloneDatabase(ownerDB, *regressionDatabaseName, *template, c.opts.ProtectedDatabases); err != nil {
+				return err
+			}
+		}
+
+		out.Println("✅ Regression test complete, migration all up and all down, and migrate again, all pass.")
+
+		// os.Exit skips the deferred stop
+		container.stop()
+		os.Exit(0)
+		return nil
+	}
+}
+
+// runRegression applies all migrations, rolls them back and applies them again, printing the status after each step.
+// With data, the database is seeded after the first up and checked to keep its data, see regressionData.
+// With report, each migration applied or rolled back is recorded in report.
+// With baseline, db must already be bootstrapped to baseline, whose migrations are left out of the cycle.
+func runRegression(db *gorm.DB, migrations []*Migration, opts Options, data *regressionData, report *regressionReport, baseline string) error {
+	if baseline != "" {
+		if err := checkBaseline(db, migrations, opts, baseline); err != nil {
+			return err
+		}
+	}
+	hooked := migrations
+	if report != nil {
+		hooked = withHooks(hooked, report.hooks())
+	}
+	if opts.SlowMigration > 0 {
+		hooked = withHooks(hooked, timingHooks(nil, opts.SlowMigration))
+	}
+	m := getMigrator(db, hooked, opts)
+
+	report.setPhase(regressionPhaseUp)
+	if err := m.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	printMigrationStatus(db, migrations, opts, true)
+	if data != nil {
+		report.setPhase(regressionPhaseData)
+		if err := data.run(db, m, opts); err != nil {
+			return err
+		}
+	}
+
+	report.setPhase(regressionPhaseDown)
+	if err := rollbackToBaseline(m, baseline); err != nil {
+		return fmt.Errorf("failed to rollback all migrations: %w", err)
+	}
+	printMigrationStatus(db, migrations, opts, true)
+
+	report.setPhase(regressionPhaseUpAgain)
+	if err := m.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate again database: %w", err)
+	}
+
+	printMigrationStatus(db, migrations, opts, true)
+	return nil
+}
+
+func (c *cli) handleExample(fs *flag.FlagSet) func() error {
+	ownerDatabaseURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL, or a temporary SQLite database)")
+	exampleDatabaseName := fs.String("db-name", defaultExampleDatabase, "Name of the disposable example database")
+	keep := fs.Bool("keep", false, "Keep the example database instead of deleting it afterwards")
+
+	return func() error {
+		if *ownerDatabaseURL == "" {
+			if err := runSQLiteExample(*exampleDatabaseName, *keep); err != nil {
+				return err
+			}
+			os.Exit(0)
+		}
+		if err := checkDisposableDatabase(*ownerDatabaseURL, c.resolveURL(urlFlags[0]), *exampleDatabaseName); err != nil {
+			return err
+		}
+		if err := runExample(*ownerDatabaseURL, *exampleDatabaseName, *keep, c.opts.ProtectedDatabases, c.getGormFromURL); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleSeed(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+
+	return func() error {
+		if len(c.opts.Seeds) == 0 {
+			return fmt.Errorf("no seeds configured, set Options.Seeds")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := RunSeeds(db, c.opts.Seeds); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleLoadFixtures(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	dir := fs.String("dir", "fixtures", "Directory of the .yaml, .yml, .json and .csv fixture files")
+	allowProtected := fs.Bool("allow-protected", false, "Run even if db-url points at a protected host")
+
+	return func() error {
+		if err := c.protected.check("load-fixtures", *databaseURL, *allowProtected); err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := LoadFixtures(db, os.DirFS(*dir), "."); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleExportFixtures(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	tables := fs.String("tables", "", "Comma-separated tables to export")
+	outDir := fs.String("out", "", "Output directory for the fixture files")
+	format := fs.String("format", "yaml", "Fixture file format: yaml or json")
+	limit := fs.Int("limit", 0, "Maximum number of rows exported per table (default all)")
+	mask := fs.String("mask", "", "Comma-separated columns to mask, as column or table.column")
+	anonymize := fs.Bool("anonymize", false, "Anonymize the exported values with the rules of Options.Anonymize")
+
+	return func() error {
+		if *tables == "" {
+			return fmt.Errorf("tables is required")
+		}
+		if *outDir == "" {
+			return fmt.Errorf("out is required")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		opts := FixtureExportOptions{Format: *format, Limit: *limit, Mask: splitList(*mask), AnonymizeSecret: c.opts.AnonymizeSecret}
+		if *anonymize {
+			if err := validateAnonymizeRules(c.opts.Anonymize); err != nil {
+				return err
+			}
+			opts.Anonymize = c.opts.Anonymize
+		}
+		if err := ExportFixtures(db, splitList(*tables), *outDir, opts); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleSnapshotData(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	tables := fs.String("tables", "", "Comma-separated tables to snapshot, parents before children")
+	outDir := fs.String("out", "", "Output directory for the snapshot")
+
+	return func() error {
+		if *tables == "" {
+			return fmt.Errorf("tables is required")
+		}
+		if *outDir == "" {
+			return fmt.Errorf("out is required")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := SnapshotData(db, splitList(*tables), *outDir); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleRestoreData(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	in := fs.String("in", "", "Snapshot directory written by snapshot-data")
+
+	return func() error {
+		if *in == "" {
+			return fmt.Errorf("in is required")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := RestoreData(db, *in); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleCopyData(fs *flag.FlagSet) func() error {
+	sourceURL := fs.String("source-db-url", "", "Connection URL of the database to copy the rows from")
+	databaseURL := fs.String("db-url", "", "Connection URL of the database to copy the rows to (default $DATABASE_URL)")
+	var copyOpts CopyDataOptions
+	tables := fs.String("tables", "", "Comma-separated tables to copy (default all but the gormeasy history tables)")
+	fs.IntVar(&copyOpts.BatchSize, "batch-size", defaultSeedBatchSize, "Number of rows inserted per statement")
+	fs.BoolVar(&copyOpts.Clear, "clear", false, "Delete the rows of the tables in the target first, after confirmation")
+	anonymize := fs.Bool("anonymize", false, "Anonymize the copied values with the rules of Options.Anonymize")
+	yes := addYesFlag(fs)
+	allowProtected := fs.Bool("allow-protected", false, "Run even if db-url points at a protected host")
+
+	return func() error {
+		if *sourceURL == "" {
+			return fmt.Errorf("source-db-url is required")
+		}
+		if *sourceURL == *databaseURL {
+			return fmt.Errorf("source-db-url and db-url are the same database")
+		}
+		if err := c.protected.check("copy-data", *databaseURL, *allowProtected); err != nil {
+			return err
+		}
+		if *anonymize {
+			copyOpts.Anonymize = c.opts.Anonymize
+			if err := validateAnonymizeRules(copyOpts.Anonymize); err != nil {
+				return err
+			}
+		}
+		source, err := getGorm(*sourceURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open source database: %w", err)
+		}
+		target, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if copyOpts.Clear {
+			dbName := target.Migrator().CurrentDatabase()
+			if err := confirmDestructive("delete the rows of the copied tables in "+dbName, dbName, *yes); err != nil {
+				return err
+			}
+		}
+		copyOpts.Tables = splitList(*tables)
+		if err := CopyData(source, target, c.opts, copyOpts); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleVerify(fs *flag.FlagSet) func() error {
+	ownerDatabaseURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	verifyDatabaseName := fs.String("db-name", "gormeasy_verify", "Name of the disposable database the migrations are replayed on")
+	dir := fs.String("dir", "migrations", "Migrations package directory holding "+schemaSumFile)
+	update := fs.Bool("update", false, "Record the current schema of changed migrations instead of failing")
+
+	return func() error {
+		if *ownerDatabaseURL == "" {
+			return fmt.Errorf("owner-db-url is required")
+		}
+		if err := checkDisposableDatabase(*ownerDatabaseURL, c.resolveURL(urlFlags[0]), *verifyDatabaseName); err != nil {
+			return err
+		}
+		path := filepath.Join(*dir, schemaSumFile)
+		sums, err := readSchemaSums(path)
+		if err != nil {
+			return err
+		}
+		err = withDisposableDatabase(*ownerDatabaseURL, *verifyDatabaseName, false, c.opts.ProtectedDatabases, c.getGormFromURL, func(db *gorm.DB) error {
+			return verifyMigrations(db, c.migrations, c.opts, sums, *update)
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeSchemaSums(path, c.migrations, sums); err != nil {
+			return err
+		}
+		out.Println("✅ All migrations produce their recorded schema")
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleExportSQL(fs *flag.FlagSet) func() error {
+	ownerDatabaseURL := fs.String("owner-db-url", "", "Database connection URL with permissions to create and delete databases (default $OWNER_DATABASE_URL)")
+	exportDatabaseName := fs.String("db-name", "gormeasy_export", "Name of the disposable database the migrations are replayed on")
+	outDir := fs.String("out", "migrations/sql", "Directory the SQL files are written to")
+	ids := fs.String("id", "", "Comma-separated migration IDs to export (default all)")
+	format := fs.String("format", "sql", "File layout: sql (<ID>.sql) or golang-migrate (numbered .up.sql and .down.sql files)")
+
+	return func() error {
+		if *ownerDatabaseURL == "" {
+			return fmt.Errorf("owner-db-url is required")
+		}
+		if err := checkDisposableDatabase(*ownerDatabaseURL, c.resolveURL(urlFlags[0]), *exportDatabaseName); err != nil {
+			return err
+		}
+		err := withDisposableDatabase(*ownerDatabaseURL, *exportDatabaseName, false, c.opts.ProtectedDatabases, c.getGormFromURL, func(db *gorm.DB) error {
+			return exportMigrationSQL(db, c.migrations, c.opts, splitList(*ids), *outDir, *format)
+		})
+		if err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleInit(fs *flag.FlagSet) func() error {
+	dir := fs.String("dir", ".", "Project directory to scaffold")
+
+	return func() error {
+		if err := scaffoldProject(*dir, c.opts.IDGenerator); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleNew(fs *flag.FlagSet) func() error {
+	dir := fs.String("dir", "migrations", "Migrations package directory")
+
+	return func() error {
+		name := strings.Join(fs.Args(), " ")
+		if name == "" {
+			return fmt.Errorf("migration name is required, e.g. new add-orders-index")
+		}
+		if _, err := newMigrationFile(*dir, name, c.opts.IDGenerator); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleCompile(fs *flag.FlagSet) func() error {
+	specDir := fs.String("specs", "schema", "Directory of the YAML table specs")
+	dir := fs.String("dir", "migrations", "Migrations package directory")
+	dialect := fs.String("dialect", "postgres", "Database the SQL is generated for: postgres, mysql or sqlite")
+
+	return func() error {
+		name := strings.Join(fs.Args(), " ")
+		if name == "" {
+			name = "compile schema"
+		}
+		if _, err := compileSpecs(*specDir, *dir, *dialect, name, c.opts.IDGenerator); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleDumpSchema(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	outPath := fs.String("out", "schema.sql", "Output path of the SQL file")
+
+	return func() error {
+		if *outPath == "" {
+			return fmt.Errorf("out is required")
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := DumpSchema(db, c.opts, c.migrations, *outPath); err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleLoadSchema(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	file := fs.String("file", "schema.sql", "SQL file written by dump-schema")
+
+	return func() error {
+		if *file == "" {
+			return fmt.Errorf("file is required")
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := LoadSchema(db, c.opts, c.migrations, *file); err != nil {
+			return err
+		}
+		printMigrationStatus(db, c.migrations, c.opts, true)
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleBaseline(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	to := fs.String("to", "", "Last migration ID already reflected in the database schema")
+
+	return func() error {
+		if *to == "" {
+			return fmt.Errorf("to is required")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := Baseline(db, c.opts, c.migrations, *to); err != nil {
+			return err
+		}
+		printMigrationStatus(db, c.migrations, c.opts, true)
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleImportHistory(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	from := fs.String("from", "", "Tool whose history is imported: golang-migrate, goose or flyway")
+	mapPath := fs.String("map", "", "File mapping each version of the tool to a migration ID, one \"<version> <migration ID>\" per line")
+	table := fs.String("table", "", "History table of the tool (default schema_migrations, goose_db_version or flyway_schema_history)")
+	dryRun := fs.Bool("dry-run", false, "Only print the migrations that would be recorded as applied")
+
+	return func() error {
+		if *from == "" {
+			return fmt.Errorf("from is required")
+		}
+		if *mapPath == "" {
+			return fmt.Errorf("map is required")
+		}
+		mapping, err := readVersionMapping(*mapPath)
+		if err != nil {
+			return err
+		}
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := ImportHistory(db, c.opts, c.migrations, *from, *table, mapping, *dryRun); err != nil {
+			return err
+		}
+		if !*dryRun {
+			printMigrationStatus(db, c.migrations, c.opts, true)
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+func (c *cli) handleSquash(fs *flag.FlagSet) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	to := fs.String("to", "", "Last migration ID to squash")
+	id := fs.String("id", "", "ID of the baseline migration replacing the squashed migrations")
+	outPath := fs.String("out", "", "Output path of the generated baseline migration (.go), the schema is written next to it (.sql)")
+	apply := fs.Bool("apply", false, "Rewrite the history table of an existing database instead of generating files")
+
+	return func() error {
+		if *to == "" {
+			return fmt.Errorf("to is required")
+		}
+		if *id == "" {
+			return fmt.Errorf("id is required")
+		}
+		if !*apply && *outPath == "" {
+			return fmt.Errorf("out is required")
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if *apply {
+			err = SquashHistory(db, c.opts, c.migrations, *to, *id)
+		} else {
+			err = writeSquashedMigration(db, c.opts, c.migrations, *to, *id, *outPath)
+		}
+		if err != nil {
+			return err
+		}
+		os.Exit(0)
+		return nil
+	}
+}
+
+// handleMark implements mark-applied and mark-reverted, which only change the history table.
+func (c *cli) handleMark(fs *flag.FlagSet, mark func(*gorm.DB, Options, ...string) error) func() error {
+	databaseURL := fs.String("db-url", "", "Development database connection URL (default $DATABASE_URL)")
+	ids := fs.String("id", "", "Comma-separated migration IDs")
+
+	return func() error {
+		if *ids == "" {
+			return fmt.Errorf("id is required")
+		}
+		idList := splitList(*ids)
+		if err := checkKnownMigrations(c.migrations, idList); err != nil {
+			return err
+		}
+
+		db, err := getGorm(*databaseURL, c.getGormFromURL)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		if err := mark(db, c.opts, idList...); err != nil {
+			return err
+		}
+		printMigrationStatus(db, c.migrations, c.opts, false)
+		os.Exit(0)
+		return nil
+	}
+}
+
+// addTimeoutFlags defines --statement-timeout and --lock-timeout on fs. The returned function
+// overrides the timeouts of opts with the flags that were set.
+func addTimeoutFlags(fs *flag.FlagSet) func(opts Options) Options {
+	statementTimeout := fs.Duration("statement-timeout", 0, "Abort migration statements running longer than this, e.g. 5m (default Options.StatementTimeout)")
+	lockTimeout := fs.Duration("lock-timeout", 0, "Abort migration statements waiting longer than this for a lock, e.g. 5s (default Options.LockTimeout)")
+	return func(opts Options) Options {
+		if *statementTimeout > 0 {
+			opts.StatementTimeout = *statementTimeout
+		}
+		if *lockTimeout > 0 {
+			opts.LockTimeout = *lockTimeout
+		}
+		return opts
+	}
+}
+
+// selectGroups returns the migrations of the comma-separated groups, or all migrations when groups is empty.
+func (c *cli) selectGroups(groups string) ([]*Migration, error) {
+	selected := filterGroups(c.migrations, splitList(groups))
+	if len(selected) == 0 && groups != "" {
+		return nil, fmt.Errorf("no migrations in group %s", groups)
+	}
+	return selected, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,104 @@
+package gormeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+)
+
+// TestCommandTable tests that command names are unique and every command sets up its flags
+func TestCommandTable(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, cmd := range commands {
+		for _, name := range append([]string{cmd.name}, cmd.aliases...) {
+			if seen[name] {
+				t.Errorf("Expected unique command names and aliases, got duplicate %s", name)
+			}
+			seen[name] = true
+		}
+
+		if run := cmd.setup(&cli{}, flag.NewFlagSet(cmd.name, flag.ContinueOnError)); run == nil {
+			t.Errorf("Expected command %s to return a run function, got nil", cmd.name)
+		}
+	}
+
+	if findCommand("up") == nil {
+		t.Error("Expected to find command up, got nil")
+	}
+	if cmd := findCommand("rollback"); cmd == nil || cmd.name != "down" {
+		t.Errorf("Expected alias rollback to find command down, got %v", cmd)
+	}
+	if findCommand("serve") != nil {
+		t.Error("Expected unknown command serve to be nil")
+	}
+}
+
+// TestAddShortFlags tests that shorthands share the value of their long flag
+func TestAddShortFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	dbURL := fs.String("db-url", "", "Database connection URL")
+	addShortFlags(fs)
+
+	if fs.Lookup("n") != nil {
+		t.Error("Expected no -n shorthand without a --db-name flag")
+	}
+	if err := fs.Parse([]string{"-d", "postgres://localhost/app"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *dbURL != "postgres://localhost/app" {
+		t.Errorf("Expected db-url to be set through -d, got '%s'", *dbURL)
+	}
+}
+
+// TestPrintHelpJSON tests that the JSON help lists every command with its flags and shorthands
+func TestPrintHelpJSON(t *testing.T) {
+	global := flag.NewFlagSet("test", flag.ContinueOnError)
+	global.String("db-url", "", "Default database connection URL for every command")
+	addShortFlags(global)
+
+	var buf bytes.Buffer
+	if err := printHelpJSON(&buf, global); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var doc helpDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if len(doc.GlobalFlags) != 1 || doc.GlobalFlags[0].Short != "d" {
+		t.Errorf("Expected the global --db-url flag with shorthand -d only, got %+v", doc.GlobalFlags)
+	}
+	if len(doc.Commands) != len(commands) {
+		t.Fatalf("Expected %d commands, got %d", len(commands), len(doc.Commands))
+	}
+
+	var up *helpCommand
+	for i := range doc.Commands {
+		if doc.Commands[i].Name == "up" {
+			up = &doc.Commands[i]
+		}
+	}
+	if up == nil || len(up.Aliases) != 1 || up.Aliases[0] != "migrate" {
+		t.Fatalf("Expected command up with alias migrate, got %+v", up)
+	}
+	types := make(map[string]string)
+	for _, f := range up.Flags {
+		types[f.Name] = f.Type
+	}
+	if types["no-exit"] != "bool" || types["db-url"] != "string" || types["lock-timeout"] != "duration" {
+		t.Errorf("Expected flag types bool, string and duration, got %v", types)
+	}
+}
+
+// TestFirstCommand tests finding the command after unknown global flags
+func TestFirstCommand(t *testing.T) {
+	if cmd := firstCommand([]string{"--bogus", "-v", "up", "--no-exit"}); cmd == nil || cmd.name != "up" {
+		t.Errorf("Expected up, got %v", cmd)
+	}
+	if cmd := firstCommand([]string{"--port", "8080", "up"}); cmd != nil {
+		t.Errorf("Expected no command for an application flag value, got %s", cmd.name)
+	}
+	if cmd := firstCommand([]string{"--bogus", "serve"}); cmd != nil {
+		t.Errorf("Expected no command for an application command, got %s", cmd.name)
+	}
+}
@@ -0,0 +1,121 @@
+package gormeasy
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationStatus is a snapshot of the migration state of a database.
+type MigrationStatus struct {
+	// Applied lists the applied migration IDs, in migration order.
+	Applied []string `json:"applied"`
+	// Pending lists the migration IDs that are not applied yet, in migration order.
+	Pending []string `json:"pending"`
+	// Gated lists the migration IDs held back because their GateFlag is off, in migration order.
+	// They are not part of Pending.
+	Gated []string `json:"gated,omitempty"`
+	// Unknown lists applied IDs that are not defined in code, sorted.
+	Unknown []string `json:"unknown,omitempty"`
+	// CheckedAt is when the history table was read.
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// UpToDate reports whether every known migration is applied, except the gated ones.
+func (s *MigrationStatus) UpToDate() bool {
+	return len(s.Pending) == 0
+}
+
+// GetStatus reads the history table and returns the applied and pending migrations.
+// It never creates the history table: a database without one has every migration pending.
+func GetStatus(db *gorm.DB, migrations []*Migration, opts Options) (*MigrationStatus, error) {
+	opts = opts.withDefaults()
+	status := &MigrationStatus{CheckedAt: opts.Clock.Now()}
+
+	applied := make(map[string]bool)
+	if db.Migrator().HasTable(opts.TableName) {
+		var ids []string
+		if err := db.Table(opts.TableName).Pluck(opts.IDColumnName, &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to read migration table: %w", err)
+		}
+		for _, id := range ids {
+			applied[id] = true
+		}
+	}
+
+	gated, err := gatedMigrations(db, migrations, applied, opts.FlagProvider)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if applied[m.ID] {
+			status.Applied = append(status.Applied, m.ID)
+		} else if _, ok := gated[m.ID]; ok {
+			status.Gated = append(status.Gated, m.ID)
+		} else {
+			status.Pending = append(status.Pending, m.ID)
+		}
+	}
+	status.Unknown = findUnknownMigrations(migrations, applied)
+	return status, nil
+}
+
+// statusGeneration is incremented whenever gormeasy changes a history table in this process,
+// invalidating every StatusCache.
+var statusGeneration atomic.Uint64
+
+// invalidateStatusCaches marks all cached statuses as stale, after up, down or history edits.
+func invalidateStatusCaches() {
+	statusGeneration.Add(1)
+}
+
+// StatusCache caches the migration status for a TTL, so health endpoints polled every few
+// seconds don't query the database each time. The cache is invalidated explicitly by
+// Invalidate, and automatically whenever migrations are applied or rolled back in this process.
+// It is safe for concurrent use.
+type StatusCache struct {
+	db         *gorm.DB
+	migrations []*Migration
+	opts       Options
+	ttl        time.Duration
+
+	mu         sync.Mutex
+	status     *MigrationStatus
+	generation uint64
+	expires    time.Time
+}
+
+// NewStatusCache returns a cache of the migration status of db that is refreshed after ttl.
+func NewStatusCache(db *gorm.DB, migrations []*Migration, opts Options, ttl time.Duration) *StatusCache {
+	return &StatusCache{db: db, migrations: migrations, opts: opts, ttl: ttl}
+}
+
+// Status returns the cached status, reading the history table when the cache is stale.
+// Errors are not cached.
+func (c *StatusCache) Status() (*MigrationStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation := statusGeneration.Load()
+	if c.status != nil && c.generation == generation && c.opts.withDefaults().Clock.Now().Before(c.expires) {
+		return c.status, nil
+	}
+	status, err := GetStatus(c.db, c.migrations, c.opts)
+	if err != nil {
+		return nil, err
+	}
+	c.status = status
+	c.generation = generation
+	c.expires = c.opts.withDefaults().Clock.Now().Add(c.ttl)
+	return status, nil
+}
+
+// Invalidate drops the cached status, so the next Status call reads the history table.
+func (c *StatusCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = nil
+}
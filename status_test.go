@@ -0,0 +1,40 @@
+package gormeasy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStatusCacheInvalidation tests that cached statuses expire after invalidation
+func TestStatusCacheInvalidation(t *testing.T) {
+	cached := &MigrationStatus{Applied: []string{"1-create-users"}}
+	c := NewStatusCache(nil, nil, Options{}, time.Hour)
+	c.status = cached
+	c.generation = statusGeneration.Load()
+	c.expires = time.Now().Add(time.Hour)
+
+	if status, err := c.Status(); err != nil || status != cached {
+		t.Fatalf("Expected the cached status, got %v, %v", status, err)
+	}
+
+	invalidateStatusCaches()
+	if c.generation == statusGeneration.Load() {
+		t.Error("Expected invalidateStatusCaches to change the generation")
+	}
+
+	c.generation = statusGeneration.Load()
+	c.Invalidate()
+	if c.status != nil {
+		t.Error("Expected Invalidate to drop the cached status")
+	}
+}
+
+// TestMigrationStatusUpToDate tests that a status without pending migrations is up to date
+func TestMigrationStatusUpToDate(t *testing.T) {
+	if !(&MigrationStatus{Applied: []string{"1"}}).UpToDate() {
+		t.Error("Expected status without pending migrations to be up to date")
+	}
+	if (&MigrationStatus{Pending: []string{"2"}}).UpToDate() {
+		t.Error("Expected status with pending migrations not to be up to date")
+	}
+}
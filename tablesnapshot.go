@@ -0,0 +1,110 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// tableSnapshotInfix separates the table name from the timestamp in snapshot table names.
+const tableSnapshotInfix = "_snapshot_"
+
+// tableSnapshotTimeFormat is the timestamp format of snapshot table names, which sorts by time.
+const tableSnapshotTimeFormat = "20060102150405"
+
+// maxTableNameLength is the identifier length limit of PostgreSQL (63), the lowest of the
+// supported databases.
+const maxTableNameLength = 63
+
+// SnapshotTable copies the rows of table into a new table named like orders_snapshot_20240101120000,
+// for data-modifying migrations whose Rollback restores the data with RestoreTable:
+//
+//	Migrate: func(tx *gorm.DB) error {
+//		if _, err := gormeasy.SnapshotTable(tx, "orders"); err != nil {
+//			return err
+//		}
+//		return tx.Exec("UPDATE orders SET amount = amount * 100").Error
+//	},
+//	Rollback: func(tx *gorm.DB) error {
+//		return gormeasy.RestoreTable(tx, "orders")
+//	},
+//
+// The snapshot holds the columns and rows of table, but no indexes or constraints.
+// It returns the name of the snapshot table.
+func SnapshotTable(tx *gorm.DB, table string) (string, error) {
+	if !tx.Migrator().HasTable(table) {
+		return "", fmt.Errorf("table %s does not exist", table)
+	}
+	snapshot := tableSnapshotPrefix(table) + clockOf(tx).Now().UTC().Format(tableSnapshotTimeFormat)
+	createSQL := fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", tx.Statement.Quote(snapshot), tx.Statement.Quote(table))
+	if err := tx.Exec(createSQL).Error; err != nil {
+		return "", fmt.Errorf("failed to snapshot table %s: %w", table, err)
+	}
+	out.Printf("📸 Snapshot of %s saved in: %s\n", table, snapshot)
+	return snapshot, nil
+}
+
+// RestoreTable replaces the rows of table with those of its latest snapshot taken by
+// SnapshotTable, then drops the snapshot. Only the columns of the snapshot are restored, so
+// restore the schema of table first when the migration changed it. Rows referenced by
+// foreign keys of other tables must still exist in the snapshot.
+func RestoreTable(tx *gorm.DB, table string) error {
+	tables, err := tx.Migrator().GetTables()
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	snapshot := latestTableSnapshot(table, tables)
+	if snapshot == "" {
+		return fmt.Errorf("no snapshot of table %s found", table)
+	}
+
+	columnTypes, err := tx.Migrator().ColumnTypes(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", snapshot, err)
+	}
+	columns := make([]string, len(columnTypes))
+	for i, column := range columnTypes {
+		columns[i] = tx.Statement.Quote(column.Name())
+	}
+	columnList := strings.Join(columns, ", ")
+
+	if err := tx.Exec(fmt.Sprintf("DELETE FROM %s", tx.Statement.Quote(table))).Error; err != nil {
+		return fmt.Errorf("failed to restore table %s: %w", table, err)
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tx.Statement.Quote(table), columnList, columnList, tx.Statement.Quote(snapshot))
+	if err := tx.Exec(insertSQL).Error; err != nil {
+		return fmt.Errorf("failed to restore table %s: %w", table, err)
+	}
+	if err := tx.Migrator().DropTable(snapshot); err != nil {
+		return fmt.Errorf("failed to drop snapshot %s: %w", snapshot, err)
+	}
+	out.Printf("✅ Restored %s from: %s\n", table, snapshot)
+	return nil
+}
+
+// tableSnapshotPrefix returns the name of the snapshots of table up to the timestamp,
+// shortening table so that snapshot names fit in maxTableNameLength.
+func tableSnapshotPrefix(table string) string {
+	if limit := maxTableNameLength - len(tableSnapshotInfix) - len(tableSnapshotTimeFormat); len(table) > limit {
+		table = table[:limit]
+	}
+	return table + tableSnapshotInfix
+}
+
+// latestTableSnapshot returns the most recent snapshot of table among tables, or "" if there is none.
+func latestTableSnapshot(table string, tables []string) string {
+	prefix := tableSnapshotPrefix(table)
+	var snapshots []string
+	for _, name := range tables {
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if ok && len(stamp) == len(tableSnapshotTimeFormat) && strings.Trim(stamp, "0123456789") == "" {
+			snapshots = append(snapshots, name)
+		}
+	}
+	if len(snapshots) == 0 {
+		return ""
+	}
+	return slices.Max(snapshots)
+}
@@ -0,0 +1,35 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTableSnapshotPrefix tests that snapshot names of long tables fit in the identifier limit
+func TestTableSnapshotPrefix(t *testing.T) {
+	if got := tableSnapshotPrefix("orders"); got != "orders_snapshot_" {
+		t.Errorf("Expected orders_snapshot_, got %s", got)
+	}
+	long := strings.Repeat("t", 80)
+	if got := len(tableSnapshotPrefix(long)) + len(tableSnapshotTimeFormat); got != maxTableNameLength {
+		t.Errorf("Expected snapshot names of %d characters, got %d", maxTableNameLength, got)
+	}
+}
+
+// TestLatestTableSnapshot tests that the most recent snapshot of the table is picked
+func TestLatestTableSnapshot(t *testing.T) {
+	tables := []string{
+		"orders",
+		"orders_snapshot_20240101120000",
+		"orders_snapshot_20240301090000",
+		"orders_snapshot_backup",
+		"orders_items_snapshot_20240401000000",
+		"users_snapshot_20240501000000",
+	}
+	if got := latestTableSnapshot("orders", tables); got != "orders_snapshot_20240301090000" {
+		t.Errorf("Expected orders_snapshot_20240301090000, got %s", got)
+	}
+	if got := latestTableSnapshot("payments", tables); got != "" {
+		t.Errorf("Expected no snapshot, got %s", got)
+	}
+}
@@ -0,0 +1,66 @@
+package gormeasy
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// testDatabaseCount numbers the databases created by CloneForTest in this process.
+var testDatabaseCount atomic.Int64
+
+// PrepareTemplate creates the database templateName on the server of ownerURL if it does not
+// exist and applies the pending migrations to it, so test suites can clone fully migrated
+// databases from it with CloneForTest. It is cheap once the template is up to date: call it
+// from TestMain of every package. Only PostgreSQL is supported.
+func PrepareTemplate(ownerURL, templateName string, migrations []*Migration, getGormFromURL func(string) (*gorm.DB, error), opts Options) error {
+	templateURL, err := withDatabaseName(ownerURL, templateName)
+	if err != nil {
+		return err
+	}
+	ownerDB, err := getGorm(ownerURL, getGormFromURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := CreateDatabase(ownerDB, templateName); err != nil {
+		return err
+	}
+
+	db, err := getGorm(templateURL, getGormFromURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %w", templateName, err)
+	}
+	// The template must have no open connections to be cloned
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	return RunMigrationsWithOptions(db, migrations, opts)
+}
+
+// CloneForTest creates a new database for a single test as a copy of templateName (see
+// PrepareTemplate) and returns its name together with a function deleting it again, e.g.
+// for t.Cleanup. Cloning copies the database files, which takes milliseconds instead of
+// running every migration. Names are unique across parallel tests and test processes.
+// ownerDB must be connected to another database than templateName.
+func CloneForTest(ownerDB *gorm.DB, templateName string) (string, func() error, error) {
+	dbName := testDatabaseName(templateName, os.Getpid(), testDatabaseCount.Add(1))
+	if err := CloneDatabase(ownerDB, templateName, dbName); err != nil {
+		return "", nil, err
+	}
+	drop := func() error {
+		return DeleteDatabase(ownerDB, dbName)
+	}
+	return dbName, drop, nil
+}
+
+// testDatabaseName returns the name of the n-th test database of process pid cloned from
+// templateName, within the 63 bytes PostgreSQL allows for names.
+func testDatabaseName(templateName string, pid int, n int64) string {
+	suffix := fmt.Sprintf("_t%d_%d", pid, n)
+	if len(templateName)+len(suffix) > 63 {
+		templateName = templateName[:63-len(suffix)]
+	}
+	return templateName + suffix
+}
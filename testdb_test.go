@@ -0,0 +1,17 @@
+package gormeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTestDatabaseName tests that test database names are unique and fit PostgreSQL names
+func TestTestDatabaseName(t *testing.T) {
+	if got := testDatabaseName("app_template", 4242, 7); got != "app_template_t4242_7" {
+		t.Errorf("Expected app_template_t4242_7, got %s", got)
+	}
+	long := testDatabaseName(strings.Repeat("x", 70), 4242, 12)
+	if len(long) != 63 || !strings.HasSuffix(long, "_t4242_12") {
+		t.Errorf("Expected a 63 byte name ending in _t4242_12, got %s (%d bytes)", long, len(long))
+	}
+}
@@ -0,0 +1,87 @@
+package gormeasy
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sessionSetting is a session variable set for the duration of a migration run.
+type sessionSetting struct {
+	set   string
+	reset string
+}
+
+// timeoutSettings returns the statements applying the timeouts of opts to a session of the
+// given dialect. Dialects without an equivalent setting are skipped.
+func timeoutSettings(dialect string, opts Options) []sessionSetting {
+	var settings []sessionSetting
+	switch dialect {
+	case "postgres":
+		if opts.StatementTimeout > 0 {
+			settings = append(settings, sessionSetting{
+				set:   fmt.Sprintf("SET statement_timeout = %d", opts.StatementTimeout.Milliseconds()),
+				reset: "RESET statement_timeout",
+			})
+		}
+		if opts.LockTimeout > 0 {
+			settings = append(settings, sessionSetting{
+				set:   fmt.Sprintf("SET lock_timeout = %d", opts.LockTimeout.Milliseconds()),
+				reset: "RESET lock_timeout",
+			})
+		}
+	case "mysql":
+		// MySQL only limits the execution time of SELECT statements
+		if opts.StatementTimeout > 0 {
+			settings = append(settings, sessionSetting{
+				set:   fmt.Sprintf("SET SESSION max_execution_time = %d", opts.StatementTimeout.Milliseconds()),
+				reset: "SET SESSION max_execution_time = DEFAULT",
+			})
+		}
+		if opts.LockTimeout > 0 {
+			// Lock waits are configured in whole seconds
+			seconds := max(int64((opts.LockTimeout+time.Second-1)/time.Second), 1)
+			settings = append(settings,
+				sessionSetting{
+					set:   fmt.Sprintf("SET SESSION lock_wait_timeout = %d", seconds),
+					reset: "SET SESSION lock_wait_timeout = DEFAULT",
+				},
+				sessionSetting{
+					set:   fmt.Sprintf("SET SESSION innodb_lock_wait_timeout = %d", seconds),
+					reset: "SET SESSION innodb_lock_wait_timeout = DEFAULT",
+				},
+			)
+		}
+	case "sqlite":
+		if opts.LockTimeout > 0 {
+			settings = append(settings, sessionSetting{
+				set:   fmt.Sprintf("PRAGMA busy_timeout = %d", opts.LockTimeout.Milliseconds()),
+				reset: "PRAGMA busy_timeout = 0",
+			})
+		}
+	}
+	return settings
+}
+
+// withSessionTimeouts runs fn on a session limited by Options.StatementTimeout and
+// Options.LockTimeout, so a stuck migration can't hold locks indefinitely. Session settings
+// only apply to one connection, so fn receives a session pinned to the connection they were
+// set on; they are reset before the connection is returned to the pool. Without timeouts fn
+// runs directly on db.
+func withSessionTimeouts(db *gorm.DB, opts Options, fn func(conn *gorm.DB) error) error {
+	settings := timeoutSettings(db.Dialector.Name(), opts)
+	if len(settings) == 0 {
+		return fn(db)
+	}
+	return db.Connection(func(conn *gorm.DB) error {
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		for _, s := range settings {
+			defer conn.Exec(s.reset)
+			if err := conn.Exec(s.set).Error; err != nil {
+				return fmt.Errorf("failed to set session timeout: %w", err)
+			}
+		}
+		return fn(conn)
+	})
+}
@@ -0,0 +1,66 @@
+package gormeasy
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestTimeoutSettings tests the session statements applying the timeouts on each dialect
+func TestTimeoutSettings(t *testing.T) {
+	opts := Options{StatementTimeout: 2 * time.Minute, LockTimeout: 1500 * time.Millisecond}
+
+	postgres := timeoutSettings("postgres", opts)
+	if len(postgres) != 2 || postgres[0].set != "SET statement_timeout = 120000" || postgres[1].set != "SET lock_timeout = 1500" {
+		t.Errorf("Expected statement_timeout and lock_timeout settings, got %v", postgres)
+	}
+
+	mysql := timeoutSettings("mysql", opts)
+	if len(mysql) != 3 || mysql[1].set != "SET SESSION lock_wait_timeout = 2" {
+		t.Errorf("Expected lock_wait_timeout rounded up to 2 seconds, got %v", mysql)
+	}
+
+	if settings := timeoutSettings("sqlite", Options{StatementTimeout: time.Minute}); len(settings) != 0 {
+		t.Errorf("Expected no SQLite settings without a lock timeout, got %v", settings)
+	}
+	if settings := timeoutSettings("postgres", Options{}); len(settings) != 0 {
+		t.Errorf("Expected no settings without timeouts, got %v", settings)
+	}
+}
+
+// TestWithSessionTimeoutsDisabled tests that fn runs on db itself when no timeout applies
+func TestWithSessionTimeoutsDisabled(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{Dialector: tests.DummyDialector{}}}
+	var got *gorm.DB
+	if err := withSessionTimeouts(db, Options{LockTimeout: time.Second}, func(conn *gorm.DB) error {
+		got = conn
+		return nil
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != db {
+		t.Error("Expected fn to run on db directly")
+	}
+}
+
+// TestAddTimeoutFlags tests that timeout flags override the options only when set
+func TestAddTimeoutFlags(t *testing.T) {
+	fs := flag.NewFlagSet("up", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	withTimeouts := addTimeoutFlags(fs)
+	if err := fs.Parse([]string{"--lock-timeout", "3s"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	opts := withTimeouts(Options{StatementTimeout: time.Minute, LockTimeout: time.Second})
+	if opts.StatementTimeout != time.Minute {
+		t.Errorf("Expected statement timeout 1m, got %v", opts.StatementTimeout)
+	}
+	if opts.LockTimeout != 3*time.Second {
+		t.Errorf("Expected lock timeout 3s, got %v", opts.LockTimeout)
+	}
+}
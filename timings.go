@@ -0,0 +1,84 @@
+package gormeasy
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// durationColumn is the history column holding how long a migration took to apply, in
+// milliseconds. It was added in feature version 4.
+const durationColumn = "duration_ms"
+
+// timingHooks returns the options whose hooks record how long each migration took to apply in
+// durations, when not nil, and warn about migrations running at least slow, when slow is positive.
+func timingHooks(durations map[string]time.Duration, slow time.Duration) Options {
+	return Options{AfterMigration: func(e MigrationEvent) {
+		if durations != nil && !e.Rollback {
+			durations[e.ID] = e.Duration
+		}
+		if slow > 0 && e.Duration >= slow {
+			action := "Migration"
+			if e.Rollback {
+				action = "Rollback of"
+			}
+			out.Printf("🐢 %s %s took %s (slower than %s)\n", action, e.ID, e.Duration.Round(time.Millisecond), slow)
+		}
+	}}
+}
+
+// saveDurations stores the durations recorded by timingHooks in the history rows of the
+// migrations. Migrations without a history row, e.g. rolled back with the transaction, are skipped.
+func saveDurations(db *gorm.DB, opts Options, durations map[string]time.Duration) error {
+	for id, d := range durations {
+		err := db.Table(opts.TableName).Where(map[string]any{opts.IDColumnName: id}).
+			Update(durationColumn, d.Milliseconds()).Error
+		if err != nil {
+			return fmt.Errorf("failed to record duration of migration %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// printTimings prints how long each applied migration took, marking the migrations that ran at
+// least slow, when slow is positive.
+func printTimings(entries []historyEntry, slow time.Duration) {
+	out.Println("\n=== Migration Timings ===")
+	var total time.Duration
+	slowCount, unrecorded := 0, 0
+	for _, e := range entries {
+		if e.Duration == nil {
+			out.Printf("  - %s  (not recorded)\n", e.ID)
+			unrecorded++
+			continue
+		}
+		total += *e.Duration
+		if slow > 0 && *e.Duration >= slow {
+			out.Printf("  - %s  %s  🐢 slow\n", e.ID, *e.Duration)
+			slowCount++
+		} else {
+			out.Printf("  - %s  %s\n", e.ID, *e.Duration)
+		}
+	}
+	if len(entries) == 0 {
+		out.Println("  (none)")
+		return
+	}
+	out.Printf("Total: %s of %d recorded migrations\n", total, len(entries)-unrecorded)
+	if slowCount > 0 {
+		out.Printf("⚠️  %d migrations took %s or longer.\n", slowCount, slow)
+	}
+}
+
+// addSlowFlag registers the --slow flag and returns a function applying it to opts.
+func addSlowFlag(fs *flag.FlagSet) func(opts Options) Options {
+	slow := fs.Duration("slow", 0, "Highlight migrations running this long or longer, e.g. 30s (default Options.SlowMigration)")
+	return func(opts Options) Options {
+		if *slow > 0 {
+			opts.SlowMigration = *slow
+		}
+		return opts
+	}
+}
@@ -0,0 +1,62 @@
+package gormeasy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimingHooks tests recording durations and warning about slow migrations
+func TestTimingHooks(t *testing.T) {
+	var buf bytes.Buffer
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	durations := make(map[string]time.Duration)
+	hooks := timingHooks(durations, time.Second)
+	hooks.AfterMigration(MigrationEvent{ID: "1", Duration: 200 * time.Millisecond})
+	hooks.AfterMigration(MigrationEvent{ID: "2", Duration: 3 * time.Second})
+	hooks.AfterMigration(MigrationEvent{ID: "2", Rollback: true, Duration: 2 * time.Second})
+
+	if durations["1"] != 200*time.Millisecond || durations["2"] != 3*time.Second {
+		t.Errorf("Expected the durations of the ups, got %v", durations)
+	}
+	s := buf.String()
+	for _, line := range []string{
+		"🐢 Migration 2 took 3s (slower than 1s)\n",
+		"🐢 Rollback of 2 took 2s (slower than 1s)\n",
+	} {
+		if !strings.Contains(s, line) {
+			t.Errorf("Expected %q, got %q", line, s)
+		}
+	}
+	if strings.Contains(s, "Migration 1 ") {
+		t.Errorf("Expected no warning for migration 1, got %q", s)
+	}
+}
+
+// TestPrintTimings tests the timings of status --timings
+func TestPrintTimings(t *testing.T) {
+	var buf bytes.Buffer
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	fast, slow := 150*time.Millisecond, 90*time.Second
+	printTimings([]historyEntry{{ID: "1"}, {ID: "2", Duration: &fast}, {ID: "3", Duration: &slow}}, time.Minute)
+
+	s := buf.String()
+	for _, line := range []string{
+		"  - 1  (not recorded)\n",
+		"  - 2  150ms\n",
+		"  - 3  1m30s  🐢 slow\n",
+		"Total: 1m30.15s of 2 recorded migrations\n",
+		"⚠️  1 migrations took 1m0s or longer.\n",
+	} {
+		if !strings.Contains(s, line) {
+			t.Errorf("Expected %q, got %q", line, s)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// topologicalOrder orders ids so that every id comes after the ids it depends on.
+// Ids without ordering constraints keep their original relative order.
+// It fails on duplicate ids, dependencies on unknown ids and dependency cycles.
+func topologicalOrder(kind string, ids []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if known[id] {
+			return nil, fmt.Errorf("duplicate %s: %s", kind, id)
+		}
+		known[id] = true
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(ids))
+	ordered := make([]string, 0, len(ids))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if p == id {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), id)
+			return fmt.Errorf("%s dependency cycle: %s", kind, strings.Join(cycle, " -> "))
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range deps[id] {
+			if !known[dep] {
+				return fmt.Errorf("%s %s depends on unknown %s %s", kind, id, kind, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		ordered = append(ordered, id)
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
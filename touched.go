@@ -0,0 +1,124 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// touchedTablesColumn is the history column listing the tables a migration touched,
+// comma-separated. It was added in feature version 3.
+const touchedTablesColumn = "touched_tables"
+
+// tablePatterns find the tables touched by a normalized statement. The last submatch of
+// each pattern is a table name; DROP TABLE may list several, separated by commas.
+var tablePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^CREATE (TEMPORARY |TEMP |UNLOGGED )?TABLE (IF NOT EXISTS )?([^\s(]+)`),
+	regexp.MustCompile(`^ALTER TABLE (IF EXISTS )?(ONLY )?([^\s(]+)`),
+	regexp.MustCompile(`^ALTER TABLE .*\bRENAME TO ([^\s(]+)`),
+	regexp.MustCompile(`^DROP TABLE (IF EXISTS )?([^(]+?)(?: CASCADE| RESTRICT)?$`),
+	regexp.MustCompile(`^RENAME TABLE (\S+)`),
+	regexp.MustCompile(`^RENAME TABLE \S+ TO (\S+)`),
+	createIndexPattern,
+	regexp.MustCompile(`^DROP INDEX .*\bON ([^\s(]+)`),
+	regexp.MustCompile(`^(INSERT|REPLACE) (IGNORE )?INTO ([^\s(]+)`),
+	regexp.MustCompile(`^UPDATE (ONLY )?([^\s(]+)`),
+	regexp.MustCompile(`^DELETE FROM (ONLY )?([^\s(]+)`),
+	regexp.MustCompile(`^TRUNCATE (TABLE )?(ONLY )?([^\s(,]+)`),
+}
+
+// statementTables returns the tables created, altered, dropped or written by stmt, in order
+// of appearance. Reads are not reported.
+func statementTables(stmt string) []string {
+	normalized := normalizeStatement(stmt)
+	var tables []string
+	for _, pattern := range tablePatterns {
+		m := pattern.FindStringSubmatch(normalized)
+		if m == nil {
+			continue
+		}
+		for _, name := range strings.Split(m[len(m)-1], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				tables = appendUnique(tables, unquoteTableName(stmt, name))
+			}
+		}
+	}
+	return tables
+}
+
+// appendUnique appends the names of names missing from list.
+func appendUnique(list []string, names ...string) []string {
+	for _, name := range names {
+		if !slices.Contains(list, name) {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// tableRecorder is a GORM logger recording the tables touched by the statements it logs,
+// while passing every call on to the logger it wraps.
+type tableRecorder struct {
+	logger.Interface
+	mu     *sync.Mutex
+	tables *[]string
+}
+
+// LogMode returns a recorder wrapping the logger with the given level, recording into r.
+func (r tableRecorder) LogMode(level logger.LogLevel) logger.Interface {
+	return tableRecorder{Interface: r.Interface.LogMode(level), mu: r.mu, tables: r.tables}
+}
+
+// Trace records the tables of a successful statement.
+func (r tableRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	r.Interface.Trace(ctx, begin, fc, err)
+	if err != nil {
+		return
+	}
+	sql, _ := fc()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.tables = appendUnique(*r.tables, statementTables(sql)...)
+}
+
+// recordTouchedTables returns copies of migrations whose Migrate records the touched tables
+// in touched, keyed by migration ID. Tables in exclude, such as the history table, are left out.
+func recordTouchedTables(migrations []*Migration, touched map[string][]string, exclude ...string) []*Migration {
+	recorded := make([]*Migration, len(migrations))
+	for i, m := range migrations {
+		m := *m
+		migrate := m.Migrate
+		m.Migrate = func(tx *gorm.DB) error {
+			var tables []string
+			base := tx.Logger
+			if base == nil {
+				base = logger.Default
+			}
+			err := migrate(tx.Session(&gorm.Session{Logger: tableRecorder{Interface: base, mu: &sync.Mutex{}, tables: &tables}}))
+			touched[m.ID] = slices.DeleteFunc(tables, func(t string) bool { return slices.Contains(exclude, t) })
+			return err
+		}
+		recorded[i] = &m
+	}
+	return recorded
+}
+
+// saveTouchedTables stores the tables recorded by recordTouchedTables in the history rows of
+// the migrations. Migrations without a history row, e.g. rolled back with the transaction, are skipped.
+func saveTouchedTables(db *gorm.DB, opts Options, touched map[string][]string) error {
+	for id, tables := range touched {
+		err := db.Table(opts.TableName).Where(map[string]any{opts.IDColumnName: id}).
+			Update(touchedTablesColumn, strings.Join(tables, ",")).Error
+		if err != nil {
+			return fmt.Errorf("failed to record tables of migration %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package gormeasy
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// TestStatementTables tests which tables are reported for common statements
+func TestStatementTables(t *testing.T) {
+	cases := map[string][]string{
+		`CREATE TABLE IF NOT EXISTS "users" (id bigint)`:              {"users"},
+		"ALTER TABLE `orders` ADD COLUMN total bigint":                {"orders"},
+		`ALTER TABLE public.users RENAME TO accounts`:                 {"users", "accounts"},
+		`DROP TABLE IF EXISTS users, "orders" CASCADE`:                {"users", "orders"},
+		`CREATE UNIQUE INDEX CONCURRENTLY idx_email ON users (email)`: {"users"},
+		`INSERT INTO users (name) VALUES ('a')`:                       {"users"},
+		`UPDATE users SET name = 'b' WHERE id = 1`:                    {"users"},
+		`DELETE FROM sessions WHERE expired`:                          {"sessions"},
+		`TRUNCATE TABLE logs`:                                         {"logs"},
+		`RENAME TABLE a TO b`:                                         {"a", "b"},
+		`SELECT * FROM users`:                                         nil,
+	}
+	for stmt, expected := range cases {
+		if tables := statementTables(stmt); !slices.Equal(tables, expected) {
+			t.Errorf("Expected %v for %q, got %v", expected, stmt, tables)
+		}
+	}
+}
+
+// TestRecordTouchedTables tests that the tables written by a migration are recorded by ID
+func TestRecordTouchedTables(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	migrations := []*Migration{
+		{ID: "1", Migrate: func(tx *gorm.DB) error {
+			tx.Exec("CREATE TABLE users (id bigint)")
+			tx.Exec("INSERT INTO migrations (id) VALUES ('0')")
+			return tx.Exec("UPDATE users SET id = ?", 1).Error
+		}},
+	}
+
+	touched := make(map[string][]string)
+	recorded := recordTouchedTables(migrations, touched, "migrations")
+	if err := recorded[0].Migrate(db.Session(&gorm.Session{DryRun: true})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(touched["1"], []string{"users"}) {
+		t.Errorf("Expected [users] for migration 1, got %v", touched["1"])
+	}
+}
+
+// TestPrintHistory tests that history filtered by table lists only the migrations touching it
+func TestPrintHistory(t *testing.T) {
+	var buf bytes.Buffer
+	saved := out
+	out = &output{level: levelNormal, w: &buf, errW: &buf}
+	defer func() { out = saved }()
+
+	printHistory([]historyEntry{
+		{ID: "1"},
+		{ID: "2", Tables: []string{"users", "orders"}},
+		{ID: "3", Tables: []string{"orders"}},
+	}, "Users")
+
+	s := buf.String()
+	if !strings.Contains(s, "  - 2  -  users, orders\n") {
+		t.Errorf("Expected migration 2 to be listed, got %q", s)
+	}
+	if strings.Contains(s, "  - 3") || strings.Contains(s, "  - 1") {
+		t.Errorf("Expected only migration 2 to be listed, got %q", s)
+	}
+	if !strings.Contains(s, "1 migrations were applied before") {
+		t.Errorf("Expected a note about unrecorded migrations, got %q", s)
+	}
+}
@@ -0,0 +1,60 @@
+package gormeasy
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// tracerName is the instrumentation scope of the spans created by gormeasy.
+const tracerName = "github.com/ymzuiku/gormeasy"
+
+// tracer returns the tracer of opts.TracerProvider, or a tracer creating no spans without one.
+func tracer(opts Options) trace.Tracer {
+	if opts.TracerProvider == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return opts.TracerProvider.Tracer(tracerName)
+}
+
+// startSpan starts a span named name as a child of the span in the context of db, and
+// returns a session of db carrying the new span.
+func startSpan(db *gorm.DB, tr trace.Tracer, name string, attrs ...attribute.KeyValue) (*gorm.DB, trace.Span) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tr.Start(ctx, name, trace.WithAttributes(attrs...))
+	return db.WithContext(ctx), span
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceMigrations returns copies of migrations whose Migrate runs in a span with the
+// migration ID as attribute, a child of the span of the migration run.
+func traceMigrations(migrations []*Migration, tr trace.Tracer) []*Migration {
+	traced := make([]*Migration, len(migrations))
+	for i, m := range migrations {
+		m := *m
+		migrate := m.Migrate
+		m.Migrate = func(tx *gorm.DB) error {
+			tx, span := startSpan(tx, tr, "gormeasy.migration", attribute.String("gormeasy.migration.id", m.ID))
+			err := migrate(tx)
+			endSpan(span, err)
+			return err
+		}
+		traced[i] = &m
+	}
+	return traced
+}
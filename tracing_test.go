@@ -0,0 +1,81 @@
+package gormeasy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// recordedSpan is a span remembering its name, attributes and status.
+type recordedSpan struct {
+	trace.Span
+	name   string
+	attrs  []attribute.KeyValue
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, description string) { s.status = code }
+func (s *recordedSpan) End(options ...trace.SpanEndOption)            { s.ended = true }
+
+// recordingTracer is a tracer recording every span it starts.
+type recordingTracer struct {
+	embedded.Tracer
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{Span: noop.Span{}, name: name, attrs: config.Attributes()}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// TestTraceMigrations tests that every migration runs in a span with its ID and error
+func TestTraceMigrations(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	tr := &recordingTracer{}
+	var spanInMigration trace.Span
+	migrations := traceMigrations([]*Migration{
+		{ID: "1", Migrate: func(tx *gorm.DB) error {
+			spanInMigration = trace.SpanFromContext(tx.Statement.Context)
+			return nil
+		}},
+		{ID: "2", Migrate: func(tx *gorm.DB) error { return errors.New("boom") }},
+	}, tr)
+
+	migrations[0].Migrate(db)
+	if err := migrations[1].Migrate(db); err == nil {
+		t.Error("Expected the migration error to be returned")
+	}
+
+	if len(tr.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tr.spans))
+	}
+	if spanInMigration != tr.spans[0] {
+		t.Error("Expected the migration to receive the context of its span")
+	}
+	for i, id := range []string{"1", "2"} {
+		span := tr.spans[i]
+		if span.name != "gormeasy.migration" || !span.ended {
+			t.Errorf("Expected ended span gormeasy.migration, got %s (ended %v)", span.name, span.ended)
+		}
+		if len(span.attrs) != 1 || span.attrs[0] != attribute.String("gormeasy.migration.id", id) {
+			t.Errorf("Expected migration ID %s as attribute, got %v", id, span.attrs)
+		}
+	}
+	if tr.spans[0].status == codes.Error || tr.spans[1].status != codes.Error {
+		t.Errorf("Expected only the failed migration to have an error status, got %v and %v", tr.spans[0].status, tr.spans[1].status)
+	}
+}
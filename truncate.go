@@ -0,0 +1,203 @@
+package gormeasy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TruncateTables deletes all rows of tables, or of every table when tables is empty, keeping
+// the schema and the history tables of gormeasy, so integration tests can reset their data
+// without running the migrations again. Tables referencing a truncated table through a
+// foreign key must be truncated too; it returns an error naming them otherwise.
+// PostgreSQL truncates all tables in one TRUNCATE ... RESTART IDENTITY, MySQL truncates them
+// with foreign key checks disabled, and SQLite deletes the rows of referencing tables first.
+func TruncateTables(db *gorm.DB, opts Options, tables ...string) error {
+	plan, err := planTruncate(db, opts, tables)
+	if err != nil {
+		return err
+	}
+	if len(plan.referencing) > 0 {
+		return fmt.Errorf("tables %s reference the truncated tables, truncate them too", strings.Join(plan.referencing, ", "))
+	}
+	return truncate(db, plan.tables, plan.edges)
+}
+
+// truncatePlan is the set of tables truncated by the truncate command.
+type truncatePlan struct {
+	// tables are the selected tables.
+	tables []string
+	// referencing are the tables outside tables referencing one of them, transitively.
+	referencing []string
+	// edges maps each table to the tables referencing it.
+	edges map[string][]string
+}
+
+// planTruncate returns the plan truncating tables, or all tables but the gormeasy history
+// tables when tables is empty.
+func planTruncate(db *gorm.DB, opts Options, tables []string) (*truncatePlan, error) {
+	all, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	keep := historyTables(opts)
+	if len(tables) == 0 {
+		for _, table := range all {
+			if !slices.Contains(keep, table) {
+				tables = append(tables, table)
+			}
+		}
+	}
+	for _, table := range tables {
+		if !slices.Contains(all, table) {
+			return nil, fmt.Errorf("table %s does not exist", table)
+		}
+		if slices.Contains(keep, table) {
+			return nil, fmt.Errorf("table %s holds gormeasy history and is never truncated", table)
+		}
+	}
+
+	edges, err := referencingTables(db, all)
+	if err != nil {
+		return nil, err
+	}
+	plan := &truncatePlan{tables: slices.Clone(tables), edges: edges}
+	for i := 0; i < len(plan.tables); i++ {
+		for _, child := range edges[plan.tables[i]] {
+			if !slices.Contains(plan.tables, child) && !slices.Contains(plan.referencing, child) {
+				plan.referencing = append(plan.referencing, child)
+				plan.tables = append(plan.tables, child)
+			}
+		}
+	}
+	// Only the selected tables are truncated until the caller adds the referencing ones
+	plan.tables = plan.tables[:len(tables)]
+	return plan, nil
+}
+
+// historyTables returns the tables where gormeasy records migrations, data migrations,
+// maintenance runs, backfill checkpoints and the version stamp, which hold no application data.
+func historyTables(opts Options) []string {
+	opts = opts.withDefaults()
+	return []string{opts.TableName, opts.DataTableName, maintenanceRun{}.TableName(), backfillCheckpoint{}.TableName(), metadataTableName(opts)}
+}
+
+// withReferencing returns the plan's tables followed by the referencing tables, e.g. for --cascade.
+func (p *truncatePlan) withReferencing() []string {
+	return slices.Concat(p.tables, p.referencing)
+}
+
+// truncateOrder orders tables so that referencing tables come before the tables they reference.
+func truncateOrder(tables []string, edges map[string][]string) ([]string, error) {
+	deps := make(map[string][]string)
+	for _, parent := range tables {
+		for _, child := range edges[parent] {
+			if child != parent && slices.Contains(tables, child) {
+				deps[parent] = append(deps[parent], child)
+			}
+		}
+	}
+	return topologicalOrder("table", tables, deps)
+}
+
+// truncate deletes all rows of tables, which include every table referencing one of them
+// according to edges, the result of referencingTables.
+func truncate(db *gorm.DB, tables []string, edges map[string][]string) error {
+	if len(tables) == 0 {
+		out.Println("No tables to truncate")
+		return nil
+	}
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = db.Statement.Quote(table)
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		if err := db.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY").Error; err != nil {
+			return fmt.Errorf("failed to truncate tables: %w", err)
+		}
+	case "mysql":
+		// TRUNCATE refuses tables referenced by a foreign key even when the referencing table is empty
+		err := db.Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+			for _, table := range quoted {
+				if err := conn.Exec("TRUNCATE TABLE " + table).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to truncate tables: %w", err)
+		}
+	default:
+		ordered, err := truncateOrder(tables, edges)
+		if err != nil {
+			return err
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, table := range ordered {
+				if err := tx.Exec("DELETE FROM " + tx.Statement.Quote(table)).Error; err != nil {
+					return err
+				}
+			}
+			if db.Dialector.Name() == "sqlite" && tx.Migrator().HasTable("sqlite_sequence") {
+				return tx.Exec("DELETE FROM sqlite_sequence WHERE name IN ?", tables).Error
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to truncate tables: %w", err)
+		}
+	}
+	out.Printf("✅ Truncated %d tables: %s\n", len(tables), strings.Join(tables, ", "))
+	return nil
+}
+
+// foreignKeyRef is a foreign key of table Child referencing table Parent.
+type foreignKeyRef struct {
+	Child  string
+	Parent string
+}
+
+// referencingTables returns, for each of tables, the tables with a foreign key referencing it.
+func referencingTables(db *gorm.DB, tables []string) (map[string][]string, error) {
+	var refs []foreignKeyRef
+	var err error
+	switch db.Dialector.Name() {
+	case "postgres":
+		err = db.Raw(`SELECT c.relname AS child, p.relname AS parent FROM pg_constraint f
+			JOIN pg_class c ON c.oid = f.conrelid JOIN pg_class p ON p.oid = f.confrelid
+			WHERE f.contype = 'f' AND pg_table_is_visible(c.oid) AND pg_table_is_visible(p.oid)`).Scan(&refs).Error
+	case "mysql":
+		err = db.Raw(`SELECT TABLE_NAME AS child, REFERENCED_TABLE_NAME AS parent FROM information_schema.KEY_COLUMN_USAGE
+			WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL`).Scan(&refs).Error
+	case "sqlite":
+		for _, table := range tables {
+			var keys []struct{ Table string }
+			if err = db.Raw(fmt.Sprintf("PRAGMA foreign_key_list(%s)", db.Statement.Quote(table))).Scan(&keys).Error; err != nil {
+				break
+			}
+			for _, key := range keys {
+				refs = append(refs, foreignKeyRef{Child: table, Parent: key.Table})
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+
+	edges := make(map[string][]string)
+	for _, ref := range refs {
+		if !slices.Contains(edges[ref.Parent], ref.Child) {
+			edges[ref.Parent] = append(edges[ref.Parent], ref.Child)
+		}
+	}
+	return edges, nil
+}
@@ -0,0 +1,47 @@
+package gormeasy
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestTruncateOrder tests that referencing tables are truncated before the tables they reference
+func TestTruncateOrder(t *testing.T) {
+	edges := map[string][]string{
+		"users":  {"orders", "users"},
+		"orders": {"order_items"},
+	}
+	ordered, err := truncateOrder([]string{"users", "orders", "order_items", "tags"}, edges)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"order_items", "orders", "users", "tags"}
+	if !slices.Equal(ordered, expected) {
+		t.Errorf("Expected %v, got %v", expected, ordered)
+	}
+
+	edges["order_items"] = []string{"users"}
+	if _, err := truncateOrder([]string{"users", "orders", "order_items"}, edges); err == nil {
+		t.Error("Expected error for a foreign key cycle, got nil")
+	}
+}
+
+// TestPlanTruncateKeepsHistory tests that truncating all tables keeps the history and version stamp
+func TestPlanTruncateKeepsHistory(t *testing.T) {
+	db := openSQLite(t)
+	for _, table := range []string{"migrations", "migrations_meta", "users"} {
+		if err := db.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)").Error; err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	plan, err := planTruncate(db, Options{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(plan.tables, []string{"users"}) {
+		t.Errorf("Expected [users], got %v", plan.tables)
+	}
+	if _, err := planTruncate(db, Options{}, []string{"migrations_meta"}); err == nil {
+		t.Error("Expected error for truncating the version stamp, got nil")
+	}
+}
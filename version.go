@@ -0,0 +1,116 @@
+package gormeasy
+
+import (
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeatureVersion is the feature version of this gormeasy build. It is incremented whenever
+// gormeasy changes what it stores about migrations, so binaries can tell whether they
+// understand a history table. Version 2 added the applied_at history column and the
+// metadata table, version 3 the touched_tables history column, version 4 the duration_ms
+// history column.
+const FeatureVersion = 4
+
+const (
+	// metadataKeyMinVersion is the metadata key of the minimum feature version stamped by up.
+	metadataKeyMinVersion = "min_version"
+	// metadataKeyFeatureVersion is the metadata key of the feature version the history table
+	// was last upgraded to, stamped by every up.
+	metadataKeyFeatureVersion = "feature_version"
+)
+
+// metadataRow is a key/value row of the metadata table next to the history table.
+type metadataRow struct {
+	Key   string `gorm:"primaryKey;size:64"`
+	Value string `gorm:"size:255"`
+}
+
+// metadataTableName returns the table holding the metadata of the history table of opts.
+func metadataTableName(opts Options) string {
+	return opts.TableName + "_meta"
+}
+
+// stampedVersion returns the feature version stamped under key on the history table of opts,
+// or 0 when it was never stamped.
+func stampedVersion(db *gorm.DB, opts Options, key string) (int, error) {
+	table := metadataTableName(opts)
+	if !db.Migrator().HasTable(table) {
+		return 0, nil
+	}
+	var rows []metadataRow
+	if err := db.Table(table).Where(map[string]any{"key": key}).Find(&rows).Error; err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(rows[0].Value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s in %s: %q", key, table, rows[0].Value)
+	}
+	return version, nil
+}
+
+// stampedMinVersion returns the minimum feature version stamped on the history table of opts,
+// or 0 when it was never stamped.
+func stampedMinVersion(db *gorm.DB, opts Options) (int, error) {
+	return stampedVersion(db, opts, metadataKeyMinVersion)
+}
+
+// stampVersion records version under key on the metadata table of opts, creating the table.
+func stampVersion(db *gorm.DB, opts Options, key string, version int) error {
+	table := metadataTableName(opts)
+	if err := db.Table(table).AutoMigrate(&metadataRow{}); err != nil {
+		return fmt.Errorf("failed to create %s: %w", table, err)
+	}
+	row := metadataRow{Key: key, Value: strconv.Itoa(version)}
+	if err := db.Table(table).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error; err != nil {
+		return fmt.Errorf("failed to stamp %s: %w", table, err)
+	}
+	return nil
+}
+
+// checkFeatureVersion refuses to migrate when this binary is older than the minimum feature
+// version required by the project (Options.MinVersion) or stamped on the history table by a
+// newer binary, so mixed-version fleets don't write history rows older binaries don't understand.
+func checkFeatureVersion(db *gorm.DB, opts Options) error {
+	if opts.MinVersion > FeatureVersion {
+		return fmt.Errorf("this project requires gormeasy feature version %d, this binary has version %d: upgrade github.com/ymzuiku/gormeasy", opts.MinVersion, FeatureVersion)
+	}
+	stamped, err := stampedMinVersion(db, opts)
+	if err != nil {
+		return err
+	}
+	if stamped > FeatureVersion {
+		return fmt.Errorf("history table %s requires gormeasy feature version %d, this binary has version %d: upgrade github.com/ymzuiku/gormeasy before running up", opts.TableName, stamped, FeatureVersion)
+	}
+	return nil
+}
+
+// stampMinVersion records Options.MinVersion on the history table, so binaries older than
+// that version refuse to migrate the database even without the project config. The stamp
+// never decreases. Nothing is stamped when MinVersion is 0.
+func stampMinVersion(db *gorm.DB, opts Options) error {
+	if opts.MinVersion == 0 {
+		return nil
+	}
+	if stamped, err := stampedMinVersion(db, opts); err != nil || stamped >= opts.MinVersion {
+		return err
+	}
+	return withMigrationLock(db, opts, func(conn *gorm.DB) error {
+		// Another replica may have stamped a higher version while we waited for the lock
+		stamped, err := stampedMinVersion(conn, opts)
+		if err != nil || stamped >= opts.MinVersion {
+			return err
+		}
+		if err := stampVersion(conn, opts, metadataKeyMinVersion, opts.MinVersion); err != nil {
+			return err
+		}
+		out.Printf("✅ Stamped minimum gormeasy feature version %d on %s\n", opts.MinVersion, opts.TableName)
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+package gormeasy
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// TestCheckFeatureVersionRequired tests that binaries older than the project's minimum version refuse to migrate
+func TestCheckFeatureVersionRequired(t *testing.T) {
+	err := checkFeatureVersion(nil, Options{MinVersion: FeatureVersion + 1}.withDefaults())
+	if err == nil || !strings.Contains(err.Error(), "upgrade") {
+		t.Errorf("Expected an upgrade error, got %v", err)
+	}
+}
+
+// TestEnsureHistoryTableUpgradesOnce tests that an old history table is upgraded and stamped
+// with the feature version
+func TestEnsureHistoryTableUpgradesOnce(t *testing.T) {
+	db := openSQLite(t)
+	opts := Options{}.withDefaults()
+	if err := db.Exec("CREATE TABLE migrations (id varchar(255) PRIMARY KEY)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureHistoryTable(db, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasColumn(opts.TableName, durationColumn) {
+		t.Errorf("Expected the %s column to be added", durationColumn)
+	}
+	if stamped, err := stampedVersion(db, opts, metadataKeyFeatureVersion); err != nil || stamped != FeatureVersion {
+		t.Errorf("Expected feature version %d to be stamped, got %d, %v", FeatureVersion, stamped, err)
+	}
+
+	// Once stamped, the columns are not checked again
+	if err := db.Exec("ALTER TABLE migrations DROP COLUMN " + durationColumn).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureHistoryTable(db, opts); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasColumn(opts.TableName, durationColumn) {
+		t.Error("Expected a stamped history table not to be altered")
+	}
+}
+
+// TestHistoryModelColumns tests the columns of the history table
+func TestHistoryModelColumns(t *testing.T) {
+	opts := Options{IDColumnName: "version"}.withDefaults()
+	s, err := schema.Parse(historyModel(opts), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(s.DBNames) != 4 || s.DBNames[0] != "version" || s.DBNames[1] != "applied_at" || s.DBNames[2] != "touched_tables" || s.DBNames[3] != "duration_ms" {
+		t.Errorf("Expected columns [version applied_at touched_tables duration_ms], got %v", s.DBNames)
+	}
+	if !s.FieldsByDBName["version"].PrimaryKey {
+		t.Error("Expected version to be the primary key")
+	}
+	if s.FieldsByDBName["applied_at"].DefaultValue != "CURRENT_TIMESTAMP" {
+		t.Errorf("Expected applied_at to default to CURRENT_TIMESTAMP, got %q", s.FieldsByDBName["applied_at"].DefaultValue)
+	}
+}
+
+// TestMetadataTableName tests that the metadata table is named after the history table
+func TestMetadataTableName(t *testing.T) {
+	if name := metadataTableName(Options{TableName: "schema_migrations"}); name != "schema_migrations_meta" {
+		t.Errorf("Expected 'schema_migrations_meta', got '%s'", name)
+	}
+}
@@ -0,0 +1,46 @@
+package gormeasy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// waitForDatabase opens the database with open and runs a query, repeating every interval
+// until it succeeds or timeout has passed. Databases that accept connections but not yet
+// queries (e.g. PostgreSQL during recovery) are waited for as well.
+func waitForDatabase(open func() (*gorm.DB, error), timeout, interval time.Duration, clock Clock) error {
+	start := clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := pingDatabase(open, interval)
+		if err == nil {
+			out.Printf("✅ Database is ready (%s)\n", since(clock, start).Round(time.Millisecond))
+			return nil
+		}
+		if since(clock, start)+interval > timeout {
+			return fmt.Errorf("database not ready after %s: %w", timeout, err)
+		}
+		out.Printf("Waiting for database (attempt %d): %v\n", attempt, err)
+		sleep(interval)
+	}
+}
+
+// pingDatabase opens the database and runs SELECT 1, giving up after timeout.
+func pingDatabase(open func() (*gorm.DB, error), timeout time.Duration) error {
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), max(timeout, time.Second))
+	defer cancel()
+	var one int
+	return db.WithContext(ctx).Raw("SELECT 1").Row().Scan(&one)
+}
@@ -0,0 +1,38 @@
+package gormeasy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// steppingClock is a Clock advanced by the stubbed sleep.
+type steppingClock struct {
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	return c.now
+}
+
+// TestWaitForDatabaseTimeout tests that waiting gives up after the timeout
+func TestWaitForDatabaseTimeout(t *testing.T) {
+	fake := &steppingClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	sleep = func(d time.Duration) { fake.now = fake.now.Add(d) }
+	defer func() { sleep = time.Sleep }()
+
+	attempts := 0
+	err := waitForDatabase(func() (*gorm.DB, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	}, 10*time.Second, 2*time.Second, fake)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("Expected the last connection error, got %v", err)
+	}
+	if attempts != 6 {
+		t.Errorf("Expected 6 attempts (0s to 10s at 2s intervals), got %d", attempts)
+	}
+}